@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"github.com/ProtobufMan/bufman/internal/config"
+	"github.com/ProtobufMan/bufman/internal/constant"
+	"github.com/ProtobufMan/bufman/internal/core/logger"
+	"github.com/ProtobufMan/bufman/internal/dal"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"github.com/ProtobufMan/bufman/internal/services"
+)
+
+const batchSize = 100
+
+// 为历史commits记录依赖关系、生成文档，并为历史仓库建立符号索引
+// push之后生成失败的commit没有被标记为已生成，同样由这里补全
+func main() {
+	config.LoadConfig()
+
+	model.InitDB()
+
+	dal.SetDefault(config.DataBase)
+
+	repositoryService := services.NewRepositoryService()
+	docsService := services.NewDocsService()
+	backfillDependencies(repositoryService)
+	backfillPackageDocumentations(docsService)
+	backfillSymbols(docsService, repositoryService)
+}
+
+func backfillDependencies(repositoryService services.RepositoryService) {
+	var afterID int64
+	var recorded, failed int
+	for {
+		commits, err := repositoryService.ListCommitsWithoutDependencies(context.Background(), afterID, batchSize)
+		if err != nil {
+			panic(err)
+		}
+		if len(commits) == 0 {
+			break
+		}
+
+		for _, commit := range commits {
+			afterID = commit.ID
+			if remote, _ := commit.RemoteAndOwner(); remote != config.Properties.BufMan.ServerHost {
+				// 代理缓存的上游commit不记录依赖关系
+				continue
+			}
+
+			// 以commit的push用户身份解析依赖
+			ctx := context.WithValue(context.Background(), constant.UserIDKey, commit.UserID)
+			if err := repositoryService.RecordCommitDependencies(ctx, commit); err != nil {
+				logger.Errorf("Error record dependencies (commit %s): %v\n", commit.CommitName, err.Error())
+				failed++
+				continue
+			}
+			recorded++
+		}
+	}
+
+	logger.Infof("dependencies recorded for %d commits, %d failed\n", recorded, failed)
+}
+
+func backfillPackageDocumentations(docsService services.DocsService) {
+	var afterID int64
+	var generated, failed int
+	for {
+		commits, err := docsService.ListCommitsWithoutPackageDocumentation(context.Background(), afterID, batchSize)
+		if err != nil {
+			panic(err)
+		}
+		if len(commits) == 0 {
+			break
+		}
+
+		for _, commit := range commits {
+			afterID = commit.ID
+
+			// 以commit的push用户身份解析依赖
+			ctx := context.WithValue(context.Background(), constant.UserIDKey, commit.UserID)
+			if err := docsService.GeneratePackageDocumentations(ctx, commit); err != nil {
+				logger.Errorf("Error generate package documentations (commit %s): %v\n", commit.CommitName, err.Error())
+				failed++
+				continue
+			}
+			generated++
+		}
+	}
+
+	logger.Infof("package documentations generated for %d commits, %d failed\n", generated, failed)
+}
+
+func backfillSymbols(docsService services.DocsService, repositoryService services.RepositoryService) {
+	var indexed, failed int
+	for offset := 0; ; offset += batchSize {
+		repositories, err := repositoryService.ListRepositories(context.Background(), offset, batchSize, false)
+		if err != nil {
+			panic(err)
+		}
+		if len(repositories) == 0 {
+			break
+		}
+
+		for _, repository := range repositories {
+			if repository.Remote != "" {
+				// 代理缓存的上游仓库不建立符号索引
+				continue
+			}
+
+			// 以仓库所有者的身份解析依赖
+			ctx := context.WithValue(context.Background(), constant.UserIDKey, repository.UserID)
+			if err := docsService.IndexRepositorySymbols(ctx, repository.RepositoryID); err != nil {
+				logger.Errorf("Error index symbols (repository %s): %v\n", repository.RepositoryID, err.Error())
+				failed++
+				continue
+			}
+			indexed++
+		}
+	}
+
+	logger.Infof("symbols indexed for %d repositories, %d failed\n", indexed, failed)
+}
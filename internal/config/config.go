@@ -2,6 +2,7 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"github.com/ProtobufMan/bufman/internal/constant"
 	"github.com/ProtobufMan/bufman/internal/core/logger"
 	"github.com/docker/docker/client"
@@ -45,6 +46,32 @@ type BufMan struct {
 	PageTokenSecret     string        `mapstructure:"page_token_secret"`
 
 	UseFSStorage bool `mapstructure:"use_fs_storage"`
+
+	DependencyConflictStrategy string `mapstructure:"dependency_conflict_strategy"`
+
+	Upstreams []Upstream `mapstructure:"upstreams"`
+
+	CompileCacheSize     int64 `mapstructure:"compile_cache_size"`      // 编译结果缓存的容量，单位MB，为0时不使用缓存
+	ImageCacheSize       int64 `mapstructure:"image_cache_size"`        // image缓存的容量，单位MB，为0时不使用缓存
+	SchemaCacheSize      int64 `mapstructure:"schema_cache_size"`       // 导出的OpenAPI和JSON Schema缓存的容量，单位MB，为0时不使用缓存
+	BlameCacheSize       int64 `mapstructure:"blame_cache_size"`        // blame结果缓存的容量，单位MB，为0时不使用缓存
+	FileHistoryCacheSize int64 `mapstructure:"file_history_cache_size"` // 文件历史缓存的容量，单位MB，为0时不使用缓存
+}
+
+// Upstream 上游registry，依赖其他remote上的模块时通过上游下载并缓存到本地
+type Upstream struct {
+	Remote string `mapstructure:"remote"` // 上游的remote，例如 bufman.example.com
+	Url    string `mapstructure:"url"`    // 上游服务地址，例如 https://bufman.example.com
+	Token  string `mapstructure:"token"`  // 访问上游使用的token，可以为空
+
+	Timeout time.Duration    `mapstructure:"timeout"` // 访问上游的超时时间，为0时使用默认值
+	Access  []UpstreamAccess `mapstructure:"access"`  // 允许通过上游拉取模块的本地用户，未配置时拒绝所有用户
+}
+
+// UpstreamAccess 上游仓库的访问规则
+type UpstreamAccess struct {
+	Repository string   `mapstructure:"repository"` // 上游仓库，格式为 owner/name，支持 owner/* 以及 *
+	Users      []string `mapstructure:"users"`      // 允许访问的本地用户名，* 表示所有登录用户
 }
 
 type MySQL struct {
@@ -91,6 +118,14 @@ func LoadConfig() {
 			Port:                8080,
 			PageTokenExpireTime: time.Minute * 10, // 默认过期时间为10分钟
 			PageTokenSecret:     "123456",
+
+			DependencyConflictStrategy: constant.DependencyConflictStrategyFail,
+
+			CompileCacheSize:     256,
+			ImageCacheSize:       128,
+			SchemaCacheSize:      64,
+			BlameCacheSize:       32,
+			FileHistoryCacheSize: 16,
 		},
 		Docker: Docker{
 			Host:               client.DefaultDockerHost,
@@ -115,6 +150,11 @@ func LoadConfig() {
 	// 从环境变量中读取
 	loadFromENV()
 
+	// 校验配置
+	if err := validate(); err != nil {
+		panic(err)
+	}
+
 	// gin、logger设置level
 	gin.SetMode(Properties.BufMan.Mode)
 	err := logger.SetLevel(Properties.BufMan.Mode)
@@ -167,6 +207,22 @@ func loadFromENV() {
 	}
 }
 
+// validate 校验配置项的取值
+func validate() error {
+	switch Properties.BufMan.DependencyConflictStrategy {
+	case constant.DependencyConflictStrategyFail, constant.DependencyConflictStrategyNewest, constant.DependencyConflictStrategyRoot:
+	default:
+		return fmt.Errorf("unknown dependency_conflict_strategy %q, must be one of %q, %q, %q",
+			Properties.BufMan.DependencyConflictStrategy,
+			constant.DependencyConflictStrategyFail,
+			constant.DependencyConflictStrategyNewest,
+			constant.DependencyConflictStrategyRoot,
+		)
+	}
+
+	return nil
+}
+
 func NewDockerClient() (*client.Client, error) {
 	options := make([]client.Opt, 0, 4)
 	options = append(options, client.WithAPIVersionNegotiation())
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"github.com/ProtobufMan/bufman/internal/constant"
+	"testing"
+)
+
+func TestValidateDependencyConflictStrategy(t *testing.T) {
+	origin := Properties
+	defer func() { Properties = origin }()
+
+	for strategy, ok := range map[string]bool{
+		constant.DependencyConflictStrategyFail:   true,
+		constant.DependencyConflictStrategyNewest: true,
+		constant.DependencyConflictStrategyRoot:   true,
+		"":                                        false,
+		"Newest":                                  false,
+		"latest":                                  false,
+	} {
+		Properties = &Config{BufMan: BufMan{DependencyConflictStrategy: strategy}}
+		if err := validate(); (err == nil) != ok {
+			t.Errorf("validate(%q) = %v, want ok %v", strategy, err, ok)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package constant
 
+import "time"
+
 /*
 !! Warning
 !! Warning
@@ -16,6 +18,8 @@ const (
 	CommitLength  = 32
 	UserIDKey     = "user_id"
 	DefaultBranch = "main"
+
+	WarningHeader = "Bufman-Warning" // push等操作返回的警告信息
 )
 
 const (
@@ -62,3 +66,24 @@ const (
 	MaxQueryLength = 200
 	QueryPattern   = ".*"
 )
+
+// 依赖冲突处理策略
+const (
+	DependencyConflictStrategyFail   = "fail"   // 返回错误
+	DependencyConflictStrategyNewest = "newest" // 选取sequence id最大的commit
+	DependencyConflictStrategyRoot   = "root"   // 以根模块直接声明的依赖为准，其余冲突返回错误
+)
+
+// 上游代理
+const (
+	DefaultUpstreamTimeout = 30 * time.Second // 访问上游的默认超时时间
+	UpstreamAccessAll      = "*"              // 上游访问规则中匹配所有仓库或者所有登录用户
+)
+
+// push之后生成包文档以及符号索引的后台队列，队列满或者重试失败的commit由 cmd/docs_backfill 补全
+const (
+	DocsWorkers       = 4
+	DocsQueueSize     = 256
+	DocsMaxRetries    = 3
+	DocsRetryInterval = 5 * time.Second
+)
@@ -2,12 +2,15 @@ package controllers
 
 import (
 	"context"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufconfig"
 	"github.com/ProtobufMan/bufman-cli/private/gen/proto/connect/bufman/alpha/registry/v1alpha1/registryv1alpha1connect"
 	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
 	"github.com/ProtobufMan/bufman/internal/constant"
+	"github.com/ProtobufMan/bufman/internal/core/lock"
 	"github.com/ProtobufMan/bufman/internal/core/logger"
 	"github.com/ProtobufMan/bufman/internal/core/security"
 	"github.com/ProtobufMan/bufman/internal/core/validity"
+	"github.com/ProtobufMan/bufman/internal/dto"
 	"github.com/ProtobufMan/bufman/internal/e"
 	"github.com/ProtobufMan/bufman/internal/services"
 )
@@ -100,6 +103,32 @@ func (controller *CommitController) GetRepositoryCommitByReference(ctx context.C
 	return resp, nil
 }
 
+func (controller *CommitController) ResolveRepositoryReference(ctx context.Context, req *dto.ResolveRepositoryReferenceRequest) (*dto.ResolveRepositoryReferenceResponse, e.ResponseError) {
+	// 尝试获取user ID
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 验证用户权限
+	repository, permissionErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, "resolve repository reference")
+	if permissionErr != nil {
+		logger.Errorf("Error Check Permission: %v\n", permissionErr.Error())
+		return nil, permissionErr
+	}
+
+	// 查询
+	commit, kind, respErr := controller.commitService.ResolveRepositoryReference(ctx, repository.RepositoryID, req.Reference)
+	if respErr != nil {
+		logger.Errorf("Error resolve repository reference: %v\n", respErr.Error())
+		return nil, respErr
+	}
+
+	resp := &dto.ResolveRepositoryReferenceResponse{
+		ReferenceKind:    kind.String(),
+		RepositoryCommit: commit.ToProtoRepositoryCommit(),
+		Warnings:         commit.Warnings(),
+	}
+	return resp, nil
+}
+
 func (controller *CommitController) ListRepositoryDraftCommits(ctx context.Context, req *registryv1alpha1.ListRepositoryDraftCommitsRequest) (*registryv1alpha1.ListRepositoryDraftCommitsResponse, e.ResponseError) {
 	// 验证参数
 	argErr := controller.validator.CheckPageSize(req.GetPageSize())
@@ -171,3 +200,49 @@ func (controller *CommitController) DeleteRepositoryDraftCommit(ctx context.Cont
 	resp := &registryv1alpha1.DeleteRepositoryDraftCommitResponse{}
 	return resp, nil
 }
+
+func (controller *CommitController) VerifyLock(ctx context.Context, req *dto.VerifyLockRequest) (*dto.VerifyLockResponse, e.ResponseError) {
+	// 尝试获取user ID
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 解析buf.lock和buf.yaml
+	lockFile, err := lock.Parse([]byte(req.Lock))
+	if err != nil {
+		argErr := e.NewInvalidArgumentError(err.Error())
+		logger.Errorf("Error Check Args: %v\n", argErr.Error())
+		return nil, argErr
+	}
+	var bufConfig *bufconfig.Config
+	if req.Config != "" {
+		bufConfig, err = bufconfig.GetConfigForData(ctx, []byte(req.Config))
+		if err != nil {
+			argErr := e.NewInvalidArgumentError(err.Error())
+			logger.Errorf("Error Check Args: %v\n", argErr.Error())
+			return nil, argErr
+		}
+	}
+
+	report, respErr := controller.commitService.VerifyLock(ctx, userID, lockFile, bufConfig)
+	if respErr != nil {
+		logger.Errorf("Error verify lock: %v\n", respErr.Error())
+		return nil, respErr
+	}
+
+	resp := &dto.VerifyLockResponse{
+		Valid:   report.Valid(),
+		Pins:    make([]*dto.LockPinResult, 0, len(report.Pins)),
+		Missing: report.Missing,
+	}
+	for _, pinResult := range report.Pins {
+		resp.Pins = append(resp.Pins, &dto.LockPinResult{
+			Remote:     pinResult.Pin.Remote,
+			Owner:      pinResult.Pin.Owner,
+			Repository: pinResult.Pin.Repository,
+			Commit:     pinResult.Pin.Commit,
+			Digest:     pinResult.Pin.Digest,
+			Status:     pinResult.Status,
+			Message:    pinResult.Message,
+		})
+	}
+	return resp, nil
+}
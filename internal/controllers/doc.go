@@ -1,18 +1,31 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"github.com/ProtobufMan/bufman-cli/private/gen/proto/connect/bufman/alpha/registry/v1alpha1/registryv1alpha1connect"
 	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
 	"github.com/ProtobufMan/bufman/internal/constant"
+	"github.com/ProtobufMan/bufman/internal/core/archive"
+	"github.com/ProtobufMan/bufman/internal/core/docexport"
 	"github.com/ProtobufMan/bufman/internal/core/logger"
+	"github.com/ProtobufMan/bufman/internal/core/openapi"
+	"github.com/ProtobufMan/bufman/internal/core/security"
 	"github.com/ProtobufMan/bufman/internal/core/validity"
+	"github.com/ProtobufMan/bufman/internal/dto"
 	"github.com/ProtobufMan/bufman/internal/e"
 	"github.com/ProtobufMan/bufman/internal/services"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
 )
 
 type DocController struct {
 	docsService          services.DocsService
+	blameService         services.BlameService
+	fileHistoryService   services.FileHistoryService
 	authorizationService services.AuthorizationService
 	validator            validity.Validator
 }
@@ -20,6 +33,8 @@ type DocController struct {
 func NewDocController() *DocController {
 	return &DocController{
 		docsService:          services.NewDocsService(),
+		blameService:         services.NewBlameService(),
+		fileHistoryService:   services.NewFileHistoryService(),
 		authorizationService: services.NewAuthorizationService(),
 		validator:            validity.NewValidator(),
 	}
@@ -147,3 +162,382 @@ func (controller *DocController) GetPackageDocumentation(ctx context.Context, re
 	}
 	return resp, nil
 }
+
+func (controller *DocController) ExportDocumentation(ctx context.Context, req *dto.ExportDocumentationRequest) (*dto.ExportDocumentationResponse, e.ResponseError) {
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 验证参数
+	format := req.Format
+	if format == "" {
+		format = docexport.FormatMarkdown
+	}
+	if format != docexport.FormatMarkdown && format != docexport.FormatHTML {
+		argErr := e.NewInvalidArgumentError(fmt.Sprintf("format %s (must be markdown or html)", req.Format))
+		logger.Errorf("Error check: %v\n", argErr.Error())
+
+		return nil, argErr
+	}
+	archiveFormat := req.Archive
+	if archiveFormat == "" {
+		archiveFormat = archive.FormatZip
+	}
+	contentType, archiveErr := archive.ContentType(archiveFormat)
+	if archiveErr != nil {
+		argErr := e.NewInvalidArgumentError(fmt.Sprintf("archive %s (must be zip or tar.gz)", req.Archive))
+		logger.Errorf("Error check: %v\n", argErr.Error())
+
+		return nil, argErr
+	}
+
+	// 检查用户权限
+	repository, checkErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, "export documentation")
+	if checkErr != nil {
+		logger.Errorf("Error Check: %v\n", checkErr.Error())
+
+		return nil, checkErr
+	}
+
+	commit, packageDocumentations, respErr := controller.docsService.GetAllPackageDocumentations(ctx, repository.RepositoryID, req.Reference)
+	if respErr != nil {
+		logger.Errorf("Error get package docs: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	// 渲染文档
+	files, err := docexport.Export(docexport.Module{
+		Identity: commit.IdentityString(),
+		Commit:   commit.CommitName,
+	}, packageDocumentations, format)
+	if err != nil {
+		respErr = e.NewInternalError(err.Error())
+		logger.Errorf("Error export docs: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	// 所有文件放在同一个目录下
+	directory := fmt.Sprintf("%s-%s-%s", req.RepositoryOwner, req.RepositoryName, commit.CommitName)
+	for _, file := range files {
+		file.Path = directory + "/" + file.Path
+	}
+	buffer := &bytes.Buffer{}
+	if err = archive.Write(buffer, archiveFormat, files, commit.CreatedTime); err != nil {
+		respErr = e.NewInternalError(err.Error())
+		logger.Errorf("Error write archive: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	resp := &dto.ExportDocumentationResponse{
+		FileName:    directory + "." + archiveFormat,
+		ContentType: contentType,
+		Content:     buffer.Bytes(),
+	}
+	return resp, nil
+}
+
+func (controller *DocController) ExportSchema(ctx context.Context, req *dto.ExportSchemaRequest) (*dto.ExportSchemaResponse, e.ResponseError) {
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 验证参数
+	format := req.Format
+	if format == "" {
+		format = openapi.FormatOpenAPI
+	}
+	if format != openapi.FormatOpenAPI && format != openapi.FormatJSONSchema {
+		argErr := e.NewInvalidArgumentError(fmt.Sprintf("format %s (must be openapi or jsonschema)", req.Format))
+		logger.Errorf("Error check: %v\n", argErr.Error())
+
+		return nil, argErr
+	}
+
+	// 检查用户权限
+	repository, checkErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, "export schema")
+	if checkErr != nil {
+		logger.Errorf("Error Check: %v\n", checkErr.Error())
+
+		return nil, checkErr
+	}
+
+	commit, content, respErr := controller.docsService.ExportSchema(ctx, repository.RepositoryID, req.Reference, req.PackageName, format)
+	if respErr != nil {
+		logger.Errorf("Error export schema: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	resp := &dto.ExportSchemaResponse{
+		FileName: fmt.Sprintf("%s-%s.%s.json", req.PackageName, commit.CommitName, format),
+		Content:  content,
+	}
+	return resp, nil
+}
+
+func (controller *DocController) GetSourceDirectory(ctx context.Context, req *dto.GetSourceDirectoryRequest) (*registryv1alpha1.GetSourceDirectoryInfoResponse, e.ResponseError) {
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 检查用户权限
+	repository, checkErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, registryv1alpha1connect.DocServiceGetSourceDirectoryInfoProcedure)
+	if checkErr != nil {
+		logger.Errorf("Error Check: %v\n", checkErr.Error())
+
+		return nil, checkErr
+	}
+
+	// 获取目录结构信息
+	directoryInfo, respErr := controller.docsService.GetSourceDirectoryInfo(ctx, repository.RepositoryID, req.Reference)
+	if respErr != nil {
+		logger.Errorf("Error get source dir info: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	// 只返回请求的目录
+	directory, ok := directoryInfo.ToProtoDirectoryInfo(cleanSourcePath(req.Path))
+	if !ok {
+		respErr = e.NewNotFoundError(fmt.Sprintf("directory %s", req.Path))
+		logger.Errorf("Error get source dir info: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	resp := &registryv1alpha1.GetSourceDirectoryInfoResponse{
+		Root: directory,
+	}
+	return resp, nil
+}
+
+func (controller *DocController) DownloadSourceFile(ctx context.Context, req *dto.DownloadSourceFileRequest) (*dto.DownloadSourceFileResponse, e.ResponseError) {
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 检查用户权限
+	repository, checkErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, registryv1alpha1connect.DocServiceGetSourceFileProcedure)
+	if checkErr != nil {
+		logger.Errorf("Error Check: %v\n", checkErr.Error())
+
+		return nil, checkErr
+	}
+
+	// 获取源码内容
+	filePath := cleanSourcePath(req.Path)
+	content, respErr := controller.docsService.GetSourceFile(ctx, repository.RepositoryID, req.Reference, filePath)
+	if respErr != nil {
+		logger.Errorf("Error get source file: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	resp := &dto.DownloadSourceFileResponse{
+		FileName:    path.Base(filePath),
+		ContentType: sourceContentType(filePath, content),
+		Content:     content,
+	}
+	return resp, nil
+}
+
+func (controller *DocController) GetDefinition(ctx context.Context, req *dto.GetDefinitionRequest) (*dto.GetDefinitionResponse, e.ResponseError) {
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 验证参数
+	if req.Line < 0 || req.Column < 0 {
+		argErr := e.NewInvalidArgumentError(fmt.Sprintf("position %d:%d (must not be negative)", req.Line, req.Column))
+		logger.Errorf("Error check: %v\n", argErr.Error())
+
+		return nil, argErr
+	}
+
+	// 检查用户权限
+	repository, checkErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, registryv1alpha1connect.DocServiceGetSourceFileProcedure)
+	if checkErr != nil {
+		logger.Errorf("Error Check: %v\n", checkErr.Error())
+
+		return nil, checkErr
+	}
+
+	definition, respErr := controller.docsService.GetDefinition(ctx, repository.RepositoryID, req.Reference, cleanSourcePath(req.Path), req.Line, req.Column)
+	if respErr != nil {
+		logger.Errorf("Error get definition: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	resp := &dto.GetDefinitionResponse{
+		Kind:            definition.Kind,
+		Name:            definition.Name,
+		FullName:        definition.FullName,
+		PackageName:     definition.PackageName,
+		Remote:          definition.Remote,
+		RepositoryOwner: definition.Owner,
+		RepositoryName:  definition.Repository,
+		CommitName:      definition.Commit,
+		FilePath:        definition.FilePath,
+		Location: &dto.SymbolLocation{
+			StartLine:   definition.StartLine,
+			StartColumn: definition.StartColumn,
+			EndLine:     definition.EndLine,
+			EndColumn:   definition.EndColumn,
+		},
+	}
+	return resp, nil
+}
+
+func (controller *DocController) ListReferences(ctx context.Context, req *dto.ListReferencesRequest) (*dto.ListReferencesResponse, e.ResponseError) {
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 验证参数
+	symbol := strings.TrimPrefix(req.Symbol, ".")
+	if symbol == "" {
+		argErr := e.NewInvalidArgumentError("symbol (must not be empty)")
+		logger.Errorf("Error check: %v\n", argErr.Error())
+
+		return nil, argErr
+	}
+
+	// 检查用户权限
+	repository, checkErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, registryv1alpha1connect.DocServiceGetSourceFileProcedure)
+	if checkErr != nil {
+		logger.Errorf("Error Check: %v\n", checkErr.Error())
+
+		return nil, checkErr
+	}
+
+	references, respErr := controller.docsService.GetReferences(ctx, repository.RepositoryID, req.Reference, symbol)
+	if respErr != nil {
+		logger.Errorf("Error list references: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	resp := &dto.ListReferencesResponse{
+		References: make([]*dto.SymbolReference, 0, len(references)),
+	}
+	for _, reference := range references {
+		resp.References = append(resp.References, &dto.SymbolReference{
+			FilePath: reference.FilePath,
+			Location: &dto.SymbolLocation{
+				StartLine:   reference.StartLine,
+				StartColumn: reference.StartColumn,
+				EndLine:     reference.EndLine,
+				EndColumn:   reference.EndColumn,
+			},
+		})
+	}
+	return resp, nil
+}
+
+func (controller *DocController) GetBlame(ctx context.Context, req *dto.GetBlameRequest) (*dto.GetBlameResponse, e.ResponseError) {
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 检查用户权限
+	repository, checkErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, registryv1alpha1connect.DocServiceGetSourceFileProcedure)
+	if checkErr != nil {
+		logger.Errorf("Error Check: %v\n", checkErr.Error())
+
+		return nil, checkErr
+	}
+
+	filePath := cleanSourcePath(req.Path)
+	commit, lines, respErr := controller.blameService.GetBlame(ctx, repository.RepositoryID, req.Reference, filePath)
+	if respErr != nil {
+		logger.Errorf("Error get blame: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	resp := &dto.GetBlameResponse{
+		CommitName: commit.CommitName,
+		Path:       filePath,
+		Lines:      make([]*dto.BlameLine, 0, len(lines)),
+	}
+	for i, line := range lines {
+		resp.Lines = append(resp.Lines, &dto.BlameLine{
+			Line:        i + 1,
+			Content:     line.Content,
+			CommitName:  line.Commit.CommitName,
+			Author:      line.Commit.UserName,
+			CreatedTime: line.Commit.CreatedTime,
+		})
+	}
+	return resp, nil
+}
+
+func (controller *DocController) GetFileHistory(ctx context.Context, req *dto.GetFileHistoryRequest) (*dto.GetFileHistoryResponse, e.ResponseError) {
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 验证参数
+	argErr := controller.validator.CheckPageSize(req.PageSize)
+	if argErr != nil {
+		logger.Errorf("Error check: %v\n", argErr.Error())
+
+		return nil, argErr
+	}
+
+	// 检查用户权限
+	repository, checkErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, registryv1alpha1connect.DocServiceGetSourceFileProcedure)
+	if checkErr != nil {
+		logger.Errorf("Error Check: %v\n", checkErr.Error())
+
+		return nil, checkErr
+	}
+
+	// 解析page token
+	pageTokenChaim, err := security.ParsePageToken(req.PageToken)
+	if err != nil {
+		logger.Errorf("Error parse page token: %v\n", err.Error())
+
+		return nil, e.NewInvalidArgumentError("page token")
+	}
+
+	changes, respErr := controller.fileHistoryService.GetFileHistory(ctx, repository.RepositoryID, req.Reference, cleanSourcePath(req.Path), pageTokenChaim.PageOffset, int(req.PageSize), req.Reverse)
+	if respErr != nil {
+		logger.Errorf("Error get file history: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	// 生成下一页token
+	nextPageToken, err := security.GenerateNextPageToken(pageTokenChaim.PageOffset, int(req.PageSize), len(changes))
+	if err != nil {
+		logger.Errorf("Error generate next page token: %v\n", err.Error())
+
+		respErr := e.NewInternalError("generate next page token")
+		return nil, respErr
+	}
+
+	resp := &dto.GetFileHistoryResponse{
+		Changes:       make([]*dto.FileChange, 0, len(changes)),
+		NextPageToken: nextPageToken,
+	}
+	for _, change := range changes {
+		resp.Changes = append(resp.Changes, &dto.FileChange{
+			CommitName:  change.Commit.CommitName,
+			Author:      change.Commit.UserName,
+			CreatedTime: change.Commit.CreatedTime,
+			ChangeType:  change.ChangeType,
+			OldPath:     change.OldPath,
+			NewPath:     change.NewPath,
+			OldDigest:   change.OldDigest,
+			NewDigest:   change.NewDigest,
+		})
+	}
+	return resp, nil
+}
+
+// cleanSourcePath 将通配路由中的路径转换为模块中的相对路径，例如 /acme/payments/v1/../v1/payment.proto -> acme/payments/v1/payment.proto
+func cleanSourcePath(sourcePath string) string {
+	return strings.TrimPrefix(path.Clean("/"+sourcePath), "/")
+}
+
+// sourceContentType 根据扩展名判断文件的Content-Type，无法判断时根据内容判断
+func sourceContentType(filePath string, content []byte) string {
+	ext := path.Ext(filePath)
+	if ext == ".proto" {
+		return "text/x-protobuf; charset=utf-8"
+	}
+	if contentType := mime.TypeByExtension(ext); contentType != "" {
+		return contentType
+	}
+
+	return http.DetectContentType(content)
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"testing"
+)
+
+func TestCleanSourcePath(t *testing.T) {
+	tests := []struct {
+		sourcePath string
+		expected   string
+	}{
+		{sourcePath: "acme/weather/v1/weather.proto", expected: "acme/weather/v1/weather.proto"},
+		{sourcePath: "/acme/weather/v1/", expected: "acme/weather/v1"},
+		{sourcePath: "./acme//weather/./v1", expected: "acme/weather/v1"},
+		// .. 不能跳出模块的根目录
+		{sourcePath: "acme/../../weather.proto", expected: "weather.proto"},
+		{sourcePath: "../../etc/passwd", expected: "etc/passwd"},
+		{sourcePath: "", expected: ""},
+		{sourcePath: "/", expected: ""},
+	}
+	for _, test := range tests {
+		if actual := cleanSourcePath(test.sourcePath); actual != test.expected {
+			t.Errorf("cleanSourcePath(%q) = %q, want %q", test.sourcePath, actual, test.expected)
+		}
+	}
+}
+
+func TestSourceContentType(t *testing.T) {
+	tests := []struct {
+		filePath string
+		content  string
+		expected string
+	}{
+		{filePath: "acme/weather/v1/weather.proto", content: "syntax = \"proto3\";", expected: "text/x-protobuf; charset=utf-8"},
+		{filePath: "gen/openapi.json", content: "{}", expected: "application/json"},
+		// 无法根据扩展名判断时根据内容判断
+		{filePath: "LICENSE", content: "Apache License", expected: "text/plain; charset=utf-8"},
+		{filePath: "assets/logo", content: "\x89PNG\r\n\x1a\n", expected: "image/png"},
+	}
+	for _, test := range tests {
+		if actual := sourceContentType(test.filePath, []byte(test.content)); actual != test.expected {
+			t.Errorf("sourceContentType(%q) = %q, want %q", test.filePath, actual, test.expected)
+		}
+	}
+}
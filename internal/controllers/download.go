@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/ProtobufMan/bufman/internal/constant"
+	"github.com/ProtobufMan/bufman/internal/core/archive"
+	"github.com/ProtobufMan/bufman/internal/core/logger"
+	"github.com/ProtobufMan/bufman/internal/dto"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/ProtobufMan/bufman/internal/services"
+)
+
+type DownloadController struct {
+	downloadService      services.DownloadService
+	authorizationService services.AuthorizationService
+}
+
+func NewDownloadController() *DownloadController {
+	return &DownloadController{
+		downloadService:      services.NewDownloadService(),
+		authorizationService: services.NewAuthorizationService(),
+	}
+}
+
+func (controller *DownloadController) DownloadArchive(ctx context.Context, req *dto.DownloadArchiveRequest) (*dto.DownloadArchiveResponse, e.ResponseError) {
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 验证参数
+	format := req.Format
+	if format == "" {
+		format = archive.FormatTarGz
+	}
+	contentType, archiveErr := archive.ContentType(format)
+	if archiveErr != nil {
+		argErr := e.NewInvalidArgumentError(fmt.Sprintf("format %s (must be tar.gz or zip)", req.Format))
+		logger.Errorf("Error check: %v\n", argErr.Error())
+
+		return nil, argErr
+	}
+
+	// 检查用户权限
+	repository, checkErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, "download archive")
+	if checkErr != nil {
+		logger.Errorf("Error Check: %v\n", checkErr.Error())
+
+		return nil, checkErr
+	}
+
+	commit, files, respErr := controller.downloadService.DownloadArchiveFiles(ctx, repository.RepositoryID, req.Reference, req.IncludeDependencies)
+	if respErr != nil {
+		logger.Errorf("Error download archive files: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	// 所有文件放在同一个目录下
+	directory := fmt.Sprintf("%s-%s-%s", req.RepositoryOwner, req.RepositoryName, commit.CommitName)
+	for _, file := range files {
+		file.Path = directory + "/" + file.Path
+	}
+
+	resp := &dto.DownloadArchiveResponse{
+		FileName:    directory + "." + format,
+		Format:      format,
+		ContentType: contentType,
+		ModTime:     commit.CreatedTime,
+		Files:       files,
+	}
+	return resp, nil
+}
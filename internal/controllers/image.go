@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/ProtobufMan/bufman/internal/constant"
+	"github.com/ProtobufMan/bufman/internal/core/image"
+	"github.com/ProtobufMan/bufman/internal/core/logger"
+	"github.com/ProtobufMan/bufman/internal/dto"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/ProtobufMan/bufman/internal/services"
+)
+
+type ImageController struct {
+	imageService         services.ImageService
+	authorizationService services.AuthorizationService
+}
+
+func NewImageController() *ImageController {
+	return &ImageController{
+		imageService:         services.NewImageService(),
+		authorizationService: services.NewAuthorizationService(),
+	}
+}
+
+func (controller *ImageController) GetImage(ctx context.Context, req *dto.GetImageRequest) (*dto.GetImageResponse, e.ResponseError) {
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 验证参数
+	format, contentType, extension, argErr := imageFormat(req.Format)
+	if argErr != nil {
+		logger.Errorf("Error check: %v\n", argErr.Error())
+
+		return nil, argErr
+	}
+
+	// 检查用户权限
+	repository, checkErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, "get image")
+	if checkErr != nil {
+		logger.Errorf("Error Check: %v\n", checkErr.Error())
+
+		return nil, checkErr
+	}
+
+	options := &image.Options{
+		ExcludeImports:    req.ExcludeImports,
+		ExcludeSourceInfo: req.ExcludeSourceInfo,
+		Types:             req.Types,
+	}
+	commit, content, respErr := controller.imageService.GetImage(ctx, repository.RepositoryID, req.Reference, options, format)
+	if respErr != nil {
+		logger.Errorf("Error get image: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	resp := &dto.GetImageResponse{
+		FileName:    fmt.Sprintf("%s-%s-%s.%s", req.RepositoryOwner, req.RepositoryName, commit.CommitName, extension),
+		ContentType: contentType,
+		Content:     content,
+	}
+	return resp, nil
+}
+
+func (controller *ImageController) GetFileDescriptorSet(ctx context.Context, req *dto.GetFileDescriptorSetRequest) (*dto.GetImageResponse, e.ResponseError) {
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 验证参数
+	format, contentType, extension, argErr := imageFormat(req.Format)
+	if argErr == nil && len(req.Symbols) == 0 {
+		argErr = e.NewInvalidArgumentError("symbols (must not be empty)")
+	}
+	if argErr != nil {
+		logger.Errorf("Error check: %v\n", argErr.Error())
+
+		return nil, argErr
+	}
+	reference := req.Reference
+	if reference == "" {
+		reference = constant.DefaultBranch
+	}
+
+	// 检查用户权限
+	repository, checkErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, "get file descriptor set")
+	if checkErr != nil {
+		logger.Errorf("Error Check: %v\n", checkErr.Error())
+
+		return nil, checkErr
+	}
+
+	commit, content, respErr := controller.imageService.GetFileDescriptorSet(ctx, repository.RepositoryID, reference, req.Symbols, format)
+	if respErr != nil {
+		logger.Errorf("Error get file descriptor set: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	resp := &dto.GetImageResponse{
+		FileName:    fmt.Sprintf("%s-%s-%s.%s", req.RepositoryOwner, req.RepositoryName, commit.CommitName, extension),
+		ContentType: contentType,
+		Content:     content,
+	}
+	return resp, nil
+}
+
+// imageFormat 校验image的输出格式，为空时使用binary
+func imageFormat(format string) (string, string, string, e.ResponseError) {
+	switch format {
+	case "", image.FormatBinary:
+		return image.FormatBinary, "application/octet-stream", "binpb", nil
+	case image.FormatJSON:
+		return image.FormatJSON, "application/json", "json", nil
+	}
+
+	return "", "", "", e.NewInvalidArgumentError(fmt.Sprintf("format %s (must be binary or json)", format))
+}
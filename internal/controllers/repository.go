@@ -2,12 +2,14 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"github.com/ProtobufMan/bufman-cli/private/gen/proto/connect/bufman/alpha/registry/v1alpha1/registryv1alpha1connect"
 	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
 	"github.com/ProtobufMan/bufman/internal/constant"
 	"github.com/ProtobufMan/bufman/internal/core/logger"
 	"github.com/ProtobufMan/bufman/internal/core/security"
 	"github.com/ProtobufMan/bufman/internal/core/validity"
+	"github.com/ProtobufMan/bufman/internal/dto"
 	"github.com/ProtobufMan/bufman/internal/e"
 	"github.com/ProtobufMan/bufman/internal/services"
 )
@@ -393,3 +395,343 @@ func (controller *RepositoryController) GetRepositoriesByFullName(ctx context.Co
 
 	return resp, nil
 }
+
+func (controller *RepositoryController) ForkRepository(ctx context.Context, req *dto.ForkRepositoryRequest) (*dto.ForkRepositoryResponse, e.ResponseError) {
+	// 获取用户ID
+	userID, ok := ctx.Value(constant.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, e.NewUnauthenticatedError("fork repository")
+	}
+
+	// 验证参数
+	repositoryName := req.Name
+	if repositoryName == "" {
+		repositoryName = req.RepositoryName
+	}
+	argErr := controller.validator.CheckRepositoryName(repositoryName)
+	if argErr != nil {
+		logger.Errorf("Error check: %v", argErr.Error())
+
+		return nil, argErr
+	}
+
+	// 验证用户是否可以访问上游仓库
+	upstream, permissionErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, "fork repository")
+	if permissionErr != nil {
+		logger.Errorf("Error check permission: %v", permissionErr.Error())
+
+		return nil, permissionErr
+	}
+
+	// fork
+	repository, err := controller.repositoryService.ForkRepository(ctx, userID, upstream, repositoryName)
+	if err != nil {
+		logger.Errorf("Error fork repo: %v", err.Error())
+
+		return nil, err
+	}
+
+	resp := &dto.ForkRepositoryResponse{
+		Repository: repository.ToProtoRepository(),
+	}
+	return resp, nil
+}
+
+func (controller *RepositoryController) CompareRepositoryWithUpstream(ctx context.Context, req *dto.CompareRepositoryWithUpstreamRequest) (*dto.CompareRepositoryWithUpstreamResponse, e.ResponseError) {
+	// 尝试获取user ID
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 验证用户权限
+	repository, permissionErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, "compare repository with upstream")
+	if permissionErr != nil {
+		logger.Errorf("Error check permission: %v", permissionErr.Error())
+
+		return nil, permissionErr
+	}
+	if repository.ForkedFromRepositoryID == "" {
+		argErr := e.NewInvalidArgumentError("repository (not a fork)")
+		logger.Errorf("Error check: %v", argErr.Error())
+
+		return nil, argErr
+	}
+
+	// 上游仓库也需要有访问权限
+	upstream, permissionErr := controller.authorizationService.CheckRepositoryCanAccessByID(userID, repository.ForkedFromRepositoryID, "compare repository with upstream")
+	if permissionErr != nil {
+		logger.Errorf("Error check permission: %v", permissionErr.Error())
+
+		return nil, permissionErr
+	}
+
+	ahead, behind, err := controller.repositoryService.CompareRepositoryWithUpstream(ctx, repository, upstream)
+	if err != nil {
+		logger.Errorf("Error compare repo with upstream: %v", err.Error())
+
+		return nil, err
+	}
+
+	resp := &dto.CompareRepositoryWithUpstreamResponse{
+		Upstream:      upstream.ToProtoRepository(),
+		AheadBy:       len(ahead),
+		BehindBy:      len(behind),
+		AheadCommits:  ahead.ToProtoRepositoryCommits(),
+		BehindCommits: behind.ToProtoRepositoryCommits(),
+	}
+	return resp, nil
+}
+
+func (controller *RepositoryController) RenameRepository(ctx context.Context, req *dto.RenameRepositoryRequest) (*dto.RenameRepositoryResponse, e.ResponseError) {
+	// 获取用户ID
+	userID, ok := ctx.Value(constant.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, e.NewUnauthenticatedError("rename repository")
+	}
+
+	// 验证参数
+	argErr := controller.validator.CheckRepositoryName(req.NewName)
+	if argErr != nil {
+		logger.Errorf("Error check: %v", argErr.Error())
+
+		return nil, argErr
+	}
+
+	// 验证用户权限
+	repository, permissionErr := controller.authorizationService.CheckRepositoryCanEdit(userID, req.RepositoryOwner, req.RepositoryName, "rename repository")
+	if permissionErr != nil {
+		logger.Errorf("Error check permission: %v", permissionErr.Error())
+
+		return nil, permissionErr
+	}
+
+	updatedRepository, err := controller.repositoryService.RenameRepository(ctx, repository, req.NewName)
+	if err != nil {
+		logger.Errorf("Error rename repo: %v", err.Error())
+
+		return nil, err
+	}
+
+	resp := &dto.RenameRepositoryResponse{
+		Repository: updatedRepository.ToProtoRepository(),
+	}
+	return resp, nil
+}
+
+func (controller *RepositoryController) TransferRepository(ctx context.Context, req *dto.TransferRepositoryRequest) (*dto.TransferRepositoryResponse, e.ResponseError) {
+	// 获取用户ID
+	userID, ok := ctx.Value(constant.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, e.NewUnauthenticatedError("transfer repository")
+	}
+
+	// 验证参数
+	argErr := controller.validator.CheckUserName(req.NewOwner)
+	if argErr != nil {
+		logger.Errorf("Error check: %v", argErr.Error())
+
+		return nil, argErr
+	}
+
+	// 只有仓库所属用户可以转移
+	repository, permissionErr := controller.authorizationService.CheckRepositoryCanDelete(userID, req.RepositoryOwner, req.RepositoryName, "transfer repository")
+	if permissionErr != nil {
+		logger.Errorf("Error check permission: %v", permissionErr.Error())
+
+		return nil, permissionErr
+	}
+
+	transfer, err := controller.repositoryService.TransferRepository(ctx, repository, req.NewOwner)
+	if err != nil {
+		logger.Errorf("Error transfer repo: %v", err.Error())
+
+		return nil, err
+	}
+
+	resp := &dto.TransferRepositoryResponse{
+		Repository:   repository.ToProtoRepository(),
+		PendingOwner: transfer.ToUserName,
+	}
+	return resp, nil
+}
+
+func (controller *RepositoryController) AcceptRepositoryTransfer(ctx context.Context, req *dto.AcceptRepositoryTransferRequest) (*dto.AcceptRepositoryTransferResponse, e.ResponseError) {
+	// 获取用户ID
+	userID, ok := ctx.Value(constant.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, e.NewUnauthenticatedError("accept repository transfer")
+	}
+
+	// 查询仓库，是否可以接受由转移记录决定
+	repository, err := controller.repositoryService.GetRepositoryByUserNameAndRepositoryName(ctx, req.RepositoryOwner, req.RepositoryName)
+	if err != nil {
+		logger.Errorf("Error get repo: %v", err.Error())
+
+		return nil, err
+	}
+
+	updatedRepository, err := controller.repositoryService.AcceptRepositoryTransfer(ctx, userID, repository)
+	if err != nil {
+		logger.Errorf("Error accept repo transfer: %v", err.Error())
+
+		return nil, err
+	}
+
+	resp := &dto.AcceptRepositoryTransferResponse{
+		Repository: updatedRepository.ToProtoRepository(),
+	}
+	return resp, nil
+}
+
+func (controller *RepositoryController) ListRepositoryDependents(ctx context.Context, req *dto.ListRepositoryDependentsRequest) (*dto.ListRepositoryDependentsResponse, e.ResponseError) {
+	// 尝试获取user ID
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 验证参数
+	argErr := controller.validator.CheckPageSize(req.PageSize)
+	if argErr != nil {
+		logger.Errorf("Error check: %v\n", argErr.Error())
+
+		return nil, argErr
+	}
+
+	// 验证用户权限
+	repository, permissionErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, "list repository dependents")
+	if permissionErr != nil {
+		logger.Errorf("Error check permission: %v\n", permissionErr.Error())
+
+		return nil, permissionErr
+	}
+
+	// 解析page token
+	pageTokenChaim, err := security.ParsePageToken(req.PageToken)
+	if err != nil {
+		logger.Errorf("Error parse page token: %v\n", err.Error())
+
+		respErr := e.NewInvalidArgumentError("page token")
+		return nil, respErr
+	}
+
+	dependents, listErr := controller.repositoryService.ListRepositoryDependents(ctx, userID, repository, req.Reference, req.Transitive, pageTokenChaim.PageOffset, int(req.PageSize))
+	if listErr != nil {
+		logger.Errorf("Error list repo dependents: %v\n", listErr.Error())
+
+		return nil, listErr
+	}
+
+	// 生成下一页token
+	nextPageToken, err := security.GenerateNextPageToken(pageTokenChaim.PageOffset, int(req.PageSize), len(dependents))
+	if err != nil {
+		logger.Errorf("Error generate next page token: %v\n", err.Error())
+
+		respErr := e.NewInternalError("generate next page token")
+		return nil, respErr
+	}
+
+	resp := &dto.ListRepositoryDependentsResponse{
+		Dependents:    make([]*dto.RepositoryDependent, 0, len(dependents)),
+		NextPageToken: nextPageToken,
+	}
+	for i := 0; i < len(dependents); i++ {
+		resp.Dependents = append(resp.Dependents, &dto.RepositoryDependent{
+			Repository:  dependents[i].Repository.ToProtoRepository(),
+			Depth:       dependents[i].Depth,
+			CommitNames: dependents[i].CommitNames,
+		})
+	}
+	return resp, nil
+}
+
+// 依赖图的输出格式
+const (
+	dependencyGraphFormatJSON    = "json"
+	dependencyGraphFormatDOT     = "dot"
+	dependencyGraphFormatMermaid = "mermaid"
+)
+
+func (controller *RepositoryController) GetDependencyGraph(ctx context.Context, req *dto.GetDependencyGraphRequest) (*dto.GetDependencyGraphResponse, e.ResponseError) {
+	// 尝试获取user ID
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 验证参数
+	format := req.Format
+	if format == "" {
+		format = dependencyGraphFormatJSON
+	}
+	if format != dependencyGraphFormatJSON && format != dependencyGraphFormatDOT && format != dependencyGraphFormatMermaid {
+		argErr := e.NewInvalidArgumentError(fmt.Sprintf("format %s (must be json, dot or mermaid)", req.Format))
+		logger.Errorf("Error check: %v\n", argErr.Error())
+
+		return nil, argErr
+	}
+
+	// 验证用户权限
+	repository, permissionErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, "get dependency graph")
+	if permissionErr != nil {
+		logger.Errorf("Error check permission: %v\n", permissionErr.Error())
+
+		return nil, permissionErr
+	}
+
+	graph, err := controller.repositoryService.GetDependencyGraph(ctx, repository, req.Reference)
+	if err != nil {
+		logger.Errorf("Error get dependency graph: %v\n", err.Error())
+
+		return nil, err
+	}
+
+	resp := &dto.GetDependencyGraphResponse{
+		Format: format,
+		Root:   graph.Root,
+	}
+	switch format {
+	case dependencyGraphFormatDOT:
+		resp.Content = graph.DOT()
+	case dependencyGraphFormatMermaid:
+		resp.Content = graph.Mermaid()
+	default:
+		resp.Nodes = make([]*dto.DependencyGraphNode, 0, len(graph.Nodes))
+		for _, node := range graph.Nodes {
+			resp.Nodes = append(resp.Nodes, &dto.DependencyGraphNode{
+				Identity:   node.Identity,
+				CommitName: node.Commit.CommitName,
+				Tags:       node.Tags,
+			})
+		}
+		resp.Edges = make([]*dto.DependencyGraphEdge, 0, len(graph.Edges))
+		for _, edge := range graph.Edges {
+			resp.Edges = append(resp.Edges, &dto.DependencyGraphEdge{
+				From: edge.From,
+				To:   edge.To,
+			})
+		}
+	}
+	return resp, nil
+}
+
+func (controller *RepositoryController) UpdateRepositoryDependencyPolicy(ctx context.Context, req *dto.UpdateRepositoryDependencyPolicyRequest) (*dto.UpdateRepositoryDependencyPolicyResponse, e.ResponseError) {
+	// 获取用户ID
+	userID, ok := ctx.Value(constant.UserIDKey).(string)
+	if !ok || userID == "" {
+		return nil, e.NewUnauthenticatedError("update repository dependency policy")
+	}
+
+	// 验证用户权限
+	repository, permissionErr := controller.authorizationService.CheckRepositoryCanEdit(userID, req.RepositoryOwner, req.RepositoryName, "update repository dependency policy")
+	if permissionErr != nil {
+		logger.Errorf("Error check permission: %v", permissionErr.Error())
+
+		return nil, permissionErr
+	}
+
+	err := controller.repositoryService.UpdateRepositoryDependencyPolicy(ctx, repository, req.StrictDependencies)
+	if err != nil {
+		logger.Errorf("Error update repo dependency policy: %v", err.Error())
+
+		return nil, err
+	}
+	repository.StrictDependencies = req.StrictDependencies
+
+	resp := &dto.UpdateRepositoryDependencyPolicyResponse{
+		Repository: repository.ToProtoRepository(),
+	}
+	return resp, nil
+}
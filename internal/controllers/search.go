@@ -8,6 +8,7 @@ import (
 	"github.com/ProtobufMan/bufman/internal/core/logger"
 	"github.com/ProtobufMan/bufman/internal/core/security"
 	"github.com/ProtobufMan/bufman/internal/core/validity"
+	"github.com/ProtobufMan/bufman/internal/dto"
 	"github.com/ProtobufMan/bufman/internal/e"
 	"github.com/ProtobufMan/bufman/internal/services"
 )
@@ -335,3 +336,86 @@ func (controller *SearchController) SearchDraft(ctx context.Context, req *regist
 
 	return resp, nil
 }
+
+func (controller *SearchController) SearchSymbol(ctx context.Context, req *dto.SearchSymbolRequest) (*dto.SearchSymbolResponse, e.ResponseError) {
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 验证参数
+	argErr := controller.validator.CheckPageSize(req.PageSize)
+	if argErr != nil {
+		logger.Errorf("Error check: %v\n", argErr.Error())
+
+		return nil, argErr
+	}
+	argErr = controller.validator.CheckQuery(req.Query)
+	if argErr != nil {
+		logger.Errorf("Error check: %v\n", argErr.Error())
+
+		return nil, argErr
+	}
+
+	// 限定仓库时查询权限
+	var repositoryID string
+	if req.RepositoryOwner != "" || req.RepositoryName != "" {
+		repository, checkErr := controller.authorizationService.CheckRepositoryCanAccess(userID, req.RepositoryOwner, req.RepositoryName, "search symbol")
+		if checkErr != nil {
+			logger.Errorf("Error check: %v\n", checkErr.Error())
+
+			return nil, checkErr
+		}
+		repositoryID = repository.RepositoryID
+	}
+
+	// 解析page token
+	pageTokenChaim, err := security.ParsePageToken(req.PageToken)
+	if err != nil {
+		logger.Errorf("Error parse page token: %v\n", err.Error())
+
+		return nil, e.NewInvalidArgumentError("page token")
+	}
+
+	// 查询结果
+	symbols, repositories, respErr := controller.searchService.SearchSymbol(ctx, userID, req.Query, req.Kind, req.PackageName, repositoryID, pageTokenChaim.PageOffset, int(req.PageSize), req.Reverse)
+	if respErr != nil {
+		logger.Errorf("Error search symbol: %v\n", respErr.Error())
+
+		return nil, respErr
+	}
+
+	// 生成下一页token
+	nextPageToken, err := security.GenerateNextPageToken(pageTokenChaim.PageOffset, int(req.PageSize), len(symbols))
+	if err != nil {
+		logger.Errorf("Error generate next page token: %v\n", err.Error())
+
+		respErr := e.NewInternalError("generate next page token")
+		return nil, respErr
+	}
+
+	resp := &dto.SearchSymbolResponse{
+		Symbols:       make([]*dto.SymbolSearchResult, 0, len(symbols)),
+		NextPageToken: nextPageToken,
+	}
+	for _, symbol := range symbols {
+		result := &dto.SymbolSearchResult{
+			Kind:        symbol.Kind,
+			Name:        symbol.Name,
+			FullName:    symbol.FullName,
+			PackageName: symbol.PackageName,
+			CommitName:  symbol.CommitName,
+			FilePath:    symbol.FilePath,
+			Location: &dto.SymbolLocation{
+				StartLine:   symbol.StartLine,
+				StartColumn: symbol.StartColumn,
+				EndLine:     symbol.EndLine,
+				EndColumn:   symbol.EndColumn,
+			},
+		}
+		if repository, ok := repositories[symbol.RepositoryID]; ok {
+			result.RepositoryOwner = repository.UserName
+			result.RepositoryName = repository.RepositoryName
+		}
+		resp.Symbols = append(resp.Symbols, result)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,109 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"time"
+)
+
+// 支持的压缩包格式
+const (
+	FormatZip   = "zip"
+	FormatTarGz = "tar.gz"
+)
+
+var ErrUnknownFormat = errors.New("unknown archive format")
+
+// File 压缩包中的文件，设置了Open时在写入该文件时才读取内容，同一时间只有一个文件的内容在内存中
+type File struct {
+	Path    string
+	Content []byte
+	Open    func() ([]byte, error)
+}
+
+func (file *File) read() ([]byte, error) {
+	if file.Open == nil {
+		return file.Content, nil
+	}
+
+	return file.Open()
+}
+
+// ContentType 压缩包格式对应的Content-Type
+func ContentType(format string) (string, error) {
+	switch format {
+	case FormatZip:
+		return "application/zip", nil
+	case FormatTarGz:
+		return "application/gzip", nil
+	}
+
+	return "", ErrUnknownFormat
+}
+
+// Write 将文件按照format打包写入w，文件的修改时间统一为modTime
+func Write(w io.Writer, format string, files []*File, modTime time.Time) error {
+	switch format {
+	case FormatZip:
+		return writeZip(w, files, modTime)
+	case FormatTarGz:
+		return writeTarGz(w, files, modTime)
+	}
+
+	return ErrUnknownFormat
+}
+
+func writeZip(w io.Writer, files []*File, modTime time.Time) error {
+	zipWriter := zip.NewWriter(w)
+	for _, file := range files {
+		content, err := file.read()
+		if err != nil {
+			return err
+		}
+		fileWriter, err := zipWriter.CreateHeader(&zip.FileHeader{
+			Name:     file.Path,
+			Method:   zip.Deflate,
+			Modified: modTime,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = fileWriter.Write(content); err != nil {
+			return err
+		}
+	}
+
+	return zipWriter.Close()
+}
+
+func writeTarGz(w io.Writer, files []*File, modTime time.Time) error {
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, file := range files {
+		content, err := file.read()
+		if err != nil {
+			return err
+		}
+		err = tarWriter.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     file.Path,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			ModTime:  modTime,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err = tarWriter.Write(content); err != nil {
+			return err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+
+	return gzipWriter.Close()
+}
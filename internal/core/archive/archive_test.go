@@ -0,0 +1,136 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+var testFiles = []*File{
+	{Path: "index.md", Content: []byte("# index\n")},
+	{Path: "acme/weather/v1.md", Content: []byte("# acme.weather.v1\n")},
+}
+
+func TestWriteZip(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	if err := Write(buffer, FormatZip, testFiles, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	zipReader, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zipReader.File) != len(testFiles) {
+		t.Fatalf("expected %d files, got %d", len(testFiles), len(zipReader.File))
+	}
+	for i, zipFile := range zipReader.File {
+		reader, err := zipFile.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(reader)
+		_ = reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if zipFile.Name != testFiles[i].Path || !bytes.Equal(content, testFiles[i].Content) {
+			t.Errorf("unexpected file %s: %q", zipFile.Name, content)
+		}
+	}
+}
+
+func TestWriteTarGz(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	if err := Write(buffer, FormatTarGz, testFiles, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	gzipReader, err := gzip.NewReader(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+	for i := 0; ; i++ {
+		header, err := tarReader.Next()
+		if errors.Is(err, io.EOF) {
+			if i != len(testFiles) {
+				t.Fatalf("expected %d files, got %d", len(testFiles), i)
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Name != testFiles[i].Path || !bytes.Equal(content, testFiles[i].Content) {
+			t.Errorf("unexpected file %s: %q", header.Name, content)
+		}
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(io.Discard, "rar", testFiles, time.Now()); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+	if _, err := ContentType("rar"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestWriteOpen(t *testing.T) {
+	// 按照写入顺序读取文件内容，读取失败时停止写入
+	var opened []string
+	files := make([]*File, 0, len(testFiles)+1)
+	for _, testFile := range testFiles {
+		testFile := testFile
+		files = append(files, &File{Path: testFile.Path, Open: func() ([]byte, error) {
+			opened = append(opened, testFile.Path)
+			return testFile.Content, nil
+		}})
+	}
+	readErr := errors.New("read failed")
+	files = append(files, &File{Path: "broken.md", Open: func() ([]byte, error) {
+		return nil, readErr
+	}})
+
+	for _, format := range []string{FormatZip, FormatTarGz} {
+		opened = nil
+		if err := Write(io.Discard, format, files, time.Now()); !errors.Is(err, readErr) {
+			t.Errorf("expected read error for %s, got %v", format, err)
+		}
+		if len(opened) != len(testFiles) || opened[0] != testFiles[0].Path || opened[1] != testFiles[1].Path {
+			t.Errorf("unexpected opened files for %s: %v", format, opened)
+		}
+	}
+
+	buffer := &bytes.Buffer{}
+	if err := Write(buffer, FormatTarGz, files[:len(testFiles)], time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	gzipReader, err := gzip.NewReader(buffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+	for _, testFile := range testFiles {
+		if _, err = tarReader.Next(); err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(content, testFile.Content) {
+			t.Errorf("unexpected content of %s: %q", testFile.Path, content)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package blame
+
+import (
+	"strings"
+)
+
+// maxEditDistance 两个版本差异超过该值时不再计算diff，直接视为整个文件重写，避免占用过多内存
+const maxEditDistance = 1000
+
+// Line 文件中的一行以及最后修改它的版本
+type Line struct {
+	Content  string
+	Revision int
+}
+
+// Blamer 按照从旧到新的顺序依次加入文件的每个版本，计算每一行最后修改的版本
+type Blamer struct {
+	lines []*Line
+}
+
+func NewBlamer() *Blamer {
+	return &Blamer{}
+}
+
+// Apply 加入文件新的版本，content为nil表示文件在该版本中被删除
+func (blamer *Blamer) Apply(revision int, content []byte) {
+	if content == nil {
+		blamer.lines = nil
+		return
+	}
+
+	newLines := SplitLines(string(content))
+	oldContents := make([]string, len(blamer.lines))
+	for i, line := range blamer.lines {
+		oldContents[i] = line.Content
+	}
+
+	matches := matchLines(oldContents, newLines)
+	lines := make([]*Line, len(newLines))
+	for i, content := range newLines {
+		if matches[i] >= 0 {
+			lines[i] = blamer.lines[matches[i]]
+		} else {
+			lines[i] = &Line{Content: content, Revision: revision}
+		}
+	}
+	blamer.lines = lines
+}
+
+// Lines 当前版本的所有行
+func (blamer *Blamer) Lines() []*Line {
+	return blamer.lines
+}
+
+// SplitLines 按行拆分，最后一个换行符之后的空行不算作一行
+func SplitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+}
+
+// matchLines 返回b中每一行在a中对应的行号，新增的行为-1
+func matchLines(a, b []string) []int {
+	matches := make([]int, len(b))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	// 去掉相同的前缀和后缀，只对中间部分计算diff
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		matches[prefix] = prefix
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		matches[len(b)-1-suffix] = len(a) - 1 - suffix
+		suffix++
+	}
+
+	middleA, middleB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	for i, j := range myers(middleA, middleB) {
+		if j >= 0 {
+			matches[prefix+i] = prefix + j
+		}
+	}
+
+	return matches
+}
+
+// myers 使用Myers算法计算最短编辑脚本，返回b中每一行在a中对应的行号
+func myers(a, b []string) []int {
+	n, m := len(a), len(b)
+	matches := make([]int, m)
+	for i := range matches {
+		matches[i] = -1
+	}
+	if n == 0 || m == 0 {
+		return matches
+	}
+
+	maxD := n + m
+	if maxD > maxEditDistance {
+		maxD = maxEditDistance
+	}
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+	for d := 0; d <= maxD; d++ {
+		// 保存第d轮开始前 [-d, d] 范围内的v，用于回溯
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				backtrack(trace, n, m, matches)
+				return matches
+			}
+		}
+	}
+
+	// 差异过大
+	return matches
+}
+
+func backtrack(trace [][]int, x, y int, matches []int) {
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		// v[i] 对应 k = i - d
+		get := func(k int) int {
+			return v[k+d]
+		}
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && get(k-1) < get(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := 0
+		if d > 0 {
+			prevX = get(prevK)
+		}
+		prevY := prevX - prevK
+		for x > prevX && y > prevY && x > 0 && y > 0 {
+			x--
+			y--
+			matches[y] = x
+		}
+		x, y = prevX, prevY
+	}
+}
@@ -0,0 +1,84 @@
+package blame
+
+import (
+	"strings"
+	"testing"
+)
+
+func revisions(lines []*Line) []int {
+	result := make([]int, len(lines))
+	for i, line := range lines {
+		result[i] = line.Revision
+	}
+
+	return result
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestBlamer(t *testing.T) {
+	blamer := NewBlamer()
+	blamer.Apply(0, []byte("syntax = \"proto3\";\npackage acme;\nmessage A {\n}\n"))
+	blamer.Apply(1, []byte("syntax = \"proto3\";\npackage acme;\nmessage A {\n  string name = 1;\n}\n"))
+	blamer.Apply(2, []byte("syntax = \"proto3\";\npackage acme;\n// A 注释\nmessage A {\n  string name = 1;\n  int32 age = 2;\n}\nmessage B {}\n"))
+
+	lines := blamer.Lines()
+	if expected := []int{0, 0, 2, 0, 1, 2, 0, 2}; !equalInts(revisions(lines), expected) {
+		t.Errorf("expected %v, got %v", expected, revisions(lines))
+	}
+	if lines[4].Content != "  string name = 1;" {
+		t.Errorf("unexpected content %q", lines[4].Content)
+	}
+
+	// 删除之后重新添加的文件视为新文件
+	blamer.Apply(3, nil)
+	blamer.Apply(4, []byte("syntax = \"proto3\";\n"))
+	if expected := []int{4}; !equalInts(revisions(blamer.Lines()), expected) {
+		t.Errorf("expected %v, got %v", expected, revisions(blamer.Lines()))
+	}
+}
+
+func TestMatchLines(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected []int
+	}{
+		{"a b c", "a b c", []int{0, 1, 2}},
+		{"a b c", "a x c", []int{0, -1, 2}},
+		{"a b c d", "b d", []int{1, 3}},
+		{"a b", "x a y b z", []int{-1, 0, -1, 1, -1}},
+		{"a b c", "c b a", []int{2, -1, -1}},
+		{"", "a", []int{-1}},
+	}
+	for _, test := range tests {
+		matches := matchLines(strings.Fields(test.a), strings.Fields(test.b))
+		if !equalInts(matches, test.expected) {
+			t.Errorf("%q -> %q: expected %v, got %v", test.a, test.b, test.expected, matches)
+		}
+	}
+}
+
+func TestMatchLinesTooManyEdits(t *testing.T) {
+	var a, b []string
+	for i := 0; i < maxEditDistance; i++ {
+		a = append(a, "a")
+		b = append(b, "b")
+	}
+	a, b = append(a, "same"), append(b, "x")
+	for _, match := range matchLines(a, b) {
+		if match != -1 {
+			t.Fatalf("expected no matches, got %v", match)
+		}
+	}
+}
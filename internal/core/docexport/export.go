@@ -0,0 +1,266 @@
+package docexport
+
+import (
+	"errors"
+	"fmt"
+	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+	"github.com/ProtobufMan/bufman/internal/core/archive"
+	"sort"
+	"strings"
+)
+
+// 支持的文档格式
+const (
+	FormatHTML     = "html"
+	FormatMarkdown = "markdown"
+)
+
+const defaultPackageFileName = "_default" // 没有声明package的文件
+
+var ErrUnknownFormat = errors.New("unknown documentation format")
+
+// Module 导出文档的模块
+type Module struct {
+	Identity string // remote/owner/repository
+	Commit   string
+}
+
+// Export 将模块所有package的文档渲染为静态文件，包括一个index文件和每个package一个文件
+func Export(module Module, packages []*registryv1alpha1.PackageDocumentation, format string) ([]*archive.File, error) {
+	newWriter, err := writerFactory(format)
+	if err != nil {
+		return nil, err
+	}
+
+	sortedPackages := make([]*registryv1alpha1.PackageDocumentation, len(packages))
+	copy(sortedPackages, packages)
+	sort.Slice(sortedPackages, func(i, j int) bool {
+		return sortedPackages[i].GetName() < sortedPackages[j].GetName()
+	})
+
+	r := &renderer{
+		module:     module,
+		localTypes: map[string]string{},
+	}
+	for _, packageDocumentation := range sortedPackages {
+		for _, message := range packageDocumentation.GetMessages() {
+			r.localTypes[message.GetFullName()] = packageDocumentation.GetName()
+		}
+		for _, enum := range packageDocumentation.GetEnums() {
+			r.localTypes[enum.GetFullName()] = packageDocumentation.GetName()
+		}
+	}
+
+	files := make([]*archive.File, 0, len(sortedPackages)+1)
+	indexWriter := newWriter()
+	files = append(files, &archive.File{
+		Path:    "index" + indexWriter.ext(),
+		Content: r.renderIndex(indexWriter, sortedPackages),
+	})
+	for _, packageDocumentation := range sortedPackages {
+		packageWriter := newWriter()
+		files = append(files, &archive.File{
+			Path:    packageFileName(packageDocumentation.GetName()) + packageWriter.ext(),
+			Content: r.renderPackage(packageWriter, packageDocumentation),
+		})
+	}
+
+	return files, nil
+}
+
+func writerFactory(format string) (func() writer, error) {
+	switch format {
+	case FormatHTML:
+		return func() writer { return &htmlWriter{} }, nil
+	case FormatMarkdown:
+		return func() writer { return &markdownWriter{} }, nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+func packageFileName(packageName string) string {
+	if packageName == "" {
+		return defaultPackageFileName
+	}
+
+	return packageName
+}
+
+type renderer struct {
+	module     Module
+	localTypes map[string]string // 模块中的message和enum -> 所在的package
+}
+
+func (r *renderer) renderIndex(w writer, packages []*registryv1alpha1.PackageDocumentation) []byte {
+	title := fmt.Sprintf("%s:%s", r.module.Identity, r.module.Commit)
+	w.begin(title)
+	w.heading(1, "", title)
+
+	items := make([]string, 0, len(packages))
+	for _, packageDocumentation := range packages {
+		name := packageDocumentation.GetName()
+		if name == "" {
+			name = defaultPackageFileName
+		}
+		items = append(items, w.link(name, packageFileName(packageDocumentation.GetName())+w.ext()))
+	}
+	w.list(items)
+
+	return w.end()
+}
+
+func (r *renderer) renderPackage(w writer, packageDocumentation *registryv1alpha1.PackageDocumentation) []byte {
+	w.begin(packageDocumentation.GetName())
+	w.heading(1, "", packageDocumentation.GetName())
+	w.paragraph(w.text(packageDocumentation.GetDescription()))
+	w.paragraph(w.link("index", "index"+w.ext()))
+
+	if len(packageDocumentation.GetServices()) > 0 {
+		w.heading(2, "", "Services")
+		for _, service := range packageDocumentation.GetServices() {
+			r.renderService(w, service)
+		}
+	}
+
+	var messages []*registryv1alpha1.Message
+	for _, message := range packageDocumentation.GetMessages() {
+		if !message.GetIsMapEntry() {
+			messages = append(messages, message)
+		}
+	}
+	if len(messages) > 0 {
+		w.heading(2, "", "Messages")
+		for _, message := range messages {
+			r.renderMessage(w, message)
+		}
+	}
+
+	if len(packageDocumentation.GetEnums()) > 0 {
+		w.heading(2, "", "Enums")
+		for _, enum := range packageDocumentation.GetEnums() {
+			r.renderEnum(w, enum)
+		}
+	}
+
+	return w.end()
+}
+
+func (r *renderer) renderService(w writer, service *registryv1alpha1.Service) {
+	w.heading(3, service.GetFullName(), service.GetNestedName()+deprecatedSuffix(service.GetServiceOptions().GetDeprecated()))
+	w.paragraph(w.text(service.GetDescription()))
+
+	rows := make([][]string, 0, len(service.GetMethods()))
+	for _, method := range service.GetMethods() {
+		rows = append(rows, []string{
+			w.text(method.GetName()) + w.text(deprecatedSuffix(method.GetMethodOptions().GetDeprecated())),
+			r.methodType(w, method.GetRequest()),
+			r.methodType(w, method.GetResponse()),
+			w.text(method.GetDescription()),
+		})
+	}
+	w.table([]string{"Method", "Request", "Response", "Description"}, rows)
+}
+
+func (r *renderer) methodType(w writer, requestResponse *registryv1alpha1.MethodRequestResponse) string {
+	typeText := r.typeLink(w, requestResponse.GetNestedType(), requestResponse.GetFullType(), requestResponse.GetImportModuleRef())
+	if requestResponse.GetStreaming() {
+		return w.text("stream ") + typeText
+	}
+
+	return typeText
+}
+
+func (r *renderer) renderMessage(w writer, message *registryv1alpha1.Message) {
+	w.heading(3, message.GetFullName(), message.GetNestedName()+deprecatedSuffix(message.GetMessageOptions().GetDeprecated()))
+	w.paragraph(w.text(message.GetDescription()))
+
+	var rows [][]string
+	for _, messageField := range message.GetFields() {
+		if field := messageField.GetField(); field != nil {
+			rows = append(rows, r.fieldRow(w, field, ""))
+		}
+		if oneof := messageField.GetOneof(); oneof != nil {
+			for _, field := range oneof.GetFields() {
+				rows = append(rows, r.fieldRow(w, field, oneof.GetName()))
+			}
+		}
+	}
+	if len(rows) > 0 {
+		w.table([]string{"Field", "Type", "Label", "Number", "Description"}, rows)
+	}
+}
+
+func (r *renderer) fieldRow(w writer, field *registryv1alpha1.Field, oneofName string) []string {
+	label := strings.ToLower(field.GetLabel())
+	if oneofName != "" {
+		label = "oneof " + oneofName
+	}
+
+	var typeText string
+	if mapEntry := field.GetMapEntry(); mapEntry != nil {
+		label = ""
+		typeText = w.text("map<"+mapEntry.GetKeyFullType()+", ") +
+			r.typeLink(w, mapEntry.GetValueNestedType(), mapEntry.GetValueFullType(), mapEntry.GetValueImportModuleRef()) +
+			w.text(">")
+	} else {
+		typeText = r.typeLink(w, field.GetNestedType(), field.GetFullType(), field.GetImportModuleRef())
+	}
+
+	return []string{
+		w.text(field.GetName()) + w.text(deprecatedSuffix(field.GetFieldOptions().GetDeprecated())),
+		typeText,
+		w.text(label),
+		fmt.Sprintf("%d", field.GetTag()),
+		w.text(field.GetDescription()),
+	}
+}
+
+func (r *renderer) renderEnum(w writer, enum *registryv1alpha1.Enum) {
+	w.heading(3, enum.GetFullName(), enum.GetNestedName()+deprecatedSuffix(enum.GetEnumOptions().GetDeprecated()))
+	w.paragraph(w.text(enum.GetDescription()))
+
+	rows := make([][]string, 0, len(enum.GetValues()))
+	for _, value := range enum.GetValues() {
+		rows = append(rows, []string{
+			w.text(value.GetName()) + w.text(deprecatedSuffix(value.GetEnumValueOptions().GetDeprecated())),
+			fmt.Sprintf("%d", value.GetNumber()),
+			w.text(value.GetDescription()),
+		})
+	}
+	w.table([]string{"Name", "Number", "Description"}, rows)
+}
+
+// typeLink 模块中的类型链接到对应package的文件，依赖模块中的类型链接到依赖模块的文档
+func (r *renderer) typeLink(w writer, nestedType, fullType string, importModuleRef *registryv1alpha1.ImportModuleRef) string {
+	if importModuleRef != nil {
+		return w.link(fullType, dependencyURL(importModuleRef, fullType))
+	}
+	if packageName, ok := r.localTypes[fullType]; ok {
+		return w.link(nestedType, packageFileName(packageName)+w.ext()+"#"+fullType)
+	}
+
+	// 标量类型或者没有依赖信息的类型，例如well known types
+	return w.text(fullType)
+}
+
+// dependencyURL 依赖模块的文档地址，例如 https://bufman.io/acme/weather/docs/<commit>:acme.weather.v1#acme.weather.v1.Weather
+func dependencyURL(importModuleRef *registryv1alpha1.ImportModuleRef, fullType string) string {
+	return fmt.Sprintf(
+		"https://%s/%s/%s/docs/%s:%s#%s",
+		importModuleRef.GetRemote(),
+		importModuleRef.GetOwner(),
+		importModuleRef.GetRepository(),
+		importModuleRef.GetCommit(),
+		importModuleRef.GetPackageName(),
+		fullType,
+	)
+}
+
+func deprecatedSuffix(deprecated bool) string {
+	if deprecated {
+		return " (deprecated)"
+	}
+
+	return ""
+}
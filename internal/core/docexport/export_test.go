@@ -0,0 +1,102 @@
+package docexport
+
+import (
+	"errors"
+	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+	"strings"
+	"testing"
+)
+
+var testPackages = []*registryv1alpha1.PackageDocumentation{
+	{
+		Name: "acme.weather.v1",
+		Messages: []*registryv1alpha1.Message{
+			{
+				Name:       "Weather",
+				NestedName: "Weather",
+				FullName:   "acme.weather.v1.Weather",
+				Fields: []*registryv1alpha1.MessageField{
+					{
+						MessageField: &registryv1alpha1.MessageField_Field{
+							Field: &registryv1alpha1.Field{
+								Name:       "condition",
+								Label:      "optional",
+								NestedType: "Condition",
+								FullType:   "acme.weather.v1.Condition",
+								Tag:        1,
+							},
+						},
+					},
+					{
+						MessageField: &registryv1alpha1.MessageField_Field{
+							Field: &registryv1alpha1.Field{
+								Name:       "location",
+								Label:      "optional",
+								NestedType: "Location",
+								FullType:   "acme.geo.v1.Location",
+								Tag:        2,
+								ImportModuleRef: &registryv1alpha1.ImportModuleRef{
+									Remote:      "bufman.io",
+									Owner:       "acme",
+									Repository:  "geo",
+									Commit:      "abc",
+									PackageName: "acme.geo.v1",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Enums: []*registryv1alpha1.Enum{
+			{
+				Name:       "Condition",
+				NestedName: "Condition",
+				FullName:   "acme.weather.v1.Condition",
+				Values: []*registryv1alpha1.EnumValue{
+					{Name: "CONDITION_UNSPECIFIED", Number: 0},
+				},
+			},
+		},
+	},
+}
+
+func TestExportMarkdown(t *testing.T) {
+	files, err := Export(Module{Identity: "bufman.io/acme/weather", Commit: "def"}, testPackages, FormatMarkdown)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].Path != "index.md" || files[1].Path != "acme.weather.v1.md" {
+		t.Fatalf("unexpected files: %v", files)
+	}
+
+	content := string(files[1].Content)
+	for _, expected := range []string{
+		"[Condition](acme.weather.v1.md#acme.weather.v1.Condition)",
+		"[acme.geo.v1.Location](https://bufman.io/acme/geo/docs/abc:acme.geo.v1#acme.geo.v1.Location)",
+		"CONDITION\\_UNSPECIFIED",
+	} {
+		if !strings.Contains(content, expected) {
+			t.Errorf("expected %q in:\n%s", expected, content)
+		}
+	}
+}
+
+func TestExportHTML(t *testing.T) {
+	files, err := Export(Module{Identity: "bufman.io/acme/weather", Commit: "def"}, testPackages, FormatHTML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(files[0].Content), `<a href="acme.weather.v1.html">acme.weather.v1</a>`) {
+		t.Errorf("unexpected index:\n%s", files[0].Content)
+	}
+	if !strings.Contains(string(files[1].Content), `<h3 id="acme.weather.v1.Weather">Weather</h3>`) {
+		t.Errorf("unexpected package:\n%s", files[1].Content)
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	if _, err := Export(Module{}, testPackages, "pdf"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+}
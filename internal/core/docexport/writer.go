@@ -0,0 +1,152 @@
+package docexport
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+)
+
+// writer 输出某一种格式的文档，render只负责组织文档结构
+type writer interface {
+	ext() string
+	begin(title string)
+	heading(level int, anchor, text string)
+	// paragraph 输出段落，text需要先经过text或者link处理
+	paragraph(text string)
+	list(items []string)
+	table(headers []string, rows [][]string)
+	// text 转义纯文本，返回值可以作为list、table中的内容
+	text(s string) string
+	// link 生成链接，text会被转义
+	link(text, href string) string
+	end() []byte
+}
+
+type markdownWriter struct {
+	buffer bytes.Buffer
+}
+
+func (w *markdownWriter) ext() string {
+	return ".md"
+}
+
+func (w *markdownWriter) begin(title string) {}
+
+func (w *markdownWriter) heading(level int, anchor, text string) {
+	if anchor != "" {
+		fmt.Fprintf(&w.buffer, "<a id=\"%s\"></a>\n\n", html.EscapeString(anchor))
+	}
+	fmt.Fprintf(&w.buffer, "%s %s\n\n", strings.Repeat("#", level), w.text(text))
+}
+
+func (w *markdownWriter) paragraph(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(&w.buffer, "%s\n\n", text)
+}
+
+func (w *markdownWriter) list(items []string) {
+	for _, item := range items {
+		fmt.Fprintf(&w.buffer, "- %s\n", item)
+	}
+	w.buffer.WriteString("\n")
+}
+
+func (w *markdownWriter) table(headers []string, rows [][]string) {
+	fmt.Fprintf(&w.buffer, "| %s |\n", strings.Join(headers, " | "))
+	fmt.Fprintf(&w.buffer, "|%s\n", strings.Repeat(" --- |", len(headers)))
+	for _, row := range rows {
+		cells := make([]string, 0, len(row))
+		for _, cell := range row {
+			// 表格中不能换行
+			cells = append(cells, strings.ReplaceAll(strings.TrimSpace(cell), "\n", "<br>"))
+		}
+		fmt.Fprintf(&w.buffer, "| %s |\n", strings.Join(cells, " | "))
+	}
+	w.buffer.WriteString("\n")
+}
+
+var markdownReplacer = strings.NewReplacer(
+	"\\", "\\\\", "`", "\\`", "*", "\\*", "_", "\\_", "[", "\\[", "]", "\\]",
+	"<", "&lt;", ">", "&gt;", "|", "\\|",
+)
+
+func (w *markdownWriter) text(s string) string {
+	return markdownReplacer.Replace(s)
+}
+
+func (w *markdownWriter) link(text, href string) string {
+	return fmt.Sprintf("[%s](%s)", w.text(text), href)
+}
+
+func (w *markdownWriter) end() []byte {
+	return w.buffer.Bytes()
+}
+
+type htmlWriter struct {
+	buffer bytes.Buffer
+}
+
+func (w *htmlWriter) ext() string {
+	return ".html"
+}
+
+func (w *htmlWriter) begin(title string) {
+	fmt.Fprintf(&w.buffer, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n", html.EscapeString(title))
+}
+
+func (w *htmlWriter) heading(level int, anchor, text string) {
+	if anchor != "" {
+		fmt.Fprintf(&w.buffer, "<h%d id=\"%s\">%s</h%d>\n", level, html.EscapeString(anchor), html.EscapeString(text), level)
+		return
+	}
+	fmt.Fprintf(&w.buffer, "<h%d>%s</h%d>\n", level, html.EscapeString(text), level)
+}
+
+func (w *htmlWriter) paragraph(text string) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(&w.buffer, "<p>%s</p>\n", strings.ReplaceAll(text, "\n", "<br>\n"))
+}
+
+func (w *htmlWriter) list(items []string) {
+	w.buffer.WriteString("<ul>\n")
+	for _, item := range items {
+		fmt.Fprintf(&w.buffer, "<li>%s</li>\n", item)
+	}
+	w.buffer.WriteString("</ul>\n")
+}
+
+func (w *htmlWriter) table(headers []string, rows [][]string) {
+	w.buffer.WriteString("<table>\n<tr>")
+	for _, header := range headers {
+		fmt.Fprintf(&w.buffer, "<th>%s</th>", header)
+	}
+	w.buffer.WriteString("</tr>\n")
+	for _, row := range rows {
+		w.buffer.WriteString("<tr>")
+		for _, cell := range row {
+			fmt.Fprintf(&w.buffer, "<td>%s</td>", strings.ReplaceAll(strings.TrimSpace(cell), "\n", "<br>"))
+		}
+		w.buffer.WriteString("</tr>\n")
+	}
+	w.buffer.WriteString("</table>\n")
+}
+
+func (w *htmlWriter) text(s string) string {
+	return html.EscapeString(s)
+}
+
+func (w *htmlWriter) link(text, href string) string {
+	return fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(href), html.EscapeString(text))
+}
+
+func (w *htmlWriter) end() []byte {
+	w.buffer.WriteString("</body>\n</html>\n")
+	return w.buffer.Bytes()
+}
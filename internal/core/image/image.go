@@ -0,0 +1,328 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"sort"
+)
+
+// 支持的输出格式
+const (
+	FormatBinary = "binary"
+	FormatJSON   = "json"
+)
+
+var (
+	ErrTypeNotFound  = errors.New("type not found")
+	ErrUnknownFormat = errors.New("unknown image format")
+)
+
+// Options 生成image的选项
+type Options struct {
+	ExcludeImports    bool     // 只包含模块中的文件
+	ExcludeSourceInfo bool     // 去掉source code info
+	Types             []string // 只包含这些类型以及它们依赖的类型，为空时包含全部
+}
+
+// Build 根据模块中的文件生成FileDescriptorSet，文件按照依赖顺序排列，被import的文件在前
+func Build(moduleFiles []protoreflect.FileDescriptor, options *Options) (*descriptorpb.FileDescriptorSet, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	moduleFilePaths := make(map[string]struct{}, len(moduleFiles))
+	for _, file := range moduleFiles {
+		moduleFilePaths[file.Path()] = struct{}{}
+	}
+	files := SortFiles(moduleFiles)
+
+	var pruner *Pruner
+	if len(options.Types) > 0 {
+		pruner = NewPruner(files)
+		for _, typeName := range options.Types {
+			if err := pruner.Add(protoreflect.FullName(typeName)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	fileDescriptorSet := &descriptorpb.FileDescriptorSet{}
+	for _, file := range files {
+		if _, ok := moduleFilePaths[file.Path()]; !ok && options.ExcludeImports {
+			continue
+		}
+
+		var fileDescriptorProto *descriptorpb.FileDescriptorProto
+		if pruner != nil {
+			fileDescriptorProto = pruner.Prune(file)
+			if fileDescriptorProto == nil {
+				continue
+			}
+		} else {
+			fileDescriptorProto = protodesc.ToFileDescriptorProto(file)
+		}
+		if options.ExcludeSourceInfo {
+			fileDescriptorProto.SourceCodeInfo = nil
+		}
+		fileDescriptorSet.File = append(fileDescriptorSet.File, fileDescriptorProto)
+	}
+
+	return fileDescriptorSet, nil
+}
+
+// SortFiles 返回files以及它们import的所有文件，被import的文件在前
+func SortFiles(files []protoreflect.FileDescriptor) []protoreflect.FileDescriptor {
+	roots := make([]protoreflect.FileDescriptor, len(files))
+	copy(roots, files)
+	sort.Slice(roots, func(i, j int) bool {
+		return roots[i].Path() < roots[j].Path()
+	})
+
+	var sorted []protoreflect.FileDescriptor
+	visited := map[string]struct{}{}
+	var visit func(file protoreflect.FileDescriptor)
+	visit = func(file protoreflect.FileDescriptor) {
+		if _, ok := visited[file.Path()]; ok {
+			return
+		}
+		visited[file.Path()] = struct{}{}
+
+		imports := file.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			visit(imports.Get(i).FileDescriptor)
+		}
+		sorted = append(sorted, file)
+	}
+	for _, file := range roots {
+		visit(file)
+	}
+
+	return sorted
+}
+
+// Pruner 计算指定类型依赖的所有类型，并裁剪文件中不需要的类型
+// 嵌套类型会保留所在的顶层message，method会保留所在的service
+type Pruner struct {
+	descriptors map[protoreflect.FullName]protoreflect.Descriptor
+	needed      map[protoreflect.FullName]struct{} // 需要保留的顶层类型
+	neededFiles map[string]struct{}
+}
+
+func NewPruner(files []protoreflect.FileDescriptor) *Pruner {
+	pruner := &Pruner{
+		descriptors: map[protoreflect.FullName]protoreflect.Descriptor{},
+		needed:      map[protoreflect.FullName]struct{}{},
+		neededFiles: map[string]struct{}{},
+	}
+	for _, file := range files {
+		pruner.indexFile(file)
+	}
+
+	return pruner
+}
+
+// Find 根据全名查找message、enum、service、method或者extension
+func (pruner *Pruner) Find(name protoreflect.FullName) (protoreflect.Descriptor, bool) {
+	descriptor, ok := pruner.descriptors[name]
+	return descriptor, ok
+}
+
+// Add 保留name对应的类型以及它依赖的类型
+func (pruner *Pruner) Add(name protoreflect.FullName) error {
+	descriptor, ok := pruner.descriptors[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrTypeNotFound, name)
+	}
+
+	pruner.addDescriptor(descriptor)
+	return nil
+}
+
+// Prune 返回只包含需要的类型的文件，文件中没有需要的类型时返回nil
+// 裁剪之后source code info中的路径不再准确，所以会被去掉
+func (pruner *Pruner) Prune(file protoreflect.FileDescriptor) *descriptorpb.FileDescriptorProto {
+	if _, ok := pruner.neededFiles[file.Path()]; !ok {
+		return nil
+	}
+
+	fileDescriptorProto := protodesc.ToFileDescriptorProto(file)
+	fileDescriptorProto.SourceCodeInfo = nil
+	packagePrefix := ""
+	if file.Package() != "" {
+		packagePrefix = string(file.Package()) + "."
+	}
+	isNeeded := func(name string) bool {
+		_, ok := pruner.needed[protoreflect.FullName(packagePrefix+name)]
+		return ok
+	}
+
+	var messages []*descriptorpb.DescriptorProto
+	for _, message := range fileDescriptorProto.GetMessageType() {
+		if isNeeded(message.GetName()) {
+			messages = append(messages, message)
+		}
+	}
+	fileDescriptorProto.MessageType = messages
+
+	var enums []*descriptorpb.EnumDescriptorProto
+	for _, enum := range fileDescriptorProto.GetEnumType() {
+		if isNeeded(enum.GetName()) {
+			enums = append(enums, enum)
+		}
+	}
+	fileDescriptorProto.EnumType = enums
+
+	var services []*descriptorpb.ServiceDescriptorProto
+	for _, service := range fileDescriptorProto.GetService() {
+		if isNeeded(service.GetName()) {
+			services = append(services, service)
+		}
+	}
+	fileDescriptorProto.Service = services
+
+	var extensions []*descriptorpb.FieldDescriptorProto
+	for _, extension := range fileDescriptorProto.GetExtension() {
+		if isNeeded(extension.GetName()) {
+			extensions = append(extensions, extension)
+		}
+	}
+	fileDescriptorProto.Extension = extensions
+
+	// 只保留仍然需要的import，并重新计算public和weak import的下标
+	var dependencies []string
+	indexes := map[int32]int32{}
+	for i, dependency := range fileDescriptorProto.GetDependency() {
+		if _, ok := pruner.neededFiles[dependency]; ok {
+			indexes[int32(i)] = int32(len(dependencies))
+			dependencies = append(dependencies, dependency)
+		}
+	}
+	fileDescriptorProto.Dependency = dependencies
+	fileDescriptorProto.PublicDependency = remapIndexes(fileDescriptorProto.GetPublicDependency(), indexes)
+	fileDescriptorProto.WeakDependency = remapIndexes(fileDescriptorProto.GetWeakDependency(), indexes)
+
+	return fileDescriptorProto
+}
+
+func remapIndexes(oldIndexes []int32, indexes map[int32]int32) []int32 {
+	var newIndexes []int32
+	for _, index := range oldIndexes {
+		if newIndex, ok := indexes[index]; ok {
+			newIndexes = append(newIndexes, newIndex)
+		}
+	}
+
+	return newIndexes
+}
+
+func (pruner *Pruner) indexFile(file protoreflect.FileDescriptor) {
+	pruner.indexMessages(file.Messages())
+	pruner.indexEnums(file.Enums())
+	pruner.indexExtensions(file.Extensions())
+
+	services := file.Services()
+	for i := 0; i < services.Len(); i++ {
+		service := services.Get(i)
+		pruner.descriptors[service.FullName()] = service
+		methods := service.Methods()
+		for j := 0; j < methods.Len(); j++ {
+			pruner.descriptors[methods.Get(j).FullName()] = methods.Get(j)
+		}
+	}
+}
+
+func (pruner *Pruner) indexMessages(messages protoreflect.MessageDescriptors) {
+	for i := 0; i < messages.Len(); i++ {
+		message := messages.Get(i)
+		pruner.descriptors[message.FullName()] = message
+		pruner.indexMessages(message.Messages())
+		pruner.indexEnums(message.Enums())
+		pruner.indexExtensions(message.Extensions())
+	}
+}
+
+func (pruner *Pruner) indexEnums(enums protoreflect.EnumDescriptors) {
+	for i := 0; i < enums.Len(); i++ {
+		pruner.descriptors[enums.Get(i).FullName()] = enums.Get(i)
+	}
+}
+
+func (pruner *Pruner) indexExtensions(extensions protoreflect.ExtensionDescriptors) {
+	for i := 0; i < extensions.Len(); i++ {
+		pruner.descriptors[extensions.Get(i).FullName()] = extensions.Get(i)
+	}
+}
+
+func (pruner *Pruner) addDescriptor(descriptor protoreflect.Descriptor) {
+	// 找到顶层类型
+	top := descriptor
+	for {
+		if _, ok := top.Parent().(protoreflect.FileDescriptor); ok {
+			break
+		}
+		top = top.Parent()
+	}
+	if _, ok := pruner.needed[top.FullName()]; ok {
+		return
+	}
+	pruner.needed[top.FullName()] = struct{}{}
+	pruner.neededFiles[top.ParentFile().Path()] = struct{}{}
+
+	switch d := top.(type) {
+	case protoreflect.MessageDescriptor:
+		pruner.addMessageDependencies(d)
+	case protoreflect.ServiceDescriptor:
+		methods := d.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			pruner.addDescriptor(methods.Get(i).Input())
+			pruner.addDescriptor(methods.Get(i).Output())
+		}
+	case protoreflect.ExtensionDescriptor:
+		pruner.addFieldDependencies(d)
+	}
+}
+
+// addMessageDependencies 加入message以及嵌套类型中所有字段依赖的类型
+func (pruner *Pruner) addMessageDependencies(message protoreflect.MessageDescriptor) {
+	fields := message.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		pruner.addFieldDependencies(fields.Get(i))
+	}
+	extensions := message.Extensions()
+	for i := 0; i < extensions.Len(); i++ {
+		pruner.addFieldDependencies(extensions.Get(i))
+	}
+	messages := message.Messages()
+	for i := 0; i < messages.Len(); i++ {
+		pruner.addMessageDependencies(messages.Get(i))
+	}
+}
+
+func (pruner *Pruner) addFieldDependencies(field protoreflect.FieldDescriptor) {
+	if field.Message() != nil {
+		pruner.addDescriptor(field.Message())
+	}
+	if field.Enum() != nil {
+		pruner.addDescriptor(field.Enum())
+	}
+	if field.IsExtension() {
+		pruner.addDescriptor(field.ContainingMessage())
+	}
+}
+
+// Marshal 将FileDescriptorSet序列化为二进制或者JSON
+func Marshal(fileDescriptorSet *descriptorpb.FileDescriptorSet, format string) ([]byte, error) {
+	switch format {
+	case FormatBinary:
+		return proto.MarshalOptions{Deterministic: true}.Marshal(fileDescriptorSet)
+	case FormatJSON:
+		return protojson.Marshal(fileDescriptorSet)
+	}
+
+	return nil, ErrUnknownFormat
+}
@@ -0,0 +1,177 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"testing"
+)
+
+var testSources = map[string]string{
+	"acme/common/v1/common.proto": `syntax = "proto3";
+package acme.common.v1;
+message Location {
+  string city = 1;
+}
+message Unused {}
+enum Unit {
+  UNIT_UNSPECIFIED = 0;
+  UNIT_CELSIUS = 1;
+}`,
+	"acme/weather/v1/weather.proto": `syntax = "proto3";
+package acme.weather.v1;
+import "acme/common/v1/common.proto";
+import "google/protobuf/timestamp.proto";
+
+message Weather {
+  message Reading {
+    acme.common.v1.Unit unit = 1;
+    double value = 2;
+  }
+  acme.common.v1.Location location = 1;
+  google.protobuf.Timestamp time = 2;
+  Reading temperature = 3;
+}
+
+message GetWeatherRequest {
+  acme.common.v1.Location location = 1;
+}
+
+message Forecast {
+  repeated Weather days = 1;
+}
+
+service WeatherService {
+  rpc GetWeather(GetWeatherRequest) returns (Weather);
+}`,
+}
+
+func compileTestFiles(t *testing.T) []protoreflect.FileDescriptor {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(testSources),
+		}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	files, err := compiler.Compile(context.Background(), "acme/common/v1/common.proto", "acme/weather/v1/weather.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return []protoreflect.FileDescriptor{files[0], files[1]}
+}
+
+func filePaths(fileDescriptorSet *descriptorpb.FileDescriptorSet) []string {
+	var paths []string
+	for _, file := range fileDescriptorSet.GetFile() {
+		paths = append(paths, file.GetName())
+	}
+
+	return paths
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestBuild(t *testing.T) {
+	fileDescriptorSet, err := Build(compileTestFiles(t), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"acme/common/v1/common.proto", "google/protobuf/timestamp.proto", "acme/weather/v1/weather.proto"}
+	if paths := filePaths(fileDescriptorSet); !equalStrings(paths, expected) {
+		t.Errorf("unexpected files %v", paths)
+	}
+	if fileDescriptorSet.GetFile()[0].GetSourceCodeInfo() == nil {
+		t.Errorf("source code info should be included")
+	}
+	if _, err = protodesc.NewFiles(fileDescriptorSet); err != nil {
+		t.Errorf("image is not self-contained: %v", err)
+	}
+}
+
+func TestBuildExcludeOptions(t *testing.T) {
+	fileDescriptorSet, err := Build(compileTestFiles(t), &Options{ExcludeImports: true, ExcludeSourceInfo: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"acme/common/v1/common.proto", "acme/weather/v1/weather.proto"}
+	if paths := filePaths(fileDescriptorSet); !equalStrings(paths, expected) {
+		t.Errorf("unexpected files %v", paths)
+	}
+	for _, file := range fileDescriptorSet.GetFile() {
+		if file.GetSourceCodeInfo() != nil {
+			t.Errorf("source code info should be excluded in %s", file.GetName())
+		}
+	}
+}
+
+func TestBuildTypes(t *testing.T) {
+	fileDescriptorSet, err := Build(compileTestFiles(t), &Options{Types: []string{"acme.weather.v1.WeatherService.GetWeather"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := protodesc.NewFiles(fileDescriptorSet)
+	if err != nil {
+		t.Fatalf("pruned image is not self-contained: %v", err)
+	}
+
+	for _, name := range []protoreflect.FullName{
+		"acme.weather.v1.WeatherService",
+		"acme.weather.v1.Weather.Reading",
+		"acme.weather.v1.GetWeatherRequest",
+		"acme.common.v1.Location",
+		"acme.common.v1.Unit",
+		"google.protobuf.Timestamp",
+	} {
+		if _, err := files.FindDescriptorByName(name); err != nil {
+			t.Errorf("missing %s", name)
+		}
+	}
+	for _, name := range []protoreflect.FullName{"acme.weather.v1.Forecast", "acme.common.v1.Unused"} {
+		if _, err := files.FindDescriptorByName(name); err == nil {
+			t.Errorf("%s should be pruned", name)
+		}
+	}
+
+	if _, err = Build(compileTestFiles(t), &Options{Types: []string{"acme.weather.v1.Missing"}}); !errors.Is(err, ErrTypeNotFound) {
+		t.Errorf("expected ErrTypeNotFound, got %v", err)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	fileDescriptorSet, err := Build(compileTestFiles(t), &Options{ExcludeImports: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := Marshal(fileDescriptorSet, FormatBinary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &descriptorpb.FileDescriptorSet{}
+	if err = proto.Unmarshal(data, decoded); err != nil || !proto.Equal(decoded, fileDescriptorSet) {
+		t.Errorf("binary image round trip failed: %v", err)
+	}
+
+	if _, err = Marshal(fileDescriptorSet, FormatJSON); err != nil {
+		t.Error(err)
+	}
+	if _, err = Marshal(fileDescriptorSet, "yaml"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+}
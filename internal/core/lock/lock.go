@@ -0,0 +1,99 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	versionV1Beta1 = "v1beta1"
+	versionV1      = "v1"
+)
+
+// 校验结果
+const (
+	PinStatusOK               = "ok"                // pin合法
+	PinStatusNotFound         = "not_found"         // 服务器上不存在pin对应的commit
+	PinStatusPermissionDenied = "permission_denied" // 没有权限访问pin对应的仓库
+	PinStatusDigestMismatch   = "digest_mismatch"   // pin的digest与服务器上commit的manifest digest不一致
+	PinStatusNotInConfig      = "not_in_config"     // buf.yaml的依赖中不需要该pin
+	PinStatusCommitMismatch   = "commit_mismatch"   // commit不满足buf.yaml中依赖的reference
+)
+
+var ErrInvalidLockFile = errors.New("invalid lock file")
+
+// File buf.lock文件
+type File struct {
+	Version string `yaml:"version"`
+	Deps    []*Pin `yaml:"deps"`
+}
+
+// Pin buf.lock中锁定的依赖
+type Pin struct {
+	Remote     string `yaml:"remote" json:"remote"`
+	Owner      string `yaml:"owner" json:"owner"`
+	Repository string `yaml:"repository" json:"repository"`
+	Branch     string `yaml:"branch,omitempty" json:"branch,omitempty"`
+	Commit     string `yaml:"commit" json:"commit"`
+	Digest     string `yaml:"digest,omitempty" json:"digest,omitempty"` // v1beta1中没有digest
+}
+
+// IdentityString remote/owner/repository
+func (pin *Pin) IdentityString() string {
+	return pin.Remote + "/" + pin.Owner + "/" + pin.Repository
+}
+
+// PinResult 单个pin的校验结果
+type PinResult struct {
+	Pin     *Pin
+	Status  string
+	Message string
+}
+
+// Report lock文件的校验结果
+type Report struct {
+	Pins    []*PinResult
+	Missing []string // buf.yaml的依赖中需要，但是没有被锁定的模块
+}
+
+// Valid 所有pin都合法，并且没有缺失的依赖
+func (report *Report) Valid() bool {
+	if len(report.Missing) > 0 {
+		return false
+	}
+	for _, pinResult := range report.Pins {
+		if pinResult.Status != PinStatusOK {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Parse 解析buf.lock文件
+func Parse(data []byte) (*File, error) {
+	file := &File{}
+	if err := yaml.Unmarshal(data, file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLockFile, err)
+	}
+
+	switch file.Version {
+	case "", versionV1Beta1, versionV1:
+	default:
+		return nil, fmt.Errorf("%w: unknown version %q", ErrInvalidLockFile, file.Version)
+	}
+
+	identities := make(map[string]struct{}, len(file.Deps))
+	for _, pin := range file.Deps {
+		if pin == nil || pin.Remote == "" || pin.Owner == "" || pin.Repository == "" || pin.Commit == "" {
+			return nil, fmt.Errorf("%w: remote, owner, repository and commit are required", ErrInvalidLockFile)
+		}
+		if _, ok := identities[pin.IdentityString()]; ok {
+			return nil, fmt.Errorf("%w: duplicated dependency %s", ErrInvalidLockFile, pin.IdentityString())
+		}
+		identities[pin.IdentityString()] = struct{}{}
+	}
+
+	return file, nil
+}
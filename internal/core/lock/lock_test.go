@@ -0,0 +1,84 @@
+package lock
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	data := []byte(`# Generated by buf. DO NOT EDIT.
+version: v1
+deps:
+  - remote: bufman.io
+    owner: acme
+    repository: weather
+    commit: 2b7a2e1a9a7f4f1c8d3e5b6a7c8d9e0f
+    digest: shake256:abcdef
+  - remote: buf.build
+    owner: googleapis
+    repository: googleapis
+    branch: main
+    commit: 75b4300737fb4efca0831636be94e517
+`)
+
+	file, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Deps) != 2 {
+		t.Fatalf("expected 2 deps, got %d", len(file.Deps))
+	}
+	if identity := file.Deps[0].IdentityString(); identity != "bufman.io/acme/weather" {
+		t.Errorf("unexpected identity %s", identity)
+	}
+	if file.Deps[0].Digest != "shake256:abcdef" {
+		t.Errorf("unexpected digest %s", file.Deps[0].Digest)
+	}
+	if file.Deps[1].Branch != "main" || file.Deps[1].Digest != "" {
+		t.Errorf("unexpected pin %+v", file.Deps[1])
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	testCases := map[string]string{
+		"syntax":          "deps: [",
+		"unknown version": "version: v2\n",
+		"missing commit":  "version: v1\ndeps:\n  - remote: bufman.io\n    owner: acme\n    repository: weather\n",
+		"duplicated": `version: v1
+deps:
+  - remote: bufman.io
+    owner: acme
+    repository: weather
+    commit: a
+  - remote: bufman.io
+    owner: acme
+    repository: weather
+    commit: b
+`,
+	}
+
+	for name, data := range testCases {
+		if _, err := Parse([]byte(data)); !errors.Is(err, ErrInvalidLockFile) {
+			t.Errorf("%s: expected ErrInvalidLockFile, got %v", name, err)
+		}
+	}
+}
+
+func TestReportValid(t *testing.T) {
+	report := &Report{
+		Pins: []*PinResult{{Status: PinStatusOK}},
+	}
+	if !report.Valid() {
+		t.Error("expected valid report")
+	}
+
+	report.Pins = append(report.Pins, &PinResult{Status: PinStatusDigestMismatch})
+	if report.Valid() {
+		t.Error("expected invalid report with digest mismatch")
+	}
+
+	report = &Report{Missing: []string{"bufman.io/acme/weather"}}
+	if report.Valid() {
+		t.Error("expected invalid report with missing dependency")
+	}
+}
@@ -0,0 +1,109 @@
+package lru
+
+import (
+	"container/list"
+	"sync"
+)
+
+// SizedLru 按照value大小限制总容量的lru，超过容量时淘汰最久未使用的value
+type SizedLru struct {
+	maxSize int64
+	size    int64
+	l       *list.List
+	cache   map[interface{}]*list.Element
+	mu      sync.Mutex
+
+	// OnEvict 淘汰value时调用
+	OnEvict func(key interface{}, value interface{})
+}
+
+type sizedNode struct {
+	Node
+	size int64
+}
+
+func NewSizedLru(maxSize int64) *SizedLru {
+	return &SizedLru{
+		maxSize: maxSize,
+		l:       list.New(),
+		cache:   make(map[interface{}]*list.Element),
+	}
+}
+
+// Add 加入value，size大于总容量的value不会被缓存
+func (l *SizedLru) Add(key interface{}, val interface{}, size int64) bool {
+	if size > l.maxSize {
+		return false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if ele, ok := l.cache[key]; ok {
+		node := ele.Value.(*sizedNode)
+		l.size += size - node.size
+		node.Val = val
+		node.size = size
+		l.l.MoveToFront(ele)
+	} else {
+		l.cache[key] = l.l.PushFront(&sizedNode{
+			Node: Node{Key: key, Val: val},
+			size: size,
+		})
+		l.size += size
+	}
+
+	for l.size > l.maxSize {
+		ele := l.l.Back()
+		if ele == nil {
+			break
+		}
+		l.removeElement(ele)
+	}
+
+	return true
+}
+
+func (l *SizedLru) Get(key interface{}) (interface{}, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if ele, ok := l.cache[key]; ok {
+		l.l.MoveToFront(ele)
+		return ele.Value.(*sizedNode).Val, true
+	}
+
+	return nil, false
+}
+
+func (l *SizedLru) Del(key interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if ele, ok := l.cache[key]; ok {
+		l.l.Remove(ele)
+		delete(l.cache, key)
+		l.size -= ele.Value.(*sizedNode).size
+	}
+}
+
+// Len 缓存的value数量
+func (l *SizedLru) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.cache)
+}
+
+// Size 缓存的value总大小
+func (l *SizedLru) Size() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size
+}
+
+func (l *SizedLru) removeElement(ele *list.Element) {
+	node := ele.Value.(*sizedNode)
+	l.l.Remove(ele)
+	delete(l.cache, node.Key)
+	l.size -= node.size
+	if l.OnEvict != nil {
+		l.OnEvict(node.Key, node.Val)
+	}
+}
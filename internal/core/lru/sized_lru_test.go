@@ -0,0 +1,56 @@
+package lru
+
+import (
+	"testing"
+)
+
+func TestSizedLru(t *testing.T) {
+	var evicted []interface{}
+	l := NewSizedLru(10)
+	l.OnEvict = func(key interface{}, value interface{}) {
+		evicted = append(evicted, key)
+	}
+
+	l.Add("a", 1, 4)
+	l.Add("b", 2, 4)
+	if l.Size() != 8 || l.Len() != 2 {
+		t.Fatalf("unexpected size %d, len %d", l.Size(), l.Len())
+	}
+
+	// a最近被使用，加入c时淘汰b
+	if val, ok := l.Get("a"); !ok || val != 1 {
+		t.Fatalf("expected a = 1, got %v %v", val, ok)
+	}
+	l.Add("c", 3, 4)
+	if _, ok := l.Get("b"); ok {
+		t.Error("expected b to be evicted")
+	}
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Errorf("unexpected evicted keys %v", evicted)
+	}
+	if l.Size() != 8 {
+		t.Errorf("unexpected size %d", l.Size())
+	}
+
+	// 更新已有的key
+	l.Add("c", 4, 6)
+	if val, _ := l.Get("c"); val != 4 {
+		t.Errorf("expected c = 4, got %v", val)
+	}
+	if l.Size() != 10 {
+		t.Errorf("unexpected size %d", l.Size())
+	}
+
+	// 超过总容量的value不会被缓存
+	if l.Add("d", 5, 11) {
+		t.Error("expected oversized value to be rejected")
+	}
+	if _, ok := l.Get("d"); ok {
+		t.Error("expected oversized value not to be cached")
+	}
+
+	l.Del("c")
+	if l.Size() != 4 || l.Len() != 1 {
+		t.Errorf("unexpected size %d, len %d", l.Size(), l.Len())
+	}
+}
@@ -0,0 +1,182 @@
+package navigation
+
+import (
+	"github.com/bufbuild/protocompile/walk"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// source code info 中用到的字段编号，定义在 google/protobuf/descriptor.proto
+const (
+	nameFieldNumber       = 1 // DescriptorProto、EnumDescriptorProto、ServiceDescriptorProto等的name
+	extendeeFieldNumber   = 2 // FieldDescriptorProto.extendee
+	inputTypeFieldNumber  = 2 // MethodDescriptorProto.input_type
+	outputTypeFieldNumber = 3 // MethodDescriptorProto.output_type
+	typeNameFieldNumber   = 6 // FieldDescriptorProto.type_name
+)
+
+// Span 源码中的范围，行列都从0开始，结束位置不包含在内
+type Span struct {
+	StartLine   int
+	StartColumn int
+	EndLine     int
+	EndColumn   int
+}
+
+// Contains 位置是否在范围内
+func (span Span) Contains(line, column int) bool {
+	if line < span.StartLine || (line == span.StartLine && column < span.StartColumn) {
+		return false
+	}
+	if line > span.EndLine || (line == span.EndLine && column >= span.EndColumn) {
+		return false
+	}
+
+	return true
+}
+
+// Reference 源码中对message或者enum的一次引用
+type Reference struct {
+	Target   protoreflect.Descriptor // 被引用的类型
+	FilePath string
+	Span     Span
+}
+
+// References 返回files中所有字段类型、extendee、method请求和响应类型对类型的引用
+func References(files []protoreflect.FileDescriptor) []*Reference {
+	var references []*Reference
+	for _, file := range files {
+		locations := file.SourceLocations()
+		addReference := func(target, descriptor protoreflect.Descriptor, fieldNumber int32) {
+			if target == nil {
+				return
+			}
+			location := locations.ByDescriptor(descriptor)
+			if location.Path == nil {
+				return
+			}
+			path := append(append(protoreflect.SourcePath{}, location.Path...), fieldNumber)
+			span, ok := spanOf(locations.ByPath(path))
+			if !ok {
+				return
+			}
+			references = append(references, &Reference{
+				Target:   target,
+				FilePath: file.Path(),
+				Span:     span,
+			})
+		}
+
+		_ = walk.Descriptors(file, func(descriptor protoreflect.Descriptor) error {
+			switch d := descriptor.(type) {
+			case protoreflect.FieldDescriptor:
+				if parent, ok := d.Parent().(protoreflect.MessageDescriptor); ok && parent.IsMapEntry() {
+					// map entry是生成的，引用记录在map字段上
+					return nil
+				}
+				addReference(fieldType(d), d, typeNameFieldNumber)
+				if d.IsExtension() {
+					addReference(d.ContainingMessage(), d, extendeeFieldNumber)
+				}
+			case protoreflect.MethodDescriptor:
+				addReference(d.Input(), d, inputTypeFieldNumber)
+				addReference(d.Output(), d, outputTypeFieldNumber)
+			}
+			return nil
+		})
+	}
+
+	return references
+}
+
+// fieldType 字段引用的message或者enum，map字段返回value的类型
+func fieldType(field protoreflect.FieldDescriptor) protoreflect.Descriptor {
+	if field.IsMap() {
+		field = field.MapValue()
+	}
+	if field.Message() != nil {
+		return field.Message()
+	}
+	if field.Enum() != nil {
+		return field.Enum()
+	}
+
+	return nil
+}
+
+// FindDefinition 查找filePath中line、column位置引用的类型
+// 位置在类型定义的名称上时返回该类型本身
+func FindDefinition(files []protoreflect.FileDescriptor, filePath string, line, column int) (protoreflect.Descriptor, bool) {
+	var file protoreflect.FileDescriptor
+	for _, f := range files {
+		if f.Path() == filePath {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		return nil, false
+	}
+
+	for _, reference := range References([]protoreflect.FileDescriptor{file}) {
+		if reference.Span.Contains(line, column) {
+			return reference.Target, true
+		}
+	}
+
+	var definition protoreflect.Descriptor
+	_ = walk.Descriptors(file, func(descriptor protoreflect.Descriptor) error {
+		if span, ok := NameSpan(descriptor); ok && span.Contains(line, column) {
+			definition = descriptor
+		}
+		return nil
+	})
+
+	return definition, definition != nil
+}
+
+// FindReferences 返回files中对fullName的所有引用
+func FindReferences(files []protoreflect.FileDescriptor, fullName protoreflect.FullName) []*Reference {
+	var references []*Reference
+	for _, reference := range References(files) {
+		if reference.Target.FullName() == fullName {
+			references = append(references, reference)
+		}
+	}
+
+	return references
+}
+
+// NameSpan 类型定义中名称的位置
+func NameSpan(descriptor protoreflect.Descriptor) (Span, bool) {
+	locations := descriptor.ParentFile().SourceLocations()
+	location := locations.ByDescriptor(descriptor)
+	if location.Path == nil {
+		return Span{}, false
+	}
+	path := append(append(protoreflect.SourcePath{}, location.Path...), nameFieldNumber)
+
+	return spanOf(locations.ByPath(path))
+}
+
+// DefinitionSpan 类型定义的位置，优先返回名称的位置
+func DefinitionSpan(descriptor protoreflect.Descriptor) Span {
+	if span, ok := NameSpan(descriptor); ok {
+		return span
+	}
+	span, _ := spanOf(descriptor.ParentFile().SourceLocations().ByDescriptor(descriptor))
+
+	return span
+}
+
+func spanOf(location protoreflect.SourceLocation) (Span, bool) {
+	if location.Path == nil {
+		return Span{}, false
+	}
+
+	return Span{
+		StartLine:   location.StartLine,
+		StartColumn: location.StartColumn,
+		EndLine:     location.EndLine,
+		EndColumn:   location.EndColumn,
+	}, true
+}
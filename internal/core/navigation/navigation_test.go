@@ -0,0 +1,90 @@
+package navigation
+
+import (
+	"context"
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"testing"
+)
+
+var testSources = map[string]string{
+	"acme/common/v1/common.proto": `syntax = "proto3";
+package acme.common.v1;
+message Location {
+  string city = 1;
+}`,
+	"acme/weather/v1/weather.proto": `syntax = "proto3";
+package acme.weather.v1;
+import "acme/common/v1/common.proto";
+
+message Weather {
+  acme.common.v1.Location location = 1;
+  map<string, Weather> nearby = 2;
+  Condition condition = 3;
+}
+
+enum Condition {
+  CONDITION_UNSPECIFIED = 0;
+}
+
+service WeatherService {
+  rpc GetWeather(acme.common.v1.Location) returns (Weather);
+}`,
+}
+
+func compileTestFiles(t *testing.T) []protoreflect.FileDescriptor {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(testSources),
+		}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	files, err := compiler.Compile(context.Background(), "acme/common/v1/common.proto", "acme/weather/v1/weather.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return []protoreflect.FileDescriptor{files[0], files[1]}
+}
+
+func TestFindDefinition(t *testing.T) {
+	files := compileTestFiles(t)
+
+	tests := []struct {
+		line, column int
+		expected     protoreflect.FullName
+	}{
+		{5, 10, "acme.common.v1.Location"},   // 字段类型
+		{6, 16, "acme.weather.v1.Weather"},   // map value类型
+		{7, 4, "acme.weather.v1.Condition"},  // enum类型
+		{15, 20, "acme.common.v1.Location"},  // method请求类型
+		{15, 52, "acme.weather.v1.Weather"},  // method响应类型
+		{10, 7, "acme.weather.v1.Condition"}, // 类型定义的名称
+	}
+	for _, test := range tests {
+		descriptor, ok := FindDefinition(files, "acme/weather/v1/weather.proto", test.line, test.column)
+		if !ok || descriptor.FullName() != test.expected {
+			t.Errorf("%d:%d expected %s, got %v", test.line, test.column, test.expected, descriptor)
+		}
+	}
+
+	if _, ok := FindDefinition(files, "acme/weather/v1/weather.proto", 1, 0); ok {
+		t.Errorf("package statement should not have a definition")
+	}
+
+	descriptor, _ := FindDefinition(files, "acme/weather/v1/weather.proto", 5, 10)
+	span := DefinitionSpan(descriptor)
+	if descriptor.ParentFile().Path() != "acme/common/v1/common.proto" || span.StartLine != 2 || span.StartColumn != 8 {
+		t.Errorf("unexpected definition span %+v", span)
+	}
+}
+
+func TestFindReferences(t *testing.T) {
+	references := FindReferences(compileTestFiles(t), "acme.weather.v1.Weather")
+	if len(references) != 2 {
+		t.Fatalf("expected 2 references, got %d", len(references))
+	}
+	if references[0].Span.StartLine != 6 || references[1].Span.StartLine != 15 {
+		t.Errorf("unexpected references %+v %+v", references[0].Span, references[1].Span)
+	}
+}
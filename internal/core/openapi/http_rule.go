@@ -0,0 +1,163 @@
+package openapi
+
+import (
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"regexp"
+	"strings"
+)
+
+// google.api.http 扩展字段的编号，定义在 google/api/annotations.proto
+const httpRuleFieldNumber protowire.Number = 72295728
+
+// google.api.HttpRule 中的字段编号
+const (
+	httpRuleGet                protowire.Number = 2
+	httpRulePut                protowire.Number = 3
+	httpRulePost               protowire.Number = 4
+	httpRuleDelete             protowire.Number = 5
+	httpRulePatch              protowire.Number = 6
+	httpRuleBody               protowire.Number = 7
+	httpRuleCustom             protowire.Number = 8
+	httpRuleAdditionalBindings protowire.Number = 11
+	httpRuleResponseBody       protowire.Number = 12
+
+	customHTTPPatternKind protowire.Number = 1
+	customHTTPPatternPath protowire.Number = 2
+)
+
+// HTTPRule google.api.http 注解
+type HTTPRule struct {
+	Method       string // 小写，例如 get、post
+	Path         string // 路径模板，例如 /v1/{name=messages/*}
+	Body         string
+	ResponseBody string
+}
+
+// httpRules 解析method上的google.api.http注解，包括additional_bindings
+// 模块不一定依赖了googleapis，所以直接从options的wire格式中读取，不依赖扩展的Go类型
+func httpRules(methodDescriptor protoreflect.MethodDescriptor) []*HTTPRule {
+	options := methodDescriptor.Options()
+	if options == nil || !options.ProtoReflect().IsValid() {
+		return nil
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(options)
+	if err != nil {
+		return nil
+	}
+
+	var rules []*HTTPRule
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return rules
+		}
+		data = data[n:]
+		if number == httpRuleFieldNumber && wireType == protowire.BytesType {
+			value, n := protowire.ConsumeBytes(data)
+			if n < 0 {
+				return rules
+			}
+			rules = append(rules, parseHTTPRule(value)...)
+			data = data[n:]
+			continue
+		}
+		n = protowire.ConsumeFieldValue(number, wireType, data)
+		if n < 0 {
+			return rules
+		}
+		data = data[n:]
+	}
+
+	return rules
+}
+
+// parseHTTPRule 解析HttpRule，返回的第一个是rule本身，后面是additional_bindings
+func parseHTTPRule(data []byte) []*HTTPRule {
+	rule := &HTTPRule{}
+	var additionalBindings []*HTTPRule
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			break
+		}
+		data = data[n:]
+		if wireType != protowire.BytesType {
+			n = protowire.ConsumeFieldValue(number, wireType, data)
+			if n < 0 {
+				break
+			}
+			data = data[n:]
+			continue
+		}
+
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			break
+		}
+		data = data[n:]
+		switch number {
+		case httpRuleGet:
+			rule.Method, rule.Path = "get", string(value)
+		case httpRulePut:
+			rule.Method, rule.Path = "put", string(value)
+		case httpRulePost:
+			rule.Method, rule.Path = "post", string(value)
+		case httpRuleDelete:
+			rule.Method, rule.Path = "delete", string(value)
+		case httpRulePatch:
+			rule.Method, rule.Path = "patch", string(value)
+		case httpRuleCustom:
+			rule.Method, rule.Path = parseCustomHTTPPattern(value)
+		case httpRuleBody:
+			rule.Body = string(value)
+		case httpRuleResponseBody:
+			rule.ResponseBody = string(value)
+		case httpRuleAdditionalBindings:
+			additionalBindings = append(additionalBindings, parseHTTPRule(value)...)
+		}
+	}
+
+	if rule.Method == "" || rule.Path == "" {
+		return additionalBindings
+	}
+	return append([]*HTTPRule{rule}, additionalBindings...)
+}
+
+func parseCustomHTTPPattern(data []byte) (method, path string) {
+	for len(data) > 0 {
+		number, wireType, n := protowire.ConsumeTag(data)
+		if n < 0 || wireType != protowire.BytesType {
+			return "", ""
+		}
+		data = data[n:]
+		value, n := protowire.ConsumeBytes(data)
+		if n < 0 {
+			return "", ""
+		}
+		data = data[n:]
+		switch number {
+		case customHTTPPatternKind:
+			method = strings.ToLower(string(value))
+		case customHTTPPatternPath:
+			path = string(value)
+		}
+	}
+
+	return method, path
+}
+
+var pathVariablePattern = regexp.MustCompile(`\{([^}=]+)(=[^}]*)?}`)
+
+// openAPIPath 将路径模板转换为OpenAPI路径，例如 /v1/{name=messages/*} -> /v1/{name}，同时返回路径参数
+func openAPIPath(template string) (string, []string) {
+	var parameters []string
+	path := pathVariablePattern.ReplaceAllStringFunc(template, func(variable string) string {
+		name := strings.TrimSpace(pathVariablePattern.FindStringSubmatch(variable)[1])
+		parameters = append(parameters, name)
+		return "{" + name + "}"
+	})
+
+	return path, parameters
+}
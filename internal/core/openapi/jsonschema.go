@@ -0,0 +1,51 @@
+package openapi
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema package中所有message和enum的schema，每个类型可以通过 #/$defs/<full name> 引用
+type JSONSchema struct {
+	Schema string             `json:"$schema"`
+	ID     string             `json:"$id"`
+	Defs   map[string]*Schema `json:"$defs"`
+}
+
+// GenerateJSONSchema 生成package中所有message和enum的JSON Schema，引用的其他package的类型也会包含在$defs中
+func GenerateJSONSchema(id string, files []protoreflect.FileDescriptor) *JSONSchema {
+	builder := newSchemaBuilder("#/$defs/")
+	for _, file := range files {
+		addMessages(builder, file.Messages())
+		enums := file.Enums()
+		for i := 0; i < enums.Len(); i++ {
+			builder.addEnum(enums.Get(i))
+		}
+	}
+
+	return &JSONSchema{
+		Schema: jsonSchemaDialect,
+		ID:     id,
+		Defs:   builder.schemas,
+	}
+}
+
+// addMessages 加入message以及嵌套的message和enum
+func addMessages(builder *schemaBuilder, messages protoreflect.MessageDescriptors) {
+	for i := 0; i < messages.Len(); i++ {
+		messageDescriptor := messages.Get(i)
+		if messageDescriptor.IsMapEntry() {
+			continue
+		}
+		if _, ok := wellKnownSchemas[messageDescriptor.FullName()]; !ok {
+			builder.addMessage(messageDescriptor)
+		}
+
+		addMessages(builder, messageDescriptor.Messages())
+		enums := messageDescriptor.Enums()
+		for j := 0; j < enums.Len(); j++ {
+			builder.addEnum(enums.Get(j))
+		}
+	}
+}
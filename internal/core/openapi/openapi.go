@@ -0,0 +1,231 @@
+package openapi
+
+import (
+	"fmt"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"strings"
+)
+
+// 支持的导出格式
+const (
+	FormatOpenAPI    = "openapi"
+	FormatJSONSchema = "jsonschema"
+)
+
+const openAPIVersion = "3.0.3"
+
+// Document OpenAPI v3文档
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       *Info                `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components *Components          `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem key为小写的http method
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string               `json:"operationId"`
+	Tags        []string             `json:"tags,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Deprecated  bool                 `json:"deprecated,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path/query
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// GenerateOpenAPI 根据package中的service生成OpenAPI文档
+// 有google.api.http注解的method按照注解转换，没有注解的method使用 POST /{service}/{method}
+func GenerateOpenAPI(title, version string, files []protoreflect.FileDescriptor) *Document {
+	builder := newSchemaBuilder("#/components/schemas/")
+	document := &Document{
+		OpenAPI: openAPIVersion,
+		Info: &Info{
+			Title:   title,
+			Version: version,
+		},
+		Paths: map[string]*PathItem{},
+	}
+
+	for _, file := range files {
+		services := file.Services()
+		for i := 0; i < services.Len(); i++ {
+			serviceDescriptor := services.Get(i)
+			methods := serviceDescriptor.Methods()
+			for j := 0; j < methods.Len(); j++ {
+				methodDescriptor := methods.Get(j)
+				rules := httpRules(methodDescriptor)
+				if len(rules) == 0 {
+					rules = []*HTTPRule{{
+						Method: "post",
+						Path:   fmt.Sprintf("/%s/%s", serviceDescriptor.FullName(), methodDescriptor.Name()),
+						Body:   "*",
+					}}
+				}
+
+				for k, rule := range rules {
+					operationID := fmt.Sprintf("%s_%s", serviceDescriptor.Name(), methodDescriptor.Name())
+					if k > 0 {
+						operationID = fmt.Sprintf("%s_%d", operationID, k)
+					}
+					path, operation := buildOperation(builder, operationID, methodDescriptor, rule)
+					pathItem, ok := document.Paths[path]
+					if !ok {
+						pathItem = &PathItem{}
+						document.Paths[path] = pathItem
+					}
+					(*pathItem)[rule.Method] = operation
+				}
+			}
+		}
+	}
+
+	document.Components = &Components{Schemas: builder.schemas}
+	return document
+}
+
+func buildOperation(builder *schemaBuilder, operationID string, methodDescriptor protoreflect.MethodDescriptor, rule *HTTPRule) (string, *Operation) {
+	request, response := methodDescriptor.Input(), methodDescriptor.Output()
+	operation := &Operation{
+		OperationID: operationID,
+		Tags:        []string{string(methodDescriptor.Parent().Name())},
+		Description: description(methodDescriptor),
+		Responses:   map[string]*Response{},
+	}
+	if options, ok := methodDescriptor.Options().(interface{ GetDeprecated() bool }); ok {
+		operation.Deprecated = options.GetDeprecated()
+	}
+
+	// 路径参数
+	path, pathParameters := openAPIPath(rule.Path)
+	usedFields := map[string]struct{}{}
+	for _, name := range pathParameters {
+		parameter := &Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		}
+		if fieldDescriptor := findField(request, name); fieldDescriptor != nil {
+			parameter.Schema = builder.fieldSchema(fieldDescriptor)
+			parameter.Description, parameter.Schema.Description = parameter.Schema.Description, ""
+		}
+		operation.Parameters = append(operation.Parameters, parameter)
+		usedFields[strings.SplitN(name, ".", 2)[0]] = struct{}{}
+	}
+
+	// 请求体
+	switch rule.Body {
+	case "":
+		// 没有请求体，其余字段作为query参数
+		fields := request.Fields()
+		for i := 0; i < fields.Len(); i++ {
+			fieldDescriptor := fields.Get(i)
+			if _, ok := usedFields[string(fieldDescriptor.Name())]; ok || !isQueryParameter(fieldDescriptor) {
+				continue
+			}
+			schema := builder.fieldSchema(fieldDescriptor)
+			parameter := &Parameter{
+				Name:        fieldDescriptor.JSONName(),
+				In:          "query",
+				Description: schema.Description,
+				Schema:      schema,
+			}
+			schema.Description = ""
+			operation.Parameters = append(operation.Parameters, parameter)
+		}
+	case "*":
+		operation.RequestBody = jsonRequestBody(builder.messageSchema(request))
+	default:
+		if fieldDescriptor := findField(request, rule.Body); fieldDescriptor != nil {
+			operation.RequestBody = jsonRequestBody(builder.fieldSchema(fieldDescriptor))
+		}
+	}
+
+	// 响应
+	responseSchema := builder.messageSchema(response)
+	if rule.ResponseBody != "" {
+		if fieldDescriptor := findField(response, rule.ResponseBody); fieldDescriptor != nil {
+			responseSchema = builder.fieldSchema(fieldDescriptor)
+		}
+	}
+	operation.Responses["200"] = &Response{
+		Description: "OK",
+		Content: map[string]*MediaType{
+			"application/json": {Schema: responseSchema},
+		},
+	}
+
+	return path, operation
+}
+
+func jsonRequestBody(schema *Schema) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content: map[string]*MediaType{
+			"application/json": {Schema: schema},
+		},
+	}
+}
+
+// isQueryParameter query参数只支持标量、enum以及它们的repeated字段
+func isQueryParameter(fieldDescriptor protoreflect.FieldDescriptor) bool {
+	if fieldDescriptor.IsMap() {
+		return false
+	}
+	if fieldDescriptor.Kind() == protoreflect.MessageKind || fieldDescriptor.Kind() == protoreflect.GroupKind {
+		_, ok := wellKnownSchemas[fieldDescriptor.Message().FullName()]
+		return ok
+	}
+
+	return true
+}
+
+// findField 查找字段路径对应的字段，例如 message.name
+func findField(messageDescriptor protoreflect.MessageDescriptor, fieldPath string) protoreflect.FieldDescriptor {
+	var fieldDescriptor protoreflect.FieldDescriptor
+	for _, name := range strings.Split(fieldPath, ".") {
+		if messageDescriptor == nil {
+			return nil
+		}
+		fieldDescriptor = messageDescriptor.Fields().ByName(protoreflect.Name(name))
+		if fieldDescriptor == nil {
+			return nil
+		}
+		messageDescriptor = fieldDescriptor.Message()
+	}
+
+	return fieldDescriptor
+}
@@ -0,0 +1,164 @@
+package openapi
+
+import (
+	"context"
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"testing"
+)
+
+var testSources = map[string]string{
+	"google/api/http.proto": `syntax = "proto3";
+package google.api;
+message HttpRule {
+  string selector = 1;
+  oneof pattern {
+    string get = 2;
+    string put = 3;
+    string post = 4;
+    string delete = 5;
+    string patch = 6;
+    CustomHttpPattern custom = 8;
+  }
+  string body = 7;
+  string response_body = 12;
+  repeated HttpRule additional_bindings = 11;
+}
+message CustomHttpPattern {
+  string kind = 1;
+  string path = 2;
+}`,
+	"google/api/annotations.proto": `syntax = "proto3";
+package google.api;
+import "google/api/http.proto";
+import "google/protobuf/descriptor.proto";
+extend google.protobuf.MethodOptions {
+  HttpRule http = 72295728;
+}`,
+	"acme/weather/v1/weather.proto": `syntax = "proto3";
+package acme.weather.v1;
+import "google/api/annotations.proto";
+import "google/protobuf/timestamp.proto";
+
+// Weather 天气
+message Weather {
+  string location = 1;
+  Condition condition = 2;
+  google.protobuf.Timestamp time = 3;
+  int64 temperature = 4;
+  map<string, Weather> nearby = 5;
+  repeated string tags = 6;
+}
+
+enum Condition {
+  CONDITION_UNSPECIFIED = 0;
+  CONDITION_SUNNY = 1;
+}
+
+message GetWeatherRequest {
+  string location = 1;
+  int32 days = 2;
+}
+
+message UpdateWeatherRequest {
+  string location = 1;
+  Weather weather = 2;
+}
+
+service WeatherService {
+  // GetWeather 获取天气
+  rpc GetWeather(GetWeatherRequest) returns (Weather) {
+    option (google.api.http) = {
+      get: "/v1/weather/{location=cities/*}"
+      additional_bindings { get: "/v1/weather" }
+    };
+  }
+  rpc UpdateWeather(UpdateWeatherRequest) returns (Weather) {
+    option (google.api.http) = {
+      patch: "/v1/weather/{location}"
+      body: "weather"
+    };
+  }
+  rpc Subscribe(GetWeatherRequest) returns (stream Weather);
+}`,
+}
+
+func compileTestFiles(t *testing.T) []protoreflect.FileDescriptor {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(testSources),
+		}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	files, err := compiler.Compile(context.Background(), "acme/weather/v1/weather.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return []protoreflect.FileDescriptor{files[0]}
+}
+
+func TestGenerateOpenAPI(t *testing.T) {
+	document := GenerateOpenAPI("acme.weather.v1", "main", compileTestFiles(t))
+
+	getOperation := (*document.Paths["/v1/weather/{location}"])["get"]
+	if getOperation == nil {
+		t.Fatalf("missing get operation: %v", document.Paths)
+	}
+	if getOperation.OperationID != "WeatherService_GetWeather" || getOperation.Description != "GetWeather 获取天气" {
+		t.Errorf("unexpected operation: %+v", getOperation)
+	}
+	if len(getOperation.Parameters) != 2 || getOperation.Parameters[0].In != "path" || getOperation.Parameters[1].Name != "days" || getOperation.Parameters[1].In != "query" {
+		t.Errorf("unexpected parameters: %+v", getOperation.Parameters)
+	}
+	if additional := (*document.Paths["/v1/weather"])["get"]; additional == nil || additional.OperationID != "WeatherService_GetWeather_1" {
+		t.Errorf("missing additional binding: %v", document.Paths)
+	}
+
+	patchOperation := (*document.Paths["/v1/weather/{location}"])["patch"]
+	if patchOperation == nil || patchOperation.RequestBody.Content["application/json"].Schema.Ref != "#/components/schemas/acme.weather.v1.Weather" {
+		t.Errorf("unexpected patch operation: %+v", patchOperation)
+	}
+
+	// 没有注解的method
+	if subscribe := (*document.Paths["/acme.weather.v1.WeatherService/Subscribe"])["post"]; subscribe == nil || subscribe.RequestBody == nil {
+		t.Errorf("missing default binding: %v", document.Paths)
+	}
+
+	weather := document.Components.Schemas["acme.weather.v1.Weather"]
+	if weather == nil || weather.Description != "Weather 天气" {
+		t.Fatalf("unexpected weather schema: %+v", weather)
+	}
+	if weather.Properties["time"].Format != "date-time" || weather.Properties["temperature"].Type != "string" {
+		t.Errorf("unexpected well known or int64 mapping: %+v", weather.Properties)
+	}
+	if weather.Properties["nearby"].AdditionalProperties.Ref != "#/components/schemas/acme.weather.v1.Weather" || weather.Properties["tags"].Items.Type != "string" {
+		t.Errorf("unexpected map or repeated mapping: %+v", weather.Properties)
+	}
+	if condition := document.Components.Schemas["acme.weather.v1.Condition"]; condition == nil || len(condition.Enum) != 2 {
+		t.Errorf("unexpected enum schema: %+v", condition)
+	}
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	schema := GenerateJSONSchema("acme.weather.v1", compileTestFiles(t))
+
+	for _, name := range []string{"acme.weather.v1.Weather", "acme.weather.v1.Condition", "acme.weather.v1.GetWeatherRequest", "acme.weather.v1.UpdateWeatherRequest"} {
+		if _, ok := schema.Defs[name]; !ok {
+			t.Errorf("missing %s in $defs", name)
+		}
+	}
+	if ref := schema.Defs["acme.weather.v1.UpdateWeatherRequest"].Properties["weather"].Ref; ref != "#/$defs/acme.weather.v1.Weather" {
+		t.Errorf("unexpected ref %s", ref)
+	}
+	if _, ok := schema.Defs["acme.weather.v1.Weather.NearbyEntry"]; ok {
+		t.Errorf("map entry should not be in $defs")
+	}
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	path, parameters := openAPIPath("/v1/{name=projects/*/messages/*}:cancel")
+	if path != "/v1/{name}:cancel" || len(parameters) != 1 || parameters[0] != "name" {
+		t.Errorf("unexpected path %s %v", path, parameters)
+	}
+}
@@ -0,0 +1,188 @@
+package openapi
+
+import (
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"strings"
+)
+
+// Schema OpenAPI v3和JSON Schema共用的schema子集，字段按照proto3 JSON mapping转换
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+}
+
+// wellKnownSchemas well known types有特殊的JSON表示，直接内联
+var wellKnownSchemas = map[protoreflect.FullName]func() *Schema{
+	"google.protobuf.Timestamp":   func() *Schema { return &Schema{Type: "string", Format: "date-time"} },
+	"google.protobuf.Duration":    func() *Schema { return &Schema{Type: "string"} },
+	"google.protobuf.FieldMask":   func() *Schema { return &Schema{Type: "string"} },
+	"google.protobuf.Struct":      func() *Schema { return &Schema{Type: "object", AdditionalProperties: &Schema{}} },
+	"google.protobuf.Value":       func() *Schema { return &Schema{} },
+	"google.protobuf.ListValue":   func() *Schema { return &Schema{Type: "array", Items: &Schema{}} },
+	"google.protobuf.Empty":       func() *Schema { return &Schema{Type: "object"} },
+	"google.protobuf.DoubleValue": func() *Schema { return &Schema{Type: "number", Format: "double"} },
+	"google.protobuf.FloatValue":  func() *Schema { return &Schema{Type: "number", Format: "float"} },
+	"google.protobuf.Int64Value":  func() *Schema { return &Schema{Type: "string", Format: "int64"} },
+	"google.protobuf.UInt64Value": func() *Schema { return &Schema{Type: "string", Format: "uint64"} },
+	"google.protobuf.Int32Value":  func() *Schema { return &Schema{Type: "integer", Format: "int32"} },
+	"google.protobuf.UInt32Value": func() *Schema { return &Schema{Type: "integer", Format: "int64"} },
+	"google.protobuf.BoolValue":   func() *Schema { return &Schema{Type: "boolean"} },
+	"google.protobuf.StringValue": func() *Schema { return &Schema{Type: "string"} },
+	"google.protobuf.BytesValue":  func() *Schema { return &Schema{Type: "string", Format: "byte"} },
+	"google.protobuf.Any": func() *Schema {
+		return &Schema{
+			Type:                 "object",
+			Properties:           map[string]*Schema{"@type": {Type: "string"}},
+			AdditionalProperties: &Schema{},
+		}
+	},
+}
+
+// schemaBuilder 生成message和enum的schema，引用的类型会递归加入schemas
+type schemaBuilder struct {
+	refPrefix string // 例如 #/components/schemas/
+	schemas   map[string]*Schema
+}
+
+func newSchemaBuilder(refPrefix string) *schemaBuilder {
+	return &schemaBuilder{
+		refPrefix: refPrefix,
+		schemas:   map[string]*Schema{},
+	}
+}
+
+// addMessage 生成message的schema，已经生成过的message直接跳过
+func (b *schemaBuilder) addMessage(messageDescriptor protoreflect.MessageDescriptor) {
+	name := string(messageDescriptor.FullName())
+	if _, ok := b.schemas[name]; ok {
+		return
+	}
+
+	schema := &Schema{
+		Type:        "object",
+		Description: description(messageDescriptor),
+		Properties:  map[string]*Schema{},
+	}
+	// 先占位，避免循环引用时无限递归
+	b.schemas[name] = schema
+
+	if options, ok := messageDescriptor.Options().(interface{ GetDeprecated() bool }); ok {
+		schema.Deprecated = options.GetDeprecated()
+	}
+
+	fields := messageDescriptor.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fieldDescriptor := fields.Get(i)
+		schema.Properties[fieldDescriptor.JSONName()] = b.fieldSchema(fieldDescriptor)
+		if fieldDescriptor.Cardinality() == protoreflect.Required {
+			schema.Required = append(schema.Required, fieldDescriptor.JSONName())
+		}
+	}
+}
+
+// addEnum 生成enum的schema，enum在JSON中使用名称表示
+func (b *schemaBuilder) addEnum(enumDescriptor protoreflect.EnumDescriptor) {
+	name := string(enumDescriptor.FullName())
+	if _, ok := b.schemas[name]; ok {
+		return
+	}
+
+	values := enumDescriptor.Values()
+	schema := &Schema{
+		Type:        "string",
+		Description: description(enumDescriptor),
+		Enum:        make([]string, 0, values.Len()),
+	}
+	for i := 0; i < values.Len(); i++ {
+		schema.Enum = append(schema.Enum, string(values.Get(i).Name()))
+	}
+	b.schemas[name] = schema
+}
+
+func (b *schemaBuilder) fieldSchema(fieldDescriptor protoreflect.FieldDescriptor) *Schema {
+	var schema *Schema
+	switch {
+	case fieldDescriptor.IsMap():
+		schema = &Schema{
+			Type:                 "object",
+			AdditionalProperties: b.singularSchema(fieldDescriptor.MapValue()),
+		}
+	case fieldDescriptor.IsList():
+		schema = &Schema{
+			Type:  "array",
+			Items: b.singularSchema(fieldDescriptor),
+		}
+	default:
+		schema = b.singularSchema(fieldDescriptor)
+	}
+
+	if schema.Ref == "" {
+		// $ref的同级字段会被忽略
+		schema.Description = description(fieldDescriptor)
+		if options, ok := fieldDescriptor.Options().(interface{ GetDeprecated() bool }); ok {
+			schema.Deprecated = options.GetDeprecated()
+		}
+	}
+
+	return schema
+}
+
+// singularSchema 忽略repeated和map，只转换字段的类型
+func (b *schemaBuilder) singularSchema(fieldDescriptor protoreflect.FieldDescriptor) *Schema {
+	switch fieldDescriptor.Kind() {
+	case protoreflect.BoolKind:
+		return &Schema{Type: "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return &Schema{Type: "integer", Format: "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return &Schema{Type: "integer", Format: "int64"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		// 64位整数在JSON中使用字符串表示
+		return &Schema{Type: "string", Format: "int64"}
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return &Schema{Type: "string", Format: "uint64"}
+	case protoreflect.FloatKind:
+		return &Schema{Type: "number", Format: "float"}
+	case protoreflect.DoubleKind:
+		return &Schema{Type: "number", Format: "double"}
+	case protoreflect.StringKind:
+		return &Schema{Type: "string"}
+	case protoreflect.BytesKind:
+		return &Schema{Type: "string", Format: "byte"}
+	case protoreflect.EnumKind:
+		b.addEnum(fieldDescriptor.Enum())
+		return b.ref(fieldDescriptor.Enum().FullName())
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return b.messageSchema(fieldDescriptor.Message())
+	}
+
+	return &Schema{}
+}
+
+// messageSchema well known types内联，其余message使用$ref引用
+func (b *schemaBuilder) messageSchema(messageDescriptor protoreflect.MessageDescriptor) *Schema {
+	if wellKnownSchema, ok := wellKnownSchemas[messageDescriptor.FullName()]; ok {
+		return wellKnownSchema()
+	}
+
+	b.addMessage(messageDescriptor)
+	return b.ref(messageDescriptor.FullName())
+}
+
+func (b *schemaBuilder) ref(fullName protoreflect.FullName) *Schema {
+	return &Schema{Ref: b.refPrefix + string(fullName)}
+}
+
+// description 使用descriptor的注释作为描述
+func description(descriptor protoreflect.Descriptor) string {
+	location := descriptor.ParentFile().SourceLocations().ByDescriptor(descriptor)
+	return strings.TrimSpace(location.LeadingComments)
+}
@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"context"
+	"expvar"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleprotocompile"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
+	"github.com/ProtobufMan/bufman/internal/config"
+	"github.com/ProtobufMan/bufman/internal/core/lru"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/bufbuild/protocompile/linker"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 编译结果中还包含AST和source info，实际占用的内存按照描述符大小的倍数估算
+const compiledSizeFactor = 4
+
+var (
+	sharedCompileCache     *compileCache
+	sharedCompileCacheOnce sync.Once
+
+	// 编译缓存的监控指标，debug模式下通过 /debug/vars 查看
+	compileCacheMetrics = expvar.NewMap("compile_cache")
+)
+
+// ModuleLoader 读取模块的文件，只在编译缓存未命中时调用
+type ModuleLoader func(ctx context.Context) (*manifest.Manifest, *manifest.BlobSet, e.ResponseError)
+
+// Module 已经push的模块，commit不可变，所以作为依赖时可以使用identity和commit作为编译缓存的key
+type Module struct {
+	Identity       bufmoduleref.ModuleIdentity
+	Commit         string
+	ManifestDigest string // manifest digest的hex，作为根模块编译时用于缓存的key
+	Load           ModuleLoader
+}
+
+// rootCacheKey 根模块编译时不设置identity，push时还没有commit的模块和已经push的模块都使用manifest digest作为key
+func rootCacheKey(manifestDigest string) string {
+	return "manifest:" + manifestDigest
+}
+
+// dependencyCacheKey 依赖编译时设置了identity和commit，使用identity和commit作为key
+func dependencyCacheKey(identity bufmoduleref.ModuleIdentity, commit string) string {
+	return identity.IdentityString() + "@" + commit
+}
+
+// compiled 编译结果
+type compiled struct {
+	linkers               linker.Files
+	parserAccessorHandler bufmoduleprotocompile.ParserAccessorHandler
+}
+
+// compileCache 按照内存占用限制容量的编译结果缓存，docs和push共享同一个缓存
+type compileCache struct {
+	lru   *lru.SizedLru // 为nil时表示不使用缓存
+	group singleflight.Group
+}
+
+func getCompileCache() *compileCache {
+	sharedCompileCacheOnce.Do(func() {
+		sharedCompileCache = newCompileCache(config.Properties.BufMan.CompileCacheSize << 20)
+	})
+
+	return sharedCompileCache
+}
+
+func newCompileCache(maxSize int64) *compileCache {
+	cache := &compileCache{}
+	if maxSize > 0 {
+		cache.lru = lru.NewSizedLru(maxSize)
+		cache.lru.OnEvict = func(key interface{}, value interface{}) {
+			compileCacheMetrics.Add("evictions", 1)
+		}
+	}
+
+	compileCacheMetrics.Set("entries", expvar.Func(func() interface{} {
+		if cache.lru == nil {
+			return 0
+		}
+		return cache.lru.Len()
+	}))
+	compileCacheMetrics.Set("bytes", expvar.Func(func() interface{} {
+		if cache.lru == nil {
+			return 0
+		}
+		return cache.lru.Size()
+	}))
+	compileCacheMetrics.Set("hit_rate", expvar.Func(func() interface{} {
+		hits, misses := metricValue("hits"), metricValue("misses")
+		if hits+misses == 0 {
+			return 0.0
+		}
+		return float64(hits) / float64(hits+misses)
+	}))
+
+	return cache
+}
+
+// getOrCompile 查询缓存，未命中时调用compileFunc编译并加入缓存，同一个key同时只会编译一次
+// 编译结果会被同时等待的请求共享，所以compileFunc使用不随任何请求取消的ctx
+func (cache *compileCache) getOrCompile(key string, compileFunc func(ctx context.Context) (*compiled, e.ResponseError)) (*compiled, e.ResponseError) {
+	if cache.lru != nil {
+		if value, ok := cache.lru.Get(key); ok {
+			compileCacheMetrics.Add("hits", 1)
+			return value.(*compiled), nil
+		}
+	}
+	compileCacheMetrics.Add("misses", 1)
+
+	value, err, shared := cache.group.Do(key, func() (interface{}, error) {
+		result, respErr := compileFunc(context.Background())
+		if respErr != nil {
+			return nil, respErr
+		}
+		if cache.lru != nil {
+			cache.lru.Add(key, result, estimateCompiledSize(result.linkers))
+		}
+
+		return result, nil
+	})
+	if shared {
+		// 等待了正在进行的编译
+		compileCacheMetrics.Add("shared", 1)
+	}
+	if err != nil {
+		return nil, err.(e.ResponseError)
+	}
+
+	return value.(*compiled), nil
+}
+
+// compileCacheKey 由模块和全部依赖组成缓存的key，依赖的顺序不影响key
+func compileCacheKey(moduleKey string, dependentModuleKeys []string) string {
+	keys := make([]string, len(dependentModuleKeys))
+	copy(keys, dependentModuleKeys)
+	sort.Strings(keys)
+
+	return moduleKey + "|" + strings.Join(keys, ",")
+}
+
+// estimateCompiledSize 估算编译结果占用的内存，包括所有被import的文件
+func estimateCompiledSize(linkers linker.Files) int64 {
+	var size int64
+	seen := map[string]struct{}{}
+	var walk func(file protoreflect.FileDescriptor)
+	walk = func(file protoreflect.FileDescriptor) {
+		if _, ok := seen[file.Path()]; ok {
+			return
+		}
+		seen[file.Path()] = struct{}{}
+		size += int64(proto.Size(protodesc.ToFileDescriptorProto(file)))
+
+		imports := file.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			walk(imports.Get(i).FileDescriptor)
+		}
+	}
+	for _, file := range linkers {
+		walk(file)
+	}
+
+	return size * compiledSizeFactor
+}
+
+func metricValue(key string) int64 {
+	if value, ok := compileCacheMetrics.Get(key).(*expvar.Int); ok {
+		return value.Value()
+	}
+
+	return 0
+}
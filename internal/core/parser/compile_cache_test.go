@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"context"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCompileCacheSingleflight(t *testing.T) {
+	cache := newCompileCache(1 << 20)
+
+	var calls int32
+	release := make(chan struct{})
+	compileFunc := func(ctx context.Context) (*compiled, e.ResponseError) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return &compiled{}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]*compiled, 10)
+	for i := 0; i < len(results); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := cache.getOrCompile("key", compileFunc)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = result
+		}(i)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// 同时请求的编译只执行一次，之后的请求命中缓存
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected one compile, got %d", n)
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i] != results[0] {
+			t.Fatal("expected shared compile result")
+		}
+	}
+}
+
+func TestCompileCacheError(t *testing.T) {
+	cache := newCompileCache(1 << 20)
+
+	calls := 0
+	compileFunc := func(ctx context.Context) (*compiled, e.ResponseError) {
+		calls++
+		return nil, e.NewInternalError("compile")
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := cache.getOrCompile("key", compileFunc); err == nil {
+			t.Fatal("expected error")
+		}
+	}
+
+	// 编译失败的结果不会被缓存
+	if calls != 2 {
+		t.Errorf("expected two compiles, got %d", calls)
+	}
+}
+
+func TestCompileCacheKey(t *testing.T) {
+	if compileCacheKey("root", []string{"b", "a"}) != compileCacheKey("root", []string{"a", "b"}) {
+		t.Error("expected key independent of dependency order")
+	}
+
+	_, weather, _ := newTestDependencies(t)
+	manifestBlob, err := weather.fileManifest.Blob()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := manifestCacheKey(weather.fileManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != rootCacheKey(manifestBlob.Digest().Hex()) {
+		t.Errorf("expected push and docs to use the same key, got %s", key)
+	}
+}
+
+// TestCompileCacheSharedByPushAndDocs push时的依赖检查和之后生成文档使用同一个编译结果
+func TestCompileCacheSharedByPushAndDocs(t *testing.T) {
+	units, weather, _ := newTestDependencies(t)
+	app := newTestModule(t, "app", map[string]string{
+		"app/v1/app.proto": "syntax = \"proto3\";\n\npackage app.v1;\n\nimport \"weather/v1/weather.proto\";\n\nmessage Report {\n  weather.v1.Weather weather = 1;\n}\n",
+	})
+
+	protoParser := &ProtoParserImpl{cache: newCompileCache(1 << 20)}
+	ctx := context.Background()
+	dependencies := []*testModule{weather, units}
+	_, err := protoParser.CheckDependencies(ctx, app.fileManifest, app.blobSet, []string{weather.identity.IdentityString()},
+		[]bufmoduleref.ModuleIdentity{weather.identity, units.identity}, []string{weather.commit, units.commit},
+		[]*manifest.Manifest{weather.fileManifest, units.fileManifest}, []*manifest.BlobSet{weather.blobSet, units.blobSet})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 命中缓存时不会读取模块的文件
+	load := func(ctx context.Context) (*manifest.Manifest, *manifest.BlobSet, e.ResponseError) {
+		t.Error("unexpected load")
+		return nil, nil, e.NewInternalError("load")
+	}
+	manifestBlob, manifestErr := app.fileManifest.Blob()
+	if manifestErr != nil {
+		t.Fatal(manifestErr)
+	}
+	module := &Module{Identity: app.identity, Commit: app.commit, ManifestDigest: manifestBlob.Digest().Hex(), Load: load}
+	dependentModules := make([]*Module, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		dependentModules = append(dependentModules, &Module{Identity: dependency.identity, Commit: dependency.commit, Load: load})
+	}
+	files, err := protoParser.GetModuleFiles(ctx, module, dependentModules)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Path() != "app/v1/app.proto" {
+		t.Errorf("unexpected files %v", files)
+	}
+}
+
+// TestCompileDetachedFromRequest 第一个请求被取消时，共享的编译不会随之失败
+func TestCompileDetachedFromRequest(t *testing.T) {
+	units, _, _ := newTestDependencies(t)
+	manifestBlob, err := units.fileManifest.Blob()
+	if err != nil {
+		t.Fatal(err)
+	}
+	module := &Module{
+		Identity:       units.identity,
+		Commit:         units.commit,
+		ManifestDigest: manifestBlob.Digest().Hex(),
+		Load: func(ctx context.Context) (*manifest.Manifest, *manifest.BlobSet, e.ResponseError) {
+			if ctx.Err() != nil {
+				return nil, nil, e.NewInternalError(ctx.Err().Error())
+			}
+			return units.fileManifest, units.blobSet, nil
+		},
+	}
+
+	protoParser := &ProtoParserImpl{cache: newCompileCache(1 << 20)}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, respErr := protoParser.GetModuleFiles(ctx, module, nil); respErr != nil {
+		t.Fatal(respErr)
+	}
+}
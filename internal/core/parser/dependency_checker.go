@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"sort"
+)
+
+// DependencyReport 依赖检查结果
+type DependencyReport struct {
+	UnusedDependencies []string            // 在buf.yaml中声明了，但是没有被任何文件import的依赖
+	UndeclaredImports  []*UndeclaredImport // 没有在buf.yaml中声明，只能通过间接依赖找到的import
+}
+
+// UndeclaredImport 只能通过间接依赖找到的import
+type UndeclaredImport struct {
+	File       string // 当前模块中的文件
+	Import     string // import的文件
+	Dependency string // import的文件所在的模块
+}
+
+// HasIssues 是否存在依赖问题
+func (report *DependencyReport) HasIssues() bool {
+	return len(report.UnusedDependencies) > 0 || len(report.UndeclaredImports) > 0
+}
+
+// Warnings 将依赖问题转换为可读的提示信息
+func (report *DependencyReport) Warnings() []string {
+	warnings := make([]string, 0, len(report.UnusedDependencies)+len(report.UndeclaredImports))
+	for _, dependency := range report.UnusedDependencies {
+		warnings = append(warnings, fmt.Sprintf("unused dependency %s", dependency))
+	}
+	for _, undeclaredImport := range report.UndeclaredImports {
+		warnings = append(warnings, fmt.Sprintf("%s imports %s from %s, which is not a declared dependency", undeclaredImport.File, undeclaredImport.Import, undeclaredImport.Dependency))
+	}
+
+	return warnings
+}
+
+func (protoParser *ProtoParserImpl) CheckDependencies(ctx context.Context, fileManifest *manifest.Manifest, blobSet *manifest.BlobSet, declaredDependencies []string, dependentIdentities []bufmoduleref.ModuleIdentity, dependentCommits []string, dependentManifests []*manifest.Manifest, dependentBlobSets []*manifest.BlobSet) (*DependencyReport, e.ResponseError) {
+	moduleKey, err := manifestCacheKey(fileManifest)
+	if err != nil {
+		return nil, e.NewInternalError(err.Error())
+	}
+	dependentModuleKeys := make([]string, 0, len(dependentIdentities))
+	for i := 0; i < len(dependentIdentities); i++ {
+		dependentModuleKeys = append(dependentModuleKeys, dependencyCacheKey(dependentIdentities[i], dependentCommits[i]))
+	}
+
+	// 编译proto文件，与push之后生成文档使用相同的key
+	result, respErr := protoParser.cache.getOrCompile(compileCacheKey(moduleKey, dependentModuleKeys), func(ctx context.Context) (*compiled, e.ResponseError) {
+		return protoParser.compileWithDependencies(ctx, fileManifest, blobSet, dependentIdentities, dependentCommits, dependentManifests, dependentBlobSets)
+	})
+	if respErr != nil {
+		return nil, respErr
+	}
+	linkers, parserAccessorHandler := result.linkers, result.parserAccessorHandler
+
+	declared := make(map[string]struct{}, len(declaredDependencies))
+	for _, dependency := range declaredDependencies {
+		declared[dependency] = struct{}{}
+	}
+
+	report := &DependencyReport{}
+	used := map[string]struct{}{}
+	for _, link := range linkers {
+		imports := link.Imports()
+		for i := 0; i < imports.Len(); i++ {
+			importPath := imports.Get(i).Path()
+			moduleIdentity := parserAccessorHandler.ModuleIdentity(importPath)
+			if moduleIdentity == nil {
+				// 当前模块中的文件或者well known types
+				continue
+			}
+
+			identity := moduleIdentity.IdentityString()
+			used[identity] = struct{}{}
+			if _, ok := declared[identity]; !ok {
+				report.UndeclaredImports = append(report.UndeclaredImports, &UndeclaredImport{
+					File:       link.Path(),
+					Import:     importPath,
+					Dependency: identity,
+				})
+			}
+		}
+	}
+
+	for _, dependency := range declaredDependencies {
+		if _, ok := used[dependency]; !ok {
+			report.UnusedDependencies = append(report.UnusedDependencies, dependency)
+		}
+	}
+	sort.Strings(report.UnusedDependencies)
+
+	return report, nil
+}
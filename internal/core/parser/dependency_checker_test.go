@@ -0,0 +1,147 @@
+package parser
+
+import (
+	"context"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
+	"strings"
+	"testing"
+)
+
+const testRemote = "bufman.io"
+
+// testModule 内存中的模块
+type testModule struct {
+	identity     bufmoduleref.ModuleIdentity
+	commit       string
+	fileManifest *manifest.Manifest
+	blobSet      *manifest.BlobSet
+}
+
+func newTestModule(t *testing.T, repositoryName string, files map[string]string) *testModule {
+	ctx := context.Background()
+	fileManifest := manifest.New()
+	blobs := make([]manifest.Blob, 0, len(files))
+	for path, content := range files {
+		blob, err := manifest.NewMemoryBlobFromReader(strings.NewReader(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = fileManifest.AddEntry(path, *blob.Digest()); err != nil {
+			t.Fatal(err)
+		}
+		blobs = append(blobs, blob)
+	}
+	blobSet, err := manifest.NewBlobSet(ctx, blobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity, err := bufmoduleref.NewModuleIdentity(testRemote, "acme", repositoryName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testModule{identity: identity, commit: repositoryName + "1", fileManifest: fileManifest, blobSet: blobSet}
+}
+
+// checkTestDependencies 检查module的依赖，declared为buf.yaml中声明的依赖
+func checkTestDependencies(t *testing.T, module *testModule, declared []*testModule, dependencies ...*testModule) *DependencyReport {
+	declaredDependencies := make([]string, 0, len(declared))
+	for _, dependency := range declared {
+		declaredDependencies = append(declaredDependencies, dependency.identity.IdentityString())
+	}
+	var identities []bufmoduleref.ModuleIdentity
+	var commits []string
+	var manifests []*manifest.Manifest
+	var blobSets []*manifest.BlobSet
+	for _, dependency := range dependencies {
+		identities = append(identities, dependency.identity)
+		commits = append(commits, dependency.commit)
+		manifests = append(manifests, dependency.fileManifest)
+		blobSets = append(blobSets, dependency.blobSet)
+	}
+
+	protoParser := &ProtoParserImpl{cache: newCompileCache(0)}
+	report, err := protoParser.CheckDependencies(context.Background(), module.fileManifest, module.blobSet, declaredDependencies, identities, commits, manifests, blobSets)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return report
+}
+
+func newTestDependencies(t *testing.T) (units, weather, extra *testModule) {
+	units = newTestModule(t, "units", map[string]string{
+		"units/v1/units.proto": "syntax = \"proto3\";\n\npackage units.v1;\n\nmessage Celsius {\n  double value = 1;\n}\n",
+	})
+	weather = newTestModule(t, "weather", map[string]string{
+		"weather/v1/weather.proto": "syntax = \"proto3\";\n\npackage weather.v1;\n\nimport \"units/v1/units.proto\";\n\nmessage Weather {\n  units.v1.Celsius temperature = 1;\n}\n",
+	})
+	extra = newTestModule(t, "extra", map[string]string{
+		"extra/v1/extra.proto": "syntax = \"proto3\";\n\npackage extra.v1;\n\nmessage Extra {}\n",
+	})
+
+	return units, weather, extra
+}
+
+func TestCheckDependencies(t *testing.T) {
+	units, weather, extra := newTestDependencies(t)
+
+	// app直接import了units，但是只声明了weather，extra声明了但是没有被使用
+	app := newTestModule(t, "app", map[string]string{
+		"app/v1/app.proto": "syntax = \"proto3\";\n\npackage app.v1;\n\nimport \"units/v1/units.proto\";\nimport \"weather/v1/weather.proto\";\n\nmessage Report {\n  weather.v1.Weather weather = 1;\n  units.v1.Celsius feels_like = 2;\n}\n",
+	})
+	report := checkTestDependencies(t, app, []*testModule{weather, extra}, weather, extra, units)
+
+	if !report.HasIssues() {
+		t.Fatal("expected issues")
+	}
+	if len(report.UnusedDependencies) != 1 || report.UnusedDependencies[0] != "bufman.io/acme/extra" {
+		t.Errorf("unexpected unused dependencies %v", report.UnusedDependencies)
+	}
+	if len(report.UndeclaredImports) != 1 {
+		t.Fatalf("expected one undeclared import, got %d", len(report.UndeclaredImports))
+	}
+	undeclaredImport := report.UndeclaredImports[0]
+	if undeclaredImport.File != "app/v1/app.proto" || undeclaredImport.Import != "units/v1/units.proto" || undeclaredImport.Dependency != "bufman.io/acme/units" {
+		t.Errorf("unexpected undeclared import %+v", undeclaredImport)
+	}
+
+	warnings := report.Warnings()
+	expected := []string{
+		"unused dependency bufman.io/acme/extra",
+		"app/v1/app.proto imports units/v1/units.proto from bufman.io/acme/units, which is not a declared dependency",
+	}
+	if strings.Join(warnings, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected warnings %q, got %q", expected, warnings)
+	}
+}
+
+func TestCheckDependenciesNoIssues(t *testing.T) {
+	units, weather, _ := newTestDependencies(t)
+
+	// 只通过weather间接使用units，不需要声明units
+	app := newTestModule(t, "app", map[string]string{
+		"app/v1/app.proto": "syntax = \"proto3\";\n\npackage app.v1;\n\nimport \"weather/v1/weather.proto\";\nimport \"google/protobuf/timestamp.proto\";\n\nmessage Report {\n  weather.v1.Weather weather = 1;\n  google.protobuf.Timestamp time = 2;\n}\n",
+	})
+	report := checkTestDependencies(t, app, []*testModule{weather}, weather, units)
+
+	if report.HasIssues() {
+		t.Errorf("expected no issues, got %q", report.Warnings())
+	}
+	if len(report.Warnings()) != 0 {
+		t.Errorf("expected no warnings, got %q", report.Warnings())
+	}
+}
+
+func TestCheckDependenciesCompileError(t *testing.T) {
+	// import的文件不存在
+	app := newTestModule(t, "app", map[string]string{
+		"app/v1/app.proto": "syntax = \"proto3\";\n\npackage app.v1;\n\nimport \"missing/v1/missing.proto\";\n",
+	})
+
+	protoParser := &ProtoParserImpl{cache: newCompileCache(0)}
+	if _, err := protoParser.CheckDependencies(context.Background(), app.fileManifest, app.blobSet, nil, nil, nil, nil, nil); err == nil {
+		t.Error("expected compile error")
+	}
+}
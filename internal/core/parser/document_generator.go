@@ -17,7 +17,6 @@ type DocumentGenerator interface {
 
 type documentGeneratorImpl struct {
 	modulePackageSet      map[string]struct{}
-	commitName            string // 当前的commitName，用于判断是否是外部依赖
 	packageLinkerMap      map[string]linker.Files
 	linkers               linker.Files
 	packageLinkers        linker.Files
@@ -25,9 +24,8 @@ type documentGeneratorImpl struct {
 	messageSet            map[string]*registryv1alpha1.Message
 }
 
-func NewDocumentGenerator(commitName string, linkers linker.Files, parserAccessorHandler bufmoduleprotocompile.ParserAccessorHandler) DocumentGenerator {
+func NewDocumentGenerator(linkers linker.Files, parserAccessorHandler bufmoduleprotocompile.ParserAccessorHandler) DocumentGenerator {
 	g := &documentGeneratorImpl{
-		commitName:            commitName,
 		linkers:               linkers,
 		parserAccessorHandler: parserAccessorHandler,
 		packageLinkerMap:      map[string]linker.Files{},
@@ -44,9 +42,9 @@ func NewDocumentGenerator(commitName string, linkers linker.Files, parserAccesso
 	return g
 }
 
-// isDependency 判断是否是外部依赖
+// isDependency 判断是否是外部依赖，编译时只有依赖设置了identity
 func (g *documentGeneratorImpl) isDependency(fileDescriptor protoreflect.FileDescriptor) bool {
-	return g.parserAccessorHandler.Commit(fileDescriptor.Path()) != g.commitName
+	return g.parserAccessorHandler.ModuleIdentity(fileDescriptor.Path()) != nil
 }
 
 func (g *documentGeneratorImpl) toProtoLocation(loc protoreflect.SourceLocation) *registryv1alpha1.Location {
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"github.com/ProtobufMan/bufman/internal/core/navigation"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Definition 类型定义的位置，可能在依赖的模块中
+type Definition struct {
+	Symbol
+	Remote     string // google/protobuf中的文件不属于任何模块，为空
+	Owner      string
+	Repository string
+	Commit     string
+}
+
+// Reference 模块中对类型的引用
+type Reference struct {
+	FilePath    string
+	StartLine   int32
+	StartColumn int32
+	EndLine     int32
+	EndColumn   int32
+}
+
+func (protoParser *ProtoParserImpl) GetDefinition(ctx context.Context, module *Module, dependentModules []*Module, filePath string, line, column int) (*Definition, e.ResponseError) {
+	// 编译proto文件
+	result, err := protoParser.compileModules(ctx, module, dependentModules)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptor, ok := navigation.FindDefinition(linkerFiles(result), filePath, line, column)
+	if !ok || symbolKind(descriptor) == "" {
+		return nil, e.NewNotFoundError(fmt.Sprintf("definition at %s:%d:%d", filePath, line, column))
+	}
+
+	file := descriptor.ParentFile()
+	span := navigation.DefinitionSpan(descriptor)
+	definition := &Definition{
+		Symbol: Symbol{
+			Kind:        symbolKind(descriptor),
+			Name:        string(descriptor.Name()),
+			FullName:    string(descriptor.FullName()),
+			PackageName: string(file.Package()),
+			FilePath:    file.Path(),
+			StartLine:   int32(span.StartLine),
+			StartColumn: int32(span.StartColumn),
+			EndLine:     int32(span.EndLine),
+			EndColumn:   int32(span.EndColumn),
+		},
+	}
+	if identity := result.parserAccessorHandler.ModuleIdentity(file.Path()); identity != nil {
+		// 依赖中的定义
+		definition.Remote, definition.Owner, definition.Repository = identity.Remote(), identity.Owner(), identity.Repository()
+		definition.Commit = result.parserAccessorHandler.Commit(file.Path())
+	} else if isModuleFile(result, file.Path()) {
+		// 编译时根模块不设置identity
+		definition.Remote, definition.Owner, definition.Repository = module.Identity.Remote(), module.Identity.Owner(), module.Identity.Repository()
+		definition.Commit = module.Commit
+	}
+
+	return definition, nil
+}
+
+func (protoParser *ProtoParserImpl) GetReferences(ctx context.Context, module *Module, dependentModules []*Module, fullName string) ([]*Reference, e.ResponseError) {
+	// 编译proto文件
+	result, err := protoParser.compileModules(ctx, module, dependentModules)
+	if err != nil {
+		return nil, err
+	}
+
+	// 只查找当前模块中的引用
+	var references []*Reference
+	for _, reference := range navigation.FindReferences(linkerFiles(result), protoreflect.FullName(fullName)) {
+		references = append(references, &Reference{
+			FilePath:    reference.FilePath,
+			StartLine:   int32(reference.Span.StartLine),
+			StartColumn: int32(reference.Span.StartColumn),
+			EndLine:     int32(reference.Span.EndLine),
+			EndColumn:   int32(reference.Span.EndColumn),
+		})
+	}
+
+	return references, nil
+}
+
+// isModuleFile 判断文件是否属于根模块
+func isModuleFile(result *compiled, path string) bool {
+	for _, link := range result.linkers {
+		if link.Path() == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+func linkerFiles(result *compiled) []protoreflect.FileDescriptor {
+	files := make([]protoreflect.FileDescriptor, 0, len(result.linkers))
+	for _, link := range result.linkers {
+		files = append(files, link)
+	}
+
+	return files
+}
@@ -2,6 +2,7 @@ package parser
 
 import (
 	"context"
+	"fmt"
 	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule"
 	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleprotocompile"
 	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
@@ -11,39 +12,48 @@ import (
 	"github.com/ProtobufMan/bufman/internal/e"
 	"github.com/bufbuild/protocompile"
 	"github.com/bufbuild/protocompile/linker"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"strings"
 )
 
 type ProtoParser interface {
-	// TryCompile 尝试编译，查看是否能够编译成功
-	TryCompile(ctx context.Context, fileManifest *manifest.Manifest, blobSet *manifest.BlobSet, dependentManifests []*manifest.Manifest, dependentBlobSets []*manifest.BlobSet) e.ResponseError
+	// CheckDependencies 编译并检查未使用的依赖以及只通过间接依赖引入的import
+	CheckDependencies(ctx context.Context, fileManifest *manifest.Manifest, blobSet *manifest.BlobSet, declaredDependencies []string, dependentIdentities []bufmoduleref.ModuleIdentity, dependentCommits []string, dependentManifests []*manifest.Manifest, dependentBlobSets []*manifest.BlobSet) (*DependencyReport, e.ResponseError)
 	// GetPackageDocumentation 获取package document
-	GetPackageDocumentation(ctx context.Context, packageName string, moduleIdentity bufmoduleref.ModuleIdentity, commitName string, fileManifest *manifest.Manifest, blobSet *manifest.BlobSet, dependentIdentities []bufmoduleref.ModuleIdentity, dependentCommits []string, dependentManifests []*manifest.Manifest, dependentBlobSets []*manifest.BlobSet) (*registryv1alpha1.PackageDocumentation, e.ResponseError)
+	GetPackageDocumentation(ctx context.Context, packageName string, module *Module, dependentModules []*Module) (*registryv1alpha1.PackageDocumentation, e.ResponseError)
 	// GetPackages 获取所有的package
-	GetPackages(ctx context.Context, fileManifest *manifest.Manifest, blobSet *manifest.BlobSet, dependentManifests []*manifest.Manifest, dependentBlobSets []*manifest.BlobSet) ([]*registryv1alpha1.ModulePackage, e.ResponseError)
+	GetPackages(ctx context.Context, module *Module, dependentModules []*Module) ([]*registryv1alpha1.ModulePackage, e.ResponseError)
+	// GetPackageFiles 获取package中所有文件编译后的描述符
+	GetPackageFiles(ctx context.Context, packageName string, module *Module, dependentModules []*Module) ([]protoreflect.FileDescriptor, e.ResponseError)
+	// GetModuleFiles 获取模块中所有文件编译后的描述符，可以通过Imports访问依赖的文件
+	GetModuleFiles(ctx context.Context, module *Module, dependentModules []*Module) ([]protoreflect.FileDescriptor, e.ResponseError)
+	// GetDefinition 获取文件中line、column位置引用的类型的定义，行列从0开始
+	GetDefinition(ctx context.Context, module *Module, dependentModules []*Module, filePath string, line, column int) (*Definition, e.ResponseError)
+	// GetReferences 获取模块中对fullName的所有引用
+	GetReferences(ctx context.Context, module *Module, dependentModules []*Module, fullName string) ([]*Reference, e.ResponseError)
+	// GetSymbols 获取模块中定义的message、field、enum、service、method
+	GetSymbols(ctx context.Context, module *Module, dependentModules []*Module) ([]*Symbol, e.ResponseError)
 }
 
 func NewProtoParser() ProtoParser {
-	return &ProtoParserImpl{}
+	return &ProtoParserImpl{
+		cache: getCompileCache(),
+	}
 }
 
 type ProtoParserImpl struct {
+	cache *compileCache
 }
 
-func (protoParser *ProtoParserImpl) GetPackages(ctx context.Context, fileManifest *manifest.Manifest, blobSet *manifest.BlobSet, dependentManifests []*manifest.Manifest, dependentBlobSets []*manifest.BlobSet) ([]*registryv1alpha1.ModulePackage, e.ResponseError) {
-	module, dependentModules, err := protoParser.getModules(ctx, fileManifest, blobSet, dependentManifests, dependentBlobSets)
-	if err != nil {
-		return nil, e.NewInternalError(err.Error())
-	}
-
+func (protoParser *ProtoParserImpl) GetPackages(ctx context.Context, module *Module, dependentModules []*Module) ([]*registryv1alpha1.ModulePackage, e.ResponseError) {
 	// 编译proto文件
-	linkers, _, err := protoParser.compile(ctx, fileManifest, module, dependentModules)
+	result, err := protoParser.compileModules(ctx, module, dependentModules)
 	if err != nil {
-		return nil, e.NewInternalError(err.Error())
+		return nil, err
 	}
 
 	packagesSet := map[string]struct{}{}
-	for _, link := range linkers {
+	for _, link := range result.linkers {
 		packagesSet[string(link.Package())] = struct{}{}
 	}
 
@@ -57,70 +67,100 @@ func (protoParser *ProtoParserImpl) GetPackages(ctx context.Context, fileManifes
 	return modulePackages, nil
 }
 
-func (protoParser *ProtoParserImpl) GetPackageDocumentation(ctx context.Context, packageName string, moduleIdentity bufmoduleref.ModuleIdentity, commitName string, fileManifest *manifest.Manifest, blobSet *manifest.BlobSet, dependentIdentities []bufmoduleref.ModuleIdentity, dependentCommits []string, dependentManifests []*manifest.Manifest, dependentBlobSets []*manifest.BlobSet) (*registryv1alpha1.PackageDocumentation, e.ResponseError) {
-	module, dependentModules, err := protoParser.getModulesWithModuleIdentityAndCommit(ctx, moduleIdentity, commitName, fileManifest, blobSet, dependentIdentities, dependentCommits, dependentManifests, dependentBlobSets)
-	if err != nil {
-		return nil, e.NewInternalError(err.Error())
-	}
-
+func (protoParser *ProtoParserImpl) GetPackageDocumentation(ctx context.Context, packageName string, module *Module, dependentModules []*Module) (*registryv1alpha1.PackageDocumentation, e.ResponseError) {
 	// 编译proto文件
-	linkers, parserAccessorHandler, err := protoParser.compile(ctx, fileManifest, module, dependentModules)
+	result, err := protoParser.compileModules(ctx, module, dependentModules)
 	if err != nil {
-		return nil, e.NewInternalError(err.Error())
+		return nil, err
 	}
 
 	// 生成package文档
-	documentGenerator := NewDocumentGenerator(commitName, linkers, parserAccessorHandler)
+	documentGenerator := NewDocumentGenerator(result.linkers, result.parserAccessorHandler)
 	return documentGenerator.GenerateDocument(packageName), nil
 }
 
-func (protoParser *ProtoParserImpl) TryCompile(ctx context.Context, fileManifest *manifest.Manifest, blobSet *manifest.BlobSet, dependentManifests []*manifest.Manifest, dependentBlobSets []*manifest.BlobSet) e.ResponseError {
-	module, dependentModules, err := protoParser.getModules(ctx, fileManifest, blobSet, dependentManifests, dependentBlobSets)
+func (protoParser *ProtoParserImpl) GetPackageFiles(ctx context.Context, packageName string, module *Module, dependentModules []*Module) ([]protoreflect.FileDescriptor, e.ResponseError) {
+	// 编译proto文件
+	result, err := protoParser.compileModules(ctx, module, dependentModules)
 	if err != nil {
-		return e.NewInternalError(err.Error())
+		return nil, err
 	}
 
-	// 尝试编译，查看是否成功
-	_, _, err = protoParser.compile(ctx, fileManifest, module, dependentModules)
-	if err != nil {
-		return e.NewInternalError(err.Error())
+	var files []protoreflect.FileDescriptor
+	for _, link := range result.linkers {
+		if string(link.Package()) == packageName {
+			files = append(files, link)
+		}
+	}
+	if len(files) == 0 {
+		return nil, e.NewNotFoundError(fmt.Sprintf("package %s", packageName))
 	}
 
-	return nil
+	return files, nil
 }
 
-func (protoParser *ProtoParserImpl) getModules(ctx context.Context, fileManifest *manifest.Manifest, blobSet *manifest.BlobSet, dependentManifests []*manifest.Manifest, dependentBlobSets []*manifest.BlobSet) (bufmodule.Module, []bufmodule.Module, error) {
-	module, err := bufmodule.NewModuleForManifestAndBlobSet(ctx, fileManifest, blobSet)
+func (protoParser *ProtoParserImpl) GetModuleFiles(ctx context.Context, module *Module, dependentModules []*Module) ([]protoreflect.FileDescriptor, e.ResponseError) {
+	// 编译proto文件
+	result, err := protoParser.compileModules(ctx, module, dependentModules)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	dependentModules := make([]bufmodule.Module, 0, len(dependentManifests))
-	for i := 0; i < len(dependentManifests); i++ {
-		dependentModule, err := bufmodule.NewModuleForManifestAndBlobSet(ctx, dependentManifests[i], dependentBlobSets[i])
-		if err != nil {
-			return nil, nil, err
-		}
-		dependentModules = append(dependentModules, dependentModule)
+
+	return linkerFiles(result), nil
+}
+
+// compileModules 编译已经push的模块，优先使用缓存，未命中时才读取模块和依赖的文件
+func (protoParser *ProtoParserImpl) compileModules(ctx context.Context, module *Module, dependentModules []*Module) (*compiled, e.ResponseError) {
+	dependentModuleKeys := make([]string, 0, len(dependentModules))
+	for _, dependentModule := range dependentModules {
+		dependentModuleKeys = append(dependentModuleKeys, dependencyCacheKey(dependentModule.Identity, dependentModule.Commit))
 	}
 
-	return module, dependentModules, nil
+	return protoParser.cache.getOrCompile(compileCacheKey(rootCacheKey(module.ManifestDigest), dependentModuleKeys), func(ctx context.Context) (*compiled, e.ResponseError) {
+		fileManifest, blobSet, respErr := module.Load(ctx)
+		if respErr != nil {
+			return nil, respErr
+		}
+		dependentIdentities := make([]bufmoduleref.ModuleIdentity, 0, len(dependentModules))
+		dependentCommits := make([]string, 0, len(dependentModules))
+		dependentManifests := make([]*manifest.Manifest, 0, len(dependentModules))
+		dependentBlobSets := make([]*manifest.BlobSet, 0, len(dependentModules))
+		for _, dependentModule := range dependentModules {
+			dependentManifest, dependentBlobSet, respErr := dependentModule.Load(ctx)
+			if respErr != nil {
+				return nil, respErr
+			}
+			dependentIdentities = append(dependentIdentities, dependentModule.Identity)
+			dependentCommits = append(dependentCommits, dependentModule.Commit)
+			dependentManifests = append(dependentManifests, dependentManifest)
+			dependentBlobSets = append(dependentBlobSets, dependentBlobSet)
+		}
+
+		return protoParser.compileWithDependencies(ctx, fileManifest, blobSet, dependentIdentities, dependentCommits, dependentManifests, dependentBlobSets)
+	})
 }
 
-func (protoParser *ProtoParserImpl) getModulesWithModuleIdentityAndCommit(ctx context.Context, moduleIdentity bufmoduleref.ModuleIdentity, commit string, fileManifest *manifest.Manifest, blobSet *manifest.BlobSet, dependentIdentities []bufmoduleref.ModuleIdentity, dependentCommits []string, dependentManifests []*manifest.Manifest, dependentBlobSets []*manifest.BlobSet) (bufmodule.Module, []bufmodule.Module, error) {
-	module, err := bufmodule.NewModuleForManifestAndBlobSet(ctx, fileManifest, blobSet, bufmodule.ModuleWithModuleIdentityAndCommit(moduleIdentity, commit))
+// compileWithDependencies 编译模块，根模块不设置identity，这样push时还没有commit的模块和已经push的模块编译结果相同，可以共用缓存
+func (protoParser *ProtoParserImpl) compileWithDependencies(ctx context.Context, fileManifest *manifest.Manifest, blobSet *manifest.BlobSet, dependentIdentities []bufmoduleref.ModuleIdentity, dependentCommits []string, dependentManifests []*manifest.Manifest, dependentBlobSets []*manifest.BlobSet) (*compiled, e.ResponseError) {
+	module, err := bufmodule.NewModuleForManifestAndBlobSet(ctx, fileManifest, blobSet)
 	if err != nil {
-		return nil, nil, err
+		return nil, e.NewInternalError(err.Error())
 	}
 	dependentModules := make([]bufmodule.Module, 0, len(dependentManifests))
 	for i := 0; i < len(dependentManifests); i++ {
 		dependentModule, err := bufmodule.NewModuleForManifestAndBlobSet(ctx, dependentManifests[i], dependentBlobSets[i], bufmodule.ModuleWithModuleIdentityAndCommit(dependentIdentities[i], dependentCommits[i]))
 		if err != nil {
-			return nil, nil, err
+			return nil, e.NewInternalError(err.Error())
 		}
 		dependentModules = append(dependentModules, dependentModule)
 	}
 
-	return module, dependentModules, nil
+	linkers, parserAccessorHandler, err := protoParser.compile(ctx, fileManifest, module, dependentModules)
+	if err != nil {
+		return nil, e.NewInternalError(err.Error())
+	}
+
+	return &compiled{linkers: linkers, parserAccessorHandler: parserAccessorHandler}, nil
 }
 
 func (protoParser *ProtoParserImpl) compile(ctx context.Context, fileManifest *manifest.Manifest, module bufmodule.Module, dependentModules []bufmodule.Module) (linker.Files, bufmoduleprotocompile.ParserAccessorHandler, error) {
@@ -156,3 +196,13 @@ func (protoParser *ProtoParserImpl) getProtoPaths(fileManifest *manifest.Manifes
 
 	return protoPaths
 }
+
+// manifestCacheKey 还没有commit的模块使用manifest digest作为缓存的key
+func manifestCacheKey(fileManifest *manifest.Manifest) (string, error) {
+	manifestBlob, err := fileManifest.Blob()
+	if err != nil {
+		return "", err
+	}
+
+	return rootCacheKey(manifestBlob.Digest().Hex()), nil
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"context"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/bufbuild/protocompile/walk"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// 符号类型
+const (
+	SymbolKindMessage = "message"
+	SymbolKindField   = "field"
+	SymbolKindEnum    = "enum"
+	SymbolKindService = "service"
+	SymbolKindMethod  = "method"
+)
+
+// Symbol 模块中定义的proto符号
+type Symbol struct {
+	Kind        string
+	Name        string
+	FullName    string
+	PackageName string
+	FilePath    string
+	StartLine   int32
+	StartColumn int32
+	EndLine     int32
+	EndColumn   int32
+}
+
+// IsSymbolKind 是否是支持的符号类型
+func IsSymbolKind(kind string) bool {
+	switch kind {
+	case SymbolKindMessage, SymbolKindField, SymbolKindEnum, SymbolKindService, SymbolKindMethod:
+		return true
+	}
+
+	return false
+}
+
+func (protoParser *ProtoParserImpl) GetSymbols(ctx context.Context, module *Module, dependentModules []*Module) ([]*Symbol, e.ResponseError) {
+	// 编译proto文件
+	result, err := protoParser.compileModules(ctx, module, dependentModules)
+	if err != nil {
+		return nil, err
+	}
+
+	// 只提取当前模块中的文件，依赖模块的符号在依赖模块push时提取
+	var symbols []*Symbol
+	for _, link := range result.linkers {
+		_ = walk.Descriptors(link, func(descriptor protoreflect.Descriptor) error {
+			kind := symbolKind(descriptor)
+			if kind == "" {
+				return nil
+			}
+
+			location := link.SourceLocations().ByDescriptor(descriptor)
+			symbols = append(symbols, &Symbol{
+				Kind:        kind,
+				Name:        string(descriptor.Name()),
+				FullName:    string(descriptor.FullName()),
+				PackageName: string(link.Package()),
+				FilePath:    link.Path(),
+				StartLine:   int32(location.StartLine),
+				StartColumn: int32(location.StartColumn),
+				EndLine:     int32(location.EndLine),
+				EndColumn:   int32(location.EndColumn),
+			})
+			return nil
+		})
+	}
+
+	return symbols, nil
+}
+
+func symbolKind(descriptor protoreflect.Descriptor) string {
+	switch d := descriptor.(type) {
+	case protoreflect.MessageDescriptor:
+		if d.IsMapEntry() {
+			// map字段生成的message，不是用户定义的
+			return ""
+		}
+		return SymbolKindMessage
+	case protoreflect.FieldDescriptor:
+		if parent, ok := d.Parent().(protoreflect.MessageDescriptor); ok && parent.IsMapEntry() {
+			return ""
+		}
+		return SymbolKindField
+	case protoreflect.EnumDescriptor:
+		return SymbolKindEnum
+	case protoreflect.ServiceDescriptor:
+		return SymbolKindService
+	case protoreflect.MethodDescriptor:
+		return SymbolKindMethod
+	}
+
+	return ""
+}
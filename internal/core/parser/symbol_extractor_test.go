@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"context"
+	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"testing"
+)
+
+// toModule 已经push的模块，编译时读取内存中的文件
+func (module *testModule) toModule(t *testing.T) *Module {
+	manifestBlob, err := module.fileManifest.Blob()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Module{
+		Identity:       module.identity,
+		Commit:         module.commit,
+		ManifestDigest: manifestBlob.Digest().Hex(),
+		Load: func(ctx context.Context) (*manifest.Manifest, *manifest.BlobSet, e.ResponseError) {
+			return module.fileManifest, module.blobSet, nil
+		},
+	}
+}
+
+func TestGetSymbols(t *testing.T) {
+	units, _, _ := newTestDependencies(t)
+	app := newTestModule(t, "app", map[string]string{
+		"app/v1/app.proto": `syntax = "proto3";
+
+package app.v1;
+
+import "units/v1/units.proto";
+
+message Report {
+  units.v1.Celsius temperature = 1;
+  map<string, string> labels = 2;
+
+  enum Level {
+    LEVEL_UNSPECIFIED = 0;
+  }
+}
+
+service ReportService {
+  rpc GetReport(Report) returns (Report);
+}
+`,
+	})
+
+	protoParser := &ProtoParserImpl{cache: newCompileCache(0)}
+	symbols, err := protoParser.GetSymbols(context.Background(), app.toModule(t), []*Module{units.toModule(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 不包含依赖模块中的符号，也不包含map字段生成的message
+	expected := map[string]string{
+		"app.v1.Report":                  SymbolKindMessage,
+		"app.v1.Report.temperature":      SymbolKindField,
+		"app.v1.Report.labels":           SymbolKindField,
+		"app.v1.Report.Level":            SymbolKindEnum,
+		"app.v1.ReportService":           SymbolKindService,
+		"app.v1.ReportService.GetReport": SymbolKindMethod,
+	}
+	actual := make(map[string]string, len(symbols))
+	for _, symbol := range symbols {
+		actual[symbol.FullName] = symbol.Kind
+		if symbol.PackageName != "app.v1" || symbol.FilePath != "app/v1/app.proto" {
+			t.Errorf("unexpected symbol location %+v", symbol)
+		}
+	}
+	if len(actual) != len(expected) {
+		t.Errorf("expected symbols %v, got %v", expected, actual)
+	}
+	for fullName, kind := range expected {
+		if actual[fullName] != kind {
+			t.Errorf("expected %s to be %s, got %q", fullName, kind, actual[fullName])
+		}
+	}
+
+	for _, symbol := range symbols {
+		if symbol.FullName == "app.v1.ReportService.GetReport" {
+			// 位置从0开始
+			if symbol.Name != "GetReport" || symbol.StartLine != 16 || symbol.StartColumn != 2 {
+				t.Errorf("unexpected method symbol %+v", symbol)
+			}
+		}
+	}
+}
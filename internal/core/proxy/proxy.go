@@ -0,0 +1,376 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
+	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
+	"github.com/ProtobufMan/bufman/internal/config"
+	"github.com/ProtobufMan/bufman/internal/constant"
+	"github.com/ProtobufMan/bufman/internal/core/logger"
+	"github.com/ProtobufMan/bufman/internal/core/storage"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/ProtobufMan/bufman/internal/mapper"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"github.com/bufbuild/connect-go"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Proxy 代理其他remote上的依赖，从上游下载commit并缓存到本地，上游不可用时使用本地缓存
+type Proxy interface {
+	CheckCanAccess(ctx context.Context, moduleReference bufmoduleref.ModuleReference) e.ResponseError // 检查当前用户是否可以通过上游拉取模块
+	GetCommit(ctx context.Context, moduleReference bufmoduleref.ModuleReference) (*model.Commit, e.ResponseError)
+}
+
+type ProxyImpl struct {
+	upstreams        map[string]Upstream                // remote -> upstream
+	access           map[string][]config.UpstreamAccess // remote -> 访问规则
+	userMapper       mapper.UserMapper
+	repositoryMapper mapper.RepositoryMapper
+	commitMapper     mapper.CommitMapper
+	storageHelper    storage.StorageHelper
+}
+
+func NewProxy() Proxy {
+	upstreams := make(map[string]Upstream, len(config.Properties.BufMan.Upstreams))
+	access := make(map[string][]config.UpstreamAccess, len(config.Properties.BufMan.Upstreams))
+	for _, upstream := range config.Properties.BufMan.Upstreams {
+		timeout := upstream.Timeout
+		if timeout <= 0 {
+			timeout = constant.DefaultUpstreamTimeout
+		}
+		upstreams[upstream.Remote] = NewUpstream(&http.Client{Timeout: timeout}, upstream.Url, upstream.Token)
+		access[upstream.Remote] = upstream.Access
+	}
+
+	return &ProxyImpl{
+		upstreams:        upstreams,
+		access:           access,
+		userMapper:       &mapper.UserMapperImpl{},
+		repositoryMapper: &mapper.RepositoryMapperImpl{},
+		commitMapper:     &mapper.CommitMapperImpl{},
+		storageHelper:    storage.NewStorageHelper(),
+	}
+}
+
+// CheckCanAccess 缓存的上游仓库对本地用户不可见，按照上游配置的访问规则检查当前用户
+func (proxy *ProxyImpl) CheckCanAccess(ctx context.Context, moduleReference bufmoduleref.ModuleReference) e.ResponseError {
+	target := fmt.Sprintf("download %s from upstream", moduleReference.IdentityString())
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+	rules := proxy.access[moduleReference.Remote()]
+	if userID == "" || len(rules) == 0 {
+		return e.NewPermissionDeniedError(target)
+	}
+
+	user, err := proxy.userMapper.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return e.NewPermissionDeniedError(target)
+		}
+		return e.NewInternalError(fmt.Sprintf("find user(%s)", err.Error()))
+	}
+	if !canAccess(rules, user.UserName, moduleReference.Owner(), moduleReference.Repository()) {
+		return e.NewPermissionDeniedError(target)
+	}
+
+	return nil
+}
+
+// canAccess 判断用户是否满足任意一条访问规则
+func canAccess(rules []config.UpstreamAccess, userName, owner, repositoryName string) bool {
+	for _, rule := range rules {
+		if !matchRepository(rule.Repository, owner, repositoryName) {
+			continue
+		}
+		for _, user := range rule.Users {
+			if user == constant.UpstreamAccessAll || user == userName {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// matchRepository 仓库规则的格式为 owner/name、owner/* 或者 *
+func matchRepository(pattern, owner, repositoryName string) bool {
+	if pattern == constant.UpstreamAccessAll {
+		return true
+	}
+	patternOwner, patternRepository, ok := strings.Cut(pattern, "/")
+	if !ok || patternOwner != owner {
+		return false
+	}
+
+	return patternRepository == constant.UpstreamAccessAll || patternRepository == repositoryName
+}
+
+func (proxy *ProxyImpl) GetCommit(ctx context.Context, moduleReference bufmoduleref.ModuleReference) (*model.Commit, e.ResponseError) {
+	upstream, ok := proxy.upstreams[moduleReference.Remote()]
+	if !ok {
+		return nil, e.NewFailedPreconditionError(fmt.Sprintf("%s is hosted on remote %s, which is not a configured upstream", moduleReference.IdentityString(), moduleReference.Remote()))
+	}
+	if respErr := proxy.CheckCanAccess(ctx, moduleReference); respErr != nil {
+		return nil, respErr
+	}
+
+	// 查询本地缓存的仓库
+	userName := ProxyUserName(moduleReference.Remote(), moduleReference.Owner())
+	repository, err := proxy.repositoryMapper.FindByUserNameAndRepositoryName(userName, moduleReference.Repository())
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, e.NewInternalError(fmt.Sprintf("find repository(%s)", err.Error()))
+		}
+	}
+
+	// commit名称不会改变，已经缓存过时不需要访问上游
+	if repository != nil && moduleReference.Reference() != "" {
+		commit, cacheErr := proxy.commitMapper.FindByRepositoryIDAndCommitName(repository.RepositoryID, moduleReference.Reference())
+		if cacheErr == nil {
+			return commit, nil
+		}
+		if !errors.Is(cacheErr, gorm.ErrRecordNotFound) {
+			return nil, e.NewInternalError(fmt.Sprintf("find commit(%s)", cacheErr.Error()))
+		}
+	}
+
+	repositoryCommit, err := upstream.GetRepositoryCommit(ctx, moduleReference.Owner(), moduleReference.Repository(), moduleReference.Reference())
+	if err != nil {
+		if connect.CodeOf(err) != connect.CodeNotFound && repository != nil {
+			// 上游不可用，使用本地缓存
+			commit, cacheErr := proxy.commitMapper.FindByRepositoryIDAndReference(repository.RepositoryID, moduleReference.Reference())
+			if cacheErr == nil {
+				logger.Errorf("Error get commit from upstream %s, use local cache: %v\n", moduleReference.Remote(), err.Error())
+				return commit, nil
+			}
+		}
+
+		return nil, toResponseError(moduleReference, err)
+	}
+
+	if repository == nil {
+		repository = &model.Repository{
+			UserID:         uuid.NewString(),
+			UserName:       userName,
+			RepositoryID:   uuid.NewString(),
+			RepositoryName: moduleReference.Repository(),
+			Visibility:     uint8(registryv1alpha1.Visibility_VISIBILITY_PRIVATE),
+			Remote:         moduleReference.Remote(),
+		}
+		err = proxy.repositoryMapper.Create(repository)
+		if err != nil {
+			if !errors.Is(err, gorm.ErrDuplicatedKey) {
+				return nil, e.NewInternalError(fmt.Sprintf("create proxy repository(%s)", err.Error()))
+			}
+
+			// 并发创建，使用已经创建的仓库
+			repository, err = proxy.repositoryMapper.FindByUserNameAndRepositoryName(userName, moduleReference.Repository())
+			if err != nil {
+				return nil, e.NewInternalError(fmt.Sprintf("find repository(%s)", err.Error()))
+			}
+		}
+	}
+
+	// 已经缓存过的commit
+	commit, err := proxy.commitMapper.FindByRepositoryIDAndCommitName(repository.RepositoryID, repositoryCommit.GetName())
+	if err == nil {
+		return commit, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, e.NewInternalError(fmt.Sprintf("find commit(%s)", err.Error()))
+	}
+
+	// 从上游下载
+	fileManifest, blobSet, err := upstream.DownloadManifestAndBlobs(ctx, moduleReference.Owner(), moduleReference.Repository(), repositoryCommit.GetName())
+	if err != nil {
+		return nil, toResponseError(moduleReference, err)
+	}
+
+	commit, respErr := proxy.toCommit(ctx, repository, repositoryCommit, fileManifest, blobSet)
+	if respErr != nil {
+		return nil, respErr
+	}
+
+	// 缓存到本地
+	respErr = proxy.saveFileManifestAndBlobs(ctx, commit)
+	if respErr != nil {
+		return nil, respErr
+	}
+	err = proxy.commitMapper.CreateProxy(commit)
+	if err != nil {
+		return nil, e.NewInternalError(fmt.Sprintf("cache commit(%s)", err.Error()))
+	}
+
+	return commit, nil
+}
+
+// ProxyUserName 缓存的上游仓库使用 remote/owner 作为用户名，不会与本地用户冲突
+func ProxyUserName(remote, owner string) string {
+	return remote + "/" + owner
+}
+
+func (proxy *ProxyImpl) toCommit(ctx context.Context, repository *model.Repository, repositoryCommit *registryv1alpha1.RepositoryCommit, fileManifest *manifest.Manifest, blobSet *manifest.BlobSet) (*model.Commit, e.ResponseError) {
+	commitID := uuid.NewString()
+	commitName := repositoryCommit.GetName()
+	createTime := repositoryCommit.GetCreateTime().AsTime()
+
+	// 生成manifest，并校验与上游记录的digest一致
+	fileManifestBlob, err := fileManifest.Blob()
+	if err != nil {
+		return nil, e.NewInternalError(err.Error())
+	}
+	manifestDigest := fileManifestBlob.Digest().Hex()
+	if upstreamDigest := repositoryCommit.GetManifestDigest(); upstreamDigest != "" && strings.TrimPrefix(upstreamDigest, string(manifest.DigestTypeShake256)+":") != manifestDigest {
+		return nil, e.NewInternalError(fmt.Sprintf("manifest digest of commit %s mismatch", commitName))
+	}
+	content, err := readBlob(ctx, fileManifestBlob)
+	if err != nil {
+		return nil, e.NewInternalError(err.Error())
+	}
+	modelFileManifest := &model.FileManifest{
+		Digest:         manifestDigest,
+		CommitID:       commitID,
+		Content:        string(content),
+		UserID:         repository.UserID,
+		UserName:       repository.UserName,
+		RepositoryID:   repository.RepositoryID,
+		RepositoryName: repository.RepositoryName,
+		CommitName:     commitName,
+		CreatedTime:    createTime,
+	}
+
+	// 生成file blobs
+	modelBlobs := make([]*model.FileBlob, 0, len(fileManifest.Paths()))
+	err = fileManifest.Range(func(path string, digest manifest.Digest) error {
+		blob, ok := blobSet.BlobFor(digest.String())
+		if !ok {
+			return fmt.Errorf("blob of %s not found", path)
+		}
+		content, err := readBlob(ctx, blob)
+		if err != nil {
+			return err
+		}
+
+		modelBlobs = append(modelBlobs, &model.FileBlob{
+			Digest:         digest.Hex(),
+			CommitID:       commitID,
+			FileName:       path,
+			Content:        string(content),
+			UserID:         repository.UserID,
+			UserName:       repository.UserName,
+			RepositoryID:   repository.RepositoryID,
+			RepositoryName: repository.RepositoryName,
+			CommitName:     commitName,
+			CreatedTime:    createTime,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, e.NewInternalError(err.Error())
+	}
+
+	// 获取bufman config、README、LICENSE
+	configBlob, err := proxy.storageHelper.GetBufManConfigFromBlob(ctx, fileManifest, blobSet)
+	if err != nil {
+		return nil, e.NewInternalError(err.Error())
+	}
+	documentBlob, licenseBlob, err := proxy.storageHelper.GetDocumentAndLicenseFromBlob(ctx, fileManifest, blobSet)
+	if err != nil {
+		return nil, e.NewInternalError(err.Error())
+	}
+
+	// 上游的tag
+	tags := make(model.Tags, 0, len(repositoryCommit.GetTags()))
+	for _, tag := range repositoryCommit.GetTags() {
+		tags = append(tags, &model.Tag{
+			UserID:       repository.UserID,
+			UserName:     repository.UserName,
+			RepositoryID: repository.RepositoryID,
+			CommitID:     commitID,
+			CommitName:   commitName,
+			TagID:        uuid.NewString(),
+			TagName:      tag.GetName(),
+		})
+	}
+
+	commit := &model.Commit{
+		UserID:         repository.UserID,
+		UserName:       repository.UserName,
+		RepositoryID:   repository.RepositoryID,
+		RepositoryName: repository.RepositoryName,
+		CommitID:       commitID,
+		CommitName:     commitName,
+		DraftName:      repositoryCommit.GetDraftName(),
+		CreatedTime:    createTime,
+		ManifestDigest: manifestDigest,
+		SequenceID:     repositoryCommit.GetCommitSequenceId(),
+		FileManifest:   modelFileManifest,
+		FileBlobs:      modelBlobs,
+		Tags:           tags,
+	}
+	if configBlob != nil {
+		commit.BufManConfigDigest = configBlob.Digest().Hex()
+	}
+	if documentBlob != nil {
+		commit.DocumentDigest = documentBlob.Digest().Hex()
+	}
+	if licenseBlob != nil {
+		commit.LicenseDigest = licenseBlob.Digest().Hex()
+	}
+
+	return commit, nil
+}
+
+func (proxy *ProxyImpl) saveFileManifestAndBlobs(ctx context.Context, commit *model.Commit) e.ResponseError {
+	for i := 0; i < len(commit.FileBlobs); i++ {
+		fileBlob := commit.FileBlobs[i]
+
+		// 如果是README文件
+		if fileBlob.Digest == commit.DocumentDigest {
+			err := proxy.storageHelper.StoreDocumentation(ctx, fileBlob)
+			if err != nil {
+				return e.NewInternalError(err.Error())
+			}
+		}
+
+		err := proxy.storageHelper.StoreBlob(ctx, fileBlob)
+		if err != nil {
+			return e.NewInternalError(err.Error())
+		}
+	}
+
+	err := proxy.storageHelper.StoreManifest(ctx, commit.FileManifest)
+	if err != nil {
+		return e.NewInternalError(err.Error())
+	}
+
+	return nil
+}
+
+func readBlob(ctx context.Context, blob manifest.Blob) ([]byte, error) {
+	readCloser, err := blob.Open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer readCloser.Close()
+
+	return io.ReadAll(readCloser)
+}
+
+func toResponseError(moduleReference bufmoduleref.ModuleReference, err error) e.ResponseError {
+	target := fmt.Sprintf("%s:%s", moduleReference.IdentityString(), moduleReference.Reference())
+	switch connect.CodeOf(err) {
+	case connect.CodeNotFound:
+		return e.NewNotFoundError(target)
+	case connect.CodePermissionDenied, connect.CodeUnauthenticated:
+		return e.NewPermissionDeniedError(fmt.Sprintf("download %s from upstream", target))
+	default:
+		return e.NewInternalError(fmt.Sprintf("download %s from upstream(%s)", target, err.Error()))
+	}
+}
@@ -0,0 +1,291 @@
+package proxy
+
+import (
+	"context"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
+	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
+	"github.com/ProtobufMan/bufman/internal/config"
+	"github.com/ProtobufMan/bufman/internal/constant"
+	"github.com/ProtobufMan/bufman/internal/mapper"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"github.com/bufbuild/connect-go"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+)
+
+const testRemote = "upstream.io"
+
+// testProxyUpstream 记录调用次数的上游
+type testProxyUpstream struct {
+	repositoryCommit *registryv1alpha1.RepositoryCommit
+	fileManifest     *manifest.Manifest
+	blobSet          *manifest.BlobSet
+	err              error
+	calls            int
+}
+
+func (upstream *testProxyUpstream) GetRepositoryCommit(ctx context.Context, owner, repositoryName, reference string) (*registryv1alpha1.RepositoryCommit, error) {
+	upstream.calls++
+	if upstream.err != nil {
+		return nil, upstream.err
+	}
+
+	return upstream.repositoryCommit, nil
+}
+
+func (upstream *testProxyUpstream) DownloadManifestAndBlobs(ctx context.Context, owner, repositoryName, reference string) (*manifest.Manifest, *manifest.BlobSet, error) {
+	upstream.calls++
+	if upstream.err != nil {
+		return nil, nil, upstream.err
+	}
+
+	return upstream.fileManifest, upstream.blobSet, nil
+}
+
+type testProxyUserMapper struct {
+	mapper.UserMapper
+}
+
+func (userMapper *testProxyUserMapper) FindByUserID(userID string) (*model.User, error) {
+	if userID != "alice-id" && userID != "bob-id" {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &model.User{UserID: userID, UserName: strings.TrimSuffix(userID, "-id")}, nil
+}
+
+type testProxyRepositoryMapper struct {
+	mapper.RepositoryMapper
+	repository *model.Repository
+}
+
+func (repositoryMapper *testProxyRepositoryMapper) FindByUserNameAndRepositoryName(userName, repositoryName string) (*model.Repository, error) {
+	repository := repositoryMapper.repository
+	if repository == nil || repository.UserName != userName || repository.RepositoryName != repositoryName {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return repository, nil
+}
+
+func (repositoryMapper *testProxyRepositoryMapper) Create(repository *model.Repository) error {
+	repositoryMapper.repository = repository
+
+	return nil
+}
+
+type testProxyCommitMapper struct {
+	mapper.CommitMapper
+	commits model.Commits
+	tags    model.Tags
+	created int
+}
+
+func (commitMapper *testProxyCommitMapper) FindByRepositoryIDAndCommitName(repositoryID string, commitName string) (*model.Commit, error) {
+	for _, commit := range commitMapper.commits {
+		if commit.RepositoryID == repositoryID && commit.CommitName == commitName {
+			return commit, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (commitMapper *testProxyCommitMapper) FindByRepositoryIDAndReference(repositoryID string, reference string) (*model.Commit, error) {
+	for _, tag := range commitMapper.tags {
+		if tag.RepositoryID == repositoryID && tag.TagName == reference {
+			return commitMapper.FindByRepositoryIDAndCommitName(repositoryID, tag.CommitName)
+		}
+	}
+
+	return commitMapper.FindByRepositoryIDAndCommitName(repositoryID, reference)
+}
+
+func (commitMapper *testProxyCommitMapper) CreateProxy(commit *model.Commit) error {
+	commitMapper.created++
+
+	return nil
+}
+
+// newTestProxy 上游仓库acme/weather已经缓存了testUpstreamCommit，并打上了v1.0.0的tag
+func newTestProxy(upstream *testProxyUpstream, cached bool) (*ProxyImpl, *testProxyCommitMapper) {
+	repositoryMapper := &testProxyRepositoryMapper{}
+	commitMapper := &testProxyCommitMapper{}
+	if cached {
+		repositoryMapper.repository = &model.Repository{
+			UserName:       ProxyUserName(testRemote, "acme"),
+			RepositoryID:   "weather-id",
+			RepositoryName: "weather",
+			Remote:         testRemote,
+		}
+		commitMapper.commits = model.Commits{{RepositoryID: "weather-id", CommitID: "commit-id", CommitName: testUpstreamCommit}}
+		commitMapper.tags = model.Tags{{RepositoryID: "weather-id", CommitName: testUpstreamCommit, TagName: "v1.0.0"}}
+	}
+
+	return &ProxyImpl{
+		upstreams: map[string]Upstream{testRemote: upstream},
+		access: map[string][]config.UpstreamAccess{
+			testRemote: {{Repository: "acme/*", Users: []string{"alice"}}},
+		},
+		userMapper:       &testProxyUserMapper{},
+		repositoryMapper: repositoryMapper,
+		commitMapper:     commitMapper,
+	}, commitMapper
+}
+
+func newTestProxyModuleReference(t *testing.T, repositoryName, reference string) bufmoduleref.ModuleReference {
+	moduleReference, err := bufmoduleref.NewModuleReference(testRemote, "acme", repositoryName, reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return moduleReference
+}
+
+func newTestProxyContext(userID string) context.Context {
+	return context.WithValue(context.Background(), constant.UserIDKey, userID)
+}
+
+func TestProxyGetCommitCacheHit(t *testing.T) {
+	upstream := &testProxyUpstream{err: connect.NewError(connect.CodeInternal, nil)}
+	proxy, _ := newTestProxy(upstream, true)
+
+	commit, err := proxy.GetCommit(newTestProxyContext("alice-id"), newTestProxyModuleReference(t, "weather", testUpstreamCommit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.CommitID != "commit-id" {
+		t.Errorf("expected cached commit, got %s", commit.CommitID)
+	}
+	if upstream.calls != 0 {
+		t.Errorf("expected no upstream calls, got %d", upstream.calls)
+	}
+}
+
+func TestProxyGetCommitOfflineFallback(t *testing.T) {
+	upstream := &testProxyUpstream{err: connect.NewError(connect.CodeUnavailable, nil)}
+	proxy, _ := newTestProxy(upstream, true)
+
+	// 上游不可用时tag使用本地缓存
+	commit, err := proxy.GetCommit(newTestProxyContext("alice-id"), newTestProxyModuleReference(t, "weather", "v1.0.0"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.CommitID != "commit-id" || upstream.calls != 1 {
+		t.Errorf("expected cached commit after one upstream call, got %s after %d calls", commit.CommitID, upstream.calls)
+	}
+
+	// 本地也没有缓存时返回上游的错误
+	_, err = proxy.GetCommit(newTestProxyContext("alice-id"), newTestProxyModuleReference(t, "weather", "v2.0.0"))
+	if err == nil || err.Code() != connect.CodeInternal {
+		t.Errorf("expected internal error, got %v", err)
+	}
+
+	// 上游明确返回不存在时不使用缓存
+	upstream.err = connect.NewError(connect.CodeNotFound, nil)
+	_, err = proxy.GetCommit(newTestProxyContext("alice-id"), newTestProxyModuleReference(t, "weather", "v1.0.0"))
+	if err == nil || err.Code() != connect.CodeNotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestProxyGetCommitDigestMismatch(t *testing.T) {
+	blob, err := manifest.NewMemoryBlobFromReader(strings.NewReader(testUpstreamContent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileManifest := manifest.New()
+	if err = fileManifest.AddEntry("acme/weather/v1/weather.proto", *blob.Digest()); err != nil {
+		t.Fatal(err)
+	}
+	blobSet, err := manifest.NewBlobSet(context.Background(), []manifest.Blob{blob})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 上游记录的digest与下载的内容不一致
+	upstream := &testProxyUpstream{
+		repositoryCommit: &registryv1alpha1.RepositoryCommit{
+			Name:           testUpstreamCommit,
+			ManifestDigest: blob.Digest().String(),
+		},
+		fileManifest: fileManifest,
+		blobSet:      blobSet,
+	}
+	proxy, commitMapper := newTestProxy(upstream, false)
+
+	_, respErr := proxy.GetCommit(newTestProxyContext("alice-id"), newTestProxyModuleReference(t, "weather", "v1.0.0"))
+	if respErr == nil || respErr.Code() != connect.CodeInternal || !strings.Contains(respErr.Error(), "mismatch") {
+		t.Fatalf("expected digest mismatch, got %v", respErr)
+	}
+	if commitMapper.created != 0 {
+		t.Errorf("expected commit not cached, got %d", commitMapper.created)
+	}
+}
+
+func TestProxyCheckCanAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     string
+		repository string
+		ok         bool
+	}{
+		{name: "allowed user", userID: "alice-id", repository: "weather", ok: true},
+		{name: "other user", userID: "bob-id", repository: "weather", ok: false},
+		{name: "anonymous", userID: "", repository: "weather", ok: false},
+		{name: "unknown user", userID: "nobody-id", repository: "weather", ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			upstream := &testProxyUpstream{err: connect.NewError(connect.CodeUnavailable, nil)}
+			proxy, _ := newTestProxy(upstream, true)
+
+			err := proxy.CheckCanAccess(newTestProxyContext(test.userID), newTestProxyModuleReference(t, test.repository, testUpstreamCommit))
+			if (err == nil) != test.ok {
+				t.Fatalf("expected ok %v, got %v", test.ok, err)
+			}
+
+			// 没有权限时不能读取缓存，也不会访问上游
+			_, err = proxy.GetCommit(newTestProxyContext(test.userID), newTestProxyModuleReference(t, test.repository, testUpstreamCommit))
+			if !test.ok && (err == nil || err.Code() != connect.CodePermissionDenied || upstream.calls != 0) {
+				t.Errorf("expected permission denied without upstream calls, got %v after %d calls", err, upstream.calls)
+			}
+		})
+	}
+}
+
+func TestCanAccess(t *testing.T) {
+	rules := []config.UpstreamAccess{
+		{Repository: "acme/weather", Users: []string{"alice"}},
+		{Repository: "acme/*", Users: []string{"bob"}},
+		{Repository: constant.UpstreamAccessAll, Users: []string{"carol"}},
+		{Repository: "public/*", Users: []string{constant.UpstreamAccessAll}},
+	}
+
+	tests := []struct {
+		userName   string
+		owner      string
+		repository string
+		expected   bool
+	}{
+		{userName: "alice", owner: "acme", repository: "weather", expected: true},
+		{userName: "alice", owner: "acme", repository: "petstore", expected: false},
+		{userName: "bob", owner: "acme", repository: "petstore", expected: true},
+		{userName: "bob", owner: "other", repository: "weather", expected: false},
+		{userName: "carol", owner: "other", repository: "weather", expected: true},
+		{userName: "dave", owner: "public", repository: "weather", expected: true},
+		{userName: "dave", owner: "acme", repository: "weather", expected: false},
+	}
+	for _, test := range tests {
+		if actual := canAccess(rules, test.userName, test.owner, test.repository); actual != test.expected {
+			t.Errorf("canAccess(%s, %s/%s) = %v, want %v", test.userName, test.owner, test.repository, actual, test.expected)
+		}
+	}
+
+	if canAccess(nil, "alice", "acme", "weather") {
+		t.Error("expected no access without rules")
+	}
+}
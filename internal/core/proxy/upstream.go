@@ -0,0 +1,69 @@
+package proxy
+
+import (
+	"context"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmanifest"
+	"github.com/ProtobufMan/bufman-cli/private/gen/proto/connect/bufman/alpha/registry/v1alpha1/registryv1alpha1connect"
+	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
+	"github.com/ProtobufMan/bufman/internal/interceptors"
+	"github.com/bufbuild/connect-go"
+)
+
+// Upstream 通过Resolve/Download接口访问上游registry
+type Upstream interface {
+	GetRepositoryCommit(ctx context.Context, owner, repositoryName, reference string) (*registryv1alpha1.RepositoryCommit, error)
+	DownloadManifestAndBlobs(ctx context.Context, owner, repositoryName, reference string) (*manifest.Manifest, *manifest.BlobSet, error)
+}
+
+type UpstreamImpl struct {
+	commitClient   registryv1alpha1connect.RepositoryCommitServiceClient
+	downloadClient registryv1alpha1connect.DownloadServiceClient
+}
+
+func NewUpstream(httpClient connect.HTTPClient, url, token string) Upstream {
+	var options []connect.ClientOption
+	if token != "" {
+		options = append(options, interceptors.WithAuthHeaderInterceptor(token))
+	}
+
+	return &UpstreamImpl{
+		commitClient:   registryv1alpha1connect.NewRepositoryCommitServiceClient(httpClient, url, options...),
+		downloadClient: registryv1alpha1connect.NewDownloadServiceClient(httpClient, url, options...),
+	}
+}
+
+func (upstream *UpstreamImpl) GetRepositoryCommit(ctx context.Context, owner, repositoryName, reference string) (*registryv1alpha1.RepositoryCommit, error) {
+	resp, err := upstream.commitClient.GetRepositoryCommitByReference(ctx, connect.NewRequest(&registryv1alpha1.GetRepositoryCommitByReferenceRequest{
+		RepositoryOwner: owner,
+		RepositoryName:  repositoryName,
+		Reference:       reference,
+	}))
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Msg.GetRepositoryCommit(), nil
+}
+
+func (upstream *UpstreamImpl) DownloadManifestAndBlobs(ctx context.Context, owner, repositoryName, reference string) (*manifest.Manifest, *manifest.BlobSet, error) {
+	resp, err := upstream.downloadClient.DownloadManifestAndBlobs(ctx, connect.NewRequest(&registryv1alpha1.DownloadManifestAndBlobsRequest{
+		Owner:      owner,
+		Repository: repositoryName,
+		Reference:  reference,
+	}))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fileManifest, err := bufmanifest.NewManifestFromProto(ctx, resp.Msg.GetManifest())
+	if err != nil {
+		return nil, nil, err
+	}
+	blobSet, err := bufmanifest.NewBlobSetFromProto(ctx, resp.Msg.GetBlobs())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return fileManifest, blobSet, nil
+}
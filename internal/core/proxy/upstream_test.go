@@ -0,0 +1,174 @@
+package proxy
+
+import (
+	"context"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmanifest"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/ProtobufMan/bufman-cli/private/gen/proto/connect/bufman/alpha/registry/v1alpha1/registryv1alpha1connect"
+	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
+	"github.com/ProtobufMan/bufman/internal/constant"
+	"github.com/bufbuild/connect-go"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	testUpstreamToken   = "upstream-token"
+	testUpstreamCommit  = "0123456789abcdef0123456789abcdef"
+	testUpstreamContent = "syntax = \"proto3\";\n\npackage acme.weather.v1;\n"
+)
+
+// testUpstreamServer 模拟上游bufman实例
+type testUpstreamServer struct {
+	registryv1alpha1connect.UnimplementedRepositoryCommitServiceHandler
+	registryv1alpha1connect.UnimplementedDownloadServiceHandler
+
+	fileManifest *manifest.Manifest
+	blobSet      *manifest.BlobSet
+}
+
+func (server *testUpstreamServer) GetRepositoryCommitByReference(ctx context.Context, req *connect.Request[registryv1alpha1.GetRepositoryCommitByReferenceRequest]) (*connect.Response[registryv1alpha1.GetRepositoryCommitByReferenceResponse], error) {
+	if err := checkTestAuthHeader(req.Header()); err != nil {
+		return nil, err
+	}
+	if req.Msg.GetRepositoryOwner() != "acme" || req.Msg.GetRepositoryName() != "weather" {
+		return nil, connect.NewError(connect.CodeNotFound, nil)
+	}
+	if req.Msg.GetReference() != "v1.0.0" && req.Msg.GetReference() != testUpstreamCommit {
+		return nil, connect.NewError(connect.CodeNotFound, nil)
+	}
+
+	manifestBlob, err := server.fileManifest.Blob()
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	return connect.NewResponse(&registryv1alpha1.GetRepositoryCommitByReferenceResponse{
+		RepositoryCommit: &registryv1alpha1.RepositoryCommit{
+			Name:             testUpstreamCommit,
+			CommitSequenceId: 3,
+			ManifestDigest:   manifestBlob.Digest().String(),
+			Tags:             []*registryv1alpha1.RepositoryTag{{Name: "v1.0.0", CommitName: testUpstreamCommit}},
+		},
+	}), nil
+}
+
+func (server *testUpstreamServer) DownloadManifestAndBlobs(ctx context.Context, req *connect.Request[registryv1alpha1.DownloadManifestAndBlobsRequest]) (*connect.Response[registryv1alpha1.DownloadManifestAndBlobsResponse], error) {
+	if err := checkTestAuthHeader(req.Header()); err != nil {
+		return nil, err
+	}
+	if req.Msg.GetReference() != testUpstreamCommit {
+		return nil, connect.NewError(connect.CodeNotFound, nil)
+	}
+
+	protoManifest, protoBlobs, err := bufmanifest.ToProtoManifestAndBlobs(ctx, server.fileManifest, server.blobSet)
+	if err != nil {
+		return nil, connect.NewError(connect.CodeInternal, err)
+	}
+
+	return connect.NewResponse(&registryv1alpha1.DownloadManifestAndBlobsResponse{
+		Manifest: protoManifest,
+		Blobs:    protoBlobs,
+	}), nil
+}
+
+func checkTestAuthHeader(header http.Header) error {
+	if header.Get(constant.AuthHeader) != constant.AuthPrefix+" "+testUpstreamToken {
+		return connect.NewError(connect.CodeUnauthenticated, nil)
+	}
+
+	return nil
+}
+
+func newTestUpstreamServer(t *testing.T) *httptest.Server {
+	ctx := context.Background()
+	blob, err := manifest.NewMemoryBlobFromReader(strings.NewReader(testUpstreamContent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileManifest := manifest.New()
+	if err = fileManifest.AddEntry("acme/weather/v1/weather.proto", *blob.Digest()); err != nil {
+		t.Fatal(err)
+	}
+	blobSet, err := manifest.NewBlobSet(ctx, []manifest.Blob{blob})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := &testUpstreamServer{fileManifest: fileManifest, blobSet: blobSet}
+	mux := http.NewServeMux()
+	mux.Handle(registryv1alpha1connect.NewRepositoryCommitServiceHandler(handler))
+	mux.Handle(registryv1alpha1connect.NewDownloadServiceHandler(handler))
+
+	return httptest.NewServer(mux)
+}
+
+func TestUpstreamGetRepositoryCommit(t *testing.T) {
+	server := newTestUpstreamServer(t)
+	defer server.Close()
+	upstream := NewUpstream(server.Client(), server.URL, testUpstreamToken)
+
+	repositoryCommit, err := upstream.GetRepositoryCommit(context.Background(), "acme", "weather", "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repositoryCommit.GetName() != testUpstreamCommit || repositoryCommit.GetCommitSequenceId() != 3 {
+		t.Errorf("unexpected commit %s", repositoryCommit.String())
+	}
+
+	_, err = upstream.GetRepositoryCommit(context.Background(), "acme", "weather", "v2.0.0")
+	if connect.CodeOf(err) != connect.CodeNotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+func TestUpstreamDownloadManifestAndBlobs(t *testing.T) {
+	server := newTestUpstreamServer(t)
+	defer server.Close()
+	upstream := NewUpstream(server.Client(), server.URL, testUpstreamToken)
+
+	ctx := context.Background()
+	fileManifest, blobSet, err := upstream.DownloadManifestAndBlobs(ctx, "acme", "weather", testUpstreamCommit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := fileManifest.Paths()
+	if len(paths) != 1 || paths[0] != "acme/weather/v1/weather.proto" {
+		t.Fatalf("unexpected paths %v", paths)
+	}
+	digest, _ := fileManifest.DigestFor(paths[0])
+	blob, ok := blobSet.BlobFor(digest.String())
+	if !ok {
+		t.Fatal("blob not found")
+	}
+	content, err := readBlob(ctx, blob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != testUpstreamContent {
+		t.Errorf("unexpected content %q", content)
+	}
+}
+
+func TestUpstreamUnauthenticated(t *testing.T) {
+	server := newTestUpstreamServer(t)
+	defer server.Close()
+	upstream := NewUpstream(server.Client(), server.URL, "")
+
+	_, err := upstream.GetRepositoryCommit(context.Background(), "acme", "weather", "v1.0.0")
+	if connect.CodeOf(err) != connect.CodeUnauthenticated {
+		t.Fatalf("expected unauthenticated, got %v", err)
+	}
+
+	moduleReference, refErr := bufmoduleref.NewModuleReference("upstream.io", "acme", "weather", "v1.0.0")
+	if refErr != nil {
+		t.Fatal(refErr)
+	}
+	if respErr := toResponseError(moduleReference, err); respErr.Code() != connect.CodePermissionDenied {
+		t.Errorf("expected permission denied, got %v", respErr.Code())
+	}
+}
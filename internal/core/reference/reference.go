@@ -0,0 +1,128 @@
+package reference
+
+import (
+	"errors"
+	"github.com/ProtobufMan/bufman/internal/constant"
+	"strings"
+	"time"
+)
+
+// Kind reference的类型
+type Kind int
+
+const (
+	KindUnknown Kind = iota
+	KindBranch
+	KindCommit
+	KindTag
+	KindDraft
+)
+
+const (
+	BranchPrefix = "branch:"
+	CommitPrefix = "commit:"
+	TagPrefix    = "tag:"
+	DraftPrefix  = "draft:"
+
+	TimeSeparator = "@" // 例如 main@2024-05-01T00:00:00Z
+)
+
+var ErrInvalidReference = errors.New("invalid reference")
+
+func (k Kind) String() string {
+	switch k {
+	case KindBranch:
+		return "branch"
+	case KindCommit:
+		return "commit"
+	case KindTag:
+		return "tag"
+	case KindDraft:
+		return "draft"
+	default:
+		return "unknown"
+	}
+}
+
+// Reference 解析后的reference
+// 带前缀的reference只会按照指定类型查询，不带前缀的reference(bare name)按照 branch > commit > tag > draft 的顺序查询
+type Reference struct {
+	Kind Kind // 为KindUnknown时表示没有前缀
+	Name string
+	Time *time.Time // 不为nil时表示查询分支在该时刻(含)之前的最后一次提交
+}
+
+// Parse 解析reference，空字符串等价于默认分支
+func Parse(reference string) (*Reference, error) {
+	// 解析时间点，仅支持分支
+	if index := strings.Index(reference, TimeSeparator); index >= 0 {
+		t, err := time.Parse(time.RFC3339, reference[index+len(TimeSeparator):])
+		if err != nil {
+			return nil, ErrInvalidReference
+		}
+
+		ref, err := Parse(reference[:index])
+		if err != nil {
+			return nil, err
+		}
+		if ref.Kind == KindUnknown && IsDefaultBranch(ref.Name) {
+			ref.Kind = KindBranch
+		}
+		if ref.Kind != KindBranch || ref.Time != nil {
+			return nil, ErrInvalidReference
+		}
+		ref.Time = &t
+
+		return ref, nil
+	}
+
+	prefixes := []struct {
+		prefix string
+		kind   Kind
+	}{
+		{BranchPrefix, KindBranch},
+		{CommitPrefix, KindCommit},
+		{TagPrefix, KindTag},
+		{DraftPrefix, KindDraft},
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(reference, p.prefix) {
+			name := strings.TrimPrefix(reference, p.prefix)
+			if name == "" {
+				return nil, ErrInvalidReference
+			}
+
+			return &Reference{Kind: p.kind, Name: name}, nil
+		}
+	}
+
+	if strings.Contains(reference, ":") {
+		// 未知前缀
+		return nil, ErrInvalidReference
+	}
+
+	return &Reference{Kind: KindUnknown, Name: reference}, nil
+}
+
+// Candidates 返回需要依次尝试的reference类型
+func (r *Reference) Candidates() []Kind {
+	if r.Kind != KindUnknown {
+		return []Kind{r.Kind}
+	}
+
+	if r.Name == "" || r.Name == constant.DefaultBranch {
+		return []Kind{KindBranch}
+	}
+
+	candidates := make([]Kind, 0, 3)
+	if len(r.Name) == constant.CommitLength {
+		candidates = append(candidates, KindCommit)
+	}
+
+	return append(candidates, KindTag, KindDraft)
+}
+
+// IsDefaultBranch 判断branch名称是否为默认分支
+func IsDefaultBranch(name string) bool {
+	return name == "" || name == constant.DefaultBranch
+}
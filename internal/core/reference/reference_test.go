@@ -0,0 +1,57 @@
+package reference
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	commitName := "0123456789abcdef0123456789abcdef"
+	tests := []struct {
+		reference  string
+		wantErr    bool
+		kind       Kind
+		name       string
+		candidates []Kind
+	}{
+		{reference: "", kind: KindUnknown, name: "", candidates: []Kind{KindBranch}},
+		{reference: "main", kind: KindUnknown, name: "main", candidates: []Kind{KindBranch}},
+		{reference: commitName, kind: KindUnknown, name: commitName, candidates: []Kind{KindCommit, KindTag, KindDraft}},
+		{reference: "v1", kind: KindUnknown, name: "v1", candidates: []Kind{KindTag, KindDraft}},
+		{reference: "branch:main", kind: KindBranch, name: "main", candidates: []Kind{KindBranch}},
+		{reference: "commit:" + commitName, kind: KindCommit, name: commitName, candidates: []Kind{KindCommit}},
+		{reference: "tag:main", kind: KindTag, name: "main", candidates: []Kind{KindTag}},
+		{reference: "draft:dev", kind: KindDraft, name: "dev", candidates: []Kind{KindDraft}},
+		{reference: "main@2024-05-01T00:00:00Z", kind: KindBranch, name: "main", candidates: []Kind{KindBranch}},
+		{reference: "branch:main@2024-05-01T08:00:00+08:00", kind: KindBranch, name: "main", candidates: []Kind{KindBranch}},
+		{reference: "tag:", wantErr: true},
+		{reference: "v1@2024-05-01T00:00:00Z", wantErr: true},
+		{reference: "main@2024-05-01", wantErr: true},
+		{reference: "unknown:v1", wantErr: true},
+	}
+
+	for _, test := range tests {
+		ref, err := Parse(test.reference)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("Parse(%q) expected error", test.reference)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Parse(%q) unexpected error: %v", test.reference, err)
+			continue
+		}
+		if ref.Kind != test.kind || ref.Name != test.name {
+			t.Errorf("Parse(%q) = (%v, %q), want (%v, %q)", test.reference, ref.Kind, ref.Name, test.kind, test.name)
+		}
+		if strings.Contains(test.reference, TimeSeparator) && (ref.Time == nil || !ref.Time.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))) {
+			t.Errorf("Parse(%q).Time = %v, want 2024-05-01T00:00:00Z", test.reference, ref.Time)
+		}
+		if !reflect.DeepEqual(ref.Candidates(), test.candidates) {
+			t.Errorf("Parse(%q).Candidates() = %v, want %v", test.reference, ref.Candidates(), test.candidates)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package resolve
+
+import (
+	"fmt"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"strings"
+)
+
+// DependencyGraph 解析后的依赖图，Nodes中第一个节点为根模块
+type DependencyGraph struct {
+	Root  string
+	Nodes []*DependencyNode
+	Edges []*DependencyEdge
+}
+
+// DependencyNode 依赖图中的模块，Identity为 remote/owner/repository
+type DependencyNode struct {
+	Identity string
+	Commit   *model.Commit
+	Tags     []string
+}
+
+// DependencyEdge From依赖To
+type DependencyEdge struct {
+	From string
+	To   string
+}
+
+// DOT 渲染为Graphviz DOT
+func (graph *DependencyGraph) DOT() string {
+	builder := &strings.Builder{}
+	builder.WriteString("digraph dependencies {\n")
+	builder.WriteString("  node [shape=box];\n")
+	for _, node := range graph.Nodes {
+		builder.WriteString(fmt.Sprintf("  %q [label=%q];\n", node.Identity, strings.Join(node.labelLines(), "\n")))
+	}
+	for _, edge := range graph.Edges {
+		builder.WriteString(fmt.Sprintf("  %q -> %q;\n", edge.From, edge.To))
+	}
+	builder.WriteString("}\n")
+
+	return builder.String()
+}
+
+// Mermaid 渲染为Mermaid flowchart，节点使用 n0、n1... 作为id
+func (graph *DependencyGraph) Mermaid() string {
+	ids := make(map[string]string, len(graph.Nodes))
+	builder := &strings.Builder{}
+	builder.WriteString("graph TD\n")
+	for i, node := range graph.Nodes {
+		id := fmt.Sprintf("n%d", i)
+		ids[node.Identity] = id
+		lines := node.labelLines()
+		for j := range lines {
+			lines[j] = strings.ReplaceAll(lines[j], "\"", "#quot;")
+		}
+		builder.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", id, strings.Join(lines, "<br/>")))
+	}
+	for _, edge := range graph.Edges {
+		from, ok := ids[edge.From]
+		if !ok {
+			continue
+		}
+		to, ok := ids[edge.To]
+		if !ok {
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("  %s --> %s\n", from, to))
+	}
+
+	return builder.String()
+}
+
+func (node *DependencyNode) labelLines() []string {
+	lines := []string{node.Identity}
+	if node.Commit != nil {
+		lines = append(lines, node.Commit.CommitName)
+	}
+	if len(node.Tags) > 0 {
+		lines = append(lines, strings.Join(node.Tags, ", "))
+	}
+
+	return lines
+}
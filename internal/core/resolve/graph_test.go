@@ -0,0 +1,49 @@
+package resolve
+
+import (
+	"github.com/ProtobufMan/bufman/internal/model"
+	"testing"
+)
+
+func newTestDependencyGraph() *DependencyGraph {
+	return &DependencyGraph{
+		Root: "bufman.io/acme/app",
+		Nodes: []*DependencyNode{
+			{Identity: "bufman.io/acme/app", Commit: &model.Commit{CommitName: "c1"}},
+			{Identity: "bufman.io/acme/weather", Commit: &model.Commit{CommitName: "c2"}, Tags: []string{"v1.0.0"}},
+			{Identity: "bufman.io/acme/units", Commit: &model.Commit{CommitName: "c3"}},
+		},
+		Edges: []*DependencyEdge{
+			{From: "bufman.io/acme/app", To: "bufman.io/acme/weather"},
+			{From: "bufman.io/acme/weather", To: "bufman.io/acme/units"},
+		},
+	}
+}
+
+func TestDependencyGraphDOT(t *testing.T) {
+	expected := `digraph dependencies {
+  node [shape=box];
+  "bufman.io/acme/app" [label="bufman.io/acme/app\nc1"];
+  "bufman.io/acme/weather" [label="bufman.io/acme/weather\nc2\nv1.0.0"];
+  "bufman.io/acme/units" [label="bufman.io/acme/units\nc3"];
+  "bufman.io/acme/app" -> "bufman.io/acme/weather";
+  "bufman.io/acme/weather" -> "bufman.io/acme/units";
+}
+`
+	if dot := newTestDependencyGraph().DOT(); dot != expected {
+		t.Errorf("unexpected dot:\n%s", dot)
+	}
+}
+
+func TestDependencyGraphMermaid(t *testing.T) {
+	expected := `graph TD
+  n0["bufman.io/acme/app<br/>c1"]
+  n1["bufman.io/acme/weather<br/>c2<br/>v1.0.0"]
+  n2["bufman.io/acme/units<br/>c3"]
+  n0 --> n1
+  n1 --> n2
+`
+	if mermaid := newTestDependencyGraph().Mermaid(); mermaid != expected {
+		t.Errorf("unexpected mermaid:\n%s", mermaid)
+	}
+}
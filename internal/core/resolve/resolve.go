@@ -6,101 +6,122 @@ import (
 	"fmt"
 	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufconfig"
 	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/ProtobufMan/bufman-cli/private/gen/proto/connect/bufman/alpha/registry/v1alpha1/registryv1alpha1connect"
 	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
 	"github.com/ProtobufMan/bufman/internal/config"
+	"github.com/ProtobufMan/bufman/internal/constant"
+	"github.com/ProtobufMan/bufman/internal/core/proxy"
+	"github.com/ProtobufMan/bufman/internal/core/reference"
 	"github.com/ProtobufMan/bufman/internal/core/storage"
+	"github.com/ProtobufMan/bufman/internal/core/version"
 	"github.com/ProtobufMan/bufman/internal/e"
 	"github.com/ProtobufMan/bufman/internal/mapper"
 	"github.com/ProtobufMan/bufman/internal/model"
 	"gorm.io/gorm"
 	"io"
+	"strings"
 )
 
 type Resolver interface {
 	GetAllDependenciesFromBufConfig(ctx context.Context, bufConfig *bufconfig.Config) (model.Commits, e.ResponseError)                         // 获取全部依赖
 	GetDirectDependenciesFromBufConfig(ctx context.Context, bufConfig *bufconfig.Config) (model.Commits, e.ResponseError)                      // 获取直接依赖
+	GetDependenciesFromBufConfig(ctx context.Context, bufConfig *bufconfig.Config) (model.Commits, model.Commits, e.ResponseError)             // 一次解析同时获取全部依赖以及冲突处理后的直接依赖
 	GetAllDependenciesFromModuleRefs(ctx context.Context, moduleReferences []bufmoduleref.ModuleReference) (model.Commits, e.ResponseError)    // 获取全部依赖
 	GetDirectDependenciesFromModuleRefs(ctx context.Context, moduleReferences []bufmoduleref.ModuleReference) (model.Commits, e.ResponseError) // 获取直接依赖
+	GetDependencyGraph(ctx context.Context, identity string, commit *model.Commit) (*DependencyGraph, e.ResponseError)                         // 获取包含依赖关系的依赖图
+	GetBufConfigFromCommitID(ctx context.Context, commitID string) (*bufconfig.Config, e.ResponseError)                                        // 读取commit中的buf.yaml
+	CheckCanAccess(ctx context.Context, moduleReference bufmoduleref.ModuleReference) e.ResponseError                                          // 检查当前用户是否可以访问依赖所在的仓库
+	SatisfiesReference(ctx context.Context, commit *model.Commit, moduleReference bufmoduleref.ModuleReference) (bool, e.ResponseError)        // 判断commit是否满足依赖reference的要求
+}
+
+// RepositoryAuthorizer 检查用户是否可以访问仓库，由services.AuthorizationService实现
+type RepositoryAuthorizer interface {
+	CheckRepositoryCanAccess(userID, ownerName, repositoryName, procedure string) (*model.Repository, e.ResponseError)
 }
 
 type ResolverImpl struct {
-	repositoryMapper mapper.RepositoryMapper
-	commitMapper     mapper.CommitMapper
-	fileMapper       mapper.FileMapper
-	storageHelper    storage.StorageHelper
+	authorizer    RepositoryAuthorizer
+	commitMapper  mapper.CommitMapper
+	tagMapper     mapper.TagMapper
+	fileMapper    mapper.FileMapper
+	storageHelper storage.StorageHelper
+	proxy         proxy.Proxy
 }
 
-func NewResolver() Resolver {
+func NewResolver(authorizer RepositoryAuthorizer) Resolver {
 	return &ResolverImpl{
-		repositoryMapper: &mapper.RepositoryMapperImpl{},
-		commitMapper:     &mapper.CommitMapperImpl{},
-		fileMapper:       &mapper.FileMapperImpl{},
-		storageHelper:    storage.NewStorageHelper(),
+		authorizer:    authorizer,
+		commitMapper:  &mapper.CommitMapperImpl{},
+		tagMapper:     &mapper.TagMapperImpl{},
+		fileMapper:    &mapper.FileMapperImpl{},
+		storageHelper: storage.NewStorageHelper(),
+		proxy:         proxy.NewProxy(),
 	}
 }
 
 func (resolver *ResolverImpl) GetAllDependenciesFromBufConfig(ctx context.Context, bufConfig *bufconfig.Config) (model.Commits, e.ResponseError) {
-	var dependentCommitSet map[string]*model.Commit
-	var err e.ResponseError
-	dependentCommitSet, err = resolver.doGetDependencies(ctx, dependentCommitSet, bufConfig.Build.DependencyModuleReferences, true)
-	if err != nil {
-		return nil, err
-	}
-
-	commits := make([]*model.Commit, 0, len(dependentCommitSet))
-	for _, commit := range dependentCommitSet {
-		commits = append(commits, commit)
-	}
-
-	return commits, nil
+	return resolver.resolveCommits(ctx, bufConfig.Build.DependencyModuleReferences, true)
 }
 
 func (resolver *ResolverImpl) GetDirectDependenciesFromBufConfig(ctx context.Context, bufConfig *bufconfig.Config) (model.Commits, e.ResponseError) {
-	var dependentCommitSet map[string]*model.Commit
-	var err e.ResponseError
-	dependentCommitSet, err = resolver.doGetDependencies(ctx, dependentCommitSet, bufConfig.Build.DependencyModuleReferences, false)
-	if err != nil {
-		return nil, err
-	}
+	return resolver.resolveCommits(ctx, bufConfig.Build.DependencyModuleReferences, false)
+}
 
-	commits := make([]*model.Commit, 0, len(dependentCommitSet))
-	for _, commit := range dependentCommitSet {
-		commits = append(commits, commit)
+func (resolver *ResolverImpl) GetDependenciesFromBufConfig(ctx context.Context, bufConfig *bufconfig.Config) (model.Commits, model.Commits, e.ResponseError) {
+	state, err := resolver.resolveDependencies(ctx, bufConfig.Build.DependencyModuleReferences, true, "")
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return commits, nil
+	return state.commits(), state.directCommits(), nil
 }
 
 func (resolver *ResolverImpl) GetAllDependenciesFromModuleRefs(ctx context.Context, moduleReferences []bufmoduleref.ModuleReference) (model.Commits, e.ResponseError) {
-	var dependentCommitSet map[string]*model.Commit
-	var err e.ResponseError
-	dependentCommitSet, err = resolver.doGetDependencies(ctx, dependentCommitSet, moduleReferences, true)
-	if err != nil {
-		return nil, err
-	}
+	return resolver.resolveCommits(ctx, moduleReferences, true)
+}
+
+func (resolver *ResolverImpl) GetDirectDependenciesFromModuleRefs(ctx context.Context, moduleReferences []bufmoduleref.ModuleReference) (model.Commits, e.ResponseError) {
+	return resolver.resolveCommits(ctx, moduleReferences, false)
+}
 
-	commits := make([]*model.Commit, 0, len(dependentCommitSet))
-	for _, commit := range dependentCommitSet {
-		commits = append(commits, commit)
+func (resolver *ResolverImpl) GetDependencyGraph(ctx context.Context, identity string, commit *model.Commit) (*DependencyGraph, e.ResponseError) {
+	graph := &DependencyGraph{
+		Root:  identity,
+		Nodes: []*DependencyNode{{Identity: identity, Commit: commit}},
+		Edges: []*DependencyEdge{},
 	}
 
-	return commits, nil
-}
+	if commit.BufManConfigDigest != "" {
+		bufConfig, err := resolver.GetBufConfigFromCommitID(ctx, commit.CommitID)
+		if err != nil {
+			return nil, err
+		}
 
-func (resolver *ResolverImpl) GetDirectDependenciesFromModuleRefs(ctx context.Context, moduleReferences []bufmoduleref.ModuleReference) (model.Commits, e.ResponseError) {
-	var dependentCommitSet map[string]*model.Commit
-	var err e.ResponseError
-	dependentCommitSet, err = resolver.doGetDependencies(ctx, dependentCommitSet, moduleReferences, false)
-	if err != nil {
-		return nil, err
+		state, err := resolver.resolveDependencies(ctx, bufConfig.Build.DependencyModuleReferences, true, identity)
+		if err != nil {
+			return nil, err
+		}
+		for _, dependencyIdentity := range state.order {
+			if dependencyIdentity == identity {
+				continue
+			}
+			graph.Nodes = append(graph.Nodes, &DependencyNode{Identity: dependencyIdentity, Commit: state.selected[dependencyIdentity].commit})
+		}
+		graph.Edges = state.edges
 	}
 
-	commits := make([]*model.Commit, 0, len(dependentCommitSet))
-	for _, commit := range dependentCommitSet {
-		commits = append(commits, commit)
+	// 查询每个commit上的tag
+	for _, node := range graph.Nodes {
+		tags, err := resolver.tagMapper.FindAllByCommitID(node.Commit.CommitID)
+		if err != nil {
+			return nil, e.NewInternalError(fmt.Sprintf("find tags(%s)", err.Error()))
+		}
+		for i := 0; i < len(tags); i++ {
+			node.Tags = append(node.Tags, tags[i].TagName)
+		}
 	}
 
-	return commits, nil
+	return graph, nil
 }
 
 func (resolver *ResolverImpl) GetBufConfigFromCommitID(ctx context.Context, commitID string) (*bufconfig.Config, e.ResponseError) {
@@ -116,7 +137,7 @@ func (resolver *ResolverImpl) GetBufConfigFromCommitID(ctx context.Context, comm
 	// 读取manifest
 	reader, err := resolver.storageHelper.ReadManifestToReader(ctx, manifestModel.Digest)
 	if err != nil {
-		return nil, nil
+		return nil, e.NewInternalError("GetDependenciesByCommitID")
 	}
 	fileManifest, err := manifest.NewFromReader(reader)
 	if err != nil {
@@ -164,67 +185,475 @@ func (resolver *ResolverImpl) GetBufConfigFromCommitID(ctx context.Context, comm
 	return bufConfig, nil
 }
 
-func (resolver *ResolverImpl) doGetDependencies(ctx context.Context, dependentCommitSet map[string]*model.Commit, dependencyReferences []bufmoduleref.ModuleReference, getAll bool) (map[string]*model.Commit, e.ResponseError) {
-	if dependentCommitSet == nil {
-		dependentCommitSet = map[string]*model.Commit{}
+// CheckCanAccess 检查当前用户是否可以访问依赖所在的仓库，其他remote上的依赖按照上游的访问规则检查
+func (resolver *ResolverImpl) CheckCanAccess(ctx context.Context, moduleReference bufmoduleref.ModuleReference) e.ResponseError {
+	if moduleReference.Remote() != config.Properties.BufMan.ServerHost {
+		return resolver.proxy.CheckCanAccess(ctx, moduleReference)
 	}
 
-	for i := 0; i < len(dependencyReferences); i++ {
-		dependencyReference := dependencyReferences[i]
-		if dependencyReference.Remote() == config.Properties.BufMan.ServerHost {
-			// 查询repo
-			repo, err := resolver.repositoryMapper.FindByUserNameAndRepositoryName(dependencyReference.Owner(), dependencyReference.Repository())
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+	_, err := resolver.authorizer.CheckRepositoryCanAccess(userID, moduleReference.Owner(), moduleReference.Repository(), registryv1alpha1connect.ResolveServiceGetModulePinsProcedure)
+
+	return err
+}
+
+// SatisfiesReference 判断commit是否满足依赖reference的要求：版本范围要求commit上有满足范围的tag，
+// 分支要求commit在分支上(指定了时间点时在该时间点之前)，其他reference要求与解析得到的commit相同
+func (resolver *ResolverImpl) SatisfiesReference(ctx context.Context, commit *model.Commit, moduleReference bufmoduleref.ModuleReference) (bool, e.ResponseError) {
+	versionRange, _, err := resolver.parseRange(commit.RepositoryID, moduleReference)
+	if err != nil {
+		return false, err
+	}
+	if versionRange != nil {
+		return resolver.commitMatchRange(commit, versionRange)
+	}
+
+	parsed, parseErr := reference.Parse(moduleReference.Reference())
+	if parseErr != nil {
+		return false, e.NewInvalidArgumentError(fmt.Sprintf("%s:%s", moduleReference.IdentityString(), moduleReference.Reference()))
+	}
+	if (parsed.Kind == reference.KindBranch || parsed.Kind == reference.KindUnknown) && reference.IsDefaultBranch(parsed.Name) {
+		if commit.DraftName != "" {
+			return false, nil
+		}
+
+		return parsed.Time == nil || !commit.CreatedTime.After(*parsed.Time), nil
+	}
+
+	_, expected, _, err := resolver.findDependency(ctx, moduleReference)
+	if err != nil {
+		return false, err
+	}
+
+	return expected.CommitName == commit.CommitName, nil
+}
+
+// resolution 一次依赖解析的状态
+type resolution struct {
+	getAll       bool
+	strategy     string
+	selected     map[string]*selectedDependency // identity -> 已经选中的依赖
+	order        []string                       // 选中依赖的顺序，保证返回结果稳定
+	direct       []string                       // 根模块直接声明的依赖
+	overrides    map[string]*model.Commit       // identity -> 冲突处理后固定使用的commit
+	requirements map[string][]*requirement      // identity -> 本次解析中对该依赖的所有要求
+	preferred    map[string]*model.Commit       // identity -> 同时满足所有要求的commit，重新解析时优先使用
+	tried        map[string]map[string]struct{} // identity -> 已经尝试过的commit name，避免反复重新解析
+	edges        []*DependencyEdge              // 依赖关系
+	edgeSet      map[DependencyEdge]struct{}
+}
+
+func (state *resolution) commits() model.Commits {
+	commits := make(model.Commits, 0, len(state.order))
+	for _, identity := range state.order {
+		commits = append(commits, state.selected[identity].commit)
+	}
+
+	return commits
+}
+
+// directCommits 直接依赖最终选中的commit
+func (state *resolution) directCommits() model.Commits {
+	commits := make(model.Commits, 0, len(state.direct))
+	for _, identity := range state.direct {
+		commits = append(commits, state.selected[identity].commit)
+	}
+
+	return commits
+}
+
+func (state *resolution) addEdge(from, to string) {
+	if from == "" {
+		return
+	}
+
+	edge := DependencyEdge{From: from, To: to}
+	if _, ok := state.edgeSet[edge]; ok {
+		return
+	}
+	state.edgeSet[edge] = struct{}{}
+	state.edges = append(state.edges, &edge)
+}
+
+// selectedDependency 选中的依赖，以及引入它的依赖路径
+type selectedDependency struct {
+	commit *model.Commit
+	path   []string
+}
+
+// requirement 依赖路径上对某个依赖的一次引用，versionRange为nil时表示固定的commit
+type requirement struct {
+	commit       *model.Commit
+	versionRange *version.Range
+	path         []string
+}
+
+// prefer 记录同时满足所有要求的commit，返回false表示该commit已经尝试过
+func (state *resolution) prefer(identity string, commit *model.Commit) bool {
+	tried, ok := state.tried[identity]
+	if !ok {
+		tried = map[string]struct{}{}
+		state.tried[identity] = tried
+	}
+	if _, ok = tried[commit.CommitName]; ok {
+		return false
+	}
+	tried[commit.CommitName] = struct{}{}
+	state.preferred[identity] = commit
+
+	return true
+}
+
+func (resolver *ResolverImpl) resolveCommits(ctx context.Context, dependencyReferences []bufmoduleref.ModuleReference, getAll bool) (model.Commits, e.ResponseError) {
+	state, err := resolver.resolveDependencies(ctx, dependencyReferences, getAll, "")
+	if err != nil {
+		return nil, err
+	}
+
+	return state.commits(), nil
+}
+
+// resolveDependencies 解析依赖，同一个仓库出现不同commit时按照配置的策略处理，root不为空时记录root到直接依赖的依赖关系
+func (resolver *ResolverImpl) resolveDependencies(ctx context.Context, dependencyReferences []bufmoduleref.ModuleReference, getAll bool, root string) (*resolution, e.ResponseError) {
+	strategy := config.Properties.BufMan.DependencyConflictStrategy
+	overrides := map[string]*model.Commit{}
+	if strategy == constant.DependencyConflictStrategyRoot {
+		// 根模块直接声明的依赖优先
+		rootPaths := map[string]string{}
+		for i := 0; i < len(dependencyReferences); i++ {
+			dependencyReference := dependencyReferences[i]
+			identity, commit, _, err := resolver.findDependency(ctx, dependencyReference)
 			if err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					return nil, e.NewNotFoundError(dependencyReference.IdentityString())
-				}
-				return nil, e.NewInternalError(fmt.Sprintf("find repository(%s)", err.Error()))
+				return nil, err
 			}
+			path := dependencyPathElement(identity, dependencyReference)
+			if pinned, ok := overrides[identity]; ok && pinned.CommitName != commit.CommitName {
+				return nil, newConflictError(identity, []string{rootPaths[identity]}, pinned, []string{path}, commit)
+			}
+			overrides[identity] = commit
+			rootPaths[identity] = path
+		}
+	}
 
-			// 查询当前reference，版本号是否一样
-			commit, err := resolver.commitMapper.FindByRepositoryIDAndReference(repo.RepositoryID, dependencyReference.Reference())
-			if err != nil {
-				if errors.Is(err, gorm.ErrRecordNotFound) {
-					return nil, e.NewNotFoundError(fmt.Sprintf("%s:%s", dependencyReference.IdentityString(), dependencyReference.Reference()))
-				}
-				return nil, e.NewInternalError(fmt.Sprintf("find reference(%s)", err.Error()))
+	preferred := map[string]*model.Commit{}
+	tried := map[string]map[string]struct{}{}
+	for {
+		state := &resolution{
+			getAll:       getAll,
+			strategy:     strategy,
+			selected:     map[string]*selectedDependency{},
+			overrides:    overrides,
+			requirements: map[string][]*requirement{},
+			preferred:    preferred,
+			tried:        tried,
+			edgeSet:      map[DependencyEdge]struct{}{},
+		}
+		restart, err := resolver.doGetDependencies(ctx, state, dependencyReferences, root, nil)
+		if err != nil {
+			return nil, err
+		}
+		if restart {
+			// 选中的commit发生了变化，之前记录的间接依赖可能已经失效，重新解析
+			continue
+		}
+
+		return state, nil
+	}
+}
+
+// doGetDependencies 深度优先遍历依赖，返回true表示需要重新解析
+func (resolver *ResolverImpl) doGetDependencies(ctx context.Context, state *resolution, dependencyReferences []bufmoduleref.ModuleReference, parent string, parentPath []string) (bool, e.ResponseError) {
+	for i := 0; i < len(dependencyReferences); i++ {
+		dependencyReference := dependencyReferences[i]
+		identity, commit, versionRange, err := resolver.findDependency(ctx, dependencyReference)
+		if err != nil {
+			return false, err
+		}
+		state.addEdge(parent, identity)
+		if len(parentPath) == 0 && !containsString(state.direct, identity) {
+			state.direct = append(state.direct, identity)
+		}
+		path := append(append(make([]string, 0, len(parentPath)+1), parentPath...), dependencyPathElement(identity, dependencyReference))
+		current := &requirement{commit: commit, versionRange: versionRange, path: path}
+		state.requirements[identity] = append(state.requirements[identity], current)
+
+		if override, ok := state.overrides[identity]; ok {
+			if state.strategy == constant.DependencyConflictStrategyNewest && commit.SequenceID > override.SequenceID {
+				// 出现了更新的commit
+				state.overrides[identity] = commit
+				return true, nil
+			}
+			commit = override
+		} else if preferred, ok := state.preferred[identity]; ok {
+			// 之前求出的交集满足当前要求时使用交集
+			satisfied, satisfyErr := resolver.satisfies(preferred, current)
+			if satisfyErr != nil {
+				return false, satisfyErr
+			}
+			if satisfied {
+				commit = preferred
 			}
+		}
 
-			dependentCommit, ok := dependentCommitSet[dependencyReference.IdentityString()]
-			if ok && dependentCommit.CommitName == dependencyReference.Reference() {
+		selected, ok := state.selected[identity]
+		if ok {
+			if selected.commit.CommitName == commit.CommitName {
+				// 当前依赖已经记录，跳过
 				continue
 			}
-			if ok {
-				// 之前已经记录过 owner/repository 的commit
-				if commit.CommitName != dependentCommit.CommitName {
-					// 同一个仓库下的依赖版本号不同，返回错误
-					return nil, e.NewInternalError(fmt.Sprintf("two different version %s and %s for %s", dependentCommit.CommitName, dependencyReference.Reference(), dependencyReference.IdentityString()))
+
+			// 版本范围只要求已经选中的commit满足范围即可
+			if versionRange != nil {
+				matched, matchErr := resolver.commitMatchRange(selected.commit, versionRange)
+				if matchErr != nil {
+					return false, matchErr
+				}
+				if matched {
+					continue
 				}
+			}
 
-				// 当前依赖已经记录，跳过
-				continue
-			} else {
-				// 如果之前没有记录过，记录依赖commit
-				dependentCommitSet[dependencyReference.IdentityString()] = commit
-
-				if getAll {
-					// 需要获取全部依赖，记录这个依赖下的依赖关系
-					dependentBufConfig, configErr := resolver.GetBufConfigFromCommitID(ctx, commit.CommitID)
-					if configErr != nil {
-						return nil, configErr
-					}
-
-					var dependentErr e.ResponseError
-					dependentCommitSet, dependentErr = resolver.doGetDependencies(ctx, dependentCommitSet, dependentBufConfig.Build.DependencyModuleReferences, true)
-					if dependentErr != nil {
-						return nil, dependentErr
-					}
+			// 对同一个依赖的所有要求求交集，存在满足全部要求的commit时使用该commit重新解析
+			intersection, intersectErr := resolver.intersect(identity, state.requirements[identity])
+			if intersectErr != nil {
+				return false, intersectErr
+			}
+			if intersection != nil && state.prefer(identity, intersection) {
+				return true, nil
+			}
+
+			if state.strategy == constant.DependencyConflictStrategyNewest {
+				if selected.commit.SequenceID >= commit.SequenceID {
+					// 已经选中的commit更新，保留
+					state.overrides[identity] = selected.commit
+					continue
 				}
+				state.overrides[identity] = commit
+				return true, nil
+			}
+
+			// 同一个仓库下的依赖版本号不同，返回错误
+			return false, newConflictError(identity, selected.path, selected.commit, path, commit)
+		}
 
+		// 如果之前没有记录过，记录依赖commit
+		state.selected[identity] = &selectedDependency{commit: commit, path: path}
+		state.order = append(state.order, identity)
+
+		if state.getAll {
+			// 需要获取全部依赖，记录这个依赖下的依赖关系
+			dependentBufConfig, configErr := resolver.GetBufConfigFromCommitID(ctx, commit.CommitID)
+			if configErr != nil {
+				return false, configErr
+			}
+
+			restart, dependentErr := resolver.doGetDependencies(ctx, state, dependentBufConfig.Build.DependencyModuleReferences, identity, path)
+			if dependentErr != nil || restart {
+				return restart, dependentErr
 			}
 		}
 	}
 
 	// 通过
-	return dependentCommitSet, nil
+	return false, nil
+}
+
+// findDependency 查询依赖所在的仓库以及reference对应的commit，reference为版本范围时同时返回解析后的范围，其他remote上的依赖通过上游代理获取
+func (resolver *ResolverImpl) findDependency(ctx context.Context, dependencyReference bufmoduleref.ModuleReference) (string, *model.Commit, *version.Range, e.ResponseError) {
+	if dependencyReference.Remote() != config.Properties.BufMan.ServerHost {
+		commit, err := resolver.proxy.GetCommit(ctx, dependencyReference)
+		if err != nil {
+			return "", nil, nil, err
+		}
+
+		// 上游已经选取了满足范围的commit，范围只用于和其他要求求交集
+		versionRange, _, err := resolver.parseRange(commit.RepositoryID, dependencyReference)
+		if err != nil {
+			return "", nil, nil, err
+		}
+
+		return dependencyReference.IdentityString(), commit, versionRange, nil
+	}
+
+	// 查询repo，间接依赖同样需要检查当前用户是否有权访问
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+	repo, checkErr := resolver.authorizer.CheckRepositoryCanAccess(userID, dependencyReference.Owner(), dependencyReference.Repository(), registryv1alpha1connect.ResolveServiceGetModulePinsProcedure)
+	if checkErr != nil {
+		return "", nil, nil, checkErr
+	}
+
+	// 仓库可能被重命名或者转移，使用仓库当前的名称记录依赖
+	identity := fmt.Sprintf("%s/%s/%s", dependencyReference.Remote(), repo.UserName, repo.RepositoryName)
+
+	commit, versionRange, findErr := resolver.findDependentCommit(repo.RepositoryID, dependencyReference)
+	if findErr != nil {
+		return "", nil, nil, findErr
+	}
+
+	return identity, commit, versionRange, nil
+}
+
+// dependencyPathElement 依赖路径中的一项，例如 bufman.io/acme/weather:v1.0.0
+func dependencyPathElement(identity string, dependencyReference bufmoduleref.ModuleReference) string {
+	if dependencyReference.Reference() == "" {
+		return identity
+	}
+
+	return fmt.Sprintf("%s:%s", identity, dependencyReference.Reference())
+}
+
+// newConflictError 依赖冲突的错误，包含引入两个commit的依赖路径
+func newConflictError(identity string, selectedPath []string, selectedCommit *model.Commit, path []string, commit *model.Commit) e.ResponseError {
+	return e.NewFailedPreconditionError(fmt.Sprintf("conflicting versions for %s: [%s] requires commit %s, but [%s] requires commit %s",
+		identity,
+		strings.Join(selectedPath, " -> "), selectedCommit.CommitName,
+		strings.Join(path, " -> "), commit.CommitName,
+	))
+}
+
+// findDependentCommit 查询依赖reference对应的commit，版本范围(例如 v1.x、^1.2.0)会选取满足范围的最高semver tag
+func (resolver *ResolverImpl) findDependentCommit(repositoryID string, dependencyReference bufmoduleref.ModuleReference) (*model.Commit, *version.Range, e.ResponseError) {
+	ref := dependencyReference.Reference()
+	versionRange, tags, respErr := resolver.parseRange(repositoryID, dependencyReference)
+	if respErr != nil {
+		return nil, nil, respErr
+	}
+	if versionRange != nil {
+		tagName, ok := versionRange.Highest(tagNames(tags))
+		if !ok {
+			return nil, nil, e.NewNotFoundError(fmt.Sprintf("semver tag matching %s:%s", dependencyReference.IdentityString(), ref))
+		}
+		ref = tagName
+	}
+
+	commit, err := resolver.findCommitByReference(repositoryID, dependencyReference.IdentityString(), ref)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return commit, versionRange, nil
+}
+
+func (resolver *ResolverImpl) findCommitByReference(repositoryID, identity, ref string) (*model.Commit, e.ResponseError) {
+	commit, err := resolver.commitMapper.FindByRepositoryIDAndReference(repositoryID, ref)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, e.NewNotFoundError(fmt.Sprintf("%s:%s", identity, ref))
+		}
+		if errors.Is(err, mapper.ErrInvalidReference) {
+			return nil, e.NewInvalidArgumentError(fmt.Sprintf("%s:%s", identity, ref))
+		}
+		return nil, e.NewInternalError(fmt.Sprintf("find reference(%s)", err.Error()))
+	}
+
+	return commit, nil
+}
+
+// parseRange 解析reference中的版本范围，同时返回仓库的所有tag；不是范围语法，或者仓库中存在同名的tag(例如名为x的tag)时返回nil
+func (resolver *ResolverImpl) parseRange(repositoryID string, dependencyReference bufmoduleref.ModuleReference) (*version.Range, model.Tags, e.ResponseError) {
+	ref := dependencyReference.Reference()
+	if !version.IsRange(ref) {
+		return nil, nil, nil
+	}
+
+	tags, err := resolver.tagMapper.FindAllByRepositoryID(repositoryID)
+	if err != nil {
+		return nil, nil, e.NewInternalError(fmt.Sprintf("find tags(%s)", err.Error()))
+	}
+	for i := 0; i < len(tags); i++ {
+		if tags[i].TagName == ref {
+			// 同名的tag优先
+			return nil, nil, nil
+		}
+	}
+
+	versionRange, err := version.ParseRange(ref)
+	if err != nil {
+		return nil, nil, e.NewInvalidArgumentError(fmt.Sprintf("version range %s:%s", dependencyReference.IdentityString(), ref))
+	}
+
+	return versionRange, tags, nil
+}
+
+// commitMatchRange 判断commit上的tag是否满足版本范围
+func (resolver *ResolverImpl) commitMatchRange(commit *model.Commit, versionRange *version.Range) (bool, e.ResponseError) {
+	tags, err := resolver.tagMapper.FindAllByCommitID(commit.CommitID)
+	if err != nil {
+		return false, e.NewInternalError(fmt.Sprintf("find tags(%s)", err.Error()))
+	}
+	for i := 0; i < len(tags); i++ {
+		if versionRange.Match(tags[i].TagName) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// satisfies 判断commit是否满足要求
+func (resolver *ResolverImpl) satisfies(commit *model.Commit, req *requirement) (bool, e.ResponseError) {
+	if req.versionRange == nil {
+		return commit.CommitName == req.commit.CommitName, nil
+	}
+
+	return resolver.commitMatchRange(commit, req.versionRange)
+}
+
+// intersect 求对同一个依赖的所有要求的交集：存在固定的commit时该commit需要满足所有范围，否则选取同时满足所有范围的最高semver tag，交集为空时返回nil
+func (resolver *ResolverImpl) intersect(identity string, requirements []*requirement) (*model.Commit, e.ResponseError) {
+	var pinned *model.Commit
+	ranges := make([]*version.Range, 0, len(requirements))
+	for _, req := range requirements {
+		if req.versionRange != nil {
+			ranges = append(ranges, req.versionRange)
+			continue
+		}
+		if pinned != nil && pinned.CommitName != req.commit.CommitName {
+			// 固定了两个不同的commit
+			return nil, nil
+		}
+		pinned = req.commit
+	}
+
+	if pinned != nil {
+		for _, versionRange := range ranges {
+			matched, err := resolver.commitMatchRange(pinned, versionRange)
+			if err != nil || !matched {
+				return nil, err
+			}
+		}
+
+		return pinned, nil
+	}
+
+	repositoryID := requirements[0].commit.RepositoryID
+	tags, err := resolver.tagMapper.FindAllByRepositoryID(repositoryID)
+	if err != nil {
+		return nil, e.NewInternalError(fmt.Sprintf("find tags(%s)", err.Error()))
+	}
+	tagName, ok := version.Highest(tagNames(tags), ranges...)
+	if !ok {
+		return nil, nil
+	}
+
+	return resolver.findCommitByReference(repositoryID, identity, tagName)
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func tagNames(tags model.Tags) []string {
+	names := make([]string, 0, len(tags))
+	for i := 0; i < len(tags); i++ {
+		names = append(names, tags[i].TagName)
+	}
+
+	return names
 }
@@ -0,0 +1,525 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufconfig"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
+	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
+	"github.com/ProtobufMan/bufman/internal/config"
+	"github.com/ProtobufMan/bufman/internal/constant"
+	"github.com/ProtobufMan/bufman/internal/core/storage"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/ProtobufMan/bufman/internal/mapper"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"github.com/bufbuild/connect-go"
+	"gorm.io/gorm"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testServerHost = "bufman.io"
+
+// testRegistry 内存中的仓库、commit、tag以及每个commit的buf.yaml
+type testRegistry struct {
+	repositories map[string]*model.Repository // owner/name -> repository
+	commits      model.Commits
+	tags         model.Tags
+	manifests    map[string][]byte // manifest digest -> manifest
+	blobs        map[string][]byte // blob digest -> content
+}
+
+func newTestRegistry() *testRegistry {
+	return &testRegistry{
+		repositories: map[string]*model.Repository{},
+		manifests:    map[string][]byte{},
+		blobs:        map[string][]byte{},
+	}
+}
+
+func (registry *testRegistry) addRepository(owner, name string, visibility registryv1alpha1.Visibility) *model.Repository {
+	repository := &model.Repository{
+		UserID:         owner + "-id",
+		UserName:       owner,
+		RepositoryID:   owner + "/" + name,
+		RepositoryName: name,
+		Visibility:     uint8(visibility),
+	}
+	registry.repositories[owner+"/"+name] = repository
+
+	return repository
+}
+
+// addCommit 添加commit，deps为buf.yaml中声明的依赖
+func (registry *testRegistry) addCommit(t *testing.T, repository *model.Repository, commitName string, sequenceID int64, deps []string, tagNames ...string) *model.Commit {
+	commit := &model.Commit{
+		UserID:             repository.UserID,
+		UserName:           repository.UserName,
+		RepositoryID:       repository.RepositoryID,
+		RepositoryName:     repository.RepositoryName,
+		CommitID:           commitName + "-id",
+		CommitName:         commitName,
+		SequenceID:         sequenceID,
+		BufManConfigDigest: commitName,
+	}
+	registry.commits = append(registry.commits, commit)
+	for _, tagName := range tagNames {
+		registry.tags = append(registry.tags, &model.Tag{
+			RepositoryID: repository.RepositoryID,
+			CommitID:     commit.CommitID,
+			CommitName:   commitName,
+			TagName:      tagName,
+		})
+	}
+
+	// 生成包含buf.yaml的manifest
+	configData := "version: v1\n"
+	if len(deps) > 0 {
+		configData += "deps:\n"
+		for _, dep := range deps {
+			configData += fmt.Sprintf("  - %s/%s\n", testServerHost, dep)
+		}
+	}
+	ctx := context.Background()
+	blob, err := manifest.NewMemoryBlobFromReader(strings.NewReader(configData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileManifest := manifest.New()
+	if err = fileManifest.AddEntry(bufconfig.AllConfigFilePaths[0], *blob.Digest()); err != nil {
+		t.Fatal(err)
+	}
+	manifestBlob, err := fileManifest.Blob()
+	if err != nil {
+		t.Fatal(err)
+	}
+	readCloser, err := manifestBlob.Open(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer readCloser.Close()
+	manifestData, err := io.ReadAll(readCloser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry.manifests[commit.CommitID] = manifestData
+	registry.blobs[blob.Digest().Hex()] = []byte(configData)
+
+	return commit
+}
+
+func (registry *testRegistry) newResolver() *ResolverImpl {
+	return &ResolverImpl{
+		authorizer:    &testAuthorizer{registry: registry},
+		commitMapper:  &testCommitMapper{registry: registry},
+		tagMapper:     &testTagMapper{registry: registry},
+		fileMapper:    &testFileMapper{registry: registry},
+		storageHelper: &testStorageHelper{registry: registry},
+	}
+}
+
+// testAuthorizer 与services.AuthorizationService相同，非公开仓库只有所属用户可以访问
+type testAuthorizer struct {
+	registry *testRegistry
+}
+
+func (authorizer *testAuthorizer) CheckRepositoryCanAccess(userID, ownerName, repositoryName, procedure string) (*model.Repository, e.ResponseError) {
+	repository, ok := authorizer.registry.repositories[ownerName+"/"+repositoryName]
+	if !ok {
+		return nil, e.NewNotFoundError(fmt.Sprintf("repository [name=%s/%s]", ownerName, repositoryName))
+	}
+	if registryv1alpha1.Visibility(repository.Visibility) != registryv1alpha1.Visibility_VISIBILITY_PUBLIC && repository.UserID != userID {
+		return nil, e.NewPermissionDeniedError(fmt.Sprintf("repository [name=%s/%s]", ownerName, repositoryName))
+	}
+
+	return repository, nil
+}
+
+type testCommitMapper struct {
+	mapper.CommitMapper
+	registry *testRegistry
+}
+
+func (commitMapper *testCommitMapper) FindByRepositoryIDAndReference(repositoryID string, reference string) (*model.Commit, error) {
+	var latest *model.Commit
+	for _, commit := range commitMapper.registry.commits {
+		if commit.RepositoryID != repositoryID {
+			continue
+		}
+		if commit.CommitName == reference {
+			return commit, nil
+		}
+		if latest == nil || commit.SequenceID > latest.SequenceID {
+			latest = commit
+		}
+	}
+	for _, tag := range commitMapper.registry.tags {
+		if tag.RepositoryID == repositoryID && tag.TagName == reference {
+			return commitMapper.findByCommitID(tag.CommitID), nil
+		}
+	}
+	if (reference == "" || reference == constant.DefaultBranch) && latest != nil {
+		return latest, nil
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (commitMapper *testCommitMapper) findByCommitID(commitID string) *model.Commit {
+	for _, commit := range commitMapper.registry.commits {
+		if commit.CommitID == commitID {
+			return commit
+		}
+	}
+
+	return nil
+}
+
+type testTagMapper struct {
+	mapper.TagMapper
+	registry *testRegistry
+}
+
+func (tagMapper *testTagMapper) FindAllByRepositoryID(repositoryID string) (model.Tags, error) {
+	var tags model.Tags
+	for _, tag := range tagMapper.registry.tags {
+		if tag.RepositoryID == repositoryID {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, nil
+}
+
+func (tagMapper *testTagMapper) FindAllByCommitID(commitID string) (model.Tags, error) {
+	var tags model.Tags
+	for _, tag := range tagMapper.registry.tags {
+		if tag.CommitID == commitID {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, nil
+}
+
+type testFileMapper struct {
+	mapper.FileMapper
+	registry *testRegistry
+}
+
+func (fileMapper *testFileMapper) FindManifestByCommitID(commitID string) (*model.FileManifest, error) {
+	if _, ok := fileMapper.registry.manifests[commitID]; !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &model.FileManifest{CommitID: commitID, Digest: commitID}, nil
+}
+
+type testStorageHelper struct {
+	storage.StorageHelper
+	registry *testRegistry
+}
+
+func (storageHelper *testStorageHelper) ReadManifestToReader(ctx context.Context, fileName string) (io.Reader, error) {
+	return bytes.NewReader(storageHelper.registry.manifests[fileName]), nil
+}
+
+func (storageHelper *testStorageHelper) ReadBlobToReader(ctx context.Context, digest string) (io.Reader, error) {
+	return bytes.NewReader(storageHelper.registry.blobs[digest]), nil
+}
+
+// testFailedStorageHelper 读取文件失败
+type testFailedStorageHelper struct {
+	storage.StorageHelper
+}
+
+func (storageHelper *testFailedStorageHelper) ReadManifestToReader(ctx context.Context, fileName string) (io.Reader, error) {
+	return nil, errors.New("storage unavailable")
+}
+
+// setTestConfig 设置测试使用的配置，返回恢复原配置的函数
+func setTestConfig(strategy string) func() {
+	properties := config.Properties
+	config.Properties = &config.Config{}
+	config.Properties.BufMan.ServerHost = testServerHost
+	config.Properties.BufMan.DependencyConflictStrategy = strategy
+
+	return func() {
+		config.Properties = properties
+	}
+}
+
+func newTestModuleReferences(t *testing.T, deps ...string) []bufmoduleref.ModuleReference {
+	moduleReferences := make([]bufmoduleref.ModuleReference, 0, len(deps))
+	for _, dep := range deps {
+		ownerAndRepository, reference, _ := strings.Cut(dep, ":")
+		owner, repository, _ := strings.Cut(ownerAndRepository, "/")
+		moduleReference, err := bufmoduleref.NewModuleReference(testServerHost, owner, repository, reference)
+		if err != nil {
+			t.Fatal(err)
+		}
+		moduleReferences = append(moduleReferences, moduleReference)
+	}
+
+	return moduleReferences
+}
+
+func commitNames(commits model.Commits) []string {
+	names := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		names = append(names, commit.CommitName)
+	}
+
+	return names
+}
+
+// newVersionedTestRegistry weather有v1.0.0、v1.1.0、v1.2.0三个版本
+func newVersionedTestRegistry(t *testing.T) (*testRegistry, *model.Repository) {
+	registry := newTestRegistry()
+	weather := registry.addRepository("acme", "weather", registryv1alpha1.Visibility_VISIBILITY_PUBLIC)
+	registry.addCommit(t, weather, "weather1", 1, nil, "v1.0.0")
+	registry.addCommit(t, weather, "weather2", 2, nil, "v1.1.0")
+	registry.addCommit(t, weather, "weather3", 3, nil, "v1.2.0")
+
+	return registry, weather
+}
+
+func TestResolveIntersectsVersionConstraints(t *testing.T) {
+	defer setTestConfig(constant.DependencyConflictStrategyFail)()
+
+	tests := []struct {
+		name     string
+		deps     []string
+		expected []string
+	}{
+		{name: "range then pin", deps: []string{"acme/weather:^1.0.0", "acme/weather:v1.1.0"}, expected: []string{"weather2"}},
+		{name: "pin then range", deps: []string{"acme/weather:v1.1.0", "acme/weather:^1.0.0"}, expected: []string{"weather2"}},
+		{name: "two ranges", deps: []string{"acme/weather:v1.x", "acme/weather:<1.2.0"}, expected: []string{"weather2"}},
+		{name: "transitive pin after range", deps: []string{"acme/weather:^1.0.0", "acme/client:v1.0.0"}, expected: []string{"weather1", "client1"}},
+		{name: "transitive pin before range", deps: []string{"acme/client:v1.0.0", "acme/weather:^1.0.0"}, expected: []string{"client1", "weather1"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry, _ := newVersionedTestRegistry(t)
+			client := registry.addRepository("acme", "client", registryv1alpha1.Visibility_VISIBILITY_PUBLIC)
+			registry.addCommit(t, client, "client1", 1, []string{"acme/weather:v1.0.0"}, "v1.0.0")
+
+			commits, err := registry.newResolver().GetAllDependenciesFromModuleRefs(context.Background(), newTestModuleReferences(t, test.deps...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if names := commitNames(commits); strings.Join(names, ",") != strings.Join(test.expected, ",") {
+				t.Errorf("expected %v, got %v", test.expected, names)
+			}
+		})
+	}
+}
+
+func TestResolveDisjointVersionConstraints(t *testing.T) {
+	defer setTestConfig(constant.DependencyConflictStrategyFail)()
+
+	registry, _ := newVersionedTestRegistry(t)
+	_, err := registry.newResolver().GetAllDependenciesFromModuleRefs(context.Background(), newTestModuleReferences(t, "acme/weather:~1.2.0", "acme/weather:v1.1.0"))
+	if err == nil || err.Code() != connect.CodeFailedPrecondition {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+}
+
+func TestResolveTagNamedLikeRange(t *testing.T) {
+	defer setTestConfig(constant.DependencyConflictStrategyFail)()
+
+	registry, weather := newVersionedTestRegistry(t)
+	registry.addCommit(t, weather, "weather4", 4, nil, "x")
+
+	// 名为x的tag优先于通配符
+	commits, err := registry.newResolver().GetAllDependenciesFromModuleRefs(context.Background(), newTestModuleReferences(t, "acme/weather:x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := commitNames(commits); len(names) != 1 || names[0] != "weather4" {
+		t.Errorf("expected [weather4], got %v", names)
+	}
+
+	// 仓库中不存在名为*的tag，按照通配符选取最高版本
+	commits, err = registry.newResolver().GetAllDependenciesFromModuleRefs(context.Background(), newTestModuleReferences(t, "acme/weather:*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := commitNames(commits); len(names) != 1 || names[0] != "weather3" {
+		t.Errorf("expected [weather3], got %v", names)
+	}
+}
+
+func TestResolveConflictStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		deps     []string
+		expected map[string][]string // strategy -> 解析结果，nil表示返回冲突错误
+	}{
+		{
+			name: "direct pin newer than transitive pin",
+			deps: []string{"acme/client:v1.0.0", "acme/weather:v1.1.0"},
+			expected: map[string][]string{
+				constant.DependencyConflictStrategyFail:   nil,
+				constant.DependencyConflictStrategyNewest: {"client1", "weather2"},
+				constant.DependencyConflictStrategyRoot:   {"client1", "weather2"},
+			},
+		},
+		{
+			name: "direct pin older than transitive pin",
+			deps: []string{"acme/weather:v1.0.0", "acme/client:v2.0.0"},
+			expected: map[string][]string{
+				constant.DependencyConflictStrategyFail:   nil,
+				constant.DependencyConflictStrategyNewest: {"weather2", "client2"},
+				constant.DependencyConflictStrategyRoot:   {"weather1", "client2"},
+			},
+		},
+		{
+			name: "two direct pins",
+			deps: []string{"acme/weather:v1.0.0", "acme/weather:v1.1.0"},
+			expected: map[string][]string{
+				constant.DependencyConflictStrategyFail:   nil,
+				constant.DependencyConflictStrategyNewest: {"weather2"},
+				constant.DependencyConflictStrategyRoot:   nil,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		for _, strategy := range []string{constant.DependencyConflictStrategyFail, constant.DependencyConflictStrategyNewest, constant.DependencyConflictStrategyRoot} {
+			expected := test.expected[strategy]
+			t.Run(test.name+"/"+strategy, func(t *testing.T) {
+				defer setTestConfig(strategy)()
+
+				registry, _ := newVersionedTestRegistry(t)
+				client := registry.addRepository("acme", "client", registryv1alpha1.Visibility_VISIBILITY_PUBLIC)
+				registry.addCommit(t, client, "client1", 1, []string{"acme/weather:v1.0.0"}, "v1.0.0")
+				registry.addCommit(t, client, "client2", 2, []string{"acme/weather:v1.1.0"}, "v2.0.0")
+
+				commits, err := registry.newResolver().GetAllDependenciesFromModuleRefs(context.Background(), newTestModuleReferences(t, test.deps...))
+				if expected == nil {
+					if err == nil || err.Code() != connect.CodeFailedPrecondition {
+						t.Fatalf("expected conflict, got %v, %v", commitNames(commits), err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if names := commitNames(commits); strings.Join(names, ",") != strings.Join(expected, ",") {
+					t.Errorf("expected %v, got %v", expected, names)
+				}
+			})
+		}
+	}
+}
+
+func TestResolvePrivateTransitiveDependency(t *testing.T) {
+	defer setTestConfig(constant.DependencyConflictStrategyFail)()
+
+	// 公开的client依赖acme的私有仓库secret
+	registry := newTestRegistry()
+	secret := registry.addRepository("acme", "secret", registryv1alpha1.Visibility_VISIBILITY_PRIVATE)
+	registry.addCommit(t, secret, "secret1", 1, nil)
+	client := registry.addRepository("acme", "client", registryv1alpha1.Visibility_VISIBILITY_PUBLIC)
+	registry.addCommit(t, client, "client1", 1, []string{"acme/secret"})
+	moduleReferences := newTestModuleReferences(t, "acme/client")
+
+	tests := []struct {
+		name   string
+		userID string
+		code   connect.Code
+	}{
+		{name: "owner", userID: "acme-id"},
+		{name: "other user", userID: "bob-id", code: connect.CodePermissionDenied},
+		{name: "anonymous", userID: "", code: connect.CodePermissionDenied},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), constant.UserIDKey, test.userID)
+			commits, err := registry.newResolver().GetAllDependenciesFromModuleRefs(ctx, moduleReferences)
+			if test.code != 0 {
+				if err == nil || err.Code() != test.code {
+					t.Fatalf("expected %v, got %v, %v", test.code, commitNames(commits), err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if names := commitNames(commits); strings.Join(names, ",") != "client1,secret1" {
+				t.Errorf("expected [client1 secret1], got %v", names)
+			}
+		})
+	}
+
+	// 只获取直接依赖时不会访问secret
+	ctx := context.WithValue(context.Background(), constant.UserIDKey, "bob-id")
+	commits, err := registry.newResolver().GetDirectDependenciesFromModuleRefs(ctx, moduleReferences)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := commitNames(commits); len(names) != 1 || names[0] != "client1" {
+		t.Errorf("expected [client1], got %v", names)
+	}
+}
+
+func TestGetBufConfigStorageError(t *testing.T) {
+	defer setTestConfig(constant.DependencyConflictStrategyFail)()
+
+	registry := newTestRegistry()
+	weather := registry.addRepository("acme", "weather", registryv1alpha1.Visibility_VISIBILITY_PUBLIC)
+	commit := registry.addCommit(t, weather, "weather1", 1, nil)
+	resolver := registry.newResolver()
+	resolver.storageHelper = &testFailedStorageHelper{}
+
+	// 读取失败时返回错误，而不是空的配置
+	bufConfig, err := resolver.GetBufConfigFromCommitID(context.Background(), commit.CommitID)
+	if err == nil || bufConfig != nil {
+		t.Errorf("expected error, got %v, %v", bufConfig, err)
+	}
+}
+
+func TestSatisfiesReference(t *testing.T) {
+	defer setTestConfig(constant.DependencyConflictStrategyFail)()
+
+	registry, _ := newVersionedTestRegistry(t)
+	createdTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, commit := range registry.commits {
+		commit.CreatedTime = createdTime.Add(time.Duration(i) * time.Hour)
+	}
+	weather1, weather2, weather3 := registry.commits[0], registry.commits[1], registry.commits[2]
+
+	tests := []struct {
+		dep      string
+		commit   *model.Commit
+		expected bool
+	}{
+		// 满足范围的旧版本也可以，不要求是最高版本
+		{dep: "acme/weather:^1.0.0", commit: weather1, expected: true},
+		{dep: "acme/weather:~1.1.0", commit: weather1, expected: false},
+		{dep: "acme/weather:v1.1.0", commit: weather2, expected: true},
+		{dep: "acme/weather:v1.1.0", commit: weather3, expected: false},
+		{dep: "acme/weather:main", commit: weather1, expected: true},
+		{dep: "acme/weather:branch:main", commit: weather2, expected: true},
+		{dep: "acme/weather:main@2023-01-01T01:30:00Z", commit: weather2, expected: true},
+		{dep: "acme/weather:main@2023-01-01T01:30:00Z", commit: weather3, expected: false},
+		{dep: "acme/weather:weather2", commit: weather2, expected: true},
+	}
+	resolver := registry.newResolver()
+	for _, test := range tests {
+		actual, err := resolver.SatisfiesReference(context.Background(), test.commit, newTestModuleReferences(t, test.dep)[0])
+		if err != nil {
+			t.Fatalf("%s: %v", test.dep, err)
+		}
+		if actual != test.expected {
+			t.Errorf("%s with %s: expected %v, got %v", test.dep, test.commit.CommitName, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package taskqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ProtobufMan/bufman/internal/core/logger"
+	"runtime/debug"
+	"time"
+)
+
+// Task 后台任务，返回错误时会按照退避时间重试，返回 Permanent 包装的错误时不再重试
+type Task func(ctx context.Context) error
+
+// permanentError 重试也不会成功的错误
+type permanentError struct {
+	err error
+}
+
+func (err *permanentError) Error() string {
+	return err.err.Error()
+}
+
+func (err *permanentError) Unwrap() error {
+	return err.err
+}
+
+// Permanent 包装重试也不会成功的错误，例如参数错误、没有权限以及编译错误，任务失败之后不再重试
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+type task struct {
+	name string
+	run  Task
+}
+
+// Queue 容量有限的后台任务队列，由固定数量的worker执行
+type Queue struct {
+	tasks         chan *task
+	maxRetries    int
+	retryInterval time.Duration
+}
+
+// NewQueue 创建队列并启动workers，任务失败时最多重试maxRetries次，每次重试的间隔翻倍
+func NewQueue(workers, size, maxRetries int, retryInterval time.Duration) *Queue {
+	queue := &Queue{
+		tasks:         make(chan *task, size),
+		maxRetries:    maxRetries,
+		retryInterval: retryInterval,
+	}
+	for i := 0; i < workers; i++ {
+		go queue.work()
+	}
+
+	return queue
+}
+
+// Submit 提交任务，队列已满时不会阻塞，直接丢弃任务并返回false
+func (queue *Queue) Submit(name string, run Task) bool {
+	select {
+	case queue.tasks <- &task{name: name, run: run}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (queue *Queue) work() {
+	for t := range queue.tasks {
+		queue.run(t)
+	}
+}
+
+func (queue *Queue) run(t *task) {
+	interval := queue.retryInterval
+	for attempt := 0; ; attempt++ {
+		err := queue.runOnce(t)
+		if err == nil {
+			return
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) || attempt >= queue.maxRetries {
+			logger.Errorf("Error run task %s after %d attempts: %v\n", t.name, attempt+1, err)
+			return
+		}
+
+		time.Sleep(interval)
+		interval *= 2
+	}
+}
+
+// runOnce 执行一次任务，任务panic时不会影响worker，同时不再重试
+func (queue *Queue) runOnce(t *task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = Permanent(fmt.Errorf("panic: %v\n%s", r, debug.Stack()))
+		}
+	}()
+
+	return t.run(context.Background())
+}
@@ -0,0 +1,130 @@
+package taskqueue
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQueueRetry(t *testing.T) {
+	queue := NewQueue(1, 1, 2, time.Millisecond)
+
+	var calls int32
+	done := make(chan struct{})
+	ok := queue.Submit("retry", func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			return errors.New("failed")
+		}
+		close(done)
+		return nil
+	})
+	if !ok {
+		t.Fatal("expected task submitted")
+	}
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected task to succeed after retries")
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("expected three attempts, got %d", n)
+	}
+}
+
+func TestQueueGiveUp(t *testing.T) {
+	queue := NewQueue(1, 2, 1, time.Millisecond)
+
+	var calls int32
+	queue.Submit("give up", func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return errors.New("failed")
+	})
+
+	// 失败的任务重试maxRetries次之后放弃，不影响之后的任务
+	done := make(chan struct{})
+	queue.Submit("next", func(ctx context.Context) error {
+		close(done)
+		return nil
+	})
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected next task to run")
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("expected two attempts, got %d", n)
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	queue := NewQueue(1, 1, 0, time.Millisecond)
+
+	// worker被第一个任务占用，队列中只能再放一个任务
+	started := make(chan struct{})
+	release := make(chan struct{})
+	queue.Submit("block", func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+	defer close(release)
+
+	if !queue.Submit("queued", func(ctx context.Context) error { return nil }) {
+		t.Fatal("expected task queued")
+	}
+	if queue.Submit("dropped", func(ctx context.Context) error { return nil }) {
+		t.Error("expected task dropped when queue is full")
+	}
+}
+
+func TestQueuePermanentError(t *testing.T) {
+	queue := NewQueue(1, 2, 3, time.Millisecond)
+
+	// 重试也不会成功的错误只执行一次
+	var calls int32
+	queue.Submit("permanent", func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return Permanent(errors.New("invalid"))
+	})
+	done := make(chan struct{})
+	queue.Submit("next", func(ctx context.Context) error {
+		close(done)
+		return nil
+	})
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected next task to run")
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected one attempt, got %d", n)
+	}
+}
+
+func TestQueuePanic(t *testing.T) {
+	queue := NewQueue(1, 2, 3, time.Millisecond)
+
+	// panic的任务不会重试，worker继续执行之后的任务
+	var calls int32
+	queue.Submit("panic", func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		panic("nil config")
+	})
+	done := make(chan struct{})
+	queue.Submit("next", func(ctx context.Context) error {
+		close(done)
+		return nil
+	})
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected next task to run after panic")
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("expected one attempt, got %d", n)
+	}
+}
@@ -87,6 +87,15 @@ func (validator *ValidatorImpl) CheckRepositoryName(repositoryName string) e.Res
 }
 
 func (validator *ValidatorImpl) CheckTagName(tagName string) e.ResponseError {
+	if tagName == constant.DefaultBranch { // tag name不能为 main
+		return e.NewInvalidArgumentError(fmt.Sprintf("tag (can not be '%v')", constant.DefaultBranch))
+	}
+
+	// 允许使用semver作为tag名称，例如 v1.2.0
+	if semver.IsValid(tagName) && len(tagName) <= constant.MaxTagLength {
+		return nil
+	}
+
 	err := validator.doCheckByLengthAndPattern(tagName, constant.MinTagLength, constant.MaxTagLength, constant.TagPattern)
 	if err != nil {
 		return e.NewInvalidArgumentError("tag name:" + err.Error())
@@ -0,0 +1,69 @@
+package version
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	commitTimestampLayout = "20060102150405"
+	commitShortNameLength = 12
+	defaultPluginVersion  = "v0.0.0"
+)
+
+// PackageVersion 根据module commit和插件计算远程包的版本号，相同的输入总是得到相同的版本号
+type PackageVersion struct {
+	PluginVersion    string    // 插件版本，例如 v1.28.1
+	PluginRevision   uint32    // 插件revision
+	CommitSequenceID int64     // module commit 在仓库中的序号，保证同一个插件下版本号按照commit顺序递增
+	CommitName       string    // module commit name
+	CommitTime       time.Time // module commit 创建时间
+}
+
+// GoVersion 例如 v1.28.1-7.20221020142030-a7a0ce2ec5b3.4
+func (v *PackageVersion) GoVersion() string {
+	return fmt.Sprintf("v%s-%d.%s-%s.%d", v.pluginVersion(), v.CommitSequenceID, v.timestamp(), v.shortCommitName(), v.PluginRevision)
+}
+
+// NPMVersion 例如 1.28.1-7.20221020142030-a7a0ce2ec5b3.4
+func (v *PackageVersion) NPMVersion() string {
+	return fmt.Sprintf("%s-%d.%s-%s.%d", v.pluginVersion(), v.CommitSequenceID, v.timestamp(), v.shortCommitName(), v.PluginRevision)
+}
+
+// SwiftVersion 例如 1.28.1-7.4+20221020142030.a7a0ce2ec5b3，
+// SwiftPM只按照预发布部分比较版本，时间和commit放在build metadata中
+func (v *PackageVersion) SwiftVersion() string {
+	return fmt.Sprintf("%s-%d.%d+%s.%s", v.pluginVersion(), v.CommitSequenceID, v.PluginRevision, v.timestamp(), v.shortCommitName())
+}
+
+// MavenVersion 例如 1.28.1.7.4.20221020142030.a7a0ce2ec5b3，与其他格式一样sequence id在插件revision之前
+func (v *PackageVersion) MavenVersion() string {
+	return fmt.Sprintf("%s.%d.%d.%s.%s", v.pluginVersion(), v.CommitSequenceID, v.PluginRevision, v.timestamp(), v.shortCommitName())
+}
+
+// pluginVersion 去掉前缀v，以及预发布等后缀
+func (v *PackageVersion) pluginVersion() string {
+	pluginVersion := v.PluginVersion
+	if pluginVersion == "" {
+		pluginVersion = defaultPluginVersion
+	}
+	pluginVersion = strings.TrimPrefix(pluginVersion, "v")
+	if index := strings.IndexAny(pluginVersion, "-+"); index >= 0 {
+		pluginVersion = pluginVersion[:index]
+	}
+
+	return pluginVersion
+}
+
+func (v *PackageVersion) timestamp() string {
+	return v.CommitTime.UTC().Format(commitTimestampLayout)
+}
+
+func (v *PackageVersion) shortCommitName() string {
+	if len(v.CommitName) > commitShortNameLength {
+		return v.CommitName[:commitShortNameLength]
+	}
+
+	return v.CommitName
+}
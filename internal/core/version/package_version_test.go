@@ -0,0 +1,115 @@
+package version
+
+import (
+	"golang.org/x/mod/semver"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPackageVersion(t *testing.T) {
+	commitTime := time.Date(2022, 10, 20, 22, 20, 30, 0, time.FixedZone("CST", 8*60*60))
+	newPackageVersion := func() *PackageVersion {
+		return &PackageVersion{
+			PluginVersion:    "v1.28.1",
+			PluginRevision:   4,
+			CommitSequenceID: 7,
+			CommitName:       "a7a0ce2ec5b34e7b9b3c8d6f1e2a0b9c",
+			CommitTime:       commitTime,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		version func(v *PackageVersion) string
+		want    string
+	}{
+		{name: "go", version: (*PackageVersion).GoVersion, want: "v1.28.1-7.20221020142030-a7a0ce2ec5b3.4"},
+		{name: "npm", version: (*PackageVersion).NPMVersion, want: "1.28.1-7.20221020142030-a7a0ce2ec5b3.4"},
+		{name: "swift", version: (*PackageVersion).SwiftVersion, want: "1.28.1-7.4+20221020142030.a7a0ce2ec5b3"},
+		{name: "maven", version: (*PackageVersion).MavenVersion, want: "1.28.1.7.4.20221020142030.a7a0ce2ec5b3"},
+	}
+
+	for _, test := range tests {
+		// 相同的输入多次计算，结果必须一致
+		for i := 0; i < 3; i++ {
+			if got := test.version(newPackageVersion()); got != test.want {
+				t.Errorf("%s version = %q, want %q", test.name, got, test.want)
+			}
+		}
+	}
+}
+
+func TestPackageVersionDefaults(t *testing.T) {
+	v := &PackageVersion{
+		PluginVersion: "v2.0.0-rc.1",
+		CommitName:    "0123456789ab",
+		CommitTime:    time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	if got, want := v.GoVersion(), "v2.0.0-0.20230102030405-0123456789ab.0"; got != want {
+		t.Errorf("GoVersion() = %q, want %q", got, want)
+	}
+
+	v.PluginVersion = ""
+	if got, want := v.NPMVersion(), "0.0.0-0.20230102030405-0123456789ab.0"; got != want {
+		t.Errorf("NPMVersion() = %q, want %q", got, want)
+	}
+}
+
+func TestPackageVersionOrderedBySequenceID(t *testing.T) {
+	// 同一时间的两个commit按照sequence id排序
+	commitTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	older := &PackageVersion{PluginVersion: "v1.0.0", CommitSequenceID: 9, CommitName: "ffffffffffff", CommitTime: commitTime}
+	newer := &PackageVersion{PluginVersion: "v1.0.0", CommitSequenceID: 10, CommitName: "000000000000", CommitTime: commitTime}
+
+	for _, pair := range [][2]string{
+		{older.GoVersion(), newer.GoVersion()},
+		{"v" + older.NPMVersion(), "v" + newer.NPMVersion()},
+		{"v" + older.SwiftVersion(), "v" + newer.SwiftVersion()},
+	} {
+		if semver.Compare(pair[0], pair[1]) >= 0 {
+			t.Errorf("expected %s < %s", pair[0], pair[1])
+		}
+	}
+}
+
+// compareMavenVersions 按照数字逐段比较，只比较两个版本都是数字的前缀
+func compareMavenVersions(a, b string) int {
+	aParts, bParts := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNumber, aErr := strconv.ParseInt(aParts[i], 10, 64)
+		bNumber, bErr := strconv.ParseInt(bParts[i], 10, 64)
+		if aErr != nil || bErr != nil {
+			return strings.Compare(aParts[i], bParts[i])
+		}
+		if aNumber != bNumber {
+			if aNumber < bNumber {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	return 0
+}
+
+func TestPackageVersionOrderedBeforePluginRevision(t *testing.T) {
+	// 插件revision更高的旧commit不能排在新commit之后
+	commitTime := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	older := &PackageVersion{PluginVersion: "v1.0.0", PluginRevision: 5, CommitSequenceID: 9, CommitName: "ffffffffffff", CommitTime: commitTime}
+	newer := &PackageVersion{PluginVersion: "v1.0.0", PluginRevision: 1, CommitSequenceID: 10, CommitName: "000000000000", CommitTime: commitTime}
+
+	for _, pair := range [][2]string{
+		{older.GoVersion(), newer.GoVersion()},
+		{"v" + older.NPMVersion(), "v" + newer.NPMVersion()},
+		{"v" + older.SwiftVersion(), "v" + newer.SwiftVersion()},
+	} {
+		if semver.Compare(pair[0], pair[1]) >= 0 {
+			t.Errorf("expected %s < %s", pair[0], pair[1])
+		}
+	}
+	if compareMavenVersions(older.MavenVersion(), newer.MavenVersion()) >= 0 {
+		t.Errorf("expected %s < %s", older.MavenVersion(), newer.MavenVersion())
+	}
+}
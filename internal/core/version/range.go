@@ -0,0 +1,265 @@
+package version
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/mod/semver"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidRange = errors.New("invalid version range")
+
+// Range semver版本范围，支持以下写法：
+//
+//	v1.x / 1.2.x / *    通配符
+//	^1.2.0              主版本号不变
+//	~1.2.0              次版本号不变
+//	>=1.2.0 <2.0.0      比较符，多个条件之间以空格或逗号分隔，需要同时满足
+type Range struct {
+	raw         string
+	constraints []constraint
+}
+
+type constraint struct {
+	op      string
+	version string // canonical semver, 例如 v1.2.0
+}
+
+// IsRange 判断reference是否使用了版本范围语法，精确的tag(例如v1.2.0)不属于范围。
+// 只根据语法判断，x、* 这类名称也可能是仓库中的tag，调用方需要优先按照tag查找
+func IsRange(reference string) bool {
+	reference = strings.TrimSpace(reference)
+	if reference == "" {
+		return false
+	}
+	if strings.ContainsAny(reference[:1], "^~<>=*") {
+		return true
+	}
+
+	for _, part := range strings.Split(strings.TrimPrefix(reference, "v"), ".") {
+		if isWildcard(part) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseRange 解析版本范围
+func ParseRange(reference string) (*Range, error) {
+	if !IsRange(reference) {
+		return nil, ErrInvalidRange
+	}
+
+	r := &Range{raw: reference}
+	fields := strings.FieldsFunc(reference, func(c rune) bool {
+		return c == ' ' || c == ','
+	})
+	for _, field := range fields {
+		constraints, err := parseConstraint(field)
+		if err != nil {
+			return nil, err
+		}
+		r.constraints = append(r.constraints, constraints...)
+	}
+	if len(r.constraints) == 0 {
+		return nil, ErrInvalidRange
+	}
+
+	return r, nil
+}
+
+func (r *Range) String() string {
+	return r.raw
+}
+
+// Match 判断版本号是否在范围内，除非范围本身指定了预发布版本，否则不匹配预发布版本
+func (r *Range) Match(version string) bool {
+	if !semver.IsValid(version) {
+		return false
+	}
+	if semver.Prerelease(version) != "" && !r.allowPrerelease() {
+		return false
+	}
+
+	for _, c := range r.constraints {
+		cmp := semver.Compare(version, c.version)
+		var ok bool
+		switch c.op {
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		case "=":
+			ok = cmp == 0
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Highest 返回范围内最高的版本号，版本号相同时按照字典序选取，保证结果确定
+func (r *Range) Highest(versions []string) (string, bool) {
+	return Highest(versions, r)
+}
+
+// Highest 返回同时满足所有范围的最高版本号，即多个范围交集中的最高版本号
+func Highest(versions []string, ranges ...*Range) (string, bool) {
+	matched := make([]string, 0, len(versions))
+	for _, v := range versions {
+		if matchAll(ranges, v) {
+			matched = append(matched, v)
+		}
+	}
+	if len(matched) == 0 {
+		return "", false
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		if cmp := semver.Compare(matched[i], matched[j]); cmp != 0 {
+			return cmp > 0
+		}
+		return matched[i] > matched[j]
+	})
+
+	return matched[0], true
+}
+
+func matchAll(ranges []*Range, version string) bool {
+	for _, r := range ranges {
+		if !r.Match(version) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *Range) allowPrerelease() bool {
+	for _, c := range r.constraints {
+		if semver.Prerelease(c.version) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func parseConstraint(field string) ([]constraint, error) {
+	switch {
+	case field == "*" || field == "x" || field == "X":
+		return []constraint{{op: ">=", version: "v0.0.0"}}, nil
+	case strings.HasPrefix(field, "^"):
+		major, minor, patch, rest, err := parseVersion(field[1:])
+		if err != nil {
+			return nil, err
+		}
+		lower := format(major, minor, patch) + rest
+		var upper string
+		switch {
+		case major > 0 || strings.Count(strings.TrimPrefix(field[1:], "v"), ".") == 0:
+			// ^0 等价于 0.x
+			upper = format(major+1, 0, 0)
+		case minor > 0:
+			upper = format(0, minor+1, 0)
+		default:
+			upper = format(0, 0, patch+1)
+		}
+		return []constraint{{op: ">=", version: lower}, {op: "<", version: upper}}, nil
+	case strings.HasPrefix(field, "~"):
+		major, minor, patch, rest, err := parseVersion(field[1:])
+		if err != nil {
+			return nil, err
+		}
+		lower := format(major, minor, patch) + rest
+		upper := format(major, minor+1, 0)
+		if strings.Count(strings.TrimPrefix(field[1:], "v"), ".") == 0 {
+			// ~1 等价于 1.x
+			upper = format(major+1, 0, 0)
+		}
+		return []constraint{{op: ">=", version: lower}, {op: "<", version: upper}}, nil
+	}
+
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(field, op) {
+			major, minor, patch, rest, err := parseVersion(field[len(op):])
+			if err != nil {
+				return nil, err
+			}
+			return []constraint{{op: op, version: format(major, minor, patch) + rest}}, nil
+		}
+	}
+
+	// 通配符 1.x / 1.2.x
+	parts := strings.Split(strings.TrimPrefix(field, "v"), ".")
+	if len(parts) > 3 {
+		return nil, ErrInvalidRange
+	}
+	numbers := make([]int, 0, 3)
+	for _, part := range parts {
+		if isWildcard(part) {
+			break
+		}
+		n, ok := parseNumber(part)
+		if !ok {
+			return nil, ErrInvalidRange
+		}
+		numbers = append(numbers, n)
+	}
+	switch len(numbers) {
+	case 0:
+		return []constraint{{op: ">=", version: "v0.0.0"}}, nil
+	case 1:
+		return []constraint{{op: ">=", version: format(numbers[0], 0, 0)}, {op: "<", version: format(numbers[0]+1, 0, 0)}}, nil
+	case 2:
+		return []constraint{{op: ">=", version: format(numbers[0], numbers[1], 0)}, {op: "<", version: format(numbers[0], numbers[1]+1, 0)}}, nil
+	default:
+		return []constraint{{op: "=", version: format(numbers[0], numbers[1], numbers[2])}}, nil
+	}
+}
+
+// parseVersion 解析版本号，缺省的部分补0，rest为预发布部分(例如 -rc.1)
+func parseVersion(s string) (major, minor, patch int, rest string, err error) {
+	v := s
+	if !strings.HasPrefix(v, "v") {
+		v = "v" + v
+	}
+	if !semver.IsValid(v) {
+		return 0, 0, 0, "", ErrInvalidRange
+	}
+
+	canonical := semver.Canonical(v)
+	rest = semver.Prerelease(canonical)
+	numbers := strings.Split(strings.TrimSuffix(strings.TrimPrefix(canonical, "v"), rest), ".")
+	major, _ = parseNumber(numbers[0])
+	minor, _ = parseNumber(numbers[1])
+	patch, _ = parseNumber(numbers[2])
+
+	return major, minor, patch, rest, nil
+}
+
+func parseNumber(s string) (int, bool) {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || strings.HasPrefix(s, "+") {
+		return 0, false
+	}
+
+	return n, true
+}
+
+func isWildcard(s string) bool {
+	return s == "x" || s == "X" || s == "*"
+}
+
+func format(major, minor, patch int) string {
+	return fmt.Sprintf("v%d.%d.%d", major, minor, patch)
+}
@@ -0,0 +1,91 @@
+package version
+
+import "testing"
+
+func TestIsRange(t *testing.T) {
+	ranges := []string{"v1.x", "1.x", "1.2.x", "*", "^1.2.0", "~1.2.0", ">=1.2.0 <2.0.0", "=v1.2.0"}
+	for _, r := range ranges {
+		if !IsRange(r) {
+			t.Errorf("IsRange(%q) = false, want true", r)
+		}
+	}
+
+	exacts := []string{"", "main", "v1.2.0", "v1", "release-1", "0123456789abcdef0123456789abcdef"}
+	for _, r := range exacts {
+		if IsRange(r) {
+			t.Errorf("IsRange(%q) = true, want false", r)
+		}
+	}
+}
+
+func TestRangeHighest(t *testing.T) {
+	versions := []string{"v0.9.0", "v1.0.0", "v1.2.0", "v1.2.5", "v1.3.0-rc.1", "v1.10.0", "v2.0.0", "main", "v0.0.3", "v0.2.9"}
+	tests := []struct {
+		reference string
+		want      string
+		ok        bool
+	}{
+		{reference: "v1.x", want: "v1.10.0", ok: true},
+		{reference: "1.2.x", want: "v1.2.5", ok: true},
+		{reference: "^1.2.0", want: "v1.10.0", ok: true},
+		{reference: "~1.2.0", want: "v1.2.5", ok: true},
+		{reference: "^0.2.0", want: "v0.2.9", ok: true},
+		{reference: "^0.0.3", want: "v0.0.3", ok: true},
+		{reference: "^0.0", ok: false}, // 上界为v0.0.1
+		{reference: "^0", want: "v0.9.0", ok: true},
+		{reference: ">=1.2.0 <1.10.0", want: "v1.2.5", ok: true},
+		{reference: ">=1.3.0-rc.0, <1.4.0", want: "v1.3.0-rc.1", ok: true},
+		{reference: "*", want: "v2.0.0", ok: true},
+		{reference: "v3.x", ok: false},
+	}
+
+	for _, test := range tests {
+		r, err := ParseRange(test.reference)
+		if err != nil {
+			t.Errorf("ParseRange(%q) unexpected error: %v", test.reference, err)
+			continue
+		}
+		got, ok := r.Highest(versions)
+		if got != test.want || ok != test.ok {
+			t.Errorf("ParseRange(%q).Highest() = (%q, %v), want (%q, %v)", test.reference, got, ok, test.want, test.ok)
+		}
+	}
+}
+
+func TestParseRangeInvalid(t *testing.T) {
+	invalids := []string{"v1.2.0", "^abc", "1.y.x", ">=1.2.0 <two"}
+	for _, r := range invalids {
+		if _, err := ParseRange(r); err == nil {
+			t.Errorf("ParseRange(%q) expected error", r)
+		}
+	}
+}
+
+func TestHighestIntersection(t *testing.T) {
+	versions := []string{"v1.0.0", "v1.2.0", "v1.2.5", "v1.10.0", "v2.0.0"}
+	tests := []struct {
+		references []string
+		want       string
+		ok         bool
+	}{
+		{references: []string{"^1.0.0", "~1.2.0"}, want: "v1.2.5", ok: true},
+		{references: []string{"v1.x", ">=1.1.0 <1.2.1"}, want: "v1.2.0", ok: true},
+		{references: []string{"*", "v2.x"}, want: "v2.0.0", ok: true},
+		{references: []string{"~1.2.0", "v2.x"}, ok: false},
+	}
+
+	for _, test := range tests {
+		ranges := make([]*Range, 0, len(test.references))
+		for _, reference := range test.references {
+			r, err := ParseRange(reference)
+			if err != nil {
+				t.Fatalf("ParseRange(%q) unexpected error: %v", reference, err)
+			}
+			ranges = append(ranges, r)
+		}
+		got, ok := Highest(versions, ranges...)
+		if got != test.want || ok != test.ok {
+			t.Errorf("Highest(%v) = (%q, %v), want (%q, %v)", test.references, got, ok, test.want, test.ok)
+		}
+	}
+}
@@ -41,6 +41,9 @@ func newCommit(db *gorm.DB, opts ...gen.DOOption) commit {
 	_commit.DocumentDigest = field.NewString(tableName, "document_digest")
 	_commit.LicenseDigest = field.NewString(tableName, "license_digest")
 	_commit.SequenceID = field.NewInt64(tableName, "sequence_id")
+	_commit.PackageDocumentationGenerated = field.NewBool(tableName, "package_documentation_generated")
+	_commit.DependenciesRecorded = field.NewBool(tableName, "dependencies_recorded")
+	_commit.DependencyWarnings = field.NewString(tableName, "dependency_warnings")
 	_commit.FileManifest = commitHasOneFileManifest{
 		db: db.Session(&gorm.Session{}),
 
@@ -59,6 +62,12 @@ func newCommit(db *gorm.DB, opts ...gen.DOOption) commit {
 		RelationField: field.NewRelation("Tags", "model.Tags"),
 	}
 
+	_commit.Dependencies = commitHasManyDependencies{
+		db: db.Session(&gorm.Session{}),
+
+		RelationField: field.NewRelation("Dependencies", "model.Dependencies"),
+	}
+
 	_commit.fillFieldMap()
 
 	return _commit
@@ -67,27 +76,32 @@ func newCommit(db *gorm.DB, opts ...gen.DOOption) commit {
 type commit struct {
 	commitDo
 
-	ALL                field.Asterisk
-	ID                 field.Int64
-	UserID             field.String
-	UserName           field.String
-	RepositoryID       field.String
-	RepositoryName     field.String
-	CommitID           field.String
-	CommitName         field.String
-	DraftName          field.String
-	CreatedTime        field.Time
-	ManifestDigest     field.String
-	BufManConfigDigest field.String
-	DocumentDigest     field.String
-	LicenseDigest      field.String
-	SequenceID         field.Int64
-	FileManifest       commitHasOneFileManifest
+	ALL                           field.Asterisk
+	ID                            field.Int64
+	UserID                        field.String
+	UserName                      field.String
+	RepositoryID                  field.String
+	RepositoryName                field.String
+	CommitID                      field.String
+	CommitName                    field.String
+	DraftName                     field.String
+	CreatedTime                   field.Time
+	ManifestDigest                field.String
+	BufManConfigDigest            field.String
+	DocumentDigest                field.String
+	LicenseDigest                 field.String
+	SequenceID                    field.Int64
+	PackageDocumentationGenerated field.Bool
+	DependenciesRecorded          field.Bool
+	DependencyWarnings            field.String
+	FileManifest                  commitHasOneFileManifest
 
 	FileBlobs commitHasManyFileBlobs
 
 	Tags commitHasManyTags
 
+	Dependencies commitHasManyDependencies
+
 	fieldMap map[string]field.Expr
 }
 
@@ -117,6 +131,9 @@ func (c *commit) updateTableName(table string) *commit {
 	c.DocumentDigest = field.NewString(table, "document_digest")
 	c.LicenseDigest = field.NewString(table, "license_digest")
 	c.SequenceID = field.NewInt64(table, "sequence_id")
+	c.PackageDocumentationGenerated = field.NewBool(table, "package_documentation_generated")
+	c.DependenciesRecorded = field.NewBool(table, "dependencies_recorded")
+	c.DependencyWarnings = field.NewString(table, "dependency_warnings")
 
 	c.fillFieldMap()
 
@@ -133,7 +150,7 @@ func (c *commit) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (c *commit) fillFieldMap() {
-	c.fieldMap = make(map[string]field.Expr, 17)
+	c.fieldMap = make(map[string]field.Expr, 21)
 	c.fieldMap["id"] = c.ID
 	c.fieldMap["user_id"] = c.UserID
 	c.fieldMap["user_name"] = c.UserName
@@ -148,6 +165,9 @@ func (c *commit) fillFieldMap() {
 	c.fieldMap["document_digest"] = c.DocumentDigest
 	c.fieldMap["license_digest"] = c.LicenseDigest
 	c.fieldMap["sequence_id"] = c.SequenceID
+	c.fieldMap["package_documentation_generated"] = c.PackageDocumentationGenerated
+	c.fieldMap["dependencies_recorded"] = c.DependenciesRecorded
+	c.fieldMap["dependency_warnings"] = c.DependencyWarnings
 
 }
 
@@ -374,6 +394,77 @@ func (a commitHasManyTagsTx) Count() int64 {
 	return a.tx.Count()
 }
 
+type commitHasManyDependencies struct {
+	db *gorm.DB
+
+	field.RelationField
+}
+
+func (a commitHasManyDependencies) Where(conds ...field.Expr) *commitHasManyDependencies {
+	if len(conds) == 0 {
+		return &a
+	}
+
+	exprs := make([]clause.Expression, 0, len(conds))
+	for _, cond := range conds {
+		exprs = append(exprs, cond.BeCond().(clause.Expression))
+	}
+	a.db = a.db.Clauses(clause.Where{Exprs: exprs})
+	return &a
+}
+
+func (a commitHasManyDependencies) WithContext(ctx context.Context) *commitHasManyDependencies {
+	a.db = a.db.WithContext(ctx)
+	return &a
+}
+
+func (a commitHasManyDependencies) Session(session *gorm.Session) *commitHasManyDependencies {
+	a.db = a.db.Session(session)
+	return &a
+}
+
+func (a commitHasManyDependencies) Model(m *model.Commit) *commitHasManyDependenciesTx {
+	return &commitHasManyDependenciesTx{a.db.Model(m).Association(a.Name())}
+}
+
+type commitHasManyDependenciesTx struct{ tx *gorm.Association }
+
+func (a commitHasManyDependenciesTx) Find() (result []*model.Dependencies, err error) {
+	return result, a.tx.Find(&result)
+}
+
+func (a commitHasManyDependenciesTx) Append(values ...*model.Dependencies) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Append(targetValues...)
+}
+
+func (a commitHasManyDependenciesTx) Replace(values ...*model.Dependencies) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Replace(targetValues...)
+}
+
+func (a commitHasManyDependenciesTx) Delete(values ...*model.Dependencies) (err error) {
+	targetValues := make([]interface{}, len(values))
+	for i, v := range values {
+		targetValues[i] = v
+	}
+	return a.tx.Delete(targetValues...)
+}
+
+func (a commitHasManyDependenciesTx) Clear() error {
+	return a.tx.Clear()
+}
+
+func (a commitHasManyDependenciesTx) Count() int64 {
+	return a.tx.Count()
+}
+
 type commitDo struct{ gen.DO }
 
 type ICommitDo interface {
@@ -0,0 +1,416 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package dal
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/ProtobufMan/bufman/internal/model"
+)
+
+func newDependency(db *gorm.DB, opts ...gen.DOOption) dependency {
+	_dependency := dependency{}
+
+	_dependency.dependencyDo.UseDB(db, opts...)
+	_dependency.dependencyDo.UseModel(&model.Dependency{})
+
+	tableName := _dependency.dependencyDo.TableName()
+	_dependency.ALL = field.NewAsterisk(tableName)
+	_dependency.ID = field.NewInt64(tableName, "id")
+	_dependency.RepositoryID = field.NewString(tableName, "repository_id")
+	_dependency.CommitID = field.NewString(tableName, "commit_id")
+	_dependency.CommitName = field.NewString(tableName, "commit_name")
+	_dependency.DependencyRepositoryID = field.NewString(tableName, "dependency_repository_id")
+	_dependency.DependencyCommitID = field.NewString(tableName, "dependency_commit_id")
+	_dependency.DependencyCommitName = field.NewString(tableName, "dependency_commit_name")
+	_dependency.Transitive = field.NewBool(tableName, "transitive")
+	_dependency.CreatedTime = field.NewTime(tableName, "created_time")
+
+	_dependency.fillFieldMap()
+
+	return _dependency
+}
+
+type dependency struct {
+	dependencyDo
+
+	ALL                    field.Asterisk
+	ID                     field.Int64
+	RepositoryID           field.String
+	CommitID               field.String
+	CommitName             field.String
+	DependencyRepositoryID field.String
+	DependencyCommitID     field.String
+	DependencyCommitName   field.String
+	Transitive             field.Bool
+	CreatedTime            field.Time
+
+	fieldMap map[string]field.Expr
+}
+
+func (d dependency) Table(newTableName string) *dependency {
+	d.dependencyDo.UseTable(newTableName)
+	return d.updateTableName(newTableName)
+}
+
+func (d dependency) As(alias string) *dependency {
+	d.dependencyDo.DO = *(d.dependencyDo.As(alias).(*gen.DO))
+	return d.updateTableName(alias)
+}
+
+func (d *dependency) updateTableName(table string) *dependency {
+	d.ALL = field.NewAsterisk(table)
+	d.ID = field.NewInt64(table, "id")
+	d.RepositoryID = field.NewString(table, "repository_id")
+	d.CommitID = field.NewString(table, "commit_id")
+	d.CommitName = field.NewString(table, "commit_name")
+	d.DependencyRepositoryID = field.NewString(table, "dependency_repository_id")
+	d.DependencyCommitID = field.NewString(table, "dependency_commit_id")
+	d.DependencyCommitName = field.NewString(table, "dependency_commit_name")
+	d.Transitive = field.NewBool(table, "transitive")
+	d.CreatedTime = field.NewTime(table, "created_time")
+
+	d.fillFieldMap()
+
+	return d
+}
+
+func (d *dependency) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := d.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (d *dependency) fillFieldMap() {
+	d.fieldMap = make(map[string]field.Expr, 9)
+	d.fieldMap["id"] = d.ID
+	d.fieldMap["repository_id"] = d.RepositoryID
+	d.fieldMap["commit_id"] = d.CommitID
+	d.fieldMap["commit_name"] = d.CommitName
+	d.fieldMap["dependency_repository_id"] = d.DependencyRepositoryID
+	d.fieldMap["dependency_commit_id"] = d.DependencyCommitID
+	d.fieldMap["dependency_commit_name"] = d.DependencyCommitName
+	d.fieldMap["transitive"] = d.Transitive
+	d.fieldMap["created_time"] = d.CreatedTime
+}
+
+func (d dependency) clone(db *gorm.DB) dependency {
+	d.dependencyDo.ReplaceConnPool(db.Statement.ConnPool)
+	return d
+}
+
+func (d dependency) replaceDB(db *gorm.DB) dependency {
+	d.dependencyDo.ReplaceDB(db)
+	return d
+}
+
+type dependencyDo struct{ gen.DO }
+
+type IDependencyDo interface {
+	gen.SubQuery
+	Debug() IDependencyDo
+	WithContext(ctx context.Context) IDependencyDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() IDependencyDo
+	WriteDB() IDependencyDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) IDependencyDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) IDependencyDo
+	Not(conds ...gen.Condition) IDependencyDo
+	Or(conds ...gen.Condition) IDependencyDo
+	Select(conds ...field.Expr) IDependencyDo
+	Where(conds ...gen.Condition) IDependencyDo
+	Order(conds ...field.Expr) IDependencyDo
+	Distinct(cols ...field.Expr) IDependencyDo
+	Omit(cols ...field.Expr) IDependencyDo
+	Join(table schema.Tabler, on ...field.Expr) IDependencyDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) IDependencyDo
+	RightJoin(table schema.Tabler, on ...field.Expr) IDependencyDo
+	Group(cols ...field.Expr) IDependencyDo
+	Having(conds ...gen.Condition) IDependencyDo
+	Limit(limit int) IDependencyDo
+	Offset(offset int) IDependencyDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) IDependencyDo
+	Unscoped() IDependencyDo
+	Create(values ...*model.Dependency) error
+	CreateInBatches(values []*model.Dependency, batchSize int) error
+	Save(values ...*model.Dependency) error
+	First() (*model.Dependency, error)
+	Take() (*model.Dependency, error)
+	Last() (*model.Dependency, error)
+	Find() ([]*model.Dependency, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.Dependency, err error)
+	FindInBatches(result *[]*model.Dependency, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.Dependency) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) IDependencyDo
+	Assign(attrs ...field.AssignExpr) IDependencyDo
+	Joins(fields ...field.RelationField) IDependencyDo
+	Preload(fields ...field.RelationField) IDependencyDo
+	FirstOrInit() (*model.Dependency, error)
+	FirstOrCreate() (*model.Dependency, error)
+	FindByPage(offset int, limit int) (result []*model.Dependency, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) IDependencyDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (d dependencyDo) Debug() IDependencyDo {
+	return d.withDO(d.DO.Debug())
+}
+
+func (d dependencyDo) WithContext(ctx context.Context) IDependencyDo {
+	return d.withDO(d.DO.WithContext(ctx))
+}
+
+func (d dependencyDo) ReadDB() IDependencyDo {
+	return d.Clauses(dbresolver.Read)
+}
+
+func (d dependencyDo) WriteDB() IDependencyDo {
+	return d.Clauses(dbresolver.Write)
+}
+
+func (d dependencyDo) Session(config *gorm.Session) IDependencyDo {
+	return d.withDO(d.DO.Session(config))
+}
+
+func (d dependencyDo) Clauses(conds ...clause.Expression) IDependencyDo {
+	return d.withDO(d.DO.Clauses(conds...))
+}
+
+func (d dependencyDo) Returning(value interface{}, columns ...string) IDependencyDo {
+	return d.withDO(d.DO.Returning(value, columns...))
+}
+
+func (d dependencyDo) Not(conds ...gen.Condition) IDependencyDo {
+	return d.withDO(d.DO.Not(conds...))
+}
+
+func (d dependencyDo) Or(conds ...gen.Condition) IDependencyDo {
+	return d.withDO(d.DO.Or(conds...))
+}
+
+func (d dependencyDo) Select(conds ...field.Expr) IDependencyDo {
+	return d.withDO(d.DO.Select(conds...))
+}
+
+func (d dependencyDo) Where(conds ...gen.Condition) IDependencyDo {
+	return d.withDO(d.DO.Where(conds...))
+}
+
+func (d dependencyDo) Exists(subquery interface{ UnderlyingDB() *gorm.DB }) IDependencyDo {
+	return d.Where(field.CompareSubQuery(field.ExistsOp, nil, subquery.UnderlyingDB()))
+}
+
+func (d dependencyDo) Order(conds ...field.Expr) IDependencyDo {
+	return d.withDO(d.DO.Order(conds...))
+}
+
+func (d dependencyDo) Distinct(cols ...field.Expr) IDependencyDo {
+	return d.withDO(d.DO.Distinct(cols...))
+}
+
+func (d dependencyDo) Omit(cols ...field.Expr) IDependencyDo {
+	return d.withDO(d.DO.Omit(cols...))
+}
+
+func (d dependencyDo) Join(table schema.Tabler, on ...field.Expr) IDependencyDo {
+	return d.withDO(d.DO.Join(table, on...))
+}
+
+func (d dependencyDo) LeftJoin(table schema.Tabler, on ...field.Expr) IDependencyDo {
+	return d.withDO(d.DO.LeftJoin(table, on...))
+}
+
+func (d dependencyDo) RightJoin(table schema.Tabler, on ...field.Expr) IDependencyDo {
+	return d.withDO(d.DO.RightJoin(table, on...))
+}
+
+func (d dependencyDo) Group(cols ...field.Expr) IDependencyDo {
+	return d.withDO(d.DO.Group(cols...))
+}
+
+func (d dependencyDo) Having(conds ...gen.Condition) IDependencyDo {
+	return d.withDO(d.DO.Having(conds...))
+}
+
+func (d dependencyDo) Limit(limit int) IDependencyDo {
+	return d.withDO(d.DO.Limit(limit))
+}
+
+func (d dependencyDo) Offset(offset int) IDependencyDo {
+	return d.withDO(d.DO.Offset(offset))
+}
+
+func (d dependencyDo) Scopes(funcs ...func(gen.Dao) gen.Dao) IDependencyDo {
+	return d.withDO(d.DO.Scopes(funcs...))
+}
+
+func (d dependencyDo) Unscoped() IDependencyDo {
+	return d.withDO(d.DO.Unscoped())
+}
+
+func (d dependencyDo) Create(values ...*model.Dependency) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return d.DO.Create(values)
+}
+
+func (d dependencyDo) CreateInBatches(values []*model.Dependency, batchSize int) error {
+	return d.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (d dependencyDo) Save(values ...*model.Dependency) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return d.DO.Save(values)
+}
+
+func (d dependencyDo) First() (*model.Dependency, error) {
+	if result, err := d.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Dependency), nil
+	}
+}
+
+func (d dependencyDo) Take() (*model.Dependency, error) {
+	if result, err := d.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Dependency), nil
+	}
+}
+
+func (d dependencyDo) Last() (*model.Dependency, error) {
+	if result, err := d.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Dependency), nil
+	}
+}
+
+func (d dependencyDo) Find() ([]*model.Dependency, error) {
+	result, err := d.DO.Find()
+	return result.([]*model.Dependency), err
+}
+
+func (d dependencyDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.Dependency, err error) {
+	buf := make([]*model.Dependency, 0, batchSize)
+	err = d.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (d dependencyDo) FindInBatches(result *[]*model.Dependency, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return d.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (d dependencyDo) Attrs(attrs ...field.AssignExpr) IDependencyDo {
+	return d.withDO(d.DO.Attrs(attrs...))
+}
+
+func (d dependencyDo) Assign(attrs ...field.AssignExpr) IDependencyDo {
+	return d.withDO(d.DO.Assign(attrs...))
+}
+
+func (d dependencyDo) Joins(fields ...field.RelationField) IDependencyDo {
+	for _, _f := range fields {
+		d = *d.withDO(d.DO.Joins(_f))
+	}
+	return &d
+}
+
+func (d dependencyDo) Preload(fields ...field.RelationField) IDependencyDo {
+	for _, _f := range fields {
+		d = *d.withDO(d.DO.Preload(_f))
+	}
+	return &d
+}
+
+func (d dependencyDo) FirstOrInit() (*model.Dependency, error) {
+	if result, err := d.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Dependency), nil
+	}
+}
+
+func (d dependencyDo) FirstOrCreate() (*model.Dependency, error) {
+	if result, err := d.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Dependency), nil
+	}
+}
+
+func (d dependencyDo) FindByPage(offset int, limit int) (result []*model.Dependency, count int64, err error) {
+	result, err = d.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = d.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (d dependencyDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = d.Count()
+	if err != nil {
+		return
+	}
+
+	err = d.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (d dependencyDo) Scan(result interface{}) (err error) {
+	return d.DO.Scan(result)
+}
+
+func (d dependencyDo) Delete(models ...*model.Dependency) (result gen.ResultInfo, err error) {
+	return d.DO.Delete(models)
+}
+
+func (d *dependencyDo) withDO(do gen.Dao) *dependencyDo {
+	d.DO = *do.(*gen.DO)
+	return d
+}
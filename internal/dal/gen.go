@@ -16,26 +16,36 @@ import (
 )
 
 var (
-	Q            = new(Query)
-	Commit       *commit
-	DockerRepo   *dockerRepo
-	FileBlob     *fileBlob
-	FileManifest *fileManifest
-	Plugin       *plugin
-	Repository   *repository
-	Tag          *tag
-	Token        *token
-	User         *user
+	Q                    = new(Query)
+	Commit               *commit
+	Dependency           *dependency
+	DockerRepo           *dockerRepo
+	FileBlob             *fileBlob
+	FileManifest         *fileManifest
+	PackageDocumentation *packageDocumentation
+	Plugin               *plugin
+	Repository           *repository
+	RepositoryRedirect   *repositoryRedirect
+	RepositoryTransfer   *repositoryTransfer
+	Symbol               *symbol
+	Tag                  *tag
+	Token                *token
+	User                 *user
 )
 
 func SetDefault(db *gorm.DB, opts ...gen.DOOption) {
 	*Q = *Use(db, opts...)
 	Commit = &Q.Commit
+	Dependency = &Q.Dependency
 	DockerRepo = &Q.DockerRepo
 	FileBlob = &Q.FileBlob
 	FileManifest = &Q.FileManifest
+	PackageDocumentation = &Q.PackageDocumentation
 	Plugin = &Q.Plugin
 	Repository = &Q.Repository
+	RepositoryRedirect = &Q.RepositoryRedirect
+	RepositoryTransfer = &Q.RepositoryTransfer
+	Symbol = &Q.Symbol
 	Tag = &Q.Tag
 	Token = &Q.Token
 	User = &Q.User
@@ -43,47 +53,62 @@ func SetDefault(db *gorm.DB, opts ...gen.DOOption) {
 
 func Use(db *gorm.DB, opts ...gen.DOOption) *Query {
 	return &Query{
-		db:           db,
-		Commit:       newCommit(db, opts...),
-		DockerRepo:   newDockerRepo(db, opts...),
-		FileBlob:     newFileBlob(db, opts...),
-		FileManifest: newFileManifest(db, opts...),
-		Plugin:       newPlugin(db, opts...),
-		Repository:   newRepository(db, opts...),
-		Tag:          newTag(db, opts...),
-		Token:        newToken(db, opts...),
-		User:         newUser(db, opts...),
+		db:                   db,
+		Commit:               newCommit(db, opts...),
+		Dependency:           newDependency(db, opts...),
+		DockerRepo:           newDockerRepo(db, opts...),
+		FileBlob:             newFileBlob(db, opts...),
+		FileManifest:         newFileManifest(db, opts...),
+		PackageDocumentation: newPackageDocumentation(db, opts...),
+		Plugin:               newPlugin(db, opts...),
+		Repository:           newRepository(db, opts...),
+		RepositoryRedirect:   newRepositoryRedirect(db, opts...),
+		RepositoryTransfer:   newRepositoryTransfer(db, opts...),
+		Symbol:               newSymbol(db, opts...),
+		Tag:                  newTag(db, opts...),
+		Token:                newToken(db, opts...),
+		User:                 newUser(db, opts...),
 	}
 }
 
 type Query struct {
 	db *gorm.DB
 
-	Commit       commit
-	DockerRepo   dockerRepo
-	FileBlob     fileBlob
-	FileManifest fileManifest
-	Plugin       plugin
-	Repository   repository
-	Tag          tag
-	Token        token
-	User         user
+	Commit               commit
+	Dependency           dependency
+	DockerRepo           dockerRepo
+	FileBlob             fileBlob
+	FileManifest         fileManifest
+	PackageDocumentation packageDocumentation
+	Plugin               plugin
+	Repository           repository
+	RepositoryRedirect   repositoryRedirect
+	RepositoryTransfer   repositoryTransfer
+	Symbol               symbol
+	Tag                  tag
+	Token                token
+	User                 user
 }
 
 func (q *Query) Available() bool { return q.db != nil }
 
 func (q *Query) clone(db *gorm.DB) *Query {
 	return &Query{
-		db:           db,
-		Commit:       q.Commit.clone(db),
-		DockerRepo:   q.DockerRepo.clone(db),
-		FileBlob:     q.FileBlob.clone(db),
-		FileManifest: q.FileManifest.clone(db),
-		Plugin:       q.Plugin.clone(db),
-		Repository:   q.Repository.clone(db),
-		Tag:          q.Tag.clone(db),
-		Token:        q.Token.clone(db),
-		User:         q.User.clone(db),
+		db:                   db,
+		Commit:               q.Commit.clone(db),
+		Dependency:           q.Dependency.clone(db),
+		DockerRepo:           q.DockerRepo.clone(db),
+		FileBlob:             q.FileBlob.clone(db),
+		FileManifest:         q.FileManifest.clone(db),
+		PackageDocumentation: q.PackageDocumentation.clone(db),
+		Plugin:               q.Plugin.clone(db),
+		Repository:           q.Repository.clone(db),
+		RepositoryRedirect:   q.RepositoryRedirect.clone(db),
+		RepositoryTransfer:   q.RepositoryTransfer.clone(db),
+		Symbol:               q.Symbol.clone(db),
+		Tag:                  q.Tag.clone(db),
+		Token:                q.Token.clone(db),
+		User:                 q.User.clone(db),
 	}
 }
 
@@ -97,42 +122,57 @@ func (q *Query) WriteDB() *Query {
 
 func (q *Query) ReplaceDB(db *gorm.DB) *Query {
 	return &Query{
-		db:           db,
-		Commit:       q.Commit.replaceDB(db),
-		DockerRepo:   q.DockerRepo.replaceDB(db),
-		FileBlob:     q.FileBlob.replaceDB(db),
-		FileManifest: q.FileManifest.replaceDB(db),
-		Plugin:       q.Plugin.replaceDB(db),
-		Repository:   q.Repository.replaceDB(db),
-		Tag:          q.Tag.replaceDB(db),
-		Token:        q.Token.replaceDB(db),
-		User:         q.User.replaceDB(db),
+		db:                   db,
+		Commit:               q.Commit.replaceDB(db),
+		Dependency:           q.Dependency.replaceDB(db),
+		DockerRepo:           q.DockerRepo.replaceDB(db),
+		FileBlob:             q.FileBlob.replaceDB(db),
+		FileManifest:         q.FileManifest.replaceDB(db),
+		PackageDocumentation: q.PackageDocumentation.replaceDB(db),
+		Plugin:               q.Plugin.replaceDB(db),
+		Repository:           q.Repository.replaceDB(db),
+		RepositoryRedirect:   q.RepositoryRedirect.replaceDB(db),
+		RepositoryTransfer:   q.RepositoryTransfer.replaceDB(db),
+		Symbol:               q.Symbol.replaceDB(db),
+		Tag:                  q.Tag.replaceDB(db),
+		Token:                q.Token.replaceDB(db),
+		User:                 q.User.replaceDB(db),
 	}
 }
 
 type queryCtx struct {
-	Commit       ICommitDo
-	DockerRepo   IDockerRepoDo
-	FileBlob     IFileBlobDo
-	FileManifest IFileManifestDo
-	Plugin       IPluginDo
-	Repository   IRepositoryDo
-	Tag          ITagDo
-	Token        ITokenDo
-	User         IUserDo
+	Commit               ICommitDo
+	Dependency           IDependencyDo
+	DockerRepo           IDockerRepoDo
+	FileBlob             IFileBlobDo
+	FileManifest         IFileManifestDo
+	PackageDocumentation IPackageDocumentationDo
+	Plugin               IPluginDo
+	Repository           IRepositoryDo
+	RepositoryRedirect   IRepositoryRedirectDo
+	RepositoryTransfer   IRepositoryTransferDo
+	Symbol               ISymbolDo
+	Tag                  ITagDo
+	Token                ITokenDo
+	User                 IUserDo
 }
 
 func (q *Query) WithContext(ctx context.Context) *queryCtx {
 	return &queryCtx{
-		Commit:       q.Commit.WithContext(ctx),
-		DockerRepo:   q.DockerRepo.WithContext(ctx),
-		FileBlob:     q.FileBlob.WithContext(ctx),
-		FileManifest: q.FileManifest.WithContext(ctx),
-		Plugin:       q.Plugin.WithContext(ctx),
-		Repository:   q.Repository.WithContext(ctx),
-		Tag:          q.Tag.WithContext(ctx),
-		Token:        q.Token.WithContext(ctx),
-		User:         q.User.WithContext(ctx),
+		Commit:               q.Commit.WithContext(ctx),
+		Dependency:           q.Dependency.WithContext(ctx),
+		DockerRepo:           q.DockerRepo.WithContext(ctx),
+		FileBlob:             q.FileBlob.WithContext(ctx),
+		FileManifest:         q.FileManifest.WithContext(ctx),
+		PackageDocumentation: q.PackageDocumentation.WithContext(ctx),
+		Plugin:               q.Plugin.WithContext(ctx),
+		Repository:           q.Repository.WithContext(ctx),
+		RepositoryRedirect:   q.RepositoryRedirect.WithContext(ctx),
+		RepositoryTransfer:   q.RepositoryTransfer.WithContext(ctx),
+		Symbol:               q.Symbol.WithContext(ctx),
+		Tag:                  q.Tag.WithContext(ctx),
+		Token:                q.Token.WithContext(ctx),
+		User:                 q.User.WithContext(ctx),
 	}
 }
 
@@ -0,0 +1,404 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package dal
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/ProtobufMan/bufman/internal/model"
+)
+
+func newPackageDocumentation(db *gorm.DB, opts ...gen.DOOption) packageDocumentation {
+	_packageDocumentation := packageDocumentation{}
+
+	_packageDocumentation.packageDocumentationDo.UseDB(db, opts...)
+	_packageDocumentation.packageDocumentationDo.UseModel(&model.PackageDocumentation{})
+
+	tableName := _packageDocumentation.packageDocumentationDo.TableName()
+	_packageDocumentation.ALL = field.NewAsterisk(tableName)
+	_packageDocumentation.ID = field.NewInt64(tableName, "id")
+	_packageDocumentation.RepositoryID = field.NewString(tableName, "repository_id")
+	_packageDocumentation.CommitID = field.NewString(tableName, "commit_id")
+	_packageDocumentation.PackageName = field.NewString(tableName, "package_name")
+	_packageDocumentation.Content = field.NewBytes(tableName, "content")
+	_packageDocumentation.CreatedTime = field.NewTime(tableName, "created_time")
+
+	_packageDocumentation.fillFieldMap()
+
+	return _packageDocumentation
+}
+
+type packageDocumentation struct {
+	packageDocumentationDo
+
+	ALL          field.Asterisk
+	ID           field.Int64
+	RepositoryID field.String
+	CommitID     field.String
+	PackageName  field.String
+	Content      field.Bytes
+	CreatedTime  field.Time
+
+	fieldMap map[string]field.Expr
+}
+
+func (p packageDocumentation) Table(newTableName string) *packageDocumentation {
+	p.packageDocumentationDo.UseTable(newTableName)
+	return p.updateTableName(newTableName)
+}
+
+func (p packageDocumentation) As(alias string) *packageDocumentation {
+	p.packageDocumentationDo.DO = *(p.packageDocumentationDo.As(alias).(*gen.DO))
+	return p.updateTableName(alias)
+}
+
+func (p *packageDocumentation) updateTableName(table string) *packageDocumentation {
+	p.ALL = field.NewAsterisk(table)
+	p.ID = field.NewInt64(table, "id")
+	p.RepositoryID = field.NewString(table, "repository_id")
+	p.CommitID = field.NewString(table, "commit_id")
+	p.PackageName = field.NewString(table, "package_name")
+	p.Content = field.NewBytes(table, "content")
+	p.CreatedTime = field.NewTime(table, "created_time")
+
+	p.fillFieldMap()
+
+	return p
+}
+
+func (p *packageDocumentation) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := p.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (p *packageDocumentation) fillFieldMap() {
+	p.fieldMap = make(map[string]field.Expr, 6)
+	p.fieldMap["id"] = p.ID
+	p.fieldMap["repository_id"] = p.RepositoryID
+	p.fieldMap["commit_id"] = p.CommitID
+	p.fieldMap["package_name"] = p.PackageName
+	p.fieldMap["content"] = p.Content
+	p.fieldMap["created_time"] = p.CreatedTime
+}
+
+func (p packageDocumentation) clone(db *gorm.DB) packageDocumentation {
+	p.packageDocumentationDo.ReplaceConnPool(db.Statement.ConnPool)
+	return p
+}
+
+func (p packageDocumentation) replaceDB(db *gorm.DB) packageDocumentation {
+	p.packageDocumentationDo.ReplaceDB(db)
+	return p
+}
+
+type packageDocumentationDo struct{ gen.DO }
+
+type IPackageDocumentationDo interface {
+	gen.SubQuery
+	Debug() IPackageDocumentationDo
+	WithContext(ctx context.Context) IPackageDocumentationDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() IPackageDocumentationDo
+	WriteDB() IPackageDocumentationDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) IPackageDocumentationDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) IPackageDocumentationDo
+	Not(conds ...gen.Condition) IPackageDocumentationDo
+	Or(conds ...gen.Condition) IPackageDocumentationDo
+	Select(conds ...field.Expr) IPackageDocumentationDo
+	Where(conds ...gen.Condition) IPackageDocumentationDo
+	Order(conds ...field.Expr) IPackageDocumentationDo
+	Distinct(cols ...field.Expr) IPackageDocumentationDo
+	Omit(cols ...field.Expr) IPackageDocumentationDo
+	Join(table schema.Tabler, on ...field.Expr) IPackageDocumentationDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) IPackageDocumentationDo
+	RightJoin(table schema.Tabler, on ...field.Expr) IPackageDocumentationDo
+	Group(cols ...field.Expr) IPackageDocumentationDo
+	Having(conds ...gen.Condition) IPackageDocumentationDo
+	Limit(limit int) IPackageDocumentationDo
+	Offset(offset int) IPackageDocumentationDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) IPackageDocumentationDo
+	Unscoped() IPackageDocumentationDo
+	Create(values ...*model.PackageDocumentation) error
+	CreateInBatches(values []*model.PackageDocumentation, batchSize int) error
+	Save(values ...*model.PackageDocumentation) error
+	First() (*model.PackageDocumentation, error)
+	Take() (*model.PackageDocumentation, error)
+	Last() (*model.PackageDocumentation, error)
+	Find() ([]*model.PackageDocumentation, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.PackageDocumentation, err error)
+	FindInBatches(result *[]*model.PackageDocumentation, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.PackageDocumentation) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) IPackageDocumentationDo
+	Assign(attrs ...field.AssignExpr) IPackageDocumentationDo
+	Joins(fields ...field.RelationField) IPackageDocumentationDo
+	Preload(fields ...field.RelationField) IPackageDocumentationDo
+	FirstOrInit() (*model.PackageDocumentation, error)
+	FirstOrCreate() (*model.PackageDocumentation, error)
+	FindByPage(offset int, limit int) (result []*model.PackageDocumentation, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) IPackageDocumentationDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (p packageDocumentationDo) Debug() IPackageDocumentationDo {
+	return p.withDO(p.DO.Debug())
+}
+
+func (p packageDocumentationDo) WithContext(ctx context.Context) IPackageDocumentationDo {
+	return p.withDO(p.DO.WithContext(ctx))
+}
+
+func (p packageDocumentationDo) ReadDB() IPackageDocumentationDo {
+	return p.Clauses(dbresolver.Read)
+}
+
+func (p packageDocumentationDo) WriteDB() IPackageDocumentationDo {
+	return p.Clauses(dbresolver.Write)
+}
+
+func (p packageDocumentationDo) Session(config *gorm.Session) IPackageDocumentationDo {
+	return p.withDO(p.DO.Session(config))
+}
+
+func (p packageDocumentationDo) Clauses(conds ...clause.Expression) IPackageDocumentationDo {
+	return p.withDO(p.DO.Clauses(conds...))
+}
+
+func (p packageDocumentationDo) Returning(value interface{}, columns ...string) IPackageDocumentationDo {
+	return p.withDO(p.DO.Returning(value, columns...))
+}
+
+func (p packageDocumentationDo) Not(conds ...gen.Condition) IPackageDocumentationDo {
+	return p.withDO(p.DO.Not(conds...))
+}
+
+func (p packageDocumentationDo) Or(conds ...gen.Condition) IPackageDocumentationDo {
+	return p.withDO(p.DO.Or(conds...))
+}
+
+func (p packageDocumentationDo) Select(conds ...field.Expr) IPackageDocumentationDo {
+	return p.withDO(p.DO.Select(conds...))
+}
+
+func (p packageDocumentationDo) Where(conds ...gen.Condition) IPackageDocumentationDo {
+	return p.withDO(p.DO.Where(conds...))
+}
+
+func (p packageDocumentationDo) Exists(subquery interface{ UnderlyingDB() *gorm.DB }) IPackageDocumentationDo {
+	return p.Where(field.CompareSubQuery(field.ExistsOp, nil, subquery.UnderlyingDB()))
+}
+
+func (p packageDocumentationDo) Order(conds ...field.Expr) IPackageDocumentationDo {
+	return p.withDO(p.DO.Order(conds...))
+}
+
+func (p packageDocumentationDo) Distinct(cols ...field.Expr) IPackageDocumentationDo {
+	return p.withDO(p.DO.Distinct(cols...))
+}
+
+func (p packageDocumentationDo) Omit(cols ...field.Expr) IPackageDocumentationDo {
+	return p.withDO(p.DO.Omit(cols...))
+}
+
+func (p packageDocumentationDo) Join(table schema.Tabler, on ...field.Expr) IPackageDocumentationDo {
+	return p.withDO(p.DO.Join(table, on...))
+}
+
+func (p packageDocumentationDo) LeftJoin(table schema.Tabler, on ...field.Expr) IPackageDocumentationDo {
+	return p.withDO(p.DO.LeftJoin(table, on...))
+}
+
+func (p packageDocumentationDo) RightJoin(table schema.Tabler, on ...field.Expr) IPackageDocumentationDo {
+	return p.withDO(p.DO.RightJoin(table, on...))
+}
+
+func (p packageDocumentationDo) Group(cols ...field.Expr) IPackageDocumentationDo {
+	return p.withDO(p.DO.Group(cols...))
+}
+
+func (p packageDocumentationDo) Having(conds ...gen.Condition) IPackageDocumentationDo {
+	return p.withDO(p.DO.Having(conds...))
+}
+
+func (p packageDocumentationDo) Limit(limit int) IPackageDocumentationDo {
+	return p.withDO(p.DO.Limit(limit))
+}
+
+func (p packageDocumentationDo) Offset(offset int) IPackageDocumentationDo {
+	return p.withDO(p.DO.Offset(offset))
+}
+
+func (p packageDocumentationDo) Scopes(funcs ...func(gen.Dao) gen.Dao) IPackageDocumentationDo {
+	return p.withDO(p.DO.Scopes(funcs...))
+}
+
+func (p packageDocumentationDo) Unscoped() IPackageDocumentationDo {
+	return p.withDO(p.DO.Unscoped())
+}
+
+func (p packageDocumentationDo) Create(values ...*model.PackageDocumentation) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return p.DO.Create(values)
+}
+
+func (p packageDocumentationDo) CreateInBatches(values []*model.PackageDocumentation, batchSize int) error {
+	return p.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (p packageDocumentationDo) Save(values ...*model.PackageDocumentation) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return p.DO.Save(values)
+}
+
+func (p packageDocumentationDo) First() (*model.PackageDocumentation, error) {
+	if result, err := p.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.PackageDocumentation), nil
+	}
+}
+
+func (p packageDocumentationDo) Take() (*model.PackageDocumentation, error) {
+	if result, err := p.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.PackageDocumentation), nil
+	}
+}
+
+func (p packageDocumentationDo) Last() (*model.PackageDocumentation, error) {
+	if result, err := p.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.PackageDocumentation), nil
+	}
+}
+
+func (p packageDocumentationDo) Find() ([]*model.PackageDocumentation, error) {
+	result, err := p.DO.Find()
+	return result.([]*model.PackageDocumentation), err
+}
+
+func (p packageDocumentationDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.PackageDocumentation, err error) {
+	buf := make([]*model.PackageDocumentation, 0, batchSize)
+	err = p.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (p packageDocumentationDo) FindInBatches(result *[]*model.PackageDocumentation, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return p.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (p packageDocumentationDo) Attrs(attrs ...field.AssignExpr) IPackageDocumentationDo {
+	return p.withDO(p.DO.Attrs(attrs...))
+}
+
+func (p packageDocumentationDo) Assign(attrs ...field.AssignExpr) IPackageDocumentationDo {
+	return p.withDO(p.DO.Assign(attrs...))
+}
+
+func (p packageDocumentationDo) Joins(fields ...field.RelationField) IPackageDocumentationDo {
+	for _, _f := range fields {
+		p = *p.withDO(p.DO.Joins(_f))
+	}
+	return &p
+}
+
+func (p packageDocumentationDo) Preload(fields ...field.RelationField) IPackageDocumentationDo {
+	for _, _f := range fields {
+		p = *p.withDO(p.DO.Preload(_f))
+	}
+	return &p
+}
+
+func (p packageDocumentationDo) FirstOrInit() (*model.PackageDocumentation, error) {
+	if result, err := p.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.PackageDocumentation), nil
+	}
+}
+
+func (p packageDocumentationDo) FirstOrCreate() (*model.PackageDocumentation, error) {
+	if result, err := p.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.PackageDocumentation), nil
+	}
+}
+
+func (p packageDocumentationDo) FindByPage(offset int, limit int) (result []*model.PackageDocumentation, count int64, err error) {
+	result, err = p.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = p.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (p packageDocumentationDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = p.Count()
+	if err != nil {
+		return
+	}
+
+	err = p.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (p packageDocumentationDo) Scan(result interface{}) (err error) {
+	return p.DO.Scan(result)
+}
+
+func (p packageDocumentationDo) Delete(models ...*model.PackageDocumentation) (result gen.ResultInfo, err error) {
+	return p.DO.Delete(models)
+}
+
+func (p *packageDocumentationDo) withDO(do gen.Dao) *packageDocumentationDo {
+	p.DO = *do.(*gen.DO)
+	return p
+}
@@ -39,6 +39,9 @@ func newRepository(db *gorm.DB, opts ...gen.DOOption) repository {
 	_repository.DeprecationMsg = field.NewString(tableName, "deprecation_msg")
 	_repository.Url = field.NewString(tableName, "url")
 	_repository.Description = field.NewString(tableName, "description")
+	_repository.ForkedFromRepositoryID = field.NewString(tableName, "forked_from_repository_id")
+	_repository.Remote = field.NewString(tableName, "remote")
+	_repository.StrictDependencies = field.NewBool(tableName, "strict_dependencies")
 	_repository.DraftCommits = repositoryHasManyDraftCommits{
 		db: db.Session(&gorm.Session{}),
 
@@ -58,6 +61,11 @@ func newRepository(db *gorm.DB, opts ...gen.DOOption) repository {
 		}{
 			RelationField: field.NewRelation("DraftCommits.Tags", "model.Tags"),
 		},
+		Dependencies: struct {
+			field.RelationField
+		}{
+			RelationField: field.NewRelation("DraftCommits.Dependencies", "model.Dependencies"),
+		},
 	}
 
 	_repository.Tags = repositoryHasManyTags{
@@ -74,20 +82,23 @@ func newRepository(db *gorm.DB, opts ...gen.DOOption) repository {
 type repository struct {
 	repositoryDo
 
-	ALL            field.Asterisk
-	ID             field.Int64
-	UserID         field.String
-	UserName       field.String
-	RepositoryID   field.String
-	RepositoryName field.String
-	CreatedTime    field.Time
-	UpdateTime     field.Time
-	Visibility     field.Uint8
-	Deprecated     field.Bool
-	DeprecationMsg field.String
-	Url            field.String
-	Description    field.String
-	DraftCommits   repositoryHasManyDraftCommits
+	ALL                    field.Asterisk
+	ID                     field.Int64
+	UserID                 field.String
+	UserName               field.String
+	RepositoryID           field.String
+	RepositoryName         field.String
+	CreatedTime            field.Time
+	UpdateTime             field.Time
+	Visibility             field.Uint8
+	Deprecated             field.Bool
+	DeprecationMsg         field.String
+	Url                    field.String
+	Description            field.String
+	ForkedFromRepositoryID field.String
+	Remote                 field.String
+	StrictDependencies     field.Bool
+	DraftCommits           repositoryHasManyDraftCommits
 
 	Tags repositoryHasManyTags
 
@@ -118,6 +129,9 @@ func (r *repository) updateTableName(table string) *repository {
 	r.DeprecationMsg = field.NewString(table, "deprecation_msg")
 	r.Url = field.NewString(table, "url")
 	r.Description = field.NewString(table, "description")
+	r.ForkedFromRepositoryID = field.NewString(table, "forked_from_repository_id")
+	r.Remote = field.NewString(table, "remote")
+	r.StrictDependencies = field.NewBool(table, "strict_dependencies")
 
 	r.fillFieldMap()
 
@@ -134,7 +148,7 @@ func (r *repository) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
 }
 
 func (r *repository) fillFieldMap() {
-	r.fieldMap = make(map[string]field.Expr, 14)
+	r.fieldMap = make(map[string]field.Expr, 17)
 	r.fieldMap["id"] = r.ID
 	r.fieldMap["user_id"] = r.UserID
 	r.fieldMap["user_name"] = r.UserName
@@ -147,6 +161,9 @@ func (r *repository) fillFieldMap() {
 	r.fieldMap["deprecation_msg"] = r.DeprecationMsg
 	r.fieldMap["url"] = r.Url
 	r.fieldMap["description"] = r.Description
+	r.fieldMap["forked_from_repository_id"] = r.ForkedFromRepositoryID
+	r.fieldMap["remote"] = r.Remote
+	r.fieldMap["strict_dependencies"] = r.StrictDependencies
 
 }
 
@@ -174,6 +191,9 @@ type repositoryHasManyDraftCommits struct {
 	Tags struct {
 		field.RelationField
 	}
+	Dependencies struct {
+		field.RelationField
+	}
 }
 
 func (a repositoryHasManyDraftCommits) Where(conds ...field.Expr) *repositoryHasManyDraftCommits {
@@ -0,0 +1,400 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package dal
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/ProtobufMan/bufman/internal/model"
+)
+
+func newRepositoryRedirect(db *gorm.DB, opts ...gen.DOOption) repositoryRedirect {
+	_repositoryRedirect := repositoryRedirect{}
+
+	_repositoryRedirect.repositoryRedirectDo.UseDB(db, opts...)
+	_repositoryRedirect.repositoryRedirectDo.UseModel(&model.RepositoryRedirect{})
+
+	tableName := _repositoryRedirect.repositoryRedirectDo.TableName()
+	_repositoryRedirect.ALL = field.NewAsterisk(tableName)
+	_repositoryRedirect.ID = field.NewInt64(tableName, "id")
+	_repositoryRedirect.UserName = field.NewString(tableName, "user_name")
+	_repositoryRedirect.RepositoryName = field.NewString(tableName, "repository_name")
+	_repositoryRedirect.RepositoryID = field.NewString(tableName, "repository_id")
+	_repositoryRedirect.CreatedTime = field.NewTime(tableName, "created_time")
+
+	_repositoryRedirect.fillFieldMap()
+
+	return _repositoryRedirect
+}
+
+type repositoryRedirect struct {
+	repositoryRedirectDo
+
+	ALL            field.Asterisk
+	ID             field.Int64
+	UserName       field.String
+	RepositoryName field.String
+	RepositoryID   field.String
+	CreatedTime    field.Time
+
+	fieldMap map[string]field.Expr
+}
+
+func (r repositoryRedirect) Table(newTableName string) *repositoryRedirect {
+	r.repositoryRedirectDo.UseTable(newTableName)
+	return r.updateTableName(newTableName)
+}
+
+func (r repositoryRedirect) As(alias string) *repositoryRedirect {
+	r.repositoryRedirectDo.DO = *(r.repositoryRedirectDo.As(alias).(*gen.DO))
+	return r.updateTableName(alias)
+}
+
+func (r *repositoryRedirect) updateTableName(table string) *repositoryRedirect {
+	r.ALL = field.NewAsterisk(table)
+	r.ID = field.NewInt64(table, "id")
+	r.UserName = field.NewString(table, "user_name")
+	r.RepositoryName = field.NewString(table, "repository_name")
+	r.RepositoryID = field.NewString(table, "repository_id")
+	r.CreatedTime = field.NewTime(table, "created_time")
+
+	r.fillFieldMap()
+
+	return r
+}
+
+func (r *repositoryRedirect) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := r.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (r *repositoryRedirect) fillFieldMap() {
+	r.fieldMap = make(map[string]field.Expr, 5)
+	r.fieldMap["id"] = r.ID
+	r.fieldMap["user_name"] = r.UserName
+	r.fieldMap["repository_name"] = r.RepositoryName
+	r.fieldMap["repository_id"] = r.RepositoryID
+	r.fieldMap["created_time"] = r.CreatedTime
+}
+
+func (r repositoryRedirect) clone(db *gorm.DB) repositoryRedirect {
+	r.repositoryRedirectDo.ReplaceConnPool(db.Statement.ConnPool)
+	return r
+}
+
+func (r repositoryRedirect) replaceDB(db *gorm.DB) repositoryRedirect {
+	r.repositoryRedirectDo.ReplaceDB(db)
+	return r
+}
+
+type repositoryRedirectDo struct{ gen.DO }
+
+type IRepositoryRedirectDo interface {
+	gen.SubQuery
+	Debug() IRepositoryRedirectDo
+	WithContext(ctx context.Context) IRepositoryRedirectDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() IRepositoryRedirectDo
+	WriteDB() IRepositoryRedirectDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) IRepositoryRedirectDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) IRepositoryRedirectDo
+	Not(conds ...gen.Condition) IRepositoryRedirectDo
+	Or(conds ...gen.Condition) IRepositoryRedirectDo
+	Select(conds ...field.Expr) IRepositoryRedirectDo
+	Where(conds ...gen.Condition) IRepositoryRedirectDo
+	Order(conds ...field.Expr) IRepositoryRedirectDo
+	Distinct(cols ...field.Expr) IRepositoryRedirectDo
+	Omit(cols ...field.Expr) IRepositoryRedirectDo
+	Join(table schema.Tabler, on ...field.Expr) IRepositoryRedirectDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) IRepositoryRedirectDo
+	RightJoin(table schema.Tabler, on ...field.Expr) IRepositoryRedirectDo
+	Group(cols ...field.Expr) IRepositoryRedirectDo
+	Having(conds ...gen.Condition) IRepositoryRedirectDo
+	Limit(limit int) IRepositoryRedirectDo
+	Offset(offset int) IRepositoryRedirectDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) IRepositoryRedirectDo
+	Unscoped() IRepositoryRedirectDo
+	Create(values ...*model.RepositoryRedirect) error
+	CreateInBatches(values []*model.RepositoryRedirect, batchSize int) error
+	Save(values ...*model.RepositoryRedirect) error
+	First() (*model.RepositoryRedirect, error)
+	Take() (*model.RepositoryRedirect, error)
+	Last() (*model.RepositoryRedirect, error)
+	Find() ([]*model.RepositoryRedirect, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.RepositoryRedirect, err error)
+	FindInBatches(result *[]*model.RepositoryRedirect, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.RepositoryRedirect) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) IRepositoryRedirectDo
+	Assign(attrs ...field.AssignExpr) IRepositoryRedirectDo
+	Joins(fields ...field.RelationField) IRepositoryRedirectDo
+	Preload(fields ...field.RelationField) IRepositoryRedirectDo
+	FirstOrInit() (*model.RepositoryRedirect, error)
+	FirstOrCreate() (*model.RepositoryRedirect, error)
+	FindByPage(offset int, limit int) (result []*model.RepositoryRedirect, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) IRepositoryRedirectDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (r repositoryRedirectDo) Debug() IRepositoryRedirectDo {
+	return r.withDO(r.DO.Debug())
+}
+
+func (r repositoryRedirectDo) WithContext(ctx context.Context) IRepositoryRedirectDo {
+	return r.withDO(r.DO.WithContext(ctx))
+}
+
+func (r repositoryRedirectDo) ReadDB() IRepositoryRedirectDo {
+	return r.Clauses(dbresolver.Read)
+}
+
+func (r repositoryRedirectDo) WriteDB() IRepositoryRedirectDo {
+	return r.Clauses(dbresolver.Write)
+}
+
+func (r repositoryRedirectDo) Session(config *gorm.Session) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Session(config))
+}
+
+func (r repositoryRedirectDo) Clauses(conds ...clause.Expression) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Clauses(conds...))
+}
+
+func (r repositoryRedirectDo) Returning(value interface{}, columns ...string) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Returning(value, columns...))
+}
+
+func (r repositoryRedirectDo) Not(conds ...gen.Condition) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Not(conds...))
+}
+
+func (r repositoryRedirectDo) Or(conds ...gen.Condition) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Or(conds...))
+}
+
+func (r repositoryRedirectDo) Select(conds ...field.Expr) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Select(conds...))
+}
+
+func (r repositoryRedirectDo) Where(conds ...gen.Condition) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Where(conds...))
+}
+
+func (r repositoryRedirectDo) Exists(subquery interface{ UnderlyingDB() *gorm.DB }) IRepositoryRedirectDo {
+	return r.Where(field.CompareSubQuery(field.ExistsOp, nil, subquery.UnderlyingDB()))
+}
+
+func (r repositoryRedirectDo) Order(conds ...field.Expr) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Order(conds...))
+}
+
+func (r repositoryRedirectDo) Distinct(cols ...field.Expr) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Distinct(cols...))
+}
+
+func (r repositoryRedirectDo) Omit(cols ...field.Expr) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Omit(cols...))
+}
+
+func (r repositoryRedirectDo) Join(table schema.Tabler, on ...field.Expr) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Join(table, on...))
+}
+
+func (r repositoryRedirectDo) LeftJoin(table schema.Tabler, on ...field.Expr) IRepositoryRedirectDo {
+	return r.withDO(r.DO.LeftJoin(table, on...))
+}
+
+func (r repositoryRedirectDo) RightJoin(table schema.Tabler, on ...field.Expr) IRepositoryRedirectDo {
+	return r.withDO(r.DO.RightJoin(table, on...))
+}
+
+func (r repositoryRedirectDo) Group(cols ...field.Expr) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Group(cols...))
+}
+
+func (r repositoryRedirectDo) Having(conds ...gen.Condition) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Having(conds...))
+}
+
+func (r repositoryRedirectDo) Limit(limit int) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Limit(limit))
+}
+
+func (r repositoryRedirectDo) Offset(offset int) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Offset(offset))
+}
+
+func (r repositoryRedirectDo) Scopes(funcs ...func(gen.Dao) gen.Dao) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Scopes(funcs...))
+}
+
+func (r repositoryRedirectDo) Unscoped() IRepositoryRedirectDo {
+	return r.withDO(r.DO.Unscoped())
+}
+
+func (r repositoryRedirectDo) Create(values ...*model.RepositoryRedirect) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return r.DO.Create(values)
+}
+
+func (r repositoryRedirectDo) CreateInBatches(values []*model.RepositoryRedirect, batchSize int) error {
+	return r.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (r repositoryRedirectDo) Save(values ...*model.RepositoryRedirect) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return r.DO.Save(values)
+}
+
+func (r repositoryRedirectDo) First() (*model.RepositoryRedirect, error) {
+	if result, err := r.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.RepositoryRedirect), nil
+	}
+}
+
+func (r repositoryRedirectDo) Take() (*model.RepositoryRedirect, error) {
+	if result, err := r.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.RepositoryRedirect), nil
+	}
+}
+
+func (r repositoryRedirectDo) Last() (*model.RepositoryRedirect, error) {
+	if result, err := r.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.RepositoryRedirect), nil
+	}
+}
+
+func (r repositoryRedirectDo) Find() ([]*model.RepositoryRedirect, error) {
+	result, err := r.DO.Find()
+	return result.([]*model.RepositoryRedirect), err
+}
+
+func (r repositoryRedirectDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.RepositoryRedirect, err error) {
+	buf := make([]*model.RepositoryRedirect, 0, batchSize)
+	err = r.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (r repositoryRedirectDo) FindInBatches(result *[]*model.RepositoryRedirect, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return r.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (r repositoryRedirectDo) Attrs(attrs ...field.AssignExpr) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Attrs(attrs...))
+}
+
+func (r repositoryRedirectDo) Assign(attrs ...field.AssignExpr) IRepositoryRedirectDo {
+	return r.withDO(r.DO.Assign(attrs...))
+}
+
+func (r repositoryRedirectDo) Joins(fields ...field.RelationField) IRepositoryRedirectDo {
+	for _, _f := range fields {
+		r = *r.withDO(r.DO.Joins(_f))
+	}
+	return &r
+}
+
+func (r repositoryRedirectDo) Preload(fields ...field.RelationField) IRepositoryRedirectDo {
+	for _, _f := range fields {
+		r = *r.withDO(r.DO.Preload(_f))
+	}
+	return &r
+}
+
+func (r repositoryRedirectDo) FirstOrInit() (*model.RepositoryRedirect, error) {
+	if result, err := r.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.RepositoryRedirect), nil
+	}
+}
+
+func (r repositoryRedirectDo) FirstOrCreate() (*model.RepositoryRedirect, error) {
+	if result, err := r.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.RepositoryRedirect), nil
+	}
+}
+
+func (r repositoryRedirectDo) FindByPage(offset int, limit int) (result []*model.RepositoryRedirect, count int64, err error) {
+	result, err = r.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = r.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (r repositoryRedirectDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = r.Count()
+	if err != nil {
+		return
+	}
+
+	err = r.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (r repositoryRedirectDo) Scan(result interface{}) (err error) {
+	return r.DO.Scan(result)
+}
+
+func (r repositoryRedirectDo) Delete(models ...*model.RepositoryRedirect) (result gen.ResultInfo, err error) {
+	return r.DO.Delete(models)
+}
+
+func (r *repositoryRedirectDo) withDO(do gen.Dao) *repositoryRedirectDo {
+	r.DO = *do.(*gen.DO)
+	return r
+}
@@ -0,0 +1,404 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package dal
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/ProtobufMan/bufman/internal/model"
+)
+
+func newRepositoryTransfer(db *gorm.DB, opts ...gen.DOOption) repositoryTransfer {
+	_repositoryTransfer := repositoryTransfer{}
+
+	_repositoryTransfer.repositoryTransferDo.UseDB(db, opts...)
+	_repositoryTransfer.repositoryTransferDo.UseModel(&model.RepositoryTransfer{})
+
+	tableName := _repositoryTransfer.repositoryTransferDo.TableName()
+	_repositoryTransfer.ALL = field.NewAsterisk(tableName)
+	_repositoryTransfer.ID = field.NewInt64(tableName, "id")
+	_repositoryTransfer.RepositoryID = field.NewString(tableName, "repository_id")
+	_repositoryTransfer.FromUserID = field.NewString(tableName, "from_user_id")
+	_repositoryTransfer.ToUserID = field.NewString(tableName, "to_user_id")
+	_repositoryTransfer.ToUserName = field.NewString(tableName, "to_user_name")
+	_repositoryTransfer.CreatedTime = field.NewTime(tableName, "created_time")
+
+	_repositoryTransfer.fillFieldMap()
+
+	return _repositoryTransfer
+}
+
+type repositoryTransfer struct {
+	repositoryTransferDo
+
+	ALL          field.Asterisk
+	ID           field.Int64
+	RepositoryID field.String
+	FromUserID   field.String
+	ToUserID     field.String
+	ToUserName   field.String
+	CreatedTime  field.Time
+
+	fieldMap map[string]field.Expr
+}
+
+func (r repositoryTransfer) Table(newTableName string) *repositoryTransfer {
+	r.repositoryTransferDo.UseTable(newTableName)
+	return r.updateTableName(newTableName)
+}
+
+func (r repositoryTransfer) As(alias string) *repositoryTransfer {
+	r.repositoryTransferDo.DO = *(r.repositoryTransferDo.As(alias).(*gen.DO))
+	return r.updateTableName(alias)
+}
+
+func (r *repositoryTransfer) updateTableName(table string) *repositoryTransfer {
+	r.ALL = field.NewAsterisk(table)
+	r.ID = field.NewInt64(table, "id")
+	r.RepositoryID = field.NewString(table, "repository_id")
+	r.FromUserID = field.NewString(table, "from_user_id")
+	r.ToUserID = field.NewString(table, "to_user_id")
+	r.ToUserName = field.NewString(table, "to_user_name")
+	r.CreatedTime = field.NewTime(table, "created_time")
+
+	r.fillFieldMap()
+
+	return r
+}
+
+func (r *repositoryTransfer) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := r.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (r *repositoryTransfer) fillFieldMap() {
+	r.fieldMap = make(map[string]field.Expr, 6)
+	r.fieldMap["id"] = r.ID
+	r.fieldMap["repository_id"] = r.RepositoryID
+	r.fieldMap["from_user_id"] = r.FromUserID
+	r.fieldMap["to_user_id"] = r.ToUserID
+	r.fieldMap["to_user_name"] = r.ToUserName
+	r.fieldMap["created_time"] = r.CreatedTime
+}
+
+func (r repositoryTransfer) clone(db *gorm.DB) repositoryTransfer {
+	r.repositoryTransferDo.ReplaceConnPool(db.Statement.ConnPool)
+	return r
+}
+
+func (r repositoryTransfer) replaceDB(db *gorm.DB) repositoryTransfer {
+	r.repositoryTransferDo.ReplaceDB(db)
+	return r
+}
+
+type repositoryTransferDo struct{ gen.DO }
+
+type IRepositoryTransferDo interface {
+	gen.SubQuery
+	Debug() IRepositoryTransferDo
+	WithContext(ctx context.Context) IRepositoryTransferDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() IRepositoryTransferDo
+	WriteDB() IRepositoryTransferDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) IRepositoryTransferDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) IRepositoryTransferDo
+	Not(conds ...gen.Condition) IRepositoryTransferDo
+	Or(conds ...gen.Condition) IRepositoryTransferDo
+	Select(conds ...field.Expr) IRepositoryTransferDo
+	Where(conds ...gen.Condition) IRepositoryTransferDo
+	Order(conds ...field.Expr) IRepositoryTransferDo
+	Distinct(cols ...field.Expr) IRepositoryTransferDo
+	Omit(cols ...field.Expr) IRepositoryTransferDo
+	Join(table schema.Tabler, on ...field.Expr) IRepositoryTransferDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) IRepositoryTransferDo
+	RightJoin(table schema.Tabler, on ...field.Expr) IRepositoryTransferDo
+	Group(cols ...field.Expr) IRepositoryTransferDo
+	Having(conds ...gen.Condition) IRepositoryTransferDo
+	Limit(limit int) IRepositoryTransferDo
+	Offset(offset int) IRepositoryTransferDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) IRepositoryTransferDo
+	Unscoped() IRepositoryTransferDo
+	Create(values ...*model.RepositoryTransfer) error
+	CreateInBatches(values []*model.RepositoryTransfer, batchSize int) error
+	Save(values ...*model.RepositoryTransfer) error
+	First() (*model.RepositoryTransfer, error)
+	Take() (*model.RepositoryTransfer, error)
+	Last() (*model.RepositoryTransfer, error)
+	Find() ([]*model.RepositoryTransfer, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.RepositoryTransfer, err error)
+	FindInBatches(result *[]*model.RepositoryTransfer, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.RepositoryTransfer) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) IRepositoryTransferDo
+	Assign(attrs ...field.AssignExpr) IRepositoryTransferDo
+	Joins(fields ...field.RelationField) IRepositoryTransferDo
+	Preload(fields ...field.RelationField) IRepositoryTransferDo
+	FirstOrInit() (*model.RepositoryTransfer, error)
+	FirstOrCreate() (*model.RepositoryTransfer, error)
+	FindByPage(offset int, limit int) (result []*model.RepositoryTransfer, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) IRepositoryTransferDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (r repositoryTransferDo) Debug() IRepositoryTransferDo {
+	return r.withDO(r.DO.Debug())
+}
+
+func (r repositoryTransferDo) WithContext(ctx context.Context) IRepositoryTransferDo {
+	return r.withDO(r.DO.WithContext(ctx))
+}
+
+func (r repositoryTransferDo) ReadDB() IRepositoryTransferDo {
+	return r.Clauses(dbresolver.Read)
+}
+
+func (r repositoryTransferDo) WriteDB() IRepositoryTransferDo {
+	return r.Clauses(dbresolver.Write)
+}
+
+func (r repositoryTransferDo) Session(config *gorm.Session) IRepositoryTransferDo {
+	return r.withDO(r.DO.Session(config))
+}
+
+func (r repositoryTransferDo) Clauses(conds ...clause.Expression) IRepositoryTransferDo {
+	return r.withDO(r.DO.Clauses(conds...))
+}
+
+func (r repositoryTransferDo) Returning(value interface{}, columns ...string) IRepositoryTransferDo {
+	return r.withDO(r.DO.Returning(value, columns...))
+}
+
+func (r repositoryTransferDo) Not(conds ...gen.Condition) IRepositoryTransferDo {
+	return r.withDO(r.DO.Not(conds...))
+}
+
+func (r repositoryTransferDo) Or(conds ...gen.Condition) IRepositoryTransferDo {
+	return r.withDO(r.DO.Or(conds...))
+}
+
+func (r repositoryTransferDo) Select(conds ...field.Expr) IRepositoryTransferDo {
+	return r.withDO(r.DO.Select(conds...))
+}
+
+func (r repositoryTransferDo) Where(conds ...gen.Condition) IRepositoryTransferDo {
+	return r.withDO(r.DO.Where(conds...))
+}
+
+func (r repositoryTransferDo) Exists(subquery interface{ UnderlyingDB() *gorm.DB }) IRepositoryTransferDo {
+	return r.Where(field.CompareSubQuery(field.ExistsOp, nil, subquery.UnderlyingDB()))
+}
+
+func (r repositoryTransferDo) Order(conds ...field.Expr) IRepositoryTransferDo {
+	return r.withDO(r.DO.Order(conds...))
+}
+
+func (r repositoryTransferDo) Distinct(cols ...field.Expr) IRepositoryTransferDo {
+	return r.withDO(r.DO.Distinct(cols...))
+}
+
+func (r repositoryTransferDo) Omit(cols ...field.Expr) IRepositoryTransferDo {
+	return r.withDO(r.DO.Omit(cols...))
+}
+
+func (r repositoryTransferDo) Join(table schema.Tabler, on ...field.Expr) IRepositoryTransferDo {
+	return r.withDO(r.DO.Join(table, on...))
+}
+
+func (r repositoryTransferDo) LeftJoin(table schema.Tabler, on ...field.Expr) IRepositoryTransferDo {
+	return r.withDO(r.DO.LeftJoin(table, on...))
+}
+
+func (r repositoryTransferDo) RightJoin(table schema.Tabler, on ...field.Expr) IRepositoryTransferDo {
+	return r.withDO(r.DO.RightJoin(table, on...))
+}
+
+func (r repositoryTransferDo) Group(cols ...field.Expr) IRepositoryTransferDo {
+	return r.withDO(r.DO.Group(cols...))
+}
+
+func (r repositoryTransferDo) Having(conds ...gen.Condition) IRepositoryTransferDo {
+	return r.withDO(r.DO.Having(conds...))
+}
+
+func (r repositoryTransferDo) Limit(limit int) IRepositoryTransferDo {
+	return r.withDO(r.DO.Limit(limit))
+}
+
+func (r repositoryTransferDo) Offset(offset int) IRepositoryTransferDo {
+	return r.withDO(r.DO.Offset(offset))
+}
+
+func (r repositoryTransferDo) Scopes(funcs ...func(gen.Dao) gen.Dao) IRepositoryTransferDo {
+	return r.withDO(r.DO.Scopes(funcs...))
+}
+
+func (r repositoryTransferDo) Unscoped() IRepositoryTransferDo {
+	return r.withDO(r.DO.Unscoped())
+}
+
+func (r repositoryTransferDo) Create(values ...*model.RepositoryTransfer) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return r.DO.Create(values)
+}
+
+func (r repositoryTransferDo) CreateInBatches(values []*model.RepositoryTransfer, batchSize int) error {
+	return r.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (r repositoryTransferDo) Save(values ...*model.RepositoryTransfer) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return r.DO.Save(values)
+}
+
+func (r repositoryTransferDo) First() (*model.RepositoryTransfer, error) {
+	if result, err := r.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.RepositoryTransfer), nil
+	}
+}
+
+func (r repositoryTransferDo) Take() (*model.RepositoryTransfer, error) {
+	if result, err := r.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.RepositoryTransfer), nil
+	}
+}
+
+func (r repositoryTransferDo) Last() (*model.RepositoryTransfer, error) {
+	if result, err := r.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.RepositoryTransfer), nil
+	}
+}
+
+func (r repositoryTransferDo) Find() ([]*model.RepositoryTransfer, error) {
+	result, err := r.DO.Find()
+	return result.([]*model.RepositoryTransfer), err
+}
+
+func (r repositoryTransferDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.RepositoryTransfer, err error) {
+	buf := make([]*model.RepositoryTransfer, 0, batchSize)
+	err = r.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (r repositoryTransferDo) FindInBatches(result *[]*model.RepositoryTransfer, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return r.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (r repositoryTransferDo) Attrs(attrs ...field.AssignExpr) IRepositoryTransferDo {
+	return r.withDO(r.DO.Attrs(attrs...))
+}
+
+func (r repositoryTransferDo) Assign(attrs ...field.AssignExpr) IRepositoryTransferDo {
+	return r.withDO(r.DO.Assign(attrs...))
+}
+
+func (r repositoryTransferDo) Joins(fields ...field.RelationField) IRepositoryTransferDo {
+	for _, _f := range fields {
+		r = *r.withDO(r.DO.Joins(_f))
+	}
+	return &r
+}
+
+func (r repositoryTransferDo) Preload(fields ...field.RelationField) IRepositoryTransferDo {
+	for _, _f := range fields {
+		r = *r.withDO(r.DO.Preload(_f))
+	}
+	return &r
+}
+
+func (r repositoryTransferDo) FirstOrInit() (*model.RepositoryTransfer, error) {
+	if result, err := r.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.RepositoryTransfer), nil
+	}
+}
+
+func (r repositoryTransferDo) FirstOrCreate() (*model.RepositoryTransfer, error) {
+	if result, err := r.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.RepositoryTransfer), nil
+	}
+}
+
+func (r repositoryTransferDo) FindByPage(offset int, limit int) (result []*model.RepositoryTransfer, count int64, err error) {
+	result, err = r.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = r.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (r repositoryTransferDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = r.Count()
+	if err != nil {
+		return
+	}
+
+	err = r.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (r repositoryTransferDo) Scan(result interface{}) (err error) {
+	return r.DO.Scan(result)
+}
+
+func (r repositoryTransferDo) Delete(models ...*model.RepositoryTransfer) (result gen.ResultInfo, err error) {
+	return r.DO.Delete(models)
+}
+
+func (r *repositoryTransferDo) withDO(do gen.Dao) *repositoryTransferDo {
+	r.DO = *do.(*gen.DO)
+	return r
+}
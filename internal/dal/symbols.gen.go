@@ -0,0 +1,436 @@
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+// Code generated by gorm.io/gen. DO NOT EDIT.
+
+package dal
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+
+	"gorm.io/gen"
+	"gorm.io/gen/field"
+
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/ProtobufMan/bufman/internal/model"
+)
+
+func newSymbol(db *gorm.DB, opts ...gen.DOOption) symbol {
+	_symbol := symbol{}
+
+	_symbol.symbolDo.UseDB(db, opts...)
+	_symbol.symbolDo.UseModel(&model.Symbol{})
+
+	tableName := _symbol.symbolDo.TableName()
+	_symbol.ALL = field.NewAsterisk(tableName)
+	_symbol.ID = field.NewInt64(tableName, "id")
+	_symbol.RepositoryID = field.NewString(tableName, "repository_id")
+	_symbol.CommitID = field.NewString(tableName, "commit_id")
+	_symbol.CommitName = field.NewString(tableName, "commit_name")
+	_symbol.Kind = field.NewString(tableName, "kind")
+	_symbol.Name = field.NewString(tableName, "name")
+	_symbol.FullName = field.NewString(tableName, "full_name")
+	_symbol.PackageName = field.NewString(tableName, "package_name")
+	_symbol.FilePath = field.NewString(tableName, "file_path")
+	_symbol.StartLine = field.NewInt32(tableName, "start_line")
+	_symbol.StartColumn = field.NewInt32(tableName, "start_column")
+	_symbol.EndLine = field.NewInt32(tableName, "end_line")
+	_symbol.EndColumn = field.NewInt32(tableName, "end_column")
+	_symbol.CreatedTime = field.NewTime(tableName, "created_time")
+
+	_symbol.fillFieldMap()
+
+	return _symbol
+}
+
+type symbol struct {
+	symbolDo
+
+	ALL          field.Asterisk
+	ID           field.Int64
+	RepositoryID field.String
+	CommitID     field.String
+	CommitName   field.String
+	Kind         field.String
+	Name         field.String
+	FullName     field.String
+	PackageName  field.String
+	FilePath     field.String
+	StartLine    field.Int32
+	StartColumn  field.Int32
+	EndLine      field.Int32
+	EndColumn    field.Int32
+	CreatedTime  field.Time
+
+	fieldMap map[string]field.Expr
+}
+
+func (s symbol) Table(newTableName string) *symbol {
+	s.symbolDo.UseTable(newTableName)
+	return s.updateTableName(newTableName)
+}
+
+func (s symbol) As(alias string) *symbol {
+	s.symbolDo.DO = *(s.symbolDo.As(alias).(*gen.DO))
+	return s.updateTableName(alias)
+}
+
+func (s *symbol) updateTableName(table string) *symbol {
+	s.ALL = field.NewAsterisk(table)
+	s.ID = field.NewInt64(table, "id")
+	s.RepositoryID = field.NewString(table, "repository_id")
+	s.CommitID = field.NewString(table, "commit_id")
+	s.CommitName = field.NewString(table, "commit_name")
+	s.Kind = field.NewString(table, "kind")
+	s.Name = field.NewString(table, "name")
+	s.FullName = field.NewString(table, "full_name")
+	s.PackageName = field.NewString(table, "package_name")
+	s.FilePath = field.NewString(table, "file_path")
+	s.StartLine = field.NewInt32(table, "start_line")
+	s.StartColumn = field.NewInt32(table, "start_column")
+	s.EndLine = field.NewInt32(table, "end_line")
+	s.EndColumn = field.NewInt32(table, "end_column")
+	s.CreatedTime = field.NewTime(table, "created_time")
+
+	s.fillFieldMap()
+
+	return s
+}
+
+func (s *symbol) GetFieldByName(fieldName string) (field.OrderExpr, bool) {
+	_f, ok := s.fieldMap[fieldName]
+	if !ok || _f == nil {
+		return nil, false
+	}
+	_oe, ok := _f.(field.OrderExpr)
+	return _oe, ok
+}
+
+func (s *symbol) fillFieldMap() {
+	s.fieldMap = make(map[string]field.Expr, 14)
+	s.fieldMap["id"] = s.ID
+	s.fieldMap["repository_id"] = s.RepositoryID
+	s.fieldMap["commit_id"] = s.CommitID
+	s.fieldMap["commit_name"] = s.CommitName
+	s.fieldMap["kind"] = s.Kind
+	s.fieldMap["name"] = s.Name
+	s.fieldMap["full_name"] = s.FullName
+	s.fieldMap["package_name"] = s.PackageName
+	s.fieldMap["file_path"] = s.FilePath
+	s.fieldMap["start_line"] = s.StartLine
+	s.fieldMap["start_column"] = s.StartColumn
+	s.fieldMap["end_line"] = s.EndLine
+	s.fieldMap["end_column"] = s.EndColumn
+	s.fieldMap["created_time"] = s.CreatedTime
+}
+
+func (s symbol) clone(db *gorm.DB) symbol {
+	s.symbolDo.ReplaceConnPool(db.Statement.ConnPool)
+	return s
+}
+
+func (s symbol) replaceDB(db *gorm.DB) symbol {
+	s.symbolDo.ReplaceDB(db)
+	return s
+}
+
+type symbolDo struct{ gen.DO }
+
+type ISymbolDo interface {
+	gen.SubQuery
+	Debug() ISymbolDo
+	WithContext(ctx context.Context) ISymbolDo
+	WithResult(fc func(tx gen.Dao)) gen.ResultInfo
+	ReplaceDB(db *gorm.DB)
+	ReadDB() ISymbolDo
+	WriteDB() ISymbolDo
+	As(alias string) gen.Dao
+	Session(config *gorm.Session) ISymbolDo
+	Columns(cols ...field.Expr) gen.Columns
+	Clauses(conds ...clause.Expression) ISymbolDo
+	Not(conds ...gen.Condition) ISymbolDo
+	Or(conds ...gen.Condition) ISymbolDo
+	Select(conds ...field.Expr) ISymbolDo
+	Where(conds ...gen.Condition) ISymbolDo
+	Order(conds ...field.Expr) ISymbolDo
+	Distinct(cols ...field.Expr) ISymbolDo
+	Omit(cols ...field.Expr) ISymbolDo
+	Join(table schema.Tabler, on ...field.Expr) ISymbolDo
+	LeftJoin(table schema.Tabler, on ...field.Expr) ISymbolDo
+	RightJoin(table schema.Tabler, on ...field.Expr) ISymbolDo
+	Group(cols ...field.Expr) ISymbolDo
+	Having(conds ...gen.Condition) ISymbolDo
+	Limit(limit int) ISymbolDo
+	Offset(offset int) ISymbolDo
+	Count() (count int64, err error)
+	Scopes(funcs ...func(gen.Dao) gen.Dao) ISymbolDo
+	Unscoped() ISymbolDo
+	Create(values ...*model.Symbol) error
+	CreateInBatches(values []*model.Symbol, batchSize int) error
+	Save(values ...*model.Symbol) error
+	First() (*model.Symbol, error)
+	Take() (*model.Symbol, error)
+	Last() (*model.Symbol, error)
+	Find() ([]*model.Symbol, error)
+	FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.Symbol, err error)
+	FindInBatches(result *[]*model.Symbol, batchSize int, fc func(tx gen.Dao, batch int) error) error
+	Pluck(column field.Expr, dest interface{}) error
+	Delete(...*model.Symbol) (info gen.ResultInfo, err error)
+	Update(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	Updates(value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumn(column field.Expr, value interface{}) (info gen.ResultInfo, err error)
+	UpdateColumnSimple(columns ...field.AssignExpr) (info gen.ResultInfo, err error)
+	UpdateColumns(value interface{}) (info gen.ResultInfo, err error)
+	UpdateFrom(q gen.SubQuery) gen.Dao
+	Attrs(attrs ...field.AssignExpr) ISymbolDo
+	Assign(attrs ...field.AssignExpr) ISymbolDo
+	Joins(fields ...field.RelationField) ISymbolDo
+	Preload(fields ...field.RelationField) ISymbolDo
+	FirstOrInit() (*model.Symbol, error)
+	FirstOrCreate() (*model.Symbol, error)
+	FindByPage(offset int, limit int) (result []*model.Symbol, count int64, err error)
+	ScanByPage(result interface{}, offset int, limit int) (count int64, err error)
+	Scan(result interface{}) (err error)
+	Returning(value interface{}, columns ...string) ISymbolDo
+	UnderlyingDB() *gorm.DB
+	schema.Tabler
+}
+
+func (s symbolDo) Debug() ISymbolDo {
+	return s.withDO(s.DO.Debug())
+}
+
+func (s symbolDo) WithContext(ctx context.Context) ISymbolDo {
+	return s.withDO(s.DO.WithContext(ctx))
+}
+
+func (s symbolDo) ReadDB() ISymbolDo {
+	return s.Clauses(dbresolver.Read)
+}
+
+func (s symbolDo) WriteDB() ISymbolDo {
+	return s.Clauses(dbresolver.Write)
+}
+
+func (s symbolDo) Session(config *gorm.Session) ISymbolDo {
+	return s.withDO(s.DO.Session(config))
+}
+
+func (s symbolDo) Clauses(conds ...clause.Expression) ISymbolDo {
+	return s.withDO(s.DO.Clauses(conds...))
+}
+
+func (s symbolDo) Returning(value interface{}, columns ...string) ISymbolDo {
+	return s.withDO(s.DO.Returning(value, columns...))
+}
+
+func (s symbolDo) Not(conds ...gen.Condition) ISymbolDo {
+	return s.withDO(s.DO.Not(conds...))
+}
+
+func (s symbolDo) Or(conds ...gen.Condition) ISymbolDo {
+	return s.withDO(s.DO.Or(conds...))
+}
+
+func (s symbolDo) Select(conds ...field.Expr) ISymbolDo {
+	return s.withDO(s.DO.Select(conds...))
+}
+
+func (s symbolDo) Where(conds ...gen.Condition) ISymbolDo {
+	return s.withDO(s.DO.Where(conds...))
+}
+
+func (s symbolDo) Exists(subquery interface{ UnderlyingDB() *gorm.DB }) ISymbolDo {
+	return s.Where(field.CompareSubQuery(field.ExistsOp, nil, subquery.UnderlyingDB()))
+}
+
+func (s symbolDo) Order(conds ...field.Expr) ISymbolDo {
+	return s.withDO(s.DO.Order(conds...))
+}
+
+func (s symbolDo) Distinct(cols ...field.Expr) ISymbolDo {
+	return s.withDO(s.DO.Distinct(cols...))
+}
+
+func (s symbolDo) Omit(cols ...field.Expr) ISymbolDo {
+	return s.withDO(s.DO.Omit(cols...))
+}
+
+func (s symbolDo) Join(table schema.Tabler, on ...field.Expr) ISymbolDo {
+	return s.withDO(s.DO.Join(table, on...))
+}
+
+func (s symbolDo) LeftJoin(table schema.Tabler, on ...field.Expr) ISymbolDo {
+	return s.withDO(s.DO.LeftJoin(table, on...))
+}
+
+func (s symbolDo) RightJoin(table schema.Tabler, on ...field.Expr) ISymbolDo {
+	return s.withDO(s.DO.RightJoin(table, on...))
+}
+
+func (s symbolDo) Group(cols ...field.Expr) ISymbolDo {
+	return s.withDO(s.DO.Group(cols...))
+}
+
+func (s symbolDo) Having(conds ...gen.Condition) ISymbolDo {
+	return s.withDO(s.DO.Having(conds...))
+}
+
+func (s symbolDo) Limit(limit int) ISymbolDo {
+	return s.withDO(s.DO.Limit(limit))
+}
+
+func (s symbolDo) Offset(offset int) ISymbolDo {
+	return s.withDO(s.DO.Offset(offset))
+}
+
+func (s symbolDo) Scopes(funcs ...func(gen.Dao) gen.Dao) ISymbolDo {
+	return s.withDO(s.DO.Scopes(funcs...))
+}
+
+func (s symbolDo) Unscoped() ISymbolDo {
+	return s.withDO(s.DO.Unscoped())
+}
+
+func (s symbolDo) Create(values ...*model.Symbol) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return s.DO.Create(values)
+}
+
+func (s symbolDo) CreateInBatches(values []*model.Symbol, batchSize int) error {
+	return s.DO.CreateInBatches(values, batchSize)
+}
+
+// Save : !!! underlying implementation is different with GORM
+// The method is equivalent to executing the statement: db.Clauses(clause.OnConflict{UpdateAll: true}).Create(values)
+func (s symbolDo) Save(values ...*model.Symbol) error {
+	if len(values) == 0 {
+		return nil
+	}
+	return s.DO.Save(values)
+}
+
+func (s symbolDo) First() (*model.Symbol, error) {
+	if result, err := s.DO.First(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Symbol), nil
+	}
+}
+
+func (s symbolDo) Take() (*model.Symbol, error) {
+	if result, err := s.DO.Take(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Symbol), nil
+	}
+}
+
+func (s symbolDo) Last() (*model.Symbol, error) {
+	if result, err := s.DO.Last(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Symbol), nil
+	}
+}
+
+func (s symbolDo) Find() ([]*model.Symbol, error) {
+	result, err := s.DO.Find()
+	return result.([]*model.Symbol), err
+}
+
+func (s symbolDo) FindInBatch(batchSize int, fc func(tx gen.Dao, batch int) error) (results []*model.Symbol, err error) {
+	buf := make([]*model.Symbol, 0, batchSize)
+	err = s.DO.FindInBatches(&buf, batchSize, func(tx gen.Dao, batch int) error {
+		defer func() { results = append(results, buf...) }()
+		return fc(tx, batch)
+	})
+	return results, err
+}
+
+func (s symbolDo) FindInBatches(result *[]*model.Symbol, batchSize int, fc func(tx gen.Dao, batch int) error) error {
+	return s.DO.FindInBatches(result, batchSize, fc)
+}
+
+func (s symbolDo) Attrs(attrs ...field.AssignExpr) ISymbolDo {
+	return s.withDO(s.DO.Attrs(attrs...))
+}
+
+func (s symbolDo) Assign(attrs ...field.AssignExpr) ISymbolDo {
+	return s.withDO(s.DO.Assign(attrs...))
+}
+
+func (s symbolDo) Joins(fields ...field.RelationField) ISymbolDo {
+	for _, _f := range fields {
+		s = *s.withDO(s.DO.Joins(_f))
+	}
+	return &s
+}
+
+func (s symbolDo) Preload(fields ...field.RelationField) ISymbolDo {
+	for _, _f := range fields {
+		s = *s.withDO(s.DO.Preload(_f))
+	}
+	return &s
+}
+
+func (s symbolDo) FirstOrInit() (*model.Symbol, error) {
+	if result, err := s.DO.FirstOrInit(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Symbol), nil
+	}
+}
+
+func (s symbolDo) FirstOrCreate() (*model.Symbol, error) {
+	if result, err := s.DO.FirstOrCreate(); err != nil {
+		return nil, err
+	} else {
+		return result.(*model.Symbol), nil
+	}
+}
+
+func (s symbolDo) FindByPage(offset int, limit int) (result []*model.Symbol, count int64, err error) {
+	result, err = s.Offset(offset).Limit(limit).Find()
+	if err != nil {
+		return
+	}
+
+	if size := len(result); 0 < limit && 0 < size && size < limit {
+		count = int64(size + offset)
+		return
+	}
+
+	count, err = s.Offset(-1).Limit(-1).Count()
+	return
+}
+
+func (s symbolDo) ScanByPage(result interface{}, offset int, limit int) (count int64, err error) {
+	count, err = s.Count()
+	if err != nil {
+		return
+	}
+
+	err = s.Offset(offset).Limit(limit).Scan(result)
+	return
+}
+
+func (s symbolDo) Scan(result interface{}) (err error) {
+	return s.DO.Scan(result)
+}
+
+func (s symbolDo) Delete(models ...*model.Symbol) (result gen.ResultInfo, err error) {
+	return s.DO.Delete(models)
+}
+
+func (s *symbolDo) withDO(do gen.Dao) *symbolDo {
+	s.DO = *do.(*gen.DO)
+	return s
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+)
+
+type ResolveRepositoryReferenceRequest struct {
+	RepositoryOwner string `uri:"repository_owner" json:"repository_owner"`
+	RepositoryName  string `uri:"repository_name" json:"repository_name"`
+	Reference       string `uri:"reference" json:"reference"` // 支持 branch: commit: tag: draft: 前缀
+}
+
+type ResolveRepositoryReferenceResponse struct {
+	ReferenceKind    string                             `json:"reference_kind"` // 匹配到的reference类型 branch/commit/tag/draft
+	RepositoryCommit *registryv1alpha1.RepositoryCommit `json:"repository_commit"`
+	Warnings         []string                           `json:"warnings,omitempty"` // push时依赖检查产生的警告
+}
+
+type VerifyLockRequest struct {
+	Lock   string `json:"lock"`   // buf.lock文件内容
+	Config string `json:"config"` // buf.yaml文件内容，为空时不检查pin与依赖是否一致
+}
+
+type LockPinResult struct {
+	Remote     string `json:"remote"`
+	Owner      string `json:"owner"`
+	Repository string `json:"repository"`
+	Commit     string `json:"commit"`
+	Digest     string `json:"digest,omitempty"`
+	Status     string `json:"status"` // ok/not_found/permission_denied/digest_mismatch/not_in_config/commit_mismatch
+	Message    string `json:"message,omitempty"`
+}
+
+type VerifyLockResponse struct {
+	Valid   bool             `json:"valid"`
+	Pins    []*LockPinResult `json:"pins"`
+	Missing []string         `json:"missing,omitempty"` // buf.yaml的依赖中需要，但是没有被锁定的模块
+}
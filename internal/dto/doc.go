@@ -0,0 +1,137 @@
+package dto
+
+import (
+	"time"
+)
+
+type ExportDocumentationRequest struct {
+	RepositoryOwner string `uri:"repository_owner" json:"repository_owner"`
+	RepositoryName  string `uri:"repository_name" json:"repository_name"`
+	Reference       string `uri:"reference" json:"reference"`
+	Format          string `form:"format" json:"format"`   // markdown(默认)、html
+	Archive         string `form:"archive" json:"archive"` // zip(默认)、tar.gz
+}
+
+type ExportDocumentationResponse struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+type ExportSchemaRequest struct {
+	RepositoryOwner string `uri:"repository_owner" json:"repository_owner"`
+	RepositoryName  string `uri:"repository_name" json:"repository_name"`
+	Reference       string `uri:"reference" json:"reference"`
+	PackageName     string `uri:"package_name" json:"package_name"`
+	Format          string `form:"format" json:"format"` // openapi(默认)、jsonschema
+}
+
+type ExportSchemaResponse struct {
+	FileName string
+	Content  []byte
+}
+
+type GetSourceDirectoryRequest struct {
+	RepositoryOwner string `uri:"repository_owner" json:"repository_owner"`
+	RepositoryName  string `uri:"repository_name" json:"repository_name"`
+	Reference       string `uri:"reference" json:"reference"`
+	Path            string `uri:"path" json:"path"` // 目录路径，为空时返回整个目录树
+}
+
+type DownloadSourceFileRequest struct {
+	RepositoryOwner string `uri:"repository_owner" json:"repository_owner"`
+	RepositoryName  string `uri:"repository_name" json:"repository_name"`
+	Reference       string `uri:"reference" json:"reference"`
+	Path            string `uri:"path" json:"path"`
+}
+
+type DownloadSourceFileResponse struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+type GetDefinitionRequest struct {
+	RepositoryOwner string `uri:"repository_owner" json:"repository_owner"`
+	RepositoryName  string `uri:"repository_name" json:"repository_name"`
+	Reference       string `uri:"reference" json:"reference"`
+	Path            string `uri:"path" json:"path"`
+	Line            int    `form:"line" json:"line"`     // 从0开始
+	Column          int    `form:"column" json:"column"` // 从0开始
+}
+
+type GetDefinitionResponse struct {
+	Kind            string          `json:"kind"`
+	Name            string          `json:"name"`
+	FullName        string          `json:"full_name"`
+	PackageName     string          `json:"package_name"`
+	Remote          string          `json:"remote"` // 定义在google/protobuf中时为空
+	RepositoryOwner string          `json:"repository_owner"`
+	RepositoryName  string          `json:"repository_name"`
+	CommitName      string          `json:"commit_name"`
+	FilePath        string          `json:"file_path"`
+	Location        *SymbolLocation `json:"location"`
+}
+
+type ListReferencesRequest struct {
+	RepositoryOwner string `uri:"repository_owner" json:"repository_owner"`
+	RepositoryName  string `uri:"repository_name" json:"repository_name"`
+	Reference       string `uri:"reference" json:"reference"`
+	Symbol          string `form:"symbol" json:"symbol"` // 类型全名，例如 acme.weather.v1.Weather
+}
+
+type SymbolReference struct {
+	FilePath string          `json:"file_path"`
+	Location *SymbolLocation `json:"location"`
+}
+
+type ListReferencesResponse struct {
+	References []*SymbolReference `json:"references"`
+}
+
+type GetBlameRequest struct {
+	RepositoryOwner string `uri:"repository_owner" json:"repository_owner"`
+	RepositoryName  string `uri:"repository_name" json:"repository_name"`
+	Reference       string `uri:"reference" json:"reference"`
+	Path            string `uri:"path" json:"path"`
+}
+
+type BlameLine struct {
+	Line        int       `json:"line"` // 从1开始
+	Content     string    `json:"content"`
+	CommitName  string    `json:"commit_name"`
+	Author      string    `json:"author"`
+	CreatedTime time.Time `json:"created_time"`
+}
+
+type GetBlameResponse struct {
+	CommitName string       `json:"commit_name"`
+	Path       string       `json:"path"`
+	Lines      []*BlameLine `json:"lines"`
+}
+
+type GetFileHistoryRequest struct {
+	RepositoryOwner string `uri:"repository_owner" json:"repository_owner"`
+	RepositoryName  string `uri:"repository_name" json:"repository_name"`
+	Reference       string `uri:"reference" json:"reference"`
+	Path            string `uri:"path" json:"path"`
+	PageSize        uint32 `form:"page_size" json:"page_size"`
+	PageToken       string `form:"page_token" json:"page_token"`
+	Reverse         bool   `form:"reverse" json:"reverse"` // 为true时从新到旧排列
+}
+
+type FileChange struct {
+	CommitName  string    `json:"commit_name"`
+	Author      string    `json:"author"`
+	CreatedTime time.Time `json:"created_time"`
+	ChangeType  string    `json:"change_type"` // added/modified/deleted/renamed
+	OldPath     string    `json:"old_path,omitempty"`
+	NewPath     string    `json:"new_path,omitempty"`
+	OldDigest   string    `json:"old_digest,omitempty"`
+	NewDigest   string    `json:"new_digest,omitempty"`
+}
+
+type GetFileHistoryResponse struct {
+	Changes       []*FileChange `json:"changes"`
+	NextPageToken string        `json:"next_page_token"`
+}
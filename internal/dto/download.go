@@ -0,0 +1,22 @@
+package dto
+
+import (
+	"github.com/ProtobufMan/bufman/internal/core/archive"
+	"time"
+)
+
+type DownloadArchiveRequest struct {
+	RepositoryOwner     string `uri:"repository_owner" json:"repository_owner"`
+	RepositoryName      string `uri:"repository_name" json:"repository_name"`
+	Reference           string `uri:"reference" json:"reference"`
+	Format              string `form:"format" json:"format"`                             // tar.gz(默认)、zip
+	IncludeDependencies bool   `form:"include_dependencies" json:"include_dependencies"` // 是否包含所有依赖的proto文件
+}
+
+type DownloadArchiveResponse struct {
+	FileName    string
+	Format      string
+	ContentType string
+	ModTime     time.Time
+	Files       []*archive.File
+}
@@ -0,0 +1,25 @@
+package dto
+
+type GetImageRequest struct {
+	RepositoryOwner   string   `uri:"repository_owner" json:"repository_owner"`
+	RepositoryName    string   `uri:"repository_name" json:"repository_name"`
+	Reference         string   `uri:"reference" json:"reference"`
+	Format            string   `form:"format" json:"format"`                           // binary(默认)、json
+	ExcludeImports    bool     `form:"exclude_imports" json:"exclude_imports"`         // 不包含依赖的文件
+	ExcludeSourceInfo bool     `form:"exclude_source_info" json:"exclude_source_info"` // 不包含source code info
+	Types             []string `form:"type" json:"types"`                              // 只包含这些类型以及它们依赖的类型，例如 acme.weather.v1.WeatherService
+}
+
+type GetImageResponse struct {
+	FileName    string
+	ContentType string
+	Content     []byte
+}
+
+type GetFileDescriptorSetRequest struct {
+	RepositoryOwner string   `json:"repository_owner"`
+	RepositoryName  string   `json:"repository_name"`
+	Reference       string   `json:"reference"` // 为空时使用main
+	Symbols         []string `json:"symbols"`   // message、enum、service、method等的全名，例如 acme.weather.v1.Weather
+	Format          string   `json:"format"`    // binary(默认)、json
+}
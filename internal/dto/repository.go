@@ -0,0 +1,114 @@
+package dto
+
+import (
+	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+)
+
+type ForkRepositoryRequest struct {
+	RepositoryOwner string `json:"repository_owner"` // 上游仓库所属用户
+	RepositoryName  string `json:"repository_name"`  // 上游仓库名
+	Name            string `json:"name"`             // fork后的仓库名，为空时与上游仓库同名
+}
+
+type ForkRepositoryResponse struct {
+	Repository *registryv1alpha1.Repository `json:"repository"`
+}
+
+type CompareRepositoryWithUpstreamRequest struct {
+	RepositoryOwner string `uri:"repository_owner" json:"repository_owner"`
+	RepositoryName  string `uri:"repository_name" json:"repository_name"`
+}
+
+type CompareRepositoryWithUpstreamResponse struct {
+	Upstream      *registryv1alpha1.Repository         `json:"upstream"`
+	AheadBy       int                                  `json:"ahead_by"`       // fork中有，上游没有的commit数量
+	BehindBy      int                                  `json:"behind_by"`      // 上游中有，fork没有的commit数量
+	AheadCommits  []*registryv1alpha1.RepositoryCommit `json:"ahead_commits"`  // fork领先上游的commits
+	BehindCommits []*registryv1alpha1.RepositoryCommit `json:"behind_commits"` // fork落后上游的commits
+}
+
+type RenameRepositoryRequest struct {
+	RepositoryOwner string `json:"repository_owner"`
+	RepositoryName  string `json:"repository_name"`
+	NewName         string `json:"new_name"` // 新的仓库名
+}
+
+type RenameRepositoryResponse struct {
+	Repository *registryv1alpha1.Repository `json:"repository"`
+}
+
+type TransferRepositoryRequest struct {
+	RepositoryOwner string `json:"repository_owner"`
+	RepositoryName  string `json:"repository_name"`
+	NewOwner        string `json:"new_owner"` // 转移到的用户名
+}
+
+type TransferRepositoryResponse struct {
+	Repository   *registryv1alpha1.Repository `json:"repository"`
+	PendingOwner string                       `json:"pending_owner"` // 等待接受转移的用户名，接受之后仓库才会转移
+}
+
+type AcceptRepositoryTransferRequest struct {
+	RepositoryOwner string `json:"repository_owner"`
+	RepositoryName  string `json:"repository_name"`
+}
+
+type AcceptRepositoryTransferResponse struct {
+	Repository *registryv1alpha1.Repository `json:"repository"`
+}
+
+type ListRepositoryDependentsRequest struct {
+	RepositoryOwner string `json:"repository_owner"`
+	RepositoryName  string `json:"repository_name"`
+	Reference       string `json:"reference"`  // 为空时查询依赖仓库任意commit的仓库
+	Transitive      bool   `json:"transitive"` // 是否包括间接依赖
+	PageSize        uint32 `json:"page_size"`
+	PageToken       string `json:"page_token"`
+}
+
+type RepositoryDependent struct {
+	Repository  *registryv1alpha1.Repository `json:"repository"`
+	Depth       int                          `json:"depth"`        // 1表示直接依赖，大于1表示间接依赖
+	CommitNames []string                     `json:"commit_names"` // 仓库中依赖目标的commit
+}
+
+type ListRepositoryDependentsResponse struct {
+	Dependents    []*RepositoryDependent `json:"dependents"`
+	NextPageToken string                 `json:"next_page_token"`
+}
+
+type GetDependencyGraphRequest struct {
+	RepositoryOwner string `uri:"repository_owner" json:"repository_owner"`
+	RepositoryName  string `uri:"repository_name" json:"repository_name"`
+	Reference       string `form:"reference" json:"reference"` // 为空时使用默认分支
+	Format          string `form:"format" json:"format"`       // json(默认)、dot、mermaid
+}
+
+type DependencyGraphNode struct {
+	Identity   string   `json:"identity"` // remote/owner/repository
+	CommitName string   `json:"commit_name"`
+	Tags       []string `json:"tags"`
+}
+
+type DependencyGraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type GetDependencyGraphResponse struct {
+	Format  string                 `json:"format"`
+	Root    string                 `json:"root"`
+	Nodes   []*DependencyGraphNode `json:"nodes,omitempty"`
+	Edges   []*DependencyGraphEdge `json:"edges,omitempty"`
+	Content string                 `json:"content,omitempty"` // dot、mermaid格式渲染的结果
+}
+
+type UpdateRepositoryDependencyPolicyRequest struct {
+	RepositoryOwner    string `json:"repository_owner"`
+	RepositoryName     string `json:"repository_name"`
+	StrictDependencies bool   `json:"strict_dependencies"` // 开启后push时存在未使用的依赖或者未声明的依赖会直接返回错误
+}
+
+type UpdateRepositoryDependencyPolicyResponse struct {
+	Repository *registryv1alpha1.Repository `json:"repository"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+type SearchSymbolRequest struct {
+	Query           string `json:"query"`                      // 匹配符号名称或者全名
+	Kind            string `json:"kind,omitempty"`             // message/field/enum/service/method，为空时不过滤
+	PackageName     string `json:"package_name,omitempty"`     // 为空时不过滤
+	RepositoryOwner string `json:"repository_owner,omitempty"` // 与repository_name同时设置时只搜索该仓库
+	RepositoryName  string `json:"repository_name,omitempty"`
+	PageSize        uint32 `json:"page_size"`
+	PageToken       string `json:"page_token"`
+	Reverse         bool   `json:"reverse"`
+}
+
+type SymbolLocation struct {
+	StartLine   int32 `json:"start_line"`
+	StartColumn int32 `json:"start_column"`
+	EndLine     int32 `json:"end_line"`
+	EndColumn   int32 `json:"end_column"`
+}
+
+type SymbolSearchResult struct {
+	Kind            string          `json:"kind"`
+	Name            string          `json:"name"`
+	FullName        string          `json:"full_name"`
+	PackageName     string          `json:"package_name"`
+	RepositoryOwner string          `json:"repository_owner"`
+	RepositoryName  string          `json:"repository_name"`
+	CommitName      string          `json:"commit_name"`
+	FilePath        string          `json:"file_path"`
+	Location        *SymbolLocation `json:"location"`
+}
+
+type SearchSymbolResponse struct {
+	Symbols       []*SymbolSearchResult `json:"symbols"`
+	NextPageToken string                `json:"next_page_token"`
+}
@@ -81,3 +81,14 @@ func NewInvalidArgumentError(object string) *InvalidArgumentError {
 		NewBaseResponseError(msg, connect.CodeInvalidArgument),
 	}
 }
+
+type FailedPreconditionError struct {
+	*BaseResponseError
+}
+
+func NewFailedPreconditionError(reason string) *FailedPreconditionError {
+	msg := fmt.Sprintf("failed precondition: %s", reason)
+	return &FailedPreconditionError{
+		NewBaseResponseError(msg, connect.CodeFailedPrecondition),
+	}
+}
@@ -3,6 +3,7 @@ package grpc_handlers
 import (
 	"context"
 	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufconfig"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
 	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
 	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
 	"github.com/ProtobufMan/bufman/internal/constant"
@@ -10,29 +11,52 @@ import (
 	"github.com/ProtobufMan/bufman/internal/core/parser"
 	"github.com/ProtobufMan/bufman/internal/core/resolve"
 	"github.com/ProtobufMan/bufman/internal/core/storage"
+	"github.com/ProtobufMan/bufman/internal/core/taskqueue"
 	"github.com/ProtobufMan/bufman/internal/core/validity"
 	"github.com/ProtobufMan/bufman/internal/e"
 	"github.com/ProtobufMan/bufman/internal/model"
 	"github.com/ProtobufMan/bufman/internal/services"
 	"github.com/bufbuild/connect-go"
 	"io"
+	"strings"
+	"sync"
 )
 
+var (
+	docsQueue     *taskqueue.Queue
+	docsQueueOnce sync.Once
+)
+
+// getDocsQueue push之后生成包文档的后台队列，所有handler共用
+func getDocsQueue() *taskqueue.Queue {
+	docsQueueOnce.Do(func() {
+		docsQueue = taskqueue.NewQueue(constant.DocsWorkers, constant.DocsQueueSize, constant.DocsMaxRetries, constant.DocsRetryInterval)
+	})
+
+	return docsQueue
+}
+
 type PushServiceHandler struct {
-	pushService   services.PushService
-	validator     validity.Validator
-	resolver      resolve.Resolver
-	storageHelper storage.StorageHelper
-	protoParser   parser.ProtoParser
+	pushService       services.PushService
+	repositoryService services.RepositoryService
+	docsService       services.DocsService
+	validator         validity.Validator
+	resolver          resolve.Resolver
+	storageHelper     storage.StorageHelper
+	protoParser       parser.ProtoParser
+	docsQueue         *taskqueue.Queue
 }
 
 func NewPushServiceHandler() *PushServiceHandler {
 	return &PushServiceHandler{
-		pushService:   services.NewPushService(),
-		validator:     validity.NewValidator(),
-		resolver:      resolve.NewResolver(),
-		storageHelper: storage.NewStorageHelper(),
-		protoParser:   parser.NewProtoParser(),
+		pushService:       services.NewPushService(),
+		repositoryService: services.NewRepositoryService(),
+		docsService:       services.NewDocsService(),
+		validator:         validity.NewValidator(),
+		resolver:          resolve.NewResolver(services.NewAuthorizationService()),
+		storageHelper:     storage.NewStorageHelper(),
+		protoParser:       parser.NewProtoParser(),
+		docsQueue:         getDocsQueue(),
 	}
 }
 
@@ -84,8 +108,11 @@ func (handler *PushServiceHandler) PushManifestAndBlobs(ctx context.Context, req
 		return nil, connect.NewError(configErr.Code(), configErr)
 	}
 
+	var dependentIdentities []bufmoduleref.ModuleIdentity
+	var dependentCommitNames []string
 	var dependentManifests []*manifest.Manifest
 	var dependentBlobSets []*manifest.BlobSet
+	var dependentCommits, directDependentCommits model.Commits
 	if bufConfigBlob != nil {
 		// 生成Config
 		reader, err := bufConfigBlob.Open(ctx)
@@ -112,19 +139,30 @@ func (handler *PushServiceHandler) PushManifestAndBlobs(ctx context.Context, req
 			return nil, connect.NewError(respErr.Code(), respErr)
 		}
 
-		// 获取全部依赖commits
-		dependentCommits, dependenceErr := handler.resolver.GetAllDependenciesFromBufConfig(ctx, bufConfig)
+		// 获取全部依赖commits，以及冲突处理后选中的直接依赖，用于记录依赖关系
+		allCommits, directCommits, dependenceErr := handler.resolver.GetDependenciesFromBufConfig(ctx, bufConfig)
 		if dependenceErr != nil {
-			logger.Errorf("Error get all dependencies: %v\n", dependenceErr.Error())
+			logger.Errorf("Error get dependencies: %v\n", dependenceErr.Error())
 
 			return nil, connect.NewError(dependenceErr.Code(), dependenceErr)
 		}
+		dependentCommits, directDependentCommits = allCommits, directCommits
 
 		// 读取依赖文件
+		dependentIdentities = make([]bufmoduleref.ModuleIdentity, 0, len(dependentCommits))
+		dependentCommitNames = make([]string, 0, len(dependentCommits))
 		dependentManifests = make([]*manifest.Manifest, 0, len(dependentCommits))
 		dependentBlobSets = make([]*manifest.BlobSet, 0, len(dependentCommits))
 		for i := 0; i < len(dependentCommits); i++ {
 			dependentCommit := dependentCommits[i]
+			dependentRemote, dependentOwner := dependentCommit.RemoteAndOwner()
+			dependentIdentity, identityErr := bufmoduleref.NewModuleIdentity(dependentRemote, dependentOwner, dependentCommit.RepositoryName)
+			if identityErr != nil {
+				logger.Errorf("Error get module identity: %v\n", identityErr.Error())
+
+				respErr := e.NewInternalError(identityErr.Error())
+				return nil, connect.NewError(respErr.Code(), respErr)
+			}
 			dependentManifest, dependentBlobSet, getErr := handler.pushService.GetManifestAndBlobSet(ctx, dependentCommit.RepositoryID, dependentCommit.CommitName)
 			if getErr != nil {
 				logger.Errorf("Error get manifest and blob set: %v\n", getErr.Error())
@@ -132,28 +170,52 @@ func (handler *PushServiceHandler) PushManifestAndBlobs(ctx context.Context, req
 				return nil, connect.NewError(getErr.Code(), getErr)
 			}
 
+			dependentIdentities = append(dependentIdentities, dependentIdentity)
+			dependentCommitNames = append(dependentCommitNames, dependentCommit.CommitName)
 			dependentManifests = append(dependentManifests, dependentManifest)
 			dependentBlobSets = append(dependentBlobSets, dependentBlobSet)
 		}
 	}
 
-	// 编译检查
-	compileErr := handler.protoParser.TryCompile(ctx, fileManifest, blobSet, dependentManifests, dependentBlobSets)
+	// 编译检查，同时检查未使用的依赖和只通过间接依赖引入的import
+	declaredDependencies := make([]string, 0, len(directDependentCommits))
+	for i := 0; i < len(directDependentCommits); i++ {
+		declaredDependencies = append(declaredDependencies, directDependentCommits[i].IdentityString())
+	}
+	dependencyReport, compileErr := handler.protoParser.CheckDependencies(ctx, fileManifest, blobSet, declaredDependencies, dependentIdentities, dependentCommitNames, dependentManifests, dependentBlobSets)
 	if compileErr != nil {
 		logger.Errorf("Error try to compile proto: %v\n", compileErr.Error())
 
 		return nil, connect.NewError(compileErr.Code(), compileErr)
 	}
+	var warnings []string
+	if dependencyReport.HasIssues() {
+		repository, repositoryErr := handler.repositoryService.GetRepositoryByUserNameAndRepositoryName(ctx, req.Msg.GetOwner(), req.Msg.GetRepository())
+		if repositoryErr != nil {
+			logger.Errorf("Error get repository: %v\n", repositoryErr.Error())
+
+			return nil, connect.NewError(repositoryErr.Code(), repositoryErr)
+		}
+
+		warnings = dependencyReport.Warnings()
+		if repository.StrictDependencies {
+			// 仓库开启了严格依赖检查
+			policyErr := e.NewFailedPreconditionError(strings.Join(warnings, "; "))
+			logger.Errorf("Error check dependencies: %v\n", policyErr.Error())
+
+			return nil, connect.NewError(policyErr.Code(), policyErr)
+		}
+	}
 
 	var commit *model.Commit
 	var serviceErr e.ResponseError
 	userID := ctx.Value(constant.UserIDKey).(string)
 	if req.Msg.DraftName != "" {
-		commit, serviceErr = handler.pushService.PushManifestAndBlobsWithDraft(ctx, userID, req.Msg.GetOwner(), req.Msg.GetRepository(), fileManifest, blobSet, req.Msg.GetDraftName())
+		commit, serviceErr = handler.pushService.PushManifestAndBlobsWithDraft(ctx, userID, req.Msg.GetOwner(), req.Msg.GetRepository(), fileManifest, blobSet, dependentCommits, directDependentCommits, warnings, req.Msg.GetDraftName())
 	} else if len(req.Msg.GetTags()) > 0 {
-		commit, serviceErr = handler.pushService.PushManifestAndBlobsWithTags(ctx, userID, req.Msg.GetOwner(), req.Msg.GetRepository(), fileManifest, blobSet, req.Msg.GetTags())
+		commit, serviceErr = handler.pushService.PushManifestAndBlobsWithTags(ctx, userID, req.Msg.GetOwner(), req.Msg.GetRepository(), fileManifest, blobSet, dependentCommits, directDependentCommits, warnings, req.Msg.GetTags())
 	} else {
-		commit, serviceErr = handler.pushService.PushManifestAndBlobs(ctx, userID, req.Msg.GetOwner(), req.Msg.GetRepository(), fileManifest, blobSet)
+		commit, serviceErr = handler.pushService.PushManifestAndBlobs(ctx, userID, req.Msg.GetOwner(), req.Msg.GetRepository(), fileManifest, blobSet, dependentCommits, directDependentCommits, warnings)
 	}
 	if serviceErr != nil {
 		logger.Errorf("Error push: %v\n", serviceErr.Error())
@@ -161,8 +223,47 @@ func (handler *PushServiceHandler) PushManifestAndBlobs(ctx context.Context, req
 		return nil, connect.NewError(serviceErr.Code(), serviceErr.Err())
 	}
 
+	// 异步生成包文档以及符号索引，队列已满时由backfill补全
+	if !handler.docsQueue.Submit("generate package documentations "+commit.CommitName, handler.generatePackageDocumentations(userID, commit)) {
+		logger.Errorf("Error generate package documentations: queue is full, commit %s is left to the backfill\n", commit.CommitName)
+	}
+
 	resp := connect.NewResponse(&registryv1alpha1.PushManifestAndBlobsResponse{
 		LocalModulePin: commit.ToProtoLocalModulePin(),
 	})
+	// buf客户端不会展示响应头，警告同时记录在commit上，可以通过解析reference的接口查看
+	for _, warning := range warnings {
+		resp.Header().Add(constant.WarningHeader, warning)
+	}
 	return resp, nil
 }
+
+// generatePackageDocumentations 生成并保存commit中所有package的文档，失败时查询文档会退回到实时编译生成
+// 非draft的commit同时更新仓库的符号索引，两者共用同一次编译结果
+// 重试全部失败时commit没有被标记为已生成，之后由backfill补全
+func (handler *PushServiceHandler) generatePackageDocumentations(userID string, commit *model.Commit) taskqueue.Task {
+	return func(ctx context.Context) error {
+		ctx = context.WithValue(ctx, constant.UserIDKey, userID)
+		if err := handler.docsService.GeneratePackageDocumentations(ctx, commit); err != nil {
+			return toTaskError(err)
+		}
+
+		if commit.DraftName == "" {
+			if err := handler.docsService.IndexSymbols(ctx, commit); err != nil {
+				return toTaskError(err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// toTaskError 只有内部错误需要重试，参数错误、没有权限以及编译错误等重试也不会成功
+func toTaskError(err e.ResponseError) error {
+	switch err.Code() {
+	case connect.CodeInternal, connect.CodeUnknown, connect.CodeUnavailable, connect.CodeDeadlineExceeded:
+		return err
+	default:
+		return taskqueue.Permanent(err)
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/ProtobufMan/bufman-cli/private/gen/proto/connect/bufman/alpha/registry/v1alpha1/registryv1alpha1connect"
 	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+	"github.com/ProtobufMan/bufman/internal/config"
 	"github.com/ProtobufMan/bufman/internal/constant"
 	"github.com/ProtobufMan/bufman/internal/core/logger"
+	"github.com/ProtobufMan/bufman/internal/core/proxy"
 	"github.com/ProtobufMan/bufman/internal/core/resolve"
+	"github.com/ProtobufMan/bufman/internal/core/version"
 	"github.com/ProtobufMan/bufman/internal/e"
 	"github.com/ProtobufMan/bufman/internal/model"
 	"github.com/ProtobufMan/bufman/internal/services"
@@ -17,27 +20,51 @@ import (
 
 type ResolveServiceHandler struct {
 	resolver             resolve.Resolver
+	proxy                proxy.Proxy
 	authorizationService services.AuthorizationService
+	commitService        services.CommitService
+	pluginService        services.PluginService
 }
 
 func NewResolveServiceHandler() *ResolveServiceHandler {
 	return &ResolveServiceHandler{
-		resolver:             resolve.NewResolver(),
+		resolver:             resolve.NewResolver(services.NewAuthorizationService()),
+		proxy:                proxy.NewProxy(),
 		authorizationService: services.NewAuthorizationService(),
+		commitService:        services.NewCommitService(),
+		pluginService:        services.NewPluginService(),
 	}
 }
 
 func (handler *ResolveServiceHandler) GetModulePins(ctx context.Context, req *connect.Request[registryv1alpha1.GetModulePinsRequest]) (*connect.Response[registryv1alpha1.GetModulePinsResponse], error) {
 	userID, _ := ctx.Value(constant.UserIDKey).(string)
 
+	moduleReferences, bufRefErr := bufmoduleref.NewModuleReferencesForProtos(req.Msg.GetModuleReferences()...)
+	if bufRefErr != nil {
+		logger.Errorf("Error read mod ref from proto: %v\n", bufRefErr.Error())
+
+		return nil, connect.NewError(e.NewInternalError(bufRefErr.Error()).Code(), bufRefErr)
+	}
+
 	// 首先检查用户权限，是否对repo有访问权限
 	var checkErr e.ResponseError
 	repositoryMap := map[string]*model.Repository{}
-	for _, moduleReference := range req.Msg.GetModuleReferences() {
-		fullName := moduleReference.GetOwner() + "/" + moduleReference.GetRepository()
+	for _, moduleReference := range moduleReferences {
+		if moduleReference.Remote() != config.Properties.BufMan.ServerHost {
+			// 其他remote上的依赖由上游代理获取，按照上游的访问规则检查
+			checkErr = handler.proxy.CheckCanAccess(ctx, moduleReference)
+			if checkErr != nil {
+				logger.Errorf("Error check: %v\n", checkErr.Error())
+
+				return nil, connect.NewError(checkErr.Code(), checkErr.Err())
+			}
+			continue
+		}
+
+		fullName := moduleReference.Owner() + "/" + moduleReference.Repository()
 		repo, ok := repositoryMap[fullName]
 		if !ok {
-			repo, checkErr = handler.authorizationService.CheckRepositoryCanAccess(userID, moduleReference.GetOwner(), moduleReference.GetRepository(), registryv1alpha1connect.ResolveServiceGetModulePinsProcedure)
+			repo, checkErr = handler.authorizationService.CheckRepositoryCanAccess(userID, moduleReference.Owner(), moduleReference.Repository(), registryv1alpha1connect.ResolveServiceGetModulePinsProcedure)
 			if checkErr != nil {
 				logger.Errorf("Error check: %v\n", checkErr.Error())
 
@@ -48,13 +75,6 @@ func (handler *ResolveServiceHandler) GetModulePins(ctx context.Context, req *co
 		repositoryMap[fullName] = repo
 	}
 
-	moduleReferences, bufRefErr := bufmoduleref.NewModuleReferencesForProtos(req.Msg.GetModuleReferences()...)
-	if bufRefErr != nil {
-		logger.Errorf("Error read mod ref from proto: %v\n", bufRefErr.Error())
-
-		return nil, connect.NewError(e.NewInternalError(bufRefErr.Error()).Code(), bufRefErr)
-	}
-
 	// 获取所有的依赖commits
 	commits, err := handler.resolver.GetAllDependenciesFromModuleRefs(ctx, moduleReferences)
 	if err != nil {
@@ -81,9 +101,18 @@ func (handler *ResolveServiceHandler) GetModulePins(ctx context.Context, req *co
 
 		ownerName := currentModulePin.Owner()
 		repositoryName := currentModulePin.Repository()
+		if currentModulePin.Remote() != config.Properties.BufMan.ServerHost {
+			// 代理缓存的上游仓库
+			ownerName = proxy.ProxyUserName(currentModulePin.Remote(), ownerName)
+		}
+		// 仓库可能已经被重命名或者转移，尽量通过仓库ID比较
+		var currentRepositoryID string
+		if currentRepository, getErr := handler.authorizationService.CheckRepositoryCanAccess(userID, ownerName, repositoryName, registryv1alpha1connect.ResolveServiceGetModulePinsProcedure); getErr == nil {
+			currentRepositoryID = currentRepository.RepositoryID
+		}
 		for _, commit := range commits {
 			// 如果current module pin在reference的查询出的commits内，则有breaking的可能
-			if commit.UserName == ownerName && commit.RepositoryName == repositoryName {
+			if commit.RepositoryID == currentRepositoryID || (commit.UserName == ownerName && commit.RepositoryName == repositoryName) {
 				commitName := currentModulePin.Commit()
 				if commit.CommitName != commitName {
 					// 版本号不一样，存在breaking
@@ -107,21 +136,96 @@ func (handler *ResolveServiceHandler) GetModulePins(ctx context.Context, req *co
 }
 
 func (handler *ResolveServiceHandler) GetGoVersion(ctx context.Context, req *connect.Request[registryv1alpha1.GetGoVersionRequest]) (*connect.Response[registryv1alpha1.GetGoVersionResponse], error) {
-	//TODO implement me
-	panic("implement me")
+	packageVersion, respErr := handler.getPackageVersion(ctx, req.Msg.GetModuleReference(), req.Msg.GetPluginReference(), registryv1alpha1connect.ResolveServiceGetGoVersionProcedure)
+	if respErr != nil {
+		logger.Errorf("Error get package version: %v\n", respErr.Error())
+
+		return nil, connect.NewError(respErr.Code(), respErr.Err())
+	}
+
+	resp := connect.NewResponse(&registryv1alpha1.GetGoVersionResponse{
+		Version: packageVersion.GoVersion(),
+	})
+	return resp, nil
 }
 
 func (handler *ResolveServiceHandler) GetSwiftVersion(ctx context.Context, req *connect.Request[registryv1alpha1.GetSwiftVersionRequest]) (*connect.Response[registryv1alpha1.GetSwiftVersionResponse], error) {
-	//TODO implement me
-	panic("implement me")
+	packageVersion, respErr := handler.getPackageVersion(ctx, req.Msg.GetModuleReference(), req.Msg.GetPluginReference(), registryv1alpha1connect.ResolveServiceGetSwiftVersionProcedure)
+	if respErr != nil {
+		logger.Errorf("Error get package version: %v\n", respErr.Error())
+
+		return nil, connect.NewError(respErr.Code(), respErr.Err())
+	}
+
+	resp := connect.NewResponse(&registryv1alpha1.GetSwiftVersionResponse{
+		Version: packageVersion.SwiftVersion(),
+	})
+	return resp, nil
 }
 
 func (handler *ResolveServiceHandler) GetMavenVersion(ctx context.Context, req *connect.Request[registryv1alpha1.GetMavenVersionRequest]) (*connect.Response[registryv1alpha1.GetMavenVersionResponse], error) {
-	//TODO implement me
-	panic("implement me")
+	packageVersion, respErr := handler.getPackageVersion(ctx, req.Msg.GetModuleReference(), req.Msg.GetPluginReference(), registryv1alpha1connect.ResolveServiceGetMavenVersionProcedure)
+	if respErr != nil {
+		logger.Errorf("Error get package version: %v\n", respErr.Error())
+
+		return nil, connect.NewError(respErr.Code(), respErr.Err())
+	}
+
+	resp := connect.NewResponse(&registryv1alpha1.GetMavenVersionResponse{
+		Version: packageVersion.MavenVersion(),
+	})
+	return resp, nil
 }
 
 func (handler *ResolveServiceHandler) GetNPMVersion(ctx context.Context, req *connect.Request[registryv1alpha1.GetNPMVersionRequest]) (*connect.Response[registryv1alpha1.GetNPMVersionResponse], error) {
-	//TODO implement me
-	panic("implement me")
+	packageVersion, respErr := handler.getPackageVersion(ctx, req.Msg.GetModuleReference(), req.Msg.GetPluginReference(), registryv1alpha1connect.ResolveServiceGetNPMVersionProcedure)
+	if respErr != nil {
+		logger.Errorf("Error get package version: %v\n", respErr.Error())
+
+		return nil, connect.NewError(respErr.Code(), respErr.Err())
+	}
+
+	resp := connect.NewResponse(&registryv1alpha1.GetNPMVersionResponse{
+		Version: packageVersion.NPMVersion(),
+	})
+	return resp, nil
+}
+
+// getPackageVersion 根据module reference和插件查询计算远程包版本号需要的信息
+func (handler *ResolveServiceHandler) getPackageVersion(ctx context.Context, moduleReference *registryv1alpha1.LocalModuleReference, pluginReference *registryv1alpha1.GetRemotePackageVersionPlugin, procedure string) (*version.PackageVersion, e.ResponseError) {
+	userID, _ := ctx.Value(constant.UserIDKey).(string)
+
+	// 检查用户权限，是否对repo有访问权限
+	repository, checkErr := handler.authorizationService.CheckRepositoryCanAccess(userID, moduleReference.GetOwner(), moduleReference.GetRepository(), procedure)
+	if checkErr != nil {
+		return nil, checkErr
+	}
+
+	// 查询reference对应的commit
+	commit, respErr := handler.commitService.GetRepositoryCommitByReference(ctx, repository.RepositoryID, moduleReference.GetReference())
+	if respErr != nil {
+		return nil, respErr
+	}
+
+	// 查询插件，未指定版本号或者revision时使用最新的
+	var plugin *model.Plugin
+	switch {
+	case pluginReference.GetVersion() == "":
+		plugin, respErr = handler.pluginService.GetLatestPlugin(ctx, pluginReference.GetOwner(), pluginReference.GetName())
+	case pluginReference.GetRevision() == 0:
+		plugin, respErr = handler.pluginService.GetLatestPluginWithVersion(ctx, pluginReference.GetOwner(), pluginReference.GetName(), pluginReference.GetVersion())
+	default:
+		plugin, respErr = handler.pluginService.GetLatestPluginWithVersionAndReversion(ctx, pluginReference.GetOwner(), pluginReference.GetName(), pluginReference.GetVersion(), pluginReference.GetRevision())
+	}
+	if respErr != nil {
+		return nil, respErr
+	}
+
+	return &version.PackageVersion{
+		PluginVersion:    plugin.Version,
+		PluginRevision:   plugin.Reversion,
+		CommitSequenceID: commit.SequenceID,
+		CommitName:       commit.CommitName,
+		CommitTime:       commit.CreatedTime,
+	}, nil
 }
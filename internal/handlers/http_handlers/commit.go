@@ -3,6 +3,7 @@ package http_handlers
 import (
 	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
 	"github.com/ProtobufMan/bufman/internal/controllers"
+	"github.com/ProtobufMan/bufman/internal/dto"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
@@ -56,6 +57,25 @@ func (group *commitGroup) GetRepositoryCommitByReference(c *gin.Context) {
 	c.JSON(http.StatusOK, NewHTTPResponse(resp))
 }
 
+func (group *commitGroup) ResolveRepositoryReference(c *gin.Context) {
+	// 绑定参数
+	req := &dto.ResolveRepositoryReferenceRequest{}
+	bindErr := c.ShouldBindUri(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.commitController.ResolveRepositoryReference(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
+
 func (group *commitGroup) ListRepositoryDraftCommits(c *gin.Context) {
 	// 绑定参数
 	req := &registryv1alpha1.ListRepositoryDraftCommitsRequest{}
@@ -96,3 +116,22 @@ func (group *commitGroup) DeleteRepositoryDraftCommit(c *gin.Context) {
 	// 正常返回
 	c.JSON(http.StatusOK, NewHTTPResponse(resp))
 }
+
+func (group *commitGroup) VerifyLock(c *gin.Context) {
+	// 绑定参数
+	req := &dto.VerifyLockRequest{}
+	bindErr := c.ShouldBindJSON(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.commitController.VerifyLock(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
@@ -1,10 +1,13 @@
 package http_handlers
 
 import (
+	"fmt"
 	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
 	"github.com/ProtobufMan/bufman/internal/controllers"
+	"github.com/ProtobufMan/bufman/internal/dto"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"strings"
 )
 
 type docGroup struct {
@@ -42,6 +45,9 @@ func (group *docGroup) GetSourceFile(c *gin.Context) {
 		return
 	}
 
+	// 通配路由中的路径以/开头
+	req.Path = strings.TrimPrefix(req.Path, "/")
+
 	resp, err := group.docController.GetSourceFile(c, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
@@ -104,3 +110,183 @@ func (group *docGroup) GetPackageDocumentation(c *gin.Context) {
 	// 正常返回
 	c.JSON(http.StatusOK, NewHTTPResponse(resp))
 }
+
+func (group *docGroup) ExportDocumentation(c *gin.Context) {
+	// 绑定参数
+	req := &dto.ExportDocumentationRequest{}
+	bindErr := c.ShouldBindUri(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+	bindErr = c.ShouldBindQuery(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.docController.ExportDocumentation(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 以附件形式返回压缩包
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", resp.FileName))
+	c.Data(http.StatusOK, resp.ContentType, resp.Content)
+}
+
+func (group *docGroup) ExportSchema(c *gin.Context) {
+	// 绑定参数
+	req := &dto.ExportSchemaRequest{}
+	bindErr := c.ShouldBindUri(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+	bindErr = c.ShouldBindQuery(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.docController.ExportSchema(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 以附件形式返回
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", resp.FileName))
+	c.Data(http.StatusOK, "application/json", resp.Content)
+}
+
+func (group *docGroup) GetSourceDirectory(c *gin.Context) {
+	// 绑定参数
+	req := &dto.GetSourceDirectoryRequest{}
+	bindErr := c.ShouldBindUri(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.docController.GetSourceDirectory(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
+
+func (group *docGroup) DownloadSourceFile(c *gin.Context) {
+	// 绑定参数
+	req := &dto.DownloadSourceFileRequest{}
+	bindErr := c.ShouldBindUri(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.docController.DownloadSourceFile(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 返回文件原始内容
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", resp.FileName))
+	c.Data(http.StatusOK, resp.ContentType, resp.Content)
+}
+
+func (group *docGroup) GetDefinition(c *gin.Context) {
+	// 绑定参数
+	req := &dto.GetDefinitionRequest{}
+	bindErr := c.ShouldBindUri(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+	bindErr = c.ShouldBindQuery(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.docController.GetDefinition(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
+
+func (group *docGroup) ListReferences(c *gin.Context) {
+	// 绑定参数
+	req := &dto.ListReferencesRequest{}
+	bindErr := c.ShouldBindUri(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+	bindErr = c.ShouldBindQuery(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.docController.ListReferences(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
+
+func (group *docGroup) GetBlame(c *gin.Context) {
+	// 绑定参数
+	req := &dto.GetBlameRequest{}
+	bindErr := c.ShouldBindUri(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.docController.GetBlame(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
+
+func (group *docGroup) GetFileHistory(c *gin.Context) {
+	// 绑定参数
+	req := &dto.GetFileHistoryRequest{}
+	bindErr := c.ShouldBindUri(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+	bindErr = c.ShouldBindQuery(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.docController.GetFileHistory(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
@@ -0,0 +1,49 @@
+package http_handlers
+
+import (
+	"fmt"
+	"github.com/ProtobufMan/bufman/internal/controllers"
+	"github.com/ProtobufMan/bufman/internal/core/archive"
+	"github.com/ProtobufMan/bufman/internal/dto"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type downloadGroup struct {
+	downloadController *controllers.DownloadController
+}
+
+var DownloadGroup = &downloadGroup{
+	downloadController: controllers.NewDownloadController(),
+}
+
+func (group *downloadGroup) DownloadArchive(c *gin.Context) {
+	// 绑定参数
+	req := &dto.DownloadArchiveRequest{}
+	bindErr := c.ShouldBindUri(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+	bindErr = c.ShouldBindQuery(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.downloadController.DownloadArchive(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 压缩包直接写入响应
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", resp.FileName))
+	c.Header("Content-Type", resp.ContentType)
+	c.Status(http.StatusOK)
+	if writeErr := archive.Write(c.Writer, resp.Format, resp.Files, resp.ModTime); writeErr != nil {
+		// 响应头已经发送，只能记录错误
+		_ = c.Error(writeErr)
+		c.Abort()
+	}
+}
@@ -0,0 +1,62 @@
+package http_handlers
+
+import (
+	"fmt"
+	"github.com/ProtobufMan/bufman/internal/controllers"
+	"github.com/ProtobufMan/bufman/internal/dto"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type imageGroup struct {
+	imageController *controllers.ImageController
+}
+
+var ImageGroup = &imageGroup{
+	imageController: controllers.NewImageController(),
+}
+
+func (group *imageGroup) GetImage(c *gin.Context) {
+	// 绑定参数
+	req := &dto.GetImageRequest{}
+	bindErr := c.ShouldBindUri(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+	bindErr = c.ShouldBindQuery(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.imageController.GetImage(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", resp.FileName))
+	c.Data(http.StatusOK, resp.ContentType, resp.Content)
+}
+
+func (group *imageGroup) GetFileDescriptorSet(c *gin.Context) {
+	// 绑定参数
+	req := &dto.GetFileDescriptorSetRequest{}
+	bindErr := c.ShouldBindJSON(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.imageController.GetFileDescriptorSet(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", resp.FileName))
+	c.Data(http.StatusOK, resp.ContentType, resp.Content)
+}
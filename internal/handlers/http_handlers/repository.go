@@ -3,6 +3,7 @@ package http_handlers
 import (
 	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
 	"github.com/ProtobufMan/bufman/internal/controllers"
+	"github.com/ProtobufMan/bufman/internal/dto"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
@@ -198,3 +199,160 @@ func (group *repositoryGroup) UpdateRepositorySettingsByName(c *gin.Context) {
 	// 正常返回
 	c.JSON(http.StatusOK, NewHTTPResponse(resp))
 }
+
+func (group *repositoryGroup) ForkRepository(c *gin.Context) {
+	// 绑定参数
+	req := &dto.ForkRepositoryRequest{}
+	bindErr := c.ShouldBindJSON(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.repositoryController.ForkRepository(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
+
+func (group *repositoryGroup) CompareRepositoryWithUpstream(c *gin.Context) {
+	// 绑定参数
+	req := &dto.CompareRepositoryWithUpstreamRequest{}
+	bindErr := c.ShouldBindUri(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.repositoryController.CompareRepositoryWithUpstream(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
+
+func (group *repositoryGroup) RenameRepository(c *gin.Context) {
+	// 绑定参数
+	req := &dto.RenameRepositoryRequest{}
+	bindErr := c.ShouldBindJSON(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.repositoryController.RenameRepository(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
+
+func (group *repositoryGroup) TransferRepository(c *gin.Context) {
+	// 绑定参数
+	req := &dto.TransferRepositoryRequest{}
+	bindErr := c.ShouldBindJSON(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.repositoryController.TransferRepository(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
+
+func (group *repositoryGroup) AcceptRepositoryTransfer(c *gin.Context) {
+	// 绑定参数
+	req := &dto.AcceptRepositoryTransferRequest{}
+	bindErr := c.ShouldBindJSON(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.repositoryController.AcceptRepositoryTransfer(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
+
+func (group *repositoryGroup) ListRepositoryDependents(c *gin.Context) {
+	// 绑定参数
+	req := &dto.ListRepositoryDependentsRequest{}
+	bindErr := c.ShouldBindJSON(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.repositoryController.ListRepositoryDependents(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
+
+func (group *repositoryGroup) GetDependencyGraph(c *gin.Context) {
+	// 绑定参数
+	req := &dto.GetDependencyGraphRequest{}
+	bindErr := c.ShouldBindUri(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+	bindErr = c.ShouldBindQuery(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.repositoryController.GetDependencyGraph(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
+
+func (group *repositoryGroup) UpdateRepositoryDependencyPolicy(c *gin.Context) {
+	// 绑定参数
+	req := &dto.UpdateRepositoryDependencyPolicyRequest{}
+	bindErr := c.ShouldBindJSON(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.repositoryController.UpdateRepositoryDependencyPolicy(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
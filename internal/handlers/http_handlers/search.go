@@ -3,6 +3,7 @@ package http_handlers
 import (
 	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
 	"github.com/ProtobufMan/bufman/internal/controllers"
+	"github.com/ProtobufMan/bufman/internal/dto"
 	"github.com/gin-gonic/gin"
 	"net/http"
 )
@@ -122,3 +123,22 @@ func (group *searchGroup) SearchDraft(c *gin.Context) {
 	// 正常返回
 	c.JSON(http.StatusOK, NewHTTPResponse(resp))
 }
+
+func (group *searchGroup) SearchSymbol(c *gin.Context) {
+	// 绑定参数
+	req := &dto.SearchSymbolRequest{}
+	bindErr := c.ShouldBindJSON(req)
+	if bindErr != nil {
+		c.JSON(http.StatusBadRequest, NewHTTPResponse(bindErr))
+		return
+	}
+
+	resp, err := group.searchController.SearchSymbol(c, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, NewHTTPResponse(err))
+		return
+	}
+
+	// 正常返回
+	c.JSON(http.StatusOK, NewHTTPResponse(resp))
+}
@@ -2,20 +2,26 @@ package mapper
 
 import (
 	"errors"
-	"github.com/ProtobufMan/bufman/internal/constant"
+	"github.com/ProtobufMan/bufman/internal/core/reference"
 	"github.com/ProtobufMan/bufman/internal/dal"
 	"github.com/ProtobufMan/bufman/internal/model"
 	"gorm.io/gorm"
+	"time"
 )
 
 type CommitMapper interface {
 	Create(commit *model.Commit) error
+	CreateProxy(commit *model.Commit) error
 	GetDraftCountsByRepositoryID(repositoryID string) (int64, error)
 	FindLastByRepositoryID(repositoryID string) (*model.Commit, error)
+	FindLastByRepositoryIDBeforeTime(repositoryID string, t time.Time) (*model.Commit, error)
 	FindByRepositoryIDAndCommitName(repositoryID string, commitName string) (*model.Commit, error)
 	FindByRepositoryIDAndTagName(repositoryID string, tagName string) (*model.Commit, error)
 	FindByRepositoryIDAndDraftName(repositoryID string, draftName string) (*model.Commit, error)
 	FindByRepositoryIDAndReference(repositoryID string, reference string) (*model.Commit, error)
+	ResolveByRepositoryIDAndReference(repositoryID string, ref string) (*model.Commit, reference.Kind, error)
+	FindAllByRepositoryID(repositoryID string) (model.Commits, error)
+	FindAllByCommitIDs(commitIDs []string) (model.Commits, error)
 	FindPageByRepositoryID(repositoryID string, offset, limit int, reverse bool) (model.Commits, error)
 	FindPageByRepositoryIDAndDraftName(repositoryID, draftName string, offset, limit int, reverse bool) (model.Commits, error)
 	FindPageByRepositoryIDAndTagName(repositoryID string, tagName string, offset, limit int, reverse bool) (model.Commits, error)
@@ -23,6 +29,8 @@ type CommitMapper interface {
 	FindPageByRepositoryIDAndReference(repositoryID string, reference string, offset, limit int, reverse bool) (model.Commits, error)
 	FindDraftPageByRepositoryID(repositoryID string, offset, limit int, reverse bool) (model.Commits, error)
 	FindDraftPageByRepositoryIDAndQuery(repositoryID, query string, offset, limit int, reverse bool) (model.Commits, error)
+	FindPageWithoutPackageDocumentation(afterID int64, limit int) (model.Commits, error)
+	FindPageWithoutDependencies(afterID int64, limit int) (model.Commits, error)
 	DeleteByRepositoryIDAndDraftName(repositoryID string, draftName string) error
 }
 
@@ -31,6 +39,8 @@ type CommitMapperImpl struct{}
 var (
 	ErrTagAndDraftDuplicated = errors.New("tag and draft duplicated")
 	ErrLastCommitDuplicated  = errors.New("same commit compared to las commit")
+	ErrTagDuplicated         = errors.New("tag duplicated")
+	ErrInvalidReference      = reference.ErrInvalidReference
 )
 
 func (c *CommitMapperImpl) Create(commit *model.Commit) error {
@@ -51,10 +61,33 @@ func (c *CommitMapperImpl) Create(commit *model.Commit) error {
 				tagNames[i] = commit.Tags[i].TagName
 			}
 			_, err = tx.Commit.Where(tx.Commit.RepositoryID.Eq(commit.RepositoryID), tx.Commit.DraftName.In(tagNames...)).First()
-			if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+			if err == nil {
 				// 冲突
 				return ErrTagAndDraftDuplicated
 			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			// 检查tag是否已经存在
+			_, err = tx.Tag.Where(tx.Tag.RepositoryID.Eq(commit.RepositoryID), tx.Tag.TagName.In(tagNames...)).First()
+			if err == nil {
+				return ErrTagDuplicated
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+		}
+
+		// 检查draft和tag是否冲突
+		if commit.DraftName != "" {
+			_, err = tx.Tag.Where(tx.Tag.RepositoryID.Eq(commit.RepositoryID), tx.Tag.TagName.Eq(commit.DraftName)).First()
+			if err == nil {
+				return ErrTagAndDraftDuplicated
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
 		}
 
 		// 存储
@@ -73,6 +106,37 @@ func (c *CommitMapperImpl) Create(commit *model.Commit) error {
 		return tx.Commit.Save(commit)
 	})
 }
+
+// CreateProxy 缓存上游的commit，保留上游的sequence id，已经存在的tag不再重复创建
+func (c *CommitMapperImpl) CreateProxy(commit *model.Commit) error {
+	return dal.Q.Transaction(func(tx *dal.Query) error {
+		if len(commit.Tags) > 0 {
+			tagNames := make([]string, len(commit.Tags))
+			for i := 0; i < len(commit.Tags); i++ {
+				tagNames[i] = commit.Tags[i].TagName
+			}
+			existTags, err := tx.Tag.Where(tx.Tag.RepositoryID.Eq(commit.RepositoryID), tx.Tag.TagName.In(tagNames...)).Find()
+			if err != nil {
+				return err
+			}
+
+			existTagNames := make(map[string]struct{}, len(existTags))
+			for i := 0; i < len(existTags); i++ {
+				existTagNames[existTags[i].TagName] = struct{}{}
+			}
+			tags := make(model.Tags, 0, len(commit.Tags))
+			for i := 0; i < len(commit.Tags); i++ {
+				if _, ok := existTagNames[commit.Tags[i].TagName]; !ok {
+					tags = append(tags, commit.Tags[i])
+				}
+			}
+			commit.Tags = tags
+		}
+
+		return tx.Commit.Create(commit)
+	})
+}
+
 func (c *CommitMapperImpl) GetDraftCountsByRepositoryID(repositoryID string) (int64, error) {
 	return dal.Commit.Where(dal.Commit.CommitID.Eq(repositoryID), dal.Commit.DraftName.Neq("")).Count()
 }
@@ -81,6 +145,10 @@ func (c *CommitMapperImpl) FindLastByRepositoryID(repositoryID string) (*model.C
 	return dal.Commit.Where(dal.Commit.RepositoryID.Eq(repositoryID), dal.Commit.DraftName.Eq("")).Last()
 }
 
+func (c *CommitMapperImpl) FindLastByRepositoryIDBeforeTime(repositoryID string, t time.Time) (*model.Commit, error) {
+	return dal.Commit.Where(dal.Commit.RepositoryID.Eq(repositoryID), dal.Commit.DraftName.Eq(""), dal.Commit.CreatedTime.Lte(t)).Last()
+}
+
 func (c *CommitMapperImpl) FindByRepositoryIDAndCommitName(repositoryID string, commitName string) (*model.Commit, error) {
 	return dal.Commit.Where(dal.Commit.RepositoryID.Eq(repositoryID), dal.Commit.CommitName.Eq(commitName)).First()
 }
@@ -114,66 +182,63 @@ func (c *CommitMapperImpl) FindByRepositoryIDAndDraftName(repositoryID string, d
 	return dal.Commit.Where(dal.Commit.RepositoryID.Eq(repositoryID), dal.Commit.DraftName.Eq(draftName)).Last()
 }
 
-func (c *CommitMapperImpl) FindByRepositoryIDAndReference(repositoryID string, reference string) (*model.Commit, error) {
-	var commit *model.Commit
-	var err error
-	if reference == "" || reference == constant.DefaultBranch {
-		commit, err = c.FindLastByRepositoryID(repositoryID)
-	} else if len(reference) == constant.CommitLength {
-		// 查询commit
-		commit, err = c.FindByRepositoryIDAndCommitName(repositoryID, reference)
-		if err != nil {
-			return nil, err
-		}
-	}
+func (c *CommitMapperImpl) FindByRepositoryIDAndReference(repositoryID string, ref string) (*model.Commit, error) {
+	commit, _, err := c.ResolveByRepositoryIDAndReference(repositoryID, ref)
+	return commit, err
+}
 
-	if commit != nil && err == nil {
-		return commit, nil
+func (c *CommitMapperImpl) ResolveByRepositoryIDAndReference(repositoryID string, ref string) (*model.Commit, reference.Kind, error) {
+	parsed, err := reference.Parse(ref)
+	if err != nil {
+		return nil, reference.KindUnknown, err
 	}
 
-	// 查询tag
-	commit, err = c.FindByRepositoryIDAndTagName(repositoryID, reference)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, err
-	}
-	if err != nil {
-		// 查询draft
-		commit, err = c.FindByRepositoryIDAndDraftName(repositoryID, reference)
+	// 按照优先级依次查询 branch > commit > tag > draft
+	for _, kind := range parsed.Candidates() {
+		var commit *model.Commit
+		switch kind {
+		case reference.KindBranch:
+			if !reference.IsDefaultBranch(parsed.Name) {
+				// 目前只支持默认分支
+				err = gorm.ErrRecordNotFound
+				break
+			}
+			if parsed.Time != nil {
+				commit, err = c.FindLastByRepositoryIDBeforeTime(repositoryID, *parsed.Time)
+				break
+			}
+			commit, err = c.FindLastByRepositoryID(repositoryID)
+		case reference.KindCommit:
+			commit, err = c.FindByRepositoryIDAndCommitName(repositoryID, parsed.Name)
+		case reference.KindTag:
+			commit, err = c.FindByRepositoryIDAndTagName(repositoryID, parsed.Name)
+		case reference.KindDraft:
+			commit, err = c.FindByRepositoryIDAndDraftName(repositoryID, parsed.Name)
+		}
 		if err != nil {
-			return nil, err
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return nil, reference.KindUnknown, err
 		}
+
+		return commit, kind, nil
 	}
 
-	return commit, nil
+	return nil, reference.KindUnknown, gorm.ErrRecordNotFound
 }
 
-func (c *CommitMapperImpl) FindByRepositoryNameAndReference(repositoryID string, reference string) (*model.Commit, error) {
-	var commit *model.Commit
-	var err error
-	if reference == "" || reference == constant.DefaultBranch {
-		commit, err = c.FindLastByRepositoryID(repositoryID)
-	} else if len(reference) == constant.CommitLength {
-		// 查询commit
-		commit, err = c.FindByRepositoryIDAndCommitName(repositoryID, reference)
-		if err != nil {
-			return nil, err
-		}
-	}
+// FindAllByRepositoryID 查询默认分支上的全部commit(不包括draft)
+func (c *CommitMapperImpl) FindAllByRepositoryID(repositoryID string) (model.Commits, error) {
+	return dal.Commit.Where(dal.Commit.RepositoryID.Eq(repositoryID), dal.Commit.DraftName.Eq("")).Order(dal.Commit.SequenceID).Find()
+}
 
-	// 查询tag
-	commit, err = c.FindByRepositoryIDAndTagName(repositoryID, reference)
-	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, err
-	}
-	if err != nil {
-		// 查询draft
-		commit, err = c.FindByRepositoryIDAndDraftName(repositoryID, reference)
-		if err != nil {
-			return nil, err
-		}
+func (c *CommitMapperImpl) FindAllByCommitIDs(commitIDs []string) (model.Commits, error) {
+	if len(commitIDs) == 0 {
+		return model.Commits{}, nil
 	}
 
-	return commit, nil
+	return dal.Commit.Where(dal.Commit.CommitID.In(commitIDs...)).Find()
 }
 
 func (c *CommitMapperImpl) FindPageByRepositoryID(repositoryID string, offset, limit int, reverse bool) (model.Commits, error) {
@@ -248,30 +313,53 @@ func (c *CommitMapperImpl) FindPageByRepositoryIDAndTagName(repositoryID string,
 	return commits, nil
 }
 
-func (c *CommitMapperImpl) FindPageByRepositoryIDAndReference(repositoryID string, reference string, offset, limit int, reverse bool) (model.Commits, error) {
-	var commits model.Commits
-	var err error
-	if reference == "" || reference == constant.DefaultBranch {
-		commits, err = c.FindPageByRepositoryID(repositoryID, offset, limit, reverse)
-	} else if len(reference) == constant.CommitLength {
-		commits, err = c.FindPageByRepositoryIDAndCommitName(repositoryID, reference, offset, limit, reverse)
-	} else {
-		commits, err = c.FindPageByRepositoryIDAndTagName(repositoryID, reference, offset, limit, reverse)
-	}
-
+func (c *CommitMapperImpl) FindPageByRepositoryIDAndReference(repositoryID string, ref string, offset, limit int, reverse bool) (model.Commits, error) {
+	parsed, err := reference.Parse(ref)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(commits) == 0 {
-		// 查询drafts
-		commits, err = c.FindPageByRepositoryIDAndDraftName(repositoryID, reference, offset, limit, reverse)
+	// 按照优先级依次查询 branch > commit > tag > draft
+	for _, kind := range parsed.Candidates() {
+		var commits model.Commits
+		switch kind {
+		case reference.KindBranch:
+			if !reference.IsDefaultBranch(parsed.Name) {
+				err = gorm.ErrRecordNotFound
+				break
+			}
+			if parsed.Time != nil {
+				// 从该时刻的最后一次提交开始查询
+				var commit *model.Commit
+				commit, err = c.FindLastByRepositoryIDBeforeTime(repositoryID, *parsed.Time)
+				if err != nil {
+					break
+				}
+				commits, err = c.FindPageByRepositoryIDAndCommitName(repositoryID, commit.CommitName, offset, limit, reverse)
+				break
+			}
+			commits, err = c.FindPageByRepositoryID(repositoryID, offset, limit, reverse)
+		case reference.KindCommit:
+			commits, err = c.FindPageByRepositoryIDAndCommitName(repositoryID, parsed.Name, offset, limit, reverse)
+		case reference.KindTag:
+			commits, err = c.FindPageByRepositoryIDAndTagName(repositoryID, parsed.Name, offset, limit, reverse)
+		case reference.KindDraft:
+			commits, err = c.FindPageByRepositoryIDAndDraftName(repositoryID, parsed.Name, offset, limit, reverse)
+			if err == nil && len(commits) == 0 {
+				err = gorm.ErrRecordNotFound
+			}
+		}
 		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
 			return nil, err
 		}
+
+		return commits, nil
 	}
 
-	return commits, nil
+	return nil, gorm.ErrRecordNotFound
 }
 
 func (c *CommitMapperImpl) FindDraftPageByRepositoryID(repositoryID string, offset, limit int, reverse bool) (model.Commits, error) {
@@ -292,9 +380,46 @@ func (c *CommitMapperImpl) FindDraftPageByRepositoryIDAndQuery(repositoryID, que
 	return stmt.Find()
 }
 
+// FindPageWithoutPackageDocumentation 按照ID顺序查询还没有生成包文档的commits
+func (c *CommitMapperImpl) FindPageWithoutPackageDocumentation(afterID int64, limit int) (model.Commits, error) {
+	return dal.Commit.Where(dal.Commit.ID.Gt(afterID), dal.Commit.PackageDocumentationGenerated.Is(false)).Order(dal.Commit.ID).Limit(limit).Find()
+}
+
+// FindPageWithoutDependencies 按照ID顺序查询还没有记录依赖关系的commits
+func (c *CommitMapperImpl) FindPageWithoutDependencies(afterID int64, limit int) (model.Commits, error) {
+	return dal.Commit.Where(dal.Commit.ID.Gt(afterID), dal.Commit.DependenciesRecorded.Is(false)).Order(dal.Commit.ID).Limit(limit).Find()
+}
+
 func (c *CommitMapperImpl) DeleteByRepositoryIDAndDraftName(repositoryID string, draftName string) error {
-	_, err := dal.Commit.Where(dal.Commit.RepositoryID.Eq(repositoryID), dal.Commit.DraftName.Eq(draftName), dal.Commit.DraftName.Neq("")).Delete()
-	return err
+	return dal.Q.Transaction(func(tx *dal.Query) error {
+		commits, err := tx.Commit.Where(tx.Commit.RepositoryID.Eq(repositoryID), tx.Commit.DraftName.Eq(draftName), tx.Commit.DraftName.Neq("")).Find()
+		if err != nil {
+			return err
+		}
+		if len(commits) == 0 {
+			return nil
+		}
+
+		commitIDs := make([]string, 0, len(commits))
+		for i := 0; i < len(commits); i++ {
+			commitIDs = append(commitIDs, commits[i].CommitID)
+		}
+
+		_, err = tx.Commit.Where(tx.Commit.CommitID.In(commitIDs...)).Delete()
+		if err != nil {
+			return err
+		}
+
+		// 删除draft的依赖关系
+		_, err = tx.Dependency.Where(tx.Dependency.CommitID.In(commitIDs...)).Delete()
+		if err != nil {
+			return err
+		}
+
+		// 删除draft的包文档
+		_, err = tx.PackageDocumentation.Where(tx.PackageDocumentation.CommitID.In(commitIDs...)).Delete()
+		return err
+	})
 }
 
 func (c *CommitMapperImpl) FindSequenceID(commit *model.Commit) (int64, error) {
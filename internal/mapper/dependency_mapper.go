@@ -0,0 +1,59 @@
+package mapper
+
+import (
+	"github.com/ProtobufMan/bufman/internal/dal"
+	"github.com/ProtobufMan/bufman/internal/model"
+)
+
+type DependencyMapper interface {
+	FindAllByRepositoryID(repositoryID string) (model.Dependencies, error)
+	FindAllByCommitID(commitID string) (model.Dependencies, error)
+	FindAllByDependencyRepositoryID(dependencyRepositoryID string) (model.Dependencies, error)
+	FindAllByDependencyCommitIDs(dependencyCommitIDs []string) (model.Dependencies, error)
+	ReplaceByCommitID(commitID string, dependencies model.Dependencies) error
+}
+
+type DependencyMapperImpl struct{}
+
+func (d *DependencyMapperImpl) FindAllByRepositoryID(repositoryID string) (model.Dependencies, error) {
+	return dal.Dependency.Where(dal.Dependency.RepositoryID.Eq(repositoryID)).Find()
+}
+
+// FindAllByCommitID 查询commit的全部依赖，包括间接依赖
+func (d *DependencyMapperImpl) FindAllByCommitID(commitID string) (model.Dependencies, error) {
+	return dal.Dependency.Where(dal.Dependency.CommitID.Eq(commitID)).Find()
+}
+
+// FindAllByDependencyRepositoryID 只查询直接依赖
+func (d *DependencyMapperImpl) FindAllByDependencyRepositoryID(dependencyRepositoryID string) (model.Dependencies, error) {
+	return dal.Dependency.Where(dal.Dependency.DependencyRepositoryID.Eq(dependencyRepositoryID), dal.Dependency.Transitive.Is(false)).Find()
+}
+
+// FindAllByDependencyCommitIDs 只查询直接依赖
+func (d *DependencyMapperImpl) FindAllByDependencyCommitIDs(dependencyCommitIDs []string) (model.Dependencies, error) {
+	if len(dependencyCommitIDs) == 0 {
+		return model.Dependencies{}, nil
+	}
+
+	return dal.Dependency.Where(dal.Dependency.DependencyCommitID.In(dependencyCommitIDs...), dal.Dependency.Transitive.Is(false)).Find()
+}
+
+// ReplaceByCommitID 重新记录commit的依赖关系，并标记commit已经记录了依赖关系
+func (d *DependencyMapperImpl) ReplaceByCommitID(commitID string, dependencies model.Dependencies) error {
+	return dal.Q.Transaction(func(tx *dal.Query) error {
+		_, err := tx.Dependency.Where(tx.Dependency.CommitID.Eq(commitID)).Delete()
+		if err != nil {
+			return err
+		}
+
+		if len(dependencies) > 0 {
+			err = tx.Dependency.CreateInBatches(dependencies, 100)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Commit.Where(tx.Commit.CommitID.Eq(commitID)).Update(tx.Commit.DependenciesRecorded, true)
+		return err
+	})
+}
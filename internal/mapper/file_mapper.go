@@ -9,6 +9,10 @@ type FileMapper interface {
 	FindAllBlobsByCommitID(commitID string) (model.FileBlobs, error)
 	FindManifestByCommitID(commitID string) (*model.FileManifest, error)
 	FindBlobByCommitIDAndPath(commitID, path string) (*model.FileBlob, error)
+	FindAllBlobsByRepositoryIDAndPath(repositoryID, path string) (model.FileBlobs, error)
+	FindAllBlobsByCommitIDAndDigest(commitID, digest string) (model.FileBlobs, error)
+	FindAllManifestsByRepositoryID(repositoryID string) (model.FileManifests, error)
+	FindAllBlobsByRepositoryID(repositoryID string) (model.FileBlobs, error)
 }
 
 type FileMapperImpl struct{}
@@ -24,3 +28,23 @@ func (f *FileMapperImpl) FindManifestByCommitID(commitID string) (*model.FileMan
 func (f *FileMapperImpl) FindBlobByCommitIDAndPath(commitID, path string) (*model.FileBlob, error) {
 	return dal.FileBlob.Where(dal.FileBlob.CommitID.Eq(commitID), dal.FileBlob.FileName.Eq(path)).First()
 }
+
+// FindAllBlobsByRepositoryIDAndPath 查询repository所有commit中路径为path的文件
+func (f *FileMapperImpl) FindAllBlobsByRepositoryIDAndPath(repositoryID, path string) (model.FileBlobs, error) {
+	return dal.FileBlob.Select(dal.FileBlob.ALL).Join(dal.Commit, dal.Commit.CommitID.EqCol(dal.FileBlob.CommitID)).Where(dal.Commit.RepositoryID.Eq(repositoryID), dal.FileBlob.FileName.Eq(path)).Find()
+}
+
+// FindAllBlobsByCommitIDAndDigest 查询commit中内容相同的文件，用于判断重命名
+func (f *FileMapperImpl) FindAllBlobsByCommitIDAndDigest(commitID, digest string) (model.FileBlobs, error) {
+	return dal.FileBlob.Where(dal.FileBlob.CommitID.Eq(commitID), dal.FileBlob.Digest.Eq(digest)).Find()
+}
+
+// FindAllManifestsByRepositoryID 查询repository所有commit的文件清单
+func (f *FileMapperImpl) FindAllManifestsByRepositoryID(repositoryID string) (model.FileManifests, error) {
+	return dal.FileManifest.Select(dal.FileManifest.ALL).Join(dal.Commit, dal.Commit.CommitID.EqCol(dal.FileManifest.CommitID)).Where(dal.Commit.RepositoryID.Eq(repositoryID)).Find()
+}
+
+// FindAllBlobsByRepositoryID 查询repository所有commit的文件
+func (f *FileMapperImpl) FindAllBlobsByRepositoryID(repositoryID string) (model.FileBlobs, error) {
+	return dal.FileBlob.Select(dal.FileBlob.ALL).Join(dal.Commit, dal.Commit.CommitID.EqCol(dal.FileBlob.CommitID)).Where(dal.Commit.RepositoryID.Eq(repositoryID)).Find()
+}
@@ -0,0 +1,51 @@
+package mapper
+
+import (
+	"github.com/ProtobufMan/bufman/internal/dal"
+	"github.com/ProtobufMan/bufman/internal/model"
+)
+
+type PackageDocumentationMapper interface {
+	CreateByCommitID(commitID string, documentations model.PackageDocumentations) error
+	FindByCommitIDAndPackageName(commitID, packageName string) (*model.PackageDocumentation, error)
+	FindAllPackageNamesByCommitID(commitID string) ([]string, error)
+	FindAllByCommitID(commitID string) (model.PackageDocumentations, error)
+}
+
+type PackageDocumentationMapperImpl struct{}
+
+// CreateByCommitID 保存commit的全部包文档，并将commit标记为已生成
+func (p *PackageDocumentationMapperImpl) CreateByCommitID(commitID string, documentations model.PackageDocumentations) error {
+	return dal.Q.Transaction(func(tx *dal.Query) error {
+		// 删除之前生成的文档
+		_, err := tx.PackageDocumentation.Where(tx.PackageDocumentation.CommitID.Eq(commitID)).Delete()
+		if err != nil {
+			return err
+		}
+
+		if len(documentations) > 0 {
+			err = tx.PackageDocumentation.CreateInBatches(documentations, 100)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Commit.Where(tx.Commit.CommitID.Eq(commitID)).Update(tx.Commit.PackageDocumentationGenerated, true)
+		return err
+	})
+}
+
+func (p *PackageDocumentationMapperImpl) FindByCommitIDAndPackageName(commitID, packageName string) (*model.PackageDocumentation, error) {
+	return dal.PackageDocumentation.Where(dal.PackageDocumentation.CommitID.Eq(commitID), dal.PackageDocumentation.PackageName.Eq(packageName)).First()
+}
+
+func (p *PackageDocumentationMapperImpl) FindAllPackageNamesByCommitID(commitID string) ([]string, error) {
+	var packageNames []string
+	err := dal.PackageDocumentation.Where(dal.PackageDocumentation.CommitID.Eq(commitID)).Pluck(dal.PackageDocumentation.PackageName, &packageNames)
+
+	return packageNames, err
+}
+
+func (p *PackageDocumentationMapperImpl) FindAllByCommitID(commitID string) (model.PackageDocumentations, error) {
+	return dal.PackageDocumentation.Where(dal.PackageDocumentation.CommitID.Eq(commitID)).Order(dal.PackageDocumentation.PackageName).Find()
+}
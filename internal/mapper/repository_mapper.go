@@ -1,16 +1,21 @@
 package mapper
 
 import (
+	"errors"
 	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
 	"github.com/ProtobufMan/bufman/internal/dal"
 	"github.com/ProtobufMan/bufman/internal/model"
+	"gorm.io/gorm"
 	"time"
 )
 
 type RepositoryMapper interface {
 	Create(repository *model.Repository) error
+	CreateFork(repository *model.Repository, commits model.Commits) error
 	FindByRepositoryID(repositoryID string) (*model.Repository, error)
+	FindAllByRepositoryIDs(repositoryIDs []string) (model.Repositories, error)
 	FindByUserNameAndRepositoryName(userName, RepositoryName string) (*model.Repository, error)
+	FindByUserNameAndRepositoryNameWithoutRedirect(userName, RepositoryName string) (*model.Repository, error)
 	FindPage(offset, limit int, reverse bool) (model.Repositories, error)
 	FindPageByQuery(query string, offset, limit int, reverse bool) (model.Repositories, error)
 	FindPageByUserID(userID string, offset, limit int, reverse bool) (model.Repositories, error)
@@ -19,8 +24,17 @@ type RepositoryMapper interface {
 	DeleteByUserNameAndRepositoryName(userName, RepositoryName string) error
 	UpdateByUserNameAndRepositoryName(userName, RepositoryName string, repository *model.Repository) error
 	UpdateDeprecatedByUserNameAndRepositoryName(userName, RepositoryName string, repository *model.Repository) error
+	UpdateOwnerAndNameByRepositoryID(repositoryID string, userID, userName, repositoryName string) error
+	CreateTransfer(transfer *model.RepositoryTransfer) error
+	FindTransferByRepositoryID(repositoryID string) (*model.RepositoryTransfer, error)
+	AcceptTransfer(transfer *model.RepositoryTransfer) error
+	UpdateStrictDependenciesByRepositoryID(repositoryID string, strictDependencies bool) error
 }
 
+var (
+	ErrRepositoryRedirected = errors.New("repository name redirected")
+)
+
 type RepositoryMapperImpl struct{}
 
 func (r *RepositoryMapperImpl) Create(repository *model.Repository) error {
@@ -31,21 +45,79 @@ func (r *RepositoryMapperImpl) Create(repository *model.Repository) error {
 			return err
 		}
 
+		// 名称上有重定向时不能创建，否则依赖旧名称的仓库会解析到新创建的仓库
+		err = r.checkRedirect(tx, repository.RepositoryID, repository.UserName, repository.RepositoryName)
+		if err != nil {
+			return err
+		}
+
 		// create
 		return tx.Repository.Create(repository)
 	})
 }
 
+func (r *RepositoryMapperImpl) CreateFork(repository *model.Repository, commits model.Commits) error {
+	return dal.Q.Transaction(func(tx *dal.Query) error {
+		// 更新用户 update time
+		_, err := tx.User.Where(tx.User.UserID.Eq(repository.UserID)).Update(tx.User.UpdateTime, time.Now())
+		if err != nil {
+			return err
+		}
+
+		err = r.checkRedirect(tx, repository.RepositoryID, repository.UserName, repository.RepositoryName)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Repository.Create(repository)
+		if err != nil {
+			return err
+		}
+
+		// 批量复制commit，文件清单、blobs、tag以及依赖关系随commit一起批量创建
+		if len(commits) == 0 {
+			return nil
+		}
+
+		return tx.Commit.CreateInBatches(commits, 100)
+	})
+}
+
 func (r *RepositoryMapperImpl) FindByRepositoryID(repositoryID string) (*model.Repository, error) {
 	return dal.Repository.Where(dal.Repository.RepositoryID.Eq(repositoryID)).First()
 }
 
+func (r *RepositoryMapperImpl) FindAllByRepositoryIDs(repositoryIDs []string) (model.Repositories, error) {
+	if len(repositoryIDs) == 0 {
+		return model.Repositories{}, nil
+	}
+
+	return dal.Repository.Where(dal.Repository.RepositoryID.In(repositoryIDs...)).Find()
+}
+
 func (r *RepositoryMapperImpl) FindByUserNameAndRepositoryName(userName, RepositoryName string) (*model.Repository, error) {
+	repository, err := dal.Repository.Where(dal.Repository.UserName.Eq(userName), dal.Repository.RepositoryName.Eq(RepositoryName)).First()
+	if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+		return repository, err
+	}
+
+	// 仓库可能被重命名或者转移，查询重定向
+	redirect, err := dal.RepositoryRedirect.Where(dal.RepositoryRedirect.UserName.Eq(userName), dal.RepositoryRedirect.RepositoryName.Eq(RepositoryName)).First()
+	if err != nil {
+		return nil, err
+	}
+
+	return r.FindByRepositoryID(redirect.RepositoryID)
+}
+
+// FindByUserNameAndRepositoryNameWithoutRedirect 只查询当前名称为 owner/name 的仓库，用于写入操作，旧名称不会解析到重命名或者转移后的仓库
+func (r *RepositoryMapperImpl) FindByUserNameAndRepositoryNameWithoutRedirect(userName, RepositoryName string) (*model.Repository, error) {
 	return dal.Repository.Where(dal.Repository.UserName.Eq(userName), dal.Repository.RepositoryName.Eq(RepositoryName)).First()
 }
 
 func (r *RepositoryMapperImpl) FindPage(offset, limit int, reverse bool) (model.Repositories, error) {
-	stmt := dal.Repository.Offset(offset).Limit(limit)
+	// 代理缓存的上游仓库不对外展示
+	stmt := dal.Repository.Where(dal.Repository.Remote.Eq("")).Offset(offset).Limit(limit)
 	if reverse {
 		stmt = stmt.Order(dal.Repository.ID.Desc())
 	}
@@ -54,7 +126,8 @@ func (r *RepositoryMapperImpl) FindPage(offset, limit int, reverse bool) (model.
 }
 
 func (r *RepositoryMapperImpl) FindPageByQuery(query string, offset, limit int, reverse bool) (model.Repositories, error) {
-	stmt := dal.Repository.Where(dal.Repository.RepositoryName.Like("%" + query + "%")).Or(dal.Repository.Description.Like("%" + query + "%")).Offset(offset).Limit(limit)
+	queryCondition := dal.Repository.Where(dal.Repository.RepositoryName.Like("%" + query + "%")).Or(dal.Repository.Description.Like("%" + query + "%"))
+	stmt := dal.Repository.Where(dal.Repository.Remote.Eq(""), queryCondition).Offset(offset).Limit(limit)
 	if reverse {
 		stmt = stmt.Order(dal.Repository.ID.Desc())
 	}
@@ -81,63 +154,235 @@ func (r *RepositoryMapperImpl) FindAccessiblePageByUserID(userID string, offset,
 }
 
 func (r *RepositoryMapperImpl) DeleteByRepositoryID(repositoryID string) error {
-	repository := &model.Repository{}
 	return dal.Q.Transaction(func(tx *dal.Query) error {
-		// 删除repo
-		_, err := tx.Repository.Where(tx.Repository.RepositoryID.Eq(repositoryID)).Delete(repository)
-		if err != nil {
-			return err
-		}
+		return r.deleteByRepositoryID(tx, repositoryID)
+	})
+}
 
-		// 删除commit
-		_, err = tx.Commit.Where(tx.Commit.RepositoryID.Eq(repositoryID)).Delete()
+func (r *RepositoryMapperImpl) DeleteByUserNameAndRepositoryName(userName, RepositoryName string) error {
+	return dal.Q.Transaction(func(tx *dal.Query) error {
+		// 查询repo，旧名称通过重定向找到仓库
+		repositoryID, err := r.findRepositoryID(tx, userName, RepositoryName)
 		if err != nil {
 			return err
 		}
 
-		// 删除tag
-		_, err = tx.Tag.Where(tx.Tag.RepositoryID.Eq(repositoryID)).Delete()
-		if err != nil {
-			return err
+		return r.deleteByRepositoryID(tx, repositoryID)
+	})
+}
+
+func (r *RepositoryMapperImpl) UpdateByUserNameAndRepositoryName(userName, RepositoryName string, repository *model.Repository) error {
+	repositoryID, err := r.findRepositoryID(dal.Q, userName, RepositoryName)
+	if err != nil {
+		return err
+	}
+	_, err = dal.Repository.Select(dal.Repository.Visibility, dal.Repository.Description).Where(dal.Repository.RepositoryID.Eq(repositoryID)).Updates(repository)
+
+	return err
+}
+
+func (r *RepositoryMapperImpl) UpdateDeprecatedByUserNameAndRepositoryName(userName, RepositoryName string, repository *model.Repository) error {
+	repositoryID, err := r.findRepositoryID(dal.Q, userName, RepositoryName)
+	if err != nil {
+		return err
+	}
+	_, err = dal.Repository.Select(dal.Repository.Deprecated, dal.Repository.DeprecationMsg).Where(dal.Repository.RepositoryID.Eq(repositoryID)).Updates(repository)
+
+	return err
+}
+
+// findRepositoryID 查询 owner/name 对应的仓库ID，仓库被重命名或者转移时通过重定向查询
+func (r *RepositoryMapperImpl) findRepositoryID(tx *dal.Query, userName, RepositoryName string) (string, error) {
+	repository, err := tx.Repository.Where(tx.Repository.UserName.Eq(userName), tx.Repository.RepositoryName.Eq(RepositoryName)).First()
+	if err == nil {
+		return repository.RepositoryID, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	redirect, err := tx.RepositoryRedirect.Where(tx.RepositoryRedirect.UserName.Eq(userName), tx.RepositoryRedirect.RepositoryName.Eq(RepositoryName)).First()
+	if err != nil {
+		return "", err
+	}
+
+	return redirect.RepositoryID, nil
+}
+
+// checkRedirect owner/name 上有指向其他仓库的重定向时返回 ErrRepositoryRedirected
+func (r *RepositoryMapperImpl) checkRedirect(tx *dal.Query, repositoryID, userName, RepositoryName string) error {
+	redirect, err := tx.RepositoryRedirect.Where(tx.RepositoryRedirect.UserName.Eq(userName), tx.RepositoryRedirect.RepositoryName.Eq(RepositoryName)).First()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
 		}
+		return err
+	}
+	if redirect.RepositoryID != repositoryID {
+		return ErrRepositoryRedirected
+	}
+
+	return nil
+}
+
+func (r *RepositoryMapperImpl) deleteByRepositoryID(tx *dal.Query, repositoryID string) error {
+	// 删除repo
+	_, err := tx.Repository.Where(tx.Repository.RepositoryID.Eq(repositoryID)).Delete()
+	if err != nil {
+		return err
+	}
+
+	// 删除commit
+	_, err = tx.Commit.Where(tx.Commit.RepositoryID.Eq(repositoryID)).Delete()
+	if err != nil {
+		return err
+	}
+
+	// 删除tag
+	_, err = tx.Tag.Where(tx.Tag.RepositoryID.Eq(repositoryID)).Delete()
+	if err != nil {
+		return err
+	}
+
+	// 删除依赖关系
+	_, err = tx.Dependency.Where(tx.Dependency.RepositoryID.Eq(repositoryID)).Delete()
+	if err != nil {
+		return err
+	}
+
+	// 删除包文档
+	_, err = tx.PackageDocumentation.Where(tx.PackageDocumentation.RepositoryID.Eq(repositoryID)).Delete()
+	if err != nil {
+		return err
+	}
+
+	// 删除符号索引
+	_, err = tx.Symbol.Where(tx.Symbol.RepositoryID.Eq(repositoryID)).Delete()
+	if err != nil {
+		return err
+	}
+
+	// 删除重定向
+	_, err = tx.RepositoryRedirect.Where(tx.RepositoryRedirect.RepositoryID.Eq(repositoryID)).Delete()
+	if err != nil {
+		return err
+	}
+
+	// 删除等待接受的转移
+	_, err = tx.RepositoryTransfer.Where(tx.RepositoryTransfer.RepositoryID.Eq(repositoryID)).Delete()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RepositoryMapperImpl) UpdateStrictDependenciesByRepositoryID(repositoryID string, strictDependencies bool) error {
+	_, err := dal.Repository.Where(dal.Repository.RepositoryID.Eq(repositoryID)).Update(dal.Repository.StrictDependencies, strictDependencies)
 
-		return nil
+	return err
+}
+
+// UpdateOwnerAndNameByRepositoryID 重命名仓库，旧的 owner/name 记录为重定向，同时更新commit和tag中冗余的用户名和仓库名
+func (r *RepositoryMapperImpl) UpdateOwnerAndNameByRepositoryID(repositoryID string, userID, userName, repositoryName string) error {
+	return dal.Q.Transaction(func(tx *dal.Query) error {
+		return r.updateOwnerAndName(tx, repositoryID, userID, userName, repositoryName)
 	})
 }
 
-func (r *RepositoryMapperImpl) DeleteByUserNameAndRepositoryName(userName, RepositoryName string) error {
-	repository := &model.Repository{}
+// CreateTransfer 发起仓库转移，覆盖仓库已有的待接受转移
+func (r *RepositoryMapperImpl) CreateTransfer(transfer *model.RepositoryTransfer) error {
 	return dal.Q.Transaction(func(tx *dal.Query) error {
-		// 删除repo
-		_, err := tx.Repository.Where(tx.Repository.UserName.Eq(userName), tx.Repository.RepositoryName.Eq(RepositoryName)).Delete(repository)
+		_, err := tx.RepositoryTransfer.Where(tx.RepositoryTransfer.RepositoryID.Eq(transfer.RepositoryID)).Delete()
 		if err != nil {
 			return err
 		}
 
-		// 删除commit
-		_, err = tx.Commit.Where(tx.Commit.RepositoryID.Eq(repository.RepositoryID)).Delete()
+		return tx.RepositoryTransfer.Create(transfer)
+	})
+}
+
+func (r *RepositoryMapperImpl) FindTransferByRepositoryID(repositoryID string) (*model.RepositoryTransfer, error) {
+	return dal.RepositoryTransfer.Where(dal.RepositoryTransfer.RepositoryID.Eq(repositoryID)).First()
+}
+
+// AcceptTransfer 新的所属用户接受转移，仓库转移给该用户，旧的 owner/name 记录为重定向
+func (r *RepositoryMapperImpl) AcceptTransfer(transfer *model.RepositoryTransfer) error {
+	return dal.Q.Transaction(func(tx *dal.Query) error {
+		repository, err := tx.Repository.Where(tx.Repository.RepositoryID.Eq(transfer.RepositoryID)).First()
 		if err != nil {
 			return err
 		}
 
-		// 删除tag
-		_, err = tx.Tag.Where(tx.Tag.RepositoryID.Eq(repository.RepositoryID)).Delete()
+		_, err = tx.RepositoryTransfer.Where(tx.RepositoryTransfer.RepositoryID.Eq(transfer.RepositoryID)).Delete()
 		if err != nil {
 			return err
 		}
 
-		return nil
+		return r.updateOwnerAndName(tx, transfer.RepositoryID, transfer.ToUserID, transfer.ToUserName, repository.RepositoryName)
 	})
 }
 
-func (r *RepositoryMapperImpl) UpdateByUserNameAndRepositoryName(userName, RepositoryName string, repository *model.Repository) error {
-	_, err := dal.Repository.Select(dal.Repository.Visibility, dal.Repository.Description).Where(dal.Repository.UserName.Eq(userName), dal.Repository.RepositoryName.Eq(RepositoryName)).Updates(repository)
+func (r *RepositoryMapperImpl) updateOwnerAndName(tx *dal.Query, repositoryID string, userID, userName, repositoryName string) error {
+	repository, err := tx.Repository.Where(tx.Repository.RepositoryID.Eq(repositoryID)).First()
+	if err != nil {
+		return err
+	}
 
-	return err
-}
+	// 新名称上的重定向指向其他仓库时不能占用
+	err = r.checkRedirect(tx, repositoryID, userName, repositoryName)
+	if err != nil {
+		return err
+	}
 
-func (r *RepositoryMapperImpl) UpdateDeprecatedByUserNameAndRepositoryName(userName, RepositoryName string, repository *model.Repository) error {
-	_, err := dal.Repository.Select(dal.Repository.Deprecated, dal.Repository.DeprecationMsg).Where(dal.Repository.UserName.Eq(userName), dal.Repository.RepositoryName.Eq(RepositoryName)).Updates(repository)
+	// 新名称如果之前是该仓库的重定向(改回旧名称)，现在由仓库本身占用
+	_, err = tx.RepositoryRedirect.Where(tx.RepositoryRedirect.UserName.Eq(userName), tx.RepositoryRedirect.RepositoryName.Eq(repositoryName)).Delete()
+	if err != nil {
+		return err
+	}
 
-	return err
+	// 记录旧名称的重定向，覆盖旧名称上已有的重定向
+	_, err = tx.RepositoryRedirect.Where(tx.RepositoryRedirect.UserName.Eq(repository.UserName), tx.RepositoryRedirect.RepositoryName.Eq(repository.RepositoryName)).Delete()
+	if err != nil {
+		return err
+	}
+	err = tx.RepositoryRedirect.Create(&model.RepositoryRedirect{
+		UserName:       repository.UserName,
+		RepositoryName: repository.RepositoryName,
+		RepositoryID:   repository.RepositoryID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// 更新repo
+	_, err = tx.Repository.Where(tx.Repository.RepositoryID.Eq(repositoryID)).UpdateSimple(
+		tx.Repository.UserID.Value(userID),
+		tx.Repository.UserName.Value(userName),
+		tx.Repository.RepositoryName.Value(repositoryName),
+	)
+	if err != nil {
+		return err
+	}
+
+	// 更新commits
+	_, err = tx.Commit.Where(tx.Commit.RepositoryID.Eq(repositoryID)).UpdateSimple(
+		tx.Commit.UserID.Value(userID),
+		tx.Commit.UserName.Value(userName),
+		tx.Commit.RepositoryName.Value(repositoryName),
+	)
+	if err != nil {
+		return err
+	}
+
+	// 更新tags
+	_, err = tx.Tag.Where(tx.Tag.RepositoryID.Eq(repositoryID)).UpdateSimple(
+		tx.Tag.UserID.Value(userID),
+		tx.Tag.UserName.Value(userName),
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
@@ -0,0 +1,86 @@
+package mapper
+
+import (
+	"errors"
+	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+	"github.com/ProtobufMan/bufman/internal/dal"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"gorm.io/gen"
+	"gorm.io/gorm"
+	"strings"
+)
+
+// likeEscaper 转义LIKE中的通配符，使用默认的转义字符 \
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+type SymbolMapper interface {
+	ReplaceByRepositoryID(repositoryID string, sequenceID int64, symbols model.Symbols) error
+	FindAccessiblePageByQuery(userID string, query *SymbolQuery, offset, limit int, reverse bool) (model.Symbols, error)
+}
+
+// SymbolQuery 符号搜索条件，为空的条件不参与过滤
+type SymbolQuery struct {
+	Query        string // 匹配符号名称或者全名
+	Kind         string
+	PackageName  string
+	RepositoryID string
+}
+
+type SymbolMapperImpl struct{}
+
+// ReplaceByRepositoryID 使用sequenceID对应commit的符号替换仓库之前的符号
+// push的后台任务完成顺序与commit顺序不一定相同，之前的符号属于更新的commit时不替换
+func (s *SymbolMapperImpl) ReplaceByRepositoryID(repositoryID string, sequenceID int64, symbols model.Symbols) error {
+	return dal.Q.Transaction(func(tx *dal.Query) error {
+		stored, err := tx.Symbol.Select(tx.Symbol.CommitID).Where(tx.Symbol.RepositoryID.Eq(repositoryID)).First()
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			newer, countErr := tx.Commit.Where(tx.Commit.CommitID.Eq(stored.CommitID), tx.Commit.SequenceID.Gt(sequenceID)).Count()
+			if countErr != nil {
+				return countErr
+			}
+			if newer > 0 {
+				return nil
+			}
+		}
+
+		_, err = tx.Symbol.Where(tx.Symbol.RepositoryID.Eq(repositoryID)).Delete()
+		if err != nil {
+			return err
+		}
+
+		if len(symbols) > 0 {
+			return tx.Symbol.CreateInBatches(symbols, 500)
+		}
+
+		return nil
+	})
+}
+
+// FindAccessiblePageByQuery 只查询public仓库以及用户自己仓库中的符号
+func (s *SymbolMapperImpl) FindAccessiblePageByQuery(userID string, query *SymbolQuery, offset, limit int, reverse bool) (model.Symbols, error) {
+	// 符号名称中常见下划线，需要按照字面匹配
+	pattern := "%" + likeEscaper.Replace(query.Query) + "%"
+	conditions := []gen.Condition{
+		dal.Symbol.Where(dal.Symbol.Name.Like(pattern)).Or(dal.Symbol.FullName.Like(pattern)),
+		dal.Repository.Where(dal.Repository.Visibility.Eq(uint8(registryv1alpha1.Visibility_VISIBILITY_PUBLIC))).Or(dal.Repository.UserID.Eq(userID)),
+	}
+	if query.Kind != "" {
+		conditions = append(conditions, dal.Symbol.Kind.Eq(query.Kind))
+	}
+	if query.PackageName != "" {
+		conditions = append(conditions, dal.Symbol.PackageName.Eq(query.PackageName))
+	}
+	if query.RepositoryID != "" {
+		conditions = append(conditions, dal.Symbol.RepositoryID.Eq(query.RepositoryID))
+	}
+
+	stmt := dal.Symbol.Select(dal.Symbol.ALL).Join(dal.Repository, dal.Repository.RepositoryID.EqCol(dal.Symbol.RepositoryID)).Where(conditions...).Offset(offset).Limit(limit)
+	if reverse {
+		stmt = stmt.Order(dal.Symbol.ID.Desc())
+	}
+
+	return stmt.Find()
+}
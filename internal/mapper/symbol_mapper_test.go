@@ -0,0 +1,19 @@
+package mapper
+
+import "testing"
+
+func TestLikeEscaper(t *testing.T) {
+	tests := map[string]string{
+		"Weather":      "Weather",
+		"get_weather":  `get\_weather`,
+		"100%":         `100\%`,
+		`a\b`:          `a\\b`,
+		`_%\`:          `\_\%\\`,
+		"weather.v1.*": "weather.v1.*",
+	}
+	for query, expected := range tests {
+		if actual := likeEscaper.Replace(query); actual != expected {
+			t.Errorf("likeEscaper.Replace(%q) = %q, want %q", query, actual, expected)
+		}
+	}
+}
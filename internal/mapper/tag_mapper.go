@@ -1,13 +1,17 @@
 package mapper
 
 import (
+	"errors"
 	"github.com/ProtobufMan/bufman/internal/dal"
 	"github.com/ProtobufMan/bufman/internal/model"
+	"gorm.io/gorm"
 )
 
 type TagMapper interface {
 	Create(tag *model.Tag) error
 	GetCountsByRepositoryID(repositoryID string) (int64, error)
+	FindAllByRepositoryID(repositoryID string) (model.Tags, error)
+	FindAllByCommitID(commitID string) (model.Tags, error)
 	FindPageByRepositoryID(repositoryID string, offset, limit int, reverse bool) (model.Tags, error)
 	FindPageByRepositoryIDAndQuery(repositoryID, query string, offset, limit int, reverse bool) (model.Tags, error)
 }
@@ -15,13 +19,41 @@ type TagMapper interface {
 type TagMapperImpl struct{}
 
 func (t *TagMapperImpl) Create(tag *model.Tag) error {
-	return dal.Tag.Create(tag)
+	return dal.Q.Transaction(func(tx *dal.Query) error {
+		// 检查tag是否已经存在
+		_, err := tx.Tag.Where(tx.Tag.RepositoryID.Eq(tag.RepositoryID), tx.Tag.TagName.Eq(tag.TagName)).First()
+		if err == nil {
+			return ErrTagDuplicated
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// 检查tag和draft是否冲突
+		_, err = tx.Commit.Where(tx.Commit.RepositoryID.Eq(tag.RepositoryID), tx.Commit.DraftName.Eq(tag.TagName)).First()
+		if err == nil {
+			return ErrTagAndDraftDuplicated
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		return tx.Tag.Create(tag)
+	})
 }
 
 func (t *TagMapperImpl) GetCountsByRepositoryID(repositoryID string) (int64, error) {
 	return dal.Tag.Where(dal.Tag.RepositoryID.Eq(repositoryID)).Count()
 }
 
+func (t *TagMapperImpl) FindAllByRepositoryID(repositoryID string) (model.Tags, error) {
+	return dal.Tag.Where(dal.Tag.RepositoryID.Eq(repositoryID)).Order(dal.Tag.ID).Find()
+}
+
+func (t *TagMapperImpl) FindAllByCommitID(commitID string) (model.Tags, error) {
+	return dal.Tag.Where(dal.Tag.CommitID.Eq(commitID)).Order(dal.Tag.ID).Find()
+}
+
 func (t *TagMapperImpl) FindPageByRepositoryID(repositoryID string, offset, limit int, reverse bool) (model.Tags, error) {
 	stmt := dal.Tag.Where(dal.Tag.RepositoryID.Eq(repositoryID)).Offset(offset).Limit(limit)
 	if reverse {
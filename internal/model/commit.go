@@ -24,6 +24,7 @@ import (
 	"github.com/ProtobufMan/bufman/internal/config"
 	"github.com/ProtobufMan/bufman/internal/constant"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"strings"
 	"time"
 )
 
@@ -44,18 +45,35 @@ type Commit struct {
 
 	SequenceID int64
 
+	PackageDocumentationGenerated bool // 是否已经生成了全部的包文档
+
+	DependenciesRecorded bool // 是否已经记录了直接依赖关系，之前push的commit由backfill补全
+
+	DependencyWarnings string `gorm:"type:text"` // push时依赖检查产生的警告，每行一条
+
 	// 文件清单
 	FileManifest *FileManifest `gorm:"foreignKey:CommitID;references:CommitID"`
 	// 文件blobs
 	FileBlobs FileBlobs `gorm:"foreignKey:CommitID;references:CommitID"`
 	// 关联的tag
 	Tags Tags `gorm:"foreignKey:RepositoryID;references:RepositoryID"`
+	// 直接依赖
+	Dependencies Dependencies `gorm:"foreignKey:CommitID;references:CommitID"`
 }
 
 func (commit *Commit) TableName() string {
 	return "commits"
 }
 
+// Warnings push时依赖检查产生的警告
+func (commit *Commit) Warnings() []string {
+	if commit.DependencyWarnings == "" {
+		return nil
+	}
+
+	return strings.Split(commit.DependencyWarnings, "\n")
+}
+
 func (commit *Commit) ToProtoLocalModulePin() *registryv1alpha1.LocalModulePin {
 	if commit == nil {
 		return (&Commit{}).ToProtoLocalModulePin()
@@ -85,9 +103,10 @@ func (commit *Commit) ToProtoModulePin() *modulev1alpha1.ModulePin {
 		return (&Commit{}).ToProtoModulePin()
 	}
 
+	remote, owner := commit.RemoteAndOwner()
 	modulePin := &modulev1alpha1.ModulePin{
-		Remote:         config.Properties.BufMan.ServerHost,
-		Owner:          commit.UserName,
+		Remote:         remote,
+		Owner:          owner,
 		Repository:     commit.RepositoryName,
 		Commit:         commit.CommitName,
 		CreateTime:     timestamppb.New(commit.CreatedTime),
@@ -97,6 +116,22 @@ func (commit *Commit) ToProtoModulePin() *modulev1alpha1.ModulePin {
 	return modulePin
 }
 
+// RemoteAndOwner 返回commit所在模块的remote和owner
+func (commit *Commit) RemoteAndOwner() (string, string) {
+	if index := strings.Index(commit.UserName, "/"); index >= 0 {
+		// 代理缓存的上游commit，用户名为 remote/owner
+		return commit.UserName[:index], commit.UserName[index+1:]
+	}
+
+	return config.Properties.BufMan.ServerHost, commit.UserName
+}
+
+// IdentityString 返回commit所在模块的 remote/owner/repository
+func (commit *Commit) IdentityString() string {
+	remote, owner := commit.RemoteAndOwner()
+	return remote + "/" + owner + "/" + commit.RepositoryName
+}
+
 func (commit *Commit) ToProtoRepositoryCommit() *registryv1alpha1.RepositoryCommit {
 	if commit == nil {
 		return (&Commit{}).ToProtoRepositoryCommit()
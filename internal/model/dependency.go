@@ -0,0 +1,48 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"time"
+)
+
+// Dependency 依赖关系，push时记录commit解析得到的全部依赖，之后生成文档等使用相同的依赖
+type Dependency struct {
+	ID                     int64     `gorm:"primaryKey;autoIncrement"`
+	RepositoryID           string    `gorm:"type:varchar(64);index"` // 依赖方所在的仓库
+	CommitID               string    `gorm:"type:varchar(64);index"` // 依赖方commit
+	CommitName             string    `gorm:"type:varchar(64)"`
+	DependencyRepositoryID string    `gorm:"type:varchar(64);index"` // 被依赖的仓库
+	DependencyCommitID     string    `gorm:"type:varchar(64);index"` // 被依赖的commit
+	DependencyCommitName   string    `gorm:"type:varchar(64)"`
+	Transitive             bool      // 是否为间接依赖，查询依赖方时只使用直接依赖
+	CreatedTime            time.Time `gorm:"autoCreateTime"`
+}
+
+func (dependency *Dependency) TableName() string {
+	return "dependencies"
+}
+
+type Dependencies []*Dependency
+
+// Dependent 依赖某个仓库的仓库
+type Dependent struct {
+	Repository  *Repository
+	Depth       int      // 1表示直接依赖，大于1表示间接依赖
+	CommitNames []string // 仓库中依赖目标的commit
+}
@@ -0,0 +1,38 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"time"
+)
+
+// PackageDocumentation push之后异步生成的包文档
+type PackageDocumentation struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement"`
+	RepositoryID string    `gorm:"type:varchar(64);index"`
+	CommitID     string    `gorm:"type:varchar(64);uniqueIndex:uni_commit_id_package_name"`
+	PackageName  string    `gorm:"type:varchar(200);uniqueIndex:uni_commit_id_package_name"`
+	Content      []byte    `gorm:"type:mediumblob"` // 序列化后的 registryv1alpha1.PackageDocumentation
+	CreatedTime  time.Time `gorm:"autoCreateTime"`
+}
+
+func (documentation *PackageDocumentation) TableName() string {
+	return "package_documentations"
+}
+
+type PackageDocumentations []*PackageDocumentation
@@ -75,6 +75,36 @@ func (fileBlobs *FileBlobs) ToProtoFileInfo() *registryv1alpha1.FileInfo {
 	return root
 }
 
+// ToProtoDirectoryInfo 只返回directory目录下的子树，directory为空时返回根目录
+func (fileBlobs *FileBlobs) ToProtoDirectoryInfo(directory string) (*registryv1alpha1.FileInfo, bool) {
+	root := fileBlobs.ToProtoFileInfo()
+	directory = strings.Trim(filepath.ToSlash(directory), "/")
+	if directory == "" || directory == "." {
+		return root, true
+	}
+
+	// 按照路径逐层查找目录
+	level := root
+	pathLists := strings.Split(directory, "/")
+	for i := 0; i < len(pathLists); i++ {
+		path := strings.Join(pathLists[:i+1], "/")
+		var next *registryv1alpha1.FileInfo
+		for _, child := range level.Children {
+			if child.Path == path && child.IsDir {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return nil, false
+		}
+
+		level = next
+	}
+
+	return level, true
+}
+
 func doToProtoFileInfo(root *registryv1alpha1.FileInfo, filePath string) {
 	// 分割file path
 	filePath = filepath.ToSlash(filePath)
@@ -0,0 +1,58 @@
+package model
+
+import (
+	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+	"testing"
+)
+
+func childPaths(fileInfo *registryv1alpha1.FileInfo) []string {
+	paths := make([]string, 0, len(fileInfo.Children))
+	for _, child := range fileInfo.Children {
+		paths = append(paths, child.Path)
+	}
+
+	return paths
+}
+
+func TestToProtoDirectoryInfo(t *testing.T) {
+	fileBlobs := FileBlobs{
+		{FileName: "buf.yaml"},
+		{FileName: "acme/weather/v1/weather.proto"},
+		{FileName: "acme/weather/v1/forecast.proto"},
+		{FileName: "acme/common/v1/location.proto"},
+	}
+
+	tests := []struct {
+		directory string
+		expected  []string
+	}{
+		{directory: "", expected: []string{"buf.yaml", "acme"}},
+		{directory: ".", expected: []string{"buf.yaml", "acme"}},
+		{directory: "acme", expected: []string{"acme/weather", "acme/common"}},
+		// 嵌套目录，首尾的 / 不影响结果
+		{directory: "/acme/weather/v1/", expected: []string{"acme/weather/v1/weather.proto", "acme/weather/v1/forecast.proto"}},
+	}
+	for _, test := range tests {
+		fileInfo, ok := fileBlobs.ToProtoDirectoryInfo(test.directory)
+		if !ok {
+			t.Fatalf("%q: expected directory", test.directory)
+		}
+		actual := childPaths(fileInfo)
+		if len(actual) != len(test.expected) {
+			t.Fatalf("%q: expected %v, got %v", test.directory, test.expected, actual)
+		}
+		for i := range actual {
+			if actual[i] != test.expected[i] {
+				t.Errorf("%q: expected %v, got %v", test.directory, test.expected, actual)
+				break
+			}
+		}
+	}
+
+	// 不存在的目录以及文件都不是目录
+	for _, directory := range []string{"acme/missing", "buf.yaml", "acme/weather/v1/weather.proto"} {
+		if _, ok := fileBlobs.ToProtoDirectoryInfo(directory); ok {
+			t.Errorf("%q: expected no directory", directory)
+		}
+	}
+}
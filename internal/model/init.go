@@ -23,7 +23,12 @@ func InitDB() {
 		// init table
 		initErr := DB.AutoMigrate(
 			&Repository{},
+			&RepositoryRedirect{},
+			&RepositoryTransfer{},
 			&Commit{},
+			&Dependency{},
+			&PackageDocumentation{},
+			&Symbol{},
 			&Tag{},
 			&User{},
 			&Token{},
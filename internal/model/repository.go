@@ -38,6 +38,12 @@ type Repository struct {
 	Url            string    // 描述信息中的Url
 	Description    string    // 描述信息
 
+	ForkedFromRepositoryID string `gorm:"type:varchar(64);index"` // fork的上游仓库，为空时表示不是fork
+
+	Remote string `gorm:"type:varchar(200);index"` // 代理缓存的上游remote，为空时表示本地仓库，不为空时UserName为 remote/owner
+
+	StrictDependencies bool // 严格依赖检查，开启后push时存在未使用的依赖或者未声明的依赖会直接返回错误
+
 	// 拥有的draft
 	DraftCommits []*Commit `gorm:"foreignKey:RepositoryID;references:RepositoryID"`
 	// 拥有的tag
@@ -48,6 +54,33 @@ func (repository *Repository) TableName() string {
 	return "repositories"
 }
 
+// RepositoryRedirect 仓库重命名或转移后，旧的 owner/name 重定向到仓库
+type RepositoryRedirect struct {
+	ID             int64     `gorm:"primaryKey;autoIncrement"`
+	UserName       string    `gorm:"type:varchar(200);uniqueIndex:uni_user_name_repository_name"` // 旧的所属用户名
+	RepositoryName string    `gorm:"type:varchar(200);uniqueIndex:uni_user_name_repository_name"` // 旧的仓库名
+	RepositoryID   string    `gorm:"type:varchar(64);index"`                                      // 重定向到的仓库
+	CreatedTime    time.Time `gorm:"autoCreateTime"`
+}
+
+func (redirect *RepositoryRedirect) TableName() string {
+	return "repository_redirects"
+}
+
+// RepositoryTransfer 等待新的所属用户接受的仓库转移，一个仓库同时只有一个
+type RepositoryTransfer struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement"`
+	RepositoryID string    `gorm:"type:varchar(64);uniqueIndex"`
+	FromUserID   string    `gorm:"type:varchar(64)"`       // 发起转移的用户
+	ToUserID     string    `gorm:"type:varchar(64);index"` // 转移到的用户
+	ToUserName   string    `gorm:"type:varchar(200)"`
+	CreatedTime  time.Time `gorm:"autoCreateTime"`
+}
+
+func (transfer *RepositoryTransfer) TableName() string {
+	return "repository_transfers"
+}
+
 func (repository *Repository) ToProtoRepository() *registryv1alpha1.Repository {
 	if repository == nil {
 		return (&Repository{}).ToProtoRepository()
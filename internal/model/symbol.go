@@ -0,0 +1,46 @@
+/*
+ * Licensed to the Apache Software Foundation (ASF) under one or more
+ * contributor license agreements.  See the NOTICE file distributed with
+ * this work for additional information regarding copyright ownership.
+ * The ASF licenses this file to You under the Apache License, Version 2.0
+ * (the "License"); you may not use this file except in compliance with
+ * the License.  You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package model
+
+import (
+	"time"
+)
+
+// Symbol push之后从编译结果中提取的proto符号，每个仓库只保留最新一次push的符号
+type Symbol struct {
+	ID           int64  `gorm:"primaryKey;autoIncrement"`
+	RepositoryID string `gorm:"type:varchar(64);index"`
+	CommitID     string `gorm:"type:varchar(64)"`
+	CommitName   string `gorm:"type:varchar(64)"`
+	Kind         string `gorm:"type:varchar(20);index:idx_kind_name"` // message/field/enum/service/method
+	Name         string `gorm:"type:varchar(200);index:idx_kind_name"`
+	FullName     string `gorm:"type:varchar(500);index"`
+	PackageName  string `gorm:"type:varchar(200);index"`
+	FilePath     string `gorm:"type:varchar(500)"`
+	StartLine    int32
+	StartColumn  int32
+	EndLine      int32
+	EndColumn    int32
+	CreatedTime  time.Time `gorm:"autoCreateTime"`
+}
+
+func (symbol *Symbol) TableName() string {
+	return "symbols"
+}
+
+type Symbols []*Symbol
@@ -29,22 +29,35 @@ func InitHTTPRouter(r *gin.Engine) {
 
 	repository := router.Group("/repository")
 	{
-		repository.POST("/create", http_handlers.RepositoryGroup.CreateRepositoryByFullName)             // 创建repository
-		repository.GET("/:id", http_handlers.RepositoryGroup.GetRepository)                              // 根据id获取repository
-		repository.POST("/list", http_handlers.RepositoryGroup.ListRepositories)                         // 批量查询所有repository
-		repository.DELETE("/:id", http_handlers.RepositoryGroup.DeleteRepository)                        // 删除repository
-		repository.POST("/list/:user_id", http_handlers.RepositoryGroup.ListUserRepositories)            // 批量查询用户的repository
-		repository.POST("/list_accessible", http_handlers.RepositoryGroup.ListRepositoriesUserCanAccess) // 批量查询当前用户可访问的repository
-		repository.PUT("/deprecate", http_handlers.RepositoryGroup.DeprecateRepositoryByName)            // 弃用repository
-		repository.PUT("/undeprecate", http_handlers.RepositoryGroup.UndeprecateRepositoryByName)        // 解除弃用
-		repository.PUT("/update", http_handlers.RepositoryGroup.UpdateRepositorySettingsByName)          // 更新repository
+		repository.POST("/create", http_handlers.RepositoryGroup.CreateRepositoryByFullName)                                            // 创建repository
+		repository.GET("/:id", http_handlers.RepositoryGroup.GetRepository)                                                             // 根据id获取repository
+		repository.POST("/list", http_handlers.RepositoryGroup.ListRepositories)                                                        // 批量查询所有repository
+		repository.DELETE("/:id", http_handlers.RepositoryGroup.DeleteRepository)                                                       // 删除repository
+		repository.POST("/list/:user_id", http_handlers.RepositoryGroup.ListUserRepositories)                                           // 批量查询用户的repository
+		repository.POST("/list_accessible", http_handlers.RepositoryGroup.ListRepositoriesUserCanAccess)                                // 批量查询当前用户可访问的repository
+		repository.PUT("/deprecate", http_handlers.RepositoryGroup.DeprecateRepositoryByName)                                           // 弃用repository
+		repository.PUT("/undeprecate", http_handlers.RepositoryGroup.UndeprecateRepositoryByName)                                       // 解除弃用
+		repository.PUT("/update", http_handlers.RepositoryGroup.UpdateRepositorySettingsByName)                                         // 更新repository
+		repository.PUT("/rename", http_handlers.RepositoryGroup.RenameRepository)                                                       // 重命名repository，旧名称重定向到新名称
+		repository.PUT("/transfer", http_handlers.RepositoryGroup.TransferRepository)                                                   // 发起转移repository给其他用户
+		repository.PUT("/transfer/accept", http_handlers.RepositoryGroup.AcceptRepositoryTransfer)                                      // 新的所属用户接受转移，旧名称重定向到新名称
+		repository.POST("/fork", http_handlers.RepositoryGroup.ForkRepository)                                                          // fork repository
+		repository.GET("/fork/compare/:repository_owner/:repository_name", http_handlers.RepositoryGroup.CompareRepositoryWithUpstream) // 与上游仓库比较commits
+		repository.POST("/dependents", http_handlers.RepositoryGroup.ListRepositoryDependents)                                          // 查询依赖该repository的repository
+		repository.GET("/dependency_graph/:repository_owner/:repository_name", http_handlers.RepositoryGroup.GetDependencyGraph)        // 获取依赖图，支持json、dot、mermaid格式
+		repository.PUT("/dependency_policy", http_handlers.RepositoryGroup.UpdateRepositoryDependencyPolicy)                            // 修改push时的依赖检查策略
+		repository.GET("/archive/:repository_owner/:repository_name/:reference", http_handlers.DownloadGroup.DownloadArchive)           // 下载tar.gz或zip压缩包，可以包含所有依赖
+		repository.GET("/image/:repository_owner/:repository_name/:reference", http_handlers.ImageGroup.GetImage)                       // 编译模块生成image(FileDescriptorSet)，支持binary、json格式
+		repository.POST("/reflection", http_handlers.ImageGroup.GetFileDescriptorSet)                                                   // 获取symbols以及依赖类型的最小FileDescriptorSet
 
 		commit := repository.Group("/commit")
 		{
 			commit.POST("/list/:repository_owner/:repository_name/:reference", http_handlers.CommitGroup.ListRepositoryCommitsByReference) // 获取reference对应commit以及之前的commits
 			commit.GET("/:repository_owner/:repository_name/:reference", http_handlers.CommitGroup.GetRepositoryCommitByReference)         // 获取reference对应commit
+			commit.GET("/resolve/:repository_owner/:repository_name/:reference", http_handlers.CommitGroup.ResolveRepositoryReference)     // 解析reference，返回匹配的类型以及commit
 			commit.POST("/draft/list/:repository_owner/:repository_name", http_handlers.CommitGroup.ListRepositoryDraftCommits)            // 获取所有的草稿
 			commit.DELETE("/draft/:repository_owner/:repository_name/:draft_name", http_handlers.CommitGroup.DeleteRepositoryDraftCommit)  // 删除草稿
+			commit.POST("/verify_lock", http_handlers.CommitGroup.VerifyLock)                                                              // 校验buf.lock中的pin是否存在、digest是否一致、是否与buf.yaml的依赖一致
 		}
 
 		tag := repository.Group("/tag")
@@ -56,10 +69,18 @@ func InitHTTPRouter(r *gin.Engine) {
 		doc := repository.Group("/doc")
 		{
 			doc.GET("/source/:repository_owner/:repository_name/:reference", http_handlers.DocGroup.GetSourceDirectoryInfo)                 // 获取目录信息
-			doc.GET("/source/:repository_owner/:repository_name/:reference/:path", http_handlers.DocGroup.GetSourceFile)                    // 获取文件源码
+			doc.GET("/source/:repository_owner/:repository_name/:reference/*path", http_handlers.DocGroup.GetSourceFile)                    // 获取文件源码，path可以包含多级目录
+			doc.GET("/raw/:repository_owner/:repository_name/:reference/*path", http_handlers.DocGroup.DownloadSourceFile)                  // 下载文件原始内容
+			doc.GET("/directory/:repository_owner/:repository_name/:reference/*path", http_handlers.DocGroup.GetSourceDirectory)            // 获取目录下的子树
+			doc.GET("/definition/:repository_owner/:repository_name/:reference/*path", http_handlers.DocGroup.GetDefinition)                // 跳转到line、column位置引用的类型的定义
+			doc.GET("/references/:repository_owner/:repository_name/:reference", http_handlers.DocGroup.ListReferences)                     // 查询模块中对symbol的所有引用
+			doc.GET("/blame/:repository_owner/:repository_name/:reference/*path", http_handlers.DocGroup.GetBlame)                          // 查询文件每一行最后修改的commit
+			doc.GET("/history/:repository_owner/:repository_name/:reference/*path", http_handlers.DocGroup.GetFileHistory)                  // 分页查询文件内容发生变化的commits
 			doc.GET("/module/:repository_owner/:repository_name/:reference", http_handlers.DocGroup.GetModuleDocumentation)                 // 获取repo说明文档
 			doc.GET("/package/:repository_owner/:repository_name/:reference", http_handlers.DocGroup.GetModulePackages)                     // 获取repo packages
 			doc.GET("/package/:repository_owner/:repository_name/:reference/:package_name", http_handlers.DocGroup.GetPackageDocumentation) //获取包说明文档
+			doc.GET("/export/:repository_owner/:repository_name/:reference", http_handlers.DocGroup.ExportDocumentation)                    // 导出静态HTML/Markdown文档压缩包
+			doc.GET("/schema/:repository_owner/:repository_name/:reference/:package_name", http_handlers.DocGroup.ExportSchema)             // 导出OpenAPI文档或JSON Schema
 		}
 	}
 
@@ -86,5 +107,6 @@ func InitHTTPRouter(r *gin.Engine) {
 		search.POST("/plugin", http_handlers.SearchGroup.SearchCurationPlugin)      // 搜索插件
 		search.POST("/tag", http_handlers.SearchGroup.SearchTag)                    // 搜索tag
 		search.POST("/draft", http_handlers.SearchGroup.SearchDraft)                // 搜索草稿
+		search.POST("/symbol", http_handlers.SearchGroup.SearchSymbol)              // 搜索message、field、enum、service、method
 	}
 }
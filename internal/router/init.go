@@ -1,11 +1,19 @@
 package router
 
-import "github.com/gin-gonic/gin"
+import (
+	"expvar"
+	"github.com/gin-gonic/gin"
+)
 
 func InitRouter() *gin.Engine {
 	router := gin.Default()
 	InitGRPCRouter(router)
 	InitHTTPRouter(router)
 
+	if gin.IsDebugging() {
+		// 运行时指标，例如编译缓存命中率，只在debug模式下提供
+		router.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+	}
+
 	return router
 }
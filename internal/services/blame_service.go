@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"github.com/ProtobufMan/bufman/internal/config"
+	"github.com/ProtobufMan/bufman/internal/core/blame"
+	"github.com/ProtobufMan/bufman/internal/core/storage"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/ProtobufMan/bufman/internal/mapper"
+	"github.com/ProtobufMan/bufman/internal/model"
+)
+
+type BlameService interface {
+	GetBlame(ctx context.Context, repositoryID, reference, path string) (*model.Commit, []*BlameLine, e.ResponseError)
+}
+
+// BlameLine 文件中的一行以及最后修改它的commit
+type BlameLine struct {
+	Content string
+	Commit  *model.Commit
+}
+
+// blameCache blame结果按照(commit, path)缓存
+var blameCache = newResultCache(func() int64 { return config.Properties.BufMan.BlameCacheSize })
+
+type BlameServiceImpl struct {
+	commitMapper  mapper.CommitMapper
+	fileMapper    mapper.FileMapper
+	storageHelper storage.StorageHelper
+	moduleLoader  *moduleLoader
+}
+
+func NewBlameService() BlameService {
+	return &BlameServiceImpl{
+		commitMapper:  &mapper.CommitMapperImpl{},
+		fileMapper:    &mapper.FileMapperImpl{},
+		storageHelper: storage.NewStorageHelper(),
+		moduleLoader:  newModuleLoader(),
+	}
+}
+
+// GetBlame 按照从旧到新的顺序遍历commit历史，计算文件每一行最后修改的commit
+func (blameService *BlameServiceImpl) GetBlame(ctx context.Context, repositoryID, reference, path string) (*model.Commit, []*BlameLine, e.ResponseError) {
+	// 查询reference对应的commit
+	target, err := blameService.moduleLoader.getCommitByReference(repositoryID, reference)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cacheKey := target.CommitID + "/" + path
+	if lines, ok := blameCache.Get(cacheKey); ok {
+		return target, lines.([]*BlameLine), nil
+	}
+
+	// 一次查询出所有commit中该路径的文件
+	fileBlobs, findErr := blameService.fileMapper.FindAllBlobsByRepositoryIDAndPath(target.RepositoryID, path)
+	if findErr != nil {
+		return nil, nil, e.NewInternalError(findErr.Error())
+	}
+	digests := make(map[string]string, len(fileBlobs))
+	for _, fileBlob := range fileBlobs {
+		digests[fileBlob.CommitID] = fileBlob.Digest
+	}
+
+	blamer := blame.NewBlamer()
+	var history model.Commits // 下标为blame中的revision
+	lastDigest := ""
+	apply := func(commit *model.Commit) e.ResponseError {
+		digest, ok := digests[commit.CommitID]
+		if !ok {
+			// 文件在该commit中不存在
+			if lastDigest != "" {
+				blamer.Apply(len(history), nil)
+				lastDigest = ""
+			}
+			return nil
+		}
+		if digest == lastDigest {
+			// 文件没有修改
+			return nil
+		}
+
+		content, err := blameService.storageHelper.ReadBlob(ctx, digest)
+		if err != nil {
+			return e.NewInternalError(err.Error())
+		}
+		blamer.Apply(len(history), content)
+		history = append(history, commit)
+		lastDigest = digest
+		return nil
+	}
+
+	// 遍历commit历史
+	if respErr := walkCommitHistory(blameService.commitMapper, target, apply); respErr != nil {
+		return nil, nil, respErr
+	}
+	if lastDigest == "" {
+		return nil, nil, e.NewNotFoundError(fmt.Sprintf("file %s", path))
+	}
+
+	var size int64
+	lines := make([]*BlameLine, 0, len(blamer.Lines()))
+	for _, line := range blamer.Lines() {
+		lines = append(lines, &BlameLine{
+			Content: line.Content,
+			Commit:  history[line.Revision],
+		})
+		size += int64(len(line.Content)) + 16
+	}
+	_ = blameCache.Add(cacheKey, lines, size)
+
+	return target, lines, nil
+}
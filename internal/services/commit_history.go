@@ -0,0 +1,41 @@
+package services
+
+import (
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/ProtobufMan/bufman/internal/mapper"
+	"github.com/ProtobufMan/bufman/internal/model"
+)
+
+// commitHistoryPageSize 遍历commit历史时每次查询的数量
+const commitHistoryPageSize = 100
+
+// walkCommitHistory 按照从旧到新的顺序遍历默认分支上target以及之前的commits，target是draft时最后遍历target
+// draft没有sequence id，使用创建时间判断先后
+func walkCommitHistory(commitMapper mapper.CommitMapper, target *model.Commit, fn func(commit *model.Commit) e.ResponseError) e.ResponseError {
+	for offset := 0; ; offset += commitHistoryPageSize {
+		commits, err := commitMapper.FindPageByRepositoryID(target.RepositoryID, offset, commitHistoryPageSize, false)
+		if err != nil {
+			return e.NewInternalError(err.Error())
+		}
+
+		finished := len(commits) < commitHistoryPageSize
+		for _, commit := range commits {
+			if target.DraftName == "" && commit.SequenceID > target.SequenceID ||
+				target.DraftName != "" && commit.CreatedTime.After(target.CreatedTime) {
+				finished = true
+				break
+			}
+			if respErr := fn(commit); respErr != nil {
+				return respErr
+			}
+		}
+		if finished {
+			break
+		}
+	}
+
+	if target.DraftName != "" {
+		return fn(target)
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"testing"
+	"time"
+)
+
+const testHistoryRepositoryID = "history-id"
+
+// newTestHistoryStore 每个commit的文件由files给出，commit名称为c1、c2...
+func newTestHistoryStore(files ...map[string]string) *testStore {
+	store := newTestStore()
+	createdTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, commitFiles := range files {
+		commitName := fmt.Sprintf("c%d", i+1)
+		store.commits = append(store.commits, &model.Commit{
+			RepositoryID: testHistoryRepositoryID,
+			CommitID:     commitName + "-id",
+			CommitName:   commitName,
+			SequenceID:   int64(i + 1),
+			CreatedTime:  createdTime.Add(time.Duration(i) * time.Hour),
+		})
+		store.files[commitName+"-id"] = commitFiles
+	}
+
+	return store
+}
+
+func walkTestHistory(t *testing.T, store *testStore, target *model.Commit) []string {
+	var visited []string
+	respErr := walkCommitHistory(&testCommitMapper{store: store}, target, func(commit *model.Commit) e.ResponseError {
+		visited = append(visited, commit.CommitName)
+		return nil
+	})
+	if respErr != nil {
+		t.Fatal(respErr)
+	}
+
+	return visited
+}
+
+func TestWalkCommitHistory(t *testing.T) {
+	store := newTestHistoryStore(nil, nil, nil, nil)
+
+	// 只遍历target以及之前的commits
+	visited := walkTestHistory(t, store, store.commits[2])
+	if fmt.Sprint(visited) != "[c1 c2 c3]" {
+		t.Errorf("unexpected visited commits %v", visited)
+	}
+}
+
+func TestWalkCommitHistoryPages(t *testing.T) {
+	files := make([]map[string]string, 2*commitHistoryPageSize+50)
+	store := newTestHistoryStore(files...)
+
+	// target在第三页，遍历到target之后不再查询
+	visited := walkTestHistory(t, store, store.commits[2*commitHistoryPageSize+10])
+	if len(visited) != 2*commitHistoryPageSize+11 || visited[len(visited)-1] != store.commits[2*commitHistoryPageSize+10].CommitName {
+		t.Errorf("unexpected visited commits, %d ending with %s", len(visited), visited[len(visited)-1])
+	}
+	if store.calls["pages"] != 3 {
+		t.Errorf("expected three pages, got %d", store.calls["pages"])
+	}
+
+	// commit数量正好是一页时，需要再查询一次才能确定已经结束
+	store = newTestHistoryStore(make([]map[string]string, commitHistoryPageSize)...)
+	visited = walkTestHistory(t, store, store.commits[commitHistoryPageSize-1])
+	if len(visited) != commitHistoryPageSize || store.calls["pages"] != 2 {
+		t.Errorf("expected %d commits in two pages, got %d in %d pages", commitHistoryPageSize, len(visited), store.calls["pages"])
+	}
+}
+
+func TestWalkCommitHistoryDraft(t *testing.T) {
+	store := newTestHistoryStore(nil, nil, nil)
+
+	// draft在c2之后创建，只遍历创建时间在draft之前的commits，最后遍历draft
+	draft := &model.Commit{
+		RepositoryID: testHistoryRepositoryID,
+		CommitID:     "draft-id",
+		CommitName:   "draft",
+		DraftName:    "feature",
+		CreatedTime:  store.commits[1].CreatedTime.Add(time.Minute),
+	}
+	visited := walkTestHistory(t, store, draft)
+	if fmt.Sprint(visited) != "[c1 c2 draft]" {
+		t.Errorf("unexpected visited commits %v", visited)
+	}
+}
+
+func TestWalkCommitHistoryError(t *testing.T) {
+	store := newTestHistoryStore(nil, nil, nil)
+
+	var visited int
+	respErr := walkCommitHistory(&testCommitMapper{store: store}, store.commits[2], func(commit *model.Commit) e.ResponseError {
+		visited++
+		if commit.CommitName == "c2" {
+			return e.NewInternalError("walk")
+		}
+		return nil
+	})
+	if respErr == nil || visited != 2 {
+		t.Errorf("expected walk to stop at c2, got %v after %d commits", respErr, visited)
+	}
+}
+
+// testBlameFileMapper 不允许逐个commit查询文件
+type testBlameFileMapper struct {
+	testFileMapper
+	singleLookups int
+}
+
+func (fileMapper *testBlameFileMapper) FindBlobByCommitIDAndPath(commitID, path string) (*model.FileBlob, error) {
+	fileMapper.singleLookups++
+	return nil, errors.New("unexpected lookup")
+}
+
+func TestGetBlame(t *testing.T) {
+	// c2修改文件，c3没有修改，c4删除，c5重新加入
+	store := newTestHistoryStore(
+		map[string]string{"a.proto": "one\n"},
+		map[string]string{"a.proto": "one\ntwo\n"},
+		map[string]string{"a.proto": "one\ntwo\n"},
+		map[string]string{},
+		map[string]string{"a.proto": "one\ntwo\nthree\n"},
+	)
+	fileMapper := &testBlameFileMapper{testFileMapper: testFileMapper{store: store}}
+	storageHelper := &testStorageHelper{}
+	commitMapper := &testCommitMapper{store: store}
+	blameService := &BlameServiceImpl{
+		commitMapper:  commitMapper,
+		fileMapper:    fileMapper,
+		storageHelper: storageHelper,
+		moduleLoader:  &moduleLoader{commitMapper: commitMapper},
+	}
+	for _, commit := range store.commits {
+		blameCache.Del(commit.CommitID + "/a.proto")
+	}
+
+	_, lines, err := blameService.GetBlame(context.Background(), testHistoryRepositoryID, "c3", "a.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lines) != 2 || lines[0].Commit.CommitName != "c1" || lines[1].Commit.CommitName != "c2" {
+		t.Errorf("unexpected blame %v", lines)
+	}
+	// 内容没有变化的commit不读取文件
+	if len(storageHelper.reads) != 2 || fileMapper.singleLookups != 0 {
+		t.Errorf("expected two reads without single lookups, got %v reads and %d lookups", storageHelper.reads, fileMapper.singleLookups)
+	}
+
+	// 删除之后重新加入的文件，所有行都属于重新加入的commit
+	_, lines, err = blameService.GetBlame(context.Background(), testHistoryRepositoryID, "c5", "a.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range lines {
+		if line.Commit.CommitName != "c5" {
+			t.Errorf("expected line %q from c5, got %s", line.Content, line.Commit.CommitName)
+		}
+	}
+
+	// 文件在target中不存在
+	_, _, err = blameService.GetBlame(context.Background(), testHistoryRepositoryID, "c4", "a.proto")
+	if err == nil {
+		t.Error("expected not found")
+	}
+}
@@ -3,29 +3,46 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufconfig"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
 	"github.com/ProtobufMan/bufman-cli/private/gen/proto/connect/bufman/alpha/registry/v1alpha1/registryv1alpha1connect"
+	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
+	"github.com/ProtobufMan/bufman/internal/config"
+	"github.com/ProtobufMan/bufman/internal/constant"
+	"github.com/ProtobufMan/bufman/internal/core/lock"
+	"github.com/ProtobufMan/bufman/internal/core/proxy"
+	"github.com/ProtobufMan/bufman/internal/core/reference"
+	"github.com/ProtobufMan/bufman/internal/core/resolve"
 	"github.com/ProtobufMan/bufman/internal/e"
 	"github.com/ProtobufMan/bufman/internal/mapper"
 	"github.com/ProtobufMan/bufman/internal/model"
+	"github.com/bufbuild/connect-go"
 	"gorm.io/gorm"
+	"sort"
 )
 
 type CommitService interface {
 	ListRepositoryCommitsByReference(ctx context.Context, repositoryID, reference string, offset, limit int, reverse bool) (model.Commits, e.ResponseError)
 	GetRepositoryCommitByReference(ctx context.Context, repositoryID, reference string) (*model.Commit, e.ResponseError)
+	ResolveRepositoryReference(ctx context.Context, repositoryID, ref string) (*model.Commit, reference.Kind, e.ResponseError)
 	ListRepositoryDraftCommits(ctx context.Context, repositoryID string, offset, limit int, reverse bool) (model.Commits, e.ResponseError)
 	DeleteRepositoryDraftCommit(ctx context.Context, repositoryID, draftName string) e.ResponseError
+	VerifyLock(ctx context.Context, userID string, lockFile *lock.File, bufConfig *bufconfig.Config) (*lock.Report, e.ResponseError)
 }
 
 type CommitServiceImpl struct {
 	repositoryMapper mapper.RepositoryMapper
 	commitMapper     mapper.CommitMapper
+	resolver         resolve.Resolver
 }
 
 func NewCommitService() CommitService {
 	return &CommitServiceImpl{
 		repositoryMapper: &mapper.RepositoryMapperImpl{},
 		commitMapper:     &mapper.CommitMapperImpl{},
+		resolver:         resolve.NewResolver(NewAuthorizationService()),
 	}
 }
 
@@ -36,6 +53,9 @@ func (commitService *CommitServiceImpl) ListRepositoryCommitsByReference(ctx con
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, e.NewNotFoundError("commits not found")
 		}
+		if errors.Is(err, mapper.ErrInvalidReference) {
+			return nil, e.NewInvalidArgumentError("reference")
+		}
 		return nil, e.NewInternalError(registryv1alpha1connect.RepositoryCommitServiceListRepositoryCommitsByReferenceProcedure)
 	}
 
@@ -49,6 +69,9 @@ func (commitService *CommitServiceImpl) GetRepositoryCommitByReference(ctx conte
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, e.NewNotFoundError("commit")
 		}
+		if errors.Is(err, mapper.ErrInvalidReference) {
+			return nil, e.NewInvalidArgumentError("reference")
+		}
 
 		return nil, e.NewInternalError(registryv1alpha1connect.RepositoryCommitServiceGetRepositoryCommitByReferenceProcedure)
 	}
@@ -56,6 +79,23 @@ func (commitService *CommitServiceImpl) GetRepositoryCommitByReference(ctx conte
 	return commit, nil
 }
 
+func (commitService *CommitServiceImpl) ResolveRepositoryReference(ctx context.Context, repositoryID, ref string) (*model.Commit, reference.Kind, e.ResponseError) {
+	// 查询commit以及reference类型
+	commit, kind, err := commitService.commitMapper.ResolveByRepositoryIDAndReference(repositoryID, ref)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, reference.KindUnknown, e.NewNotFoundError(fmt.Sprintf("reference %s", ref))
+		}
+		if errors.Is(err, mapper.ErrInvalidReference) {
+			return nil, reference.KindUnknown, e.NewInvalidArgumentError("reference")
+		}
+
+		return nil, reference.KindUnknown, e.NewInternalError("resolve reference")
+	}
+
+	return commit, kind, nil
+}
+
 func (commitService *CommitServiceImpl) ListRepositoryDraftCommits(ctx context.Context, repositoryID string, offset, limit int, reverse bool) (model.Commits, e.ResponseError) {
 	var commits model.Commits
 	var err error
@@ -81,3 +121,114 @@ func (commitService *CommitServiceImpl) DeleteRepositoryDraftCommit(ctx context.
 
 	return nil
 }
+
+// VerifyLock 校验buf.lock，pin对应的commit必须存在，digest必须一致，并且pin集合与buf.yaml中的依赖一致
+func (commitService *CommitServiceImpl) VerifyLock(ctx context.Context, userID string, lockFile *lock.File, bufConfig *bufconfig.Config) (*lock.Report, e.ResponseError) {
+	// 根据buf.yaml计算需要锁定的依赖
+	var requiredIdentities map[string]struct{}
+	directReferences := map[string]bufmoduleref.ModuleReference{} // buf.yaml中的直接依赖，锁定的commit需要满足其中的reference
+	if bufConfig != nil {
+		allCommits, respErr := commitService.resolver.GetAllDependenciesFromBufConfig(ctx, bufConfig)
+		if respErr != nil {
+			return nil, respErr
+		}
+		requiredIdentities = make(map[string]struct{}, len(allCommits))
+		for i := 0; i < len(allCommits); i++ {
+			requiredIdentities[allCommits[i].IdentityString()] = struct{}{}
+		}
+
+		for _, moduleReference := range bufConfig.Build.DependencyModuleReferences {
+			directReferences[moduleReference.IdentityString()] = moduleReference
+		}
+	}
+
+	report := &lock.Report{
+		Pins: make([]*lock.PinResult, 0, len(lockFile.Deps)),
+	}
+	pinned := make(map[string]struct{}, len(lockFile.Deps))
+	for _, pin := range lockFile.Deps {
+		pinned[pin.IdentityString()] = struct{}{}
+
+		result, commit, respErr := commitService.verifyLockPin(ctx, userID, pin)
+		if respErr != nil {
+			return nil, respErr
+		}
+		if result.Status == lock.PinStatusOK && requiredIdentities != nil {
+			if _, ok := requiredIdentities[pin.IdentityString()]; !ok {
+				result.Status = lock.PinStatusNotInConfig
+				result.Message = "not required by the dependencies in buf.yaml"
+			} else if moduleReference, ok := directReferences[pin.IdentityString()]; ok {
+				satisfied, respErr := commitService.resolver.SatisfiesReference(ctx, commit, moduleReference)
+				if respErr != nil {
+					return nil, respErr
+				}
+				if !satisfied {
+					ref := moduleReference.Reference()
+					if ref == "" {
+						ref = constant.DefaultBranch
+					}
+					result.Status = lock.PinStatusCommitMismatch
+					result.Message = fmt.Sprintf("commit does not satisfy %s in buf.yaml", ref)
+				}
+			}
+		}
+		report.Pins = append(report.Pins, result)
+	}
+
+	for identity := range requiredIdentities {
+		if _, ok := pinned[identity]; !ok {
+			report.Missing = append(report.Missing, identity)
+		}
+	}
+	sort.Strings(report.Missing)
+
+	return report, nil
+}
+
+// verifyLockPin 校验pin对应的commit是否存在，以及digest是否一致，校验通过时同时返回pin对应的commit
+func (commitService *CommitServiceImpl) verifyLockPin(ctx context.Context, userID string, pin *lock.Pin) (*lock.PinResult, *model.Commit, e.ResponseError) {
+	ownerName := pin.Owner
+	if pin.Remote != config.Properties.BufMan.ServerHost {
+		// 代理缓存的上游仓库对本地用户不可见，按照上游的访问规则检查
+		moduleReference, err := bufmoduleref.NewModuleReference(pin.Remote, pin.Owner, pin.Repository, pin.Commit)
+		if err != nil {
+			return &lock.PinResult{Pin: pin, Status: lock.PinStatusNotFound, Message: err.Error()}, nil, nil
+		}
+		if respErr := commitService.resolver.CheckCanAccess(ctx, moduleReference); respErr != nil {
+			if respErr.Code() != connect.CodePermissionDenied {
+				return nil, nil, respErr
+			}
+			return &lock.PinResult{Pin: pin, Status: lock.PinStatusPermissionDenied, Message: respErr.Error()}, nil, nil
+		}
+		ownerName = proxy.ProxyUserName(pin.Remote, pin.Owner)
+	}
+
+	repository, err := commitService.repositoryMapper.FindByUserNameAndRepositoryName(ownerName, pin.Repository)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &lock.PinResult{Pin: pin, Status: lock.PinStatusNotFound, Message: "repository not found"}, nil, nil
+		}
+
+		return nil, nil, e.NewInternalError("verify lock")
+	}
+	if repository.Remote == "" && registryv1alpha1.Visibility(repository.Visibility) != registryv1alpha1.Visibility_VISIBILITY_PUBLIC && repository.UserID != userID {
+		return &lock.PinResult{Pin: pin, Status: lock.PinStatusPermissionDenied, Message: "repository is private"}, nil, nil
+	}
+
+	commit, err := commitService.commitMapper.FindByRepositoryIDAndCommitName(repository.RepositoryID, pin.Commit)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &lock.PinResult{Pin: pin, Status: lock.PinStatusNotFound, Message: "commit not found"}, nil, nil
+		}
+
+		return nil, nil, e.NewInternalError("verify lock")
+	}
+
+	// v1beta1的lock文件中没有digest
+	manifestDigest := string(manifest.DigestTypeShake256) + ":" + commit.ManifestDigest
+	if pin.Digest != "" && pin.Digest != manifestDigest {
+		return &lock.PinResult{Pin: pin, Status: lock.PinStatusDigestMismatch, Message: fmt.Sprintf("expected digest %s", manifestDigest)}, nil, nil
+	}
+
+	return &lock.PinResult{Pin: pin, Status: lock.PinStatusOK}, commit, nil
+}
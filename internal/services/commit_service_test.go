@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+	"github.com/ProtobufMan/bufman/internal/core/lock"
+	"github.com/ProtobufMan/bufman/internal/core/proxy"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"testing"
+)
+
+func TestVerifyLockPinUsesProxyAccess(t *testing.T) {
+	// 代理缓存的上游仓库，与proxy创建的仓库一样是私有的，所属用户是随机生成的；上游只允许访问acme下的仓库
+	repositoryMapper := newTestRepositoryMapper()
+	store := newTestStore()
+	for _, owner := range []string{"acme", "other"} {
+		userName := proxy.ProxyUserName("buf.build", owner)
+		repositoryMapper.repositories[userName+"/weather"] = &model.Repository{
+			UserID:         "random-id",
+			UserName:       userName,
+			RepositoryID:   userName + "/weather",
+			RepositoryName: "weather",
+			Visibility:     uint8(registryv1alpha1.Visibility_VISIBILITY_PRIVATE),
+			Remote:         "buf.build",
+		}
+		store.commits = append(store.commits, &model.Commit{RepositoryID: userName + "/weather", CommitName: "weather1"})
+	}
+	commitService := &CommitServiceImpl{
+		repositoryMapper: repositoryMapper,
+		commitMapper:     &testCommitMapper{store: store},
+		resolver:         &testResolver{},
+	}
+
+	tests := []struct {
+		owner, commit string
+		expected      string
+	}{
+		// 本地的私有代理仓库不影响上游公开的仓库
+		{owner: "acme", commit: "weather1", expected: lock.PinStatusOK},
+		{owner: "acme", commit: "weather2", expected: lock.PinStatusNotFound},
+		{owner: "other", commit: "weather1", expected: lock.PinStatusPermissionDenied},
+	}
+	for _, test := range tests {
+		pin := &lock.Pin{Remote: "buf.build", Owner: test.owner, Repository: "weather", Commit: test.commit}
+		result, commit, err := commitService.verifyLockPin(context.Background(), "alice-id", pin)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Status != test.expected {
+			t.Errorf("%s: expected %s, got %s", pin.IdentityString(), test.expected, result.Status)
+		}
+		if (commit != nil) != (test.expected == lock.PinStatusOK) {
+			t.Errorf("%s: unexpected commit %v", pin.IdentityString(), commit)
+		}
+		if commit != nil && commit.RepositoryID != proxy.ProxyUserName(pin.Remote, pin.Owner)+"/weather" {
+			t.Errorf("%s: unexpected repository %s", pin.IdentityString(), commit.RepositoryID)
+		}
+	}
+}
@@ -2,21 +2,20 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufconfig"
-	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
 	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
 	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
 	"github.com/ProtobufMan/bufman/internal/config"
+	"github.com/ProtobufMan/bufman/internal/core/openapi"
 	"github.com/ProtobufMan/bufman/internal/core/parser"
-	"github.com/ProtobufMan/bufman/internal/core/resolve"
 	"github.com/ProtobufMan/bufman/internal/core/storage"
 	"github.com/ProtobufMan/bufman/internal/e"
 	"github.com/ProtobufMan/bufman/internal/mapper"
 	"github.com/ProtobufMan/bufman/internal/model"
+	"google.golang.org/protobuf/proto"
 	"gorm.io/gorm"
-	"io"
 )
 
 type DocsService interface {
@@ -25,23 +24,38 @@ type DocsService interface {
 	GetModulePackages(ctx context.Context, repositoryID, reference string) ([]*registryv1alpha1.ModulePackage, e.ResponseError)
 	GetModuleDocumentation(ctx context.Context, repositoryID, reference string) (*registryv1alpha1.ModuleDocumentation, e.ResponseError)
 	GetPackageDocumentation(ctx context.Context, repositoryID, reference, packageName string) (*registryv1alpha1.PackageDocumentation, e.ResponseError)
+	GetAllPackageDocumentations(ctx context.Context, repositoryID, reference string) (*model.Commit, []*registryv1alpha1.PackageDocumentation, e.ResponseError)
+	ExportSchema(ctx context.Context, repositoryID, reference, packageName, format string) (*model.Commit, []byte, e.ResponseError)
+	GetDefinition(ctx context.Context, repositoryID, reference, path string, line, column int) (*parser.Definition, e.ResponseError)
+	GetReferences(ctx context.Context, repositoryID, reference, fullName string) ([]*parser.Reference, e.ResponseError)
+	GeneratePackageDocumentations(ctx context.Context, commit *model.Commit) e.ResponseError
+	IndexSymbols(ctx context.Context, commit *model.Commit) e.ResponseError
+	IndexRepositorySymbols(ctx context.Context, repositoryID string) e.ResponseError
+	ListCommitsWithoutPackageDocumentation(ctx context.Context, afterID int64, limit int) (model.Commits, e.ResponseError)
 }
 
+// schemaCache 导出的OpenAPI和JSON Schema按照模块以及依赖的commit缓存
+var schemaCache = newResultCache(func() int64 { return config.Properties.BufMan.SchemaCacheSize })
+
 type DocsServiceImpl struct {
-	commitMapper  mapper.CommitMapper
-	fileMapper    mapper.FileMapper
-	storageHelper storage.StorageHelper
-	protoParser   parser.ProtoParser
-	resolver      resolve.Resolver
+	commitMapper               mapper.CommitMapper
+	fileMapper                 mapper.FileMapper
+	packageDocumentationMapper mapper.PackageDocumentationMapper
+	symbolMapper               mapper.SymbolMapper
+	storageHelper              storage.StorageHelper
+	protoParser                parser.ProtoParser
+	moduleLoader               *moduleLoader
 }
 
 func NewDocsService() DocsService {
 	return &DocsServiceImpl{
-		commitMapper:  &mapper.CommitMapperImpl{},
-		fileMapper:    &mapper.FileMapperImpl{},
-		storageHelper: storage.NewStorageHelper(),
-		protoParser:   parser.NewProtoParser(),
-		resolver:      resolve.NewResolver(),
+		commitMapper:               &mapper.CommitMapperImpl{},
+		fileMapper:                 &mapper.FileMapperImpl{},
+		packageDocumentationMapper: &mapper.PackageDocumentationMapperImpl{},
+		symbolMapper:               &mapper.SymbolMapperImpl{},
+		storageHelper:              storage.NewStorageHelper(),
+		protoParser:                parser.NewProtoParser(),
+		moduleLoader:               newModuleLoader(),
 	}
 }
 
@@ -52,6 +66,9 @@ func (docsService *DocsServiceImpl) GetSourceDirectoryInfo(ctx context.Context,
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, e.NewNotFoundError("commit")
 		}
+		if errors.Is(err, mapper.ErrInvalidReference) {
+			return nil, e.NewInvalidArgumentError("reference")
+		}
 
 		return nil, e.NewInternalError(err.Error())
 	}
@@ -72,6 +89,9 @@ func (docsService *DocsServiceImpl) GetSourceFile(ctx context.Context, repositor
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, e.NewNotFoundError("commit")
 		}
+		if errors.Is(err, mapper.ErrInvalidReference) {
+			return nil, e.NewInvalidArgumentError("reference")
+		}
 
 		return nil, e.NewInternalError(err.Error())
 	}
@@ -96,20 +116,36 @@ func (docsService *DocsServiceImpl) GetSourceFile(ctx context.Context, repositor
 }
 
 func (docsService *DocsServiceImpl) GetModulePackages(ctx context.Context, repositoryID, reference string) ([]*registryv1alpha1.ModulePackage, e.ResponseError) {
-	// 读取commit文件
-	fileManifest, blobSet, err := docsService.getManifestAndBlobSet(ctx, repositoryID, reference)
+	// 查询reference对应的commit
+	commit, err := docsService.moduleLoader.getCommitByReference(repositoryID, reference)
 	if err != nil {
 		return nil, err
 	}
 
-	// 读取依赖
-	dependentManifests, dependentBlobSets, err := docsService.getDependentManifestsAndBlobSets(ctx, fileManifest, blobSet)
+	// 获取模块以及依赖，使用已经生成的文档时也需要检查对依赖的访问权限
+	module, dependentModules, err := docsService.moduleLoader.getModules(ctx, commit)
 	if err != nil {
 		return nil, err
 	}
 
+	if commit.PackageDocumentationGenerated {
+		// 使用push之后生成的包文档
+		packageNames, findErr := docsService.packageDocumentationMapper.FindAllPackageNamesByCommitID(commit.CommitID)
+		if findErr != nil {
+			return nil, e.NewInternalError(findErr.Error())
+		}
+
+		packages := make([]*registryv1alpha1.ModulePackage, 0, len(packageNames))
+		for _, packageName := range packageNames {
+			packages = append(packages, &registryv1alpha1.ModulePackage{
+				Name: packageName,
+			})
+		}
+		return packages, nil
+	}
+
 	// 获取所有的packages
-	packages, err := docsService.protoParser.GetPackages(ctx, fileManifest, blobSet, dependentManifests, dependentBlobSets)
+	packages, err := docsService.protoParser.GetPackages(ctx, module, dependentModules)
 	if err != nil {
 		return nil, err
 	}
@@ -162,177 +198,269 @@ func (docsService *DocsServiceImpl) GetModuleDocumentation(ctx context.Context,
 
 func (docsService *DocsServiceImpl) GetPackageDocumentation(ctx context.Context, repositoryID, reference, packageName string) (*registryv1alpha1.PackageDocumentation, e.ResponseError) {
 	// 查询reference对应的commit
-	commit, err := docsService.commitMapper.FindByRepositoryIDAndReference(repositoryID, reference)
+	commit, err := docsService.moduleLoader.getCommitByReference(repositoryID, reference)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, e.NewNotFoundError(fmt.Sprintf("repository %s", repositoryID))
+		return nil, err
+	}
+
+	// 获取模块以及依赖，使用已经生成的文档时也需要检查对依赖的访问权限
+	module, dependentModules, err := docsService.moduleLoader.getModules(ctx, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	// 优先使用push之后生成的包文档
+	documentation, findErr := docsService.packageDocumentationMapper.FindByCommitIDAndPackageName(commit.CommitID, packageName)
+	if findErr == nil {
+		packageDocument := &registryv1alpha1.PackageDocumentation{}
+		if unmarshalErr := proto.Unmarshal(documentation.Content, packageDocument); unmarshalErr != nil {
+			return nil, e.NewInternalError(unmarshalErr.Error())
 		}
 
-		return nil, e.NewInternalError(err.Error())
+		return packageDocument, nil
+	}
+	if !errors.Is(findErr, gorm.ErrRecordNotFound) {
+		return nil, e.NewInternalError(findErr.Error())
 	}
 
-	// 获取文件清单
-	fileManifest, blobSet, err := docsService.getManifestAndBlobSetByCommitID(ctx, commit.CommitID)
+	// 还没有生成文档的commit，根据proto文件编译生成文档
+	packageDocument, err := docsService.protoParser.GetPackageDocumentation(ctx, packageName, module, dependentModules)
 	if err != nil {
-		return nil, e.NewInternalError(err.Error())
+		return nil, err
 	}
 
-	identity, err := bufmoduleref.NewModuleIdentity(config.Properties.BufMan.ServerHost, commit.UserName, commit.RepositoryName)
+	return packageDocument, nil
+}
+
+// GetAllPackageDocumentations 获取reference对应commit中所有package的文档
+func (docsService *DocsServiceImpl) GetAllPackageDocumentations(ctx context.Context, repositoryID, reference string) (*model.Commit, []*registryv1alpha1.PackageDocumentation, e.ResponseError) {
+	// 查询reference对应的commit
+	commit, err := docsService.moduleLoader.getCommitByReference(repositoryID, reference)
 	if err != nil {
-		return nil, e.NewInternalError(err.Error())
+		return nil, nil, err
 	}
-	commitName := commit.CommitName
 
-	// 获取bufConfig
-	bufConfigBlob, configErr := docsService.storageHelper.GetBufManConfigFromBlob(ctx, fileManifest, blobSet)
-	if configErr != nil {
-		return nil, e.NewInternalError(configErr.Error())
+	// 获取模块以及依赖，使用已经生成的文档时也需要检查对依赖的访问权限
+	module, dependentModules, err := docsService.moduleLoader.getModules(ctx, commit)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	var dependentManifests []*manifest.Manifest
-	var dependentBlobSets []*manifest.BlobSet
-	var dependentIdentities []bufmoduleref.ModuleIdentity
-	var dependentCommitNames []string
-	if bufConfigBlob != nil {
-		// 生成Config
-		reader, configErr := bufConfigBlob.Open(ctx)
-		if configErr != nil {
-			return nil, e.NewInternalError(configErr.Error())
-		}
-		defer reader.Close()
-		configData, configErr := io.ReadAll(reader)
-		if configErr != nil {
-			return nil, e.NewInternalError(configErr.Error())
-		}
-		bufConfig, configErr := bufconfig.GetConfigForData(ctx, configData)
-		if configErr != nil {
-			// 无法解析配置文件
-			return nil, e.NewInternalError(configErr.Error())
+	if commit.PackageDocumentationGenerated {
+		// 使用push之后生成的包文档
+		documentations, findErr := docsService.packageDocumentationMapper.FindAllByCommitID(commit.CommitID)
+		if findErr != nil {
+			return nil, nil, e.NewInternalError(findErr.Error())
 		}
 
-		// 获取全部依赖commits
-		dependentCommits, dependenceErr := docsService.resolver.GetAllDependenciesFromBufConfig(ctx, bufConfig)
-		if dependenceErr != nil {
-			return nil, e.NewInternalError(dependenceErr.Error())
+		packageDocuments := make([]*registryv1alpha1.PackageDocumentation, 0, len(documentations))
+		for _, documentation := range documentations {
+			packageDocument := &registryv1alpha1.PackageDocumentation{}
+			if unmarshalErr := proto.Unmarshal(documentation.Content, packageDocument); unmarshalErr != nil {
+				return nil, nil, e.NewInternalError(unmarshalErr.Error())
+			}
+			packageDocuments = append(packageDocuments, packageDocument)
 		}
+		return commit, packageDocuments, nil
+	}
 
-		// 读取依赖文件
-		dependentManifests = make([]*manifest.Manifest, 0, len(dependentCommits))
-		dependentBlobSets = make([]*manifest.BlobSet, 0, len(dependentCommits))
-		dependentIdentities = make([]bufmoduleref.ModuleIdentity, 0, len(dependentCommits))
-		dependentCommitNames = make([]string, 0, len(dependentCommits))
-		for i := 0; i < len(dependentCommits); i++ {
-			dependentCommit := dependentCommits[i]
-			dependentManifest, dependentBlobSet, getErr := docsService.getManifestAndBlobSetByCommitID(ctx, dependentCommit.CommitID)
-			if getErr != nil {
-				return nil, getErr
-			}
+	// 还没有生成文档的commit，编译生成
+	packages, err := docsService.protoParser.GetPackages(ctx, module, dependentModules)
+	if err != nil {
+		return nil, nil, err
+	}
 
-			dependentIdentity, err := bufmoduleref.NewModuleIdentity(config.Properties.BufMan.ServerHost, dependentCommit.UserName, dependentCommit.RepositoryName)
-			if err != nil {
-				return nil, e.NewInternalError(err.Error())
-			}
-			dependentIdentities = append(dependentIdentities, dependentIdentity)
-			dependentCommitNames = append(dependentCommitNames, dependentCommit.CommitName)
-			dependentManifests = append(dependentManifests, dependentManifest)
-			dependentBlobSets = append(dependentBlobSets, dependentBlobSet)
+	packageDocuments := make([]*registryv1alpha1.PackageDocumentation, 0, len(packages))
+	for _, modulePackage := range packages {
+		packageDocument, err := docsService.protoParser.GetPackageDocumentation(ctx, modulePackage.GetName(), module, dependentModules)
+		if err != nil {
+			return nil, nil, err
 		}
+		packageDocuments = append(packageDocuments, packageDocument)
 	}
 
-	// 根据proto文件生成文档
-	packageDocument, documentErr := docsService.protoParser.GetPackageDocumentation(ctx, packageName, identity, commitName, fileManifest, blobSet, dependentIdentities, dependentCommitNames, dependentManifests, dependentBlobSets)
+	return commit, packageDocuments, nil
+}
+
+// ExportSchema 根据package生成OpenAPI文档或者JSON Schema，结果按照commit缓存
+func (docsService *DocsServiceImpl) ExportSchema(ctx context.Context, repositoryID, reference, packageName, format string) (*model.Commit, []byte, e.ResponseError) {
+	// 查询reference对应的commit
+	commit, err := docsService.moduleLoader.getCommitByReference(repositoryID, reference)
 	if err != nil {
-		return nil, documentErr
+		return nil, nil, err
 	}
 
-	return packageDocument, nil
-}
+	// 解析依赖时会检查对依赖的访问权限，必须在查询缓存之前
+	module, dependentModules, err := docsService.moduleLoader.getModules(ctx, commit)
+	if err != nil {
+		return nil, nil, err
+	}
 
-// getDependentManifestsAndBlobSets 获取依赖的manifests和blob sets
-func (docsService *DocsServiceImpl) getDependentManifestsAndBlobSets(ctx context.Context, fileManifest *manifest.Manifest, blobSet *manifest.BlobSet) ([]*manifest.Manifest, []*manifest.BlobSet, e.ResponseError) {
-	// 获取bufConfig
-	bufConfigBlob, configErr := docsService.storageHelper.GetBufManConfigFromBlob(ctx, fileManifest, blobSet)
-	if configErr != nil {
-		return nil, nil, e.NewInternalError(configErr.Error())
-	}
-
-	var dependentManifests []*manifest.Manifest
-	var dependentBlobSets []*manifest.BlobSet
-	if bufConfigBlob != nil {
-		// 生成Config
-		reader, configErr := bufConfigBlob.Open(ctx)
-		if configErr != nil {
-			return nil, nil, e.NewInternalError(configErr.Error())
-		}
-		defer reader.Close()
-		configData, configErr := io.ReadAll(reader)
-		if configErr != nil {
-			return nil, nil, e.NewInternalError(configErr.Error())
-		}
-		bufConfig, configErr := bufconfig.GetConfigForData(ctx, configData)
-		if configErr != nil {
-			// 无法解析配置文件
-			return nil, nil, e.NewInternalError(configErr.Error())
-		}
+	cacheKey := modulesCacheKey(module, dependentModules) + "/" + packageName + "/" + format
+	if content, ok := schemaCache.Get(cacheKey); ok {
+		return commit, content.([]byte), nil
+	}
 
-		// 获取全部依赖commits
-		dependentCommits, dependenceErr := docsService.resolver.GetAllDependenciesFromBufConfig(ctx, bufConfig)
-		if dependenceErr != nil {
-			return nil, nil, e.NewInternalError(dependenceErr.Error())
-		}
+	files, err := docsService.protoParser.GetPackageFiles(ctx, packageName, module, dependentModules)
+	if err != nil {
+		return nil, nil, err
+	}
 
-		// 读取依赖文件
-		dependentManifests = make([]*manifest.Manifest, 0, len(dependentCommits))
-		dependentBlobSets = make([]*manifest.BlobSet, 0, len(dependentCommits))
-		for i := 0; i < len(dependentCommits); i++ {
-			dependentCommit := dependentCommits[i]
-			dependentManifest, dependentBlobSet, getErr := docsService.getManifestAndBlobSetByCommitID(ctx, dependentCommit.CommitID)
-			if getErr != nil {
-				return nil, nil, getErr
-			}
+	var document interface{}
+	switch format {
+	case openapi.FormatJSONSchema:
+		id := fmt.Sprintf("https://%s/schemas/%s/%s.json", commit.IdentityString(), commit.CommitName, packageName)
+		document = openapi.GenerateJSONSchema(id, files)
+	default:
+		document = openapi.GenerateOpenAPI(packageName, commit.CommitName, files)
+	}
+	content, marshalErr := json.MarshalIndent(document, "", "  ")
+	if marshalErr != nil {
+		return nil, nil, e.NewInternalError(marshalErr.Error())
+	}
+	_ = schemaCache.Add(cacheKey, content, int64(len(content)))
 
-			dependentManifests = append(dependentManifests, dependentManifest)
-			dependentBlobSets = append(dependentBlobSets, dependentBlobSet)
-		}
+	return commit, content, nil
+}
+
+// GetDefinition 获取源码中某个位置引用的类型的定义位置
+func (docsService *DocsServiceImpl) GetDefinition(ctx context.Context, repositoryID, reference, path string, line, column int) (*parser.Definition, e.ResponseError) {
+	// 查询reference对应的commit
+	commit, err := docsService.moduleLoader.getCommitByReference(repositoryID, reference)
+	if err != nil {
+		return nil, err
 	}
 
-	return dependentManifests, dependentBlobSets, nil
+	module, dependentModules, err := docsService.moduleLoader.getModules(ctx, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	return docsService.protoParser.GetDefinition(ctx, module, dependentModules, path, line, column)
 }
 
-func (docsService *DocsServiceImpl) getManifestAndBlobSet(ctx context.Context, repositoryID string, reference string) (*manifest.Manifest, *manifest.BlobSet, e.ResponseError) {
+// GetReferences 获取模块中对类型的所有引用
+func (docsService *DocsServiceImpl) GetReferences(ctx context.Context, repositoryID, reference, fullName string) ([]*parser.Reference, e.ResponseError) {
 	// 查询reference对应的commit
-	commit, err := docsService.commitMapper.FindByRepositoryIDAndReference(repositoryID, reference)
+	commit, err := docsService.moduleLoader.getCommitByReference(repositoryID, reference)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil, e.NewNotFoundError(fmt.Sprintf("repository %s", repositoryID))
-		}
+		return nil, err
+	}
 
-		return nil, nil, e.NewInternalError(err.Error())
+	module, dependentModules, err := docsService.moduleLoader.getModules(ctx, commit)
+	if err != nil {
+		return nil, err
 	}
 
-	return docsService.getManifestAndBlobSetByCommitID(ctx, commit.CommitID)
+	return docsService.protoParser.GetReferences(ctx, module, dependentModules, fullName)
 }
 
-func (docsService *DocsServiceImpl) getManifestAndBlobSetByCommitID(ctx context.Context, commitID string) (*manifest.Manifest, *manifest.BlobSet, e.ResponseError) {
-	// 查询文件清单
-	modelFileManifest, err := docsService.fileMapper.FindManifestByCommitID(commitID)
+// GeneratePackageDocumentations 生成commit中所有package的文档并保存
+func (docsService *DocsServiceImpl) GeneratePackageDocumentations(ctx context.Context, commit *model.Commit) e.ResponseError {
+	module, dependentModules, err := docsService.moduleLoader.getModules(ctx, commit)
+	if err != nil {
+		return err
+	}
+
+	packages, err := docsService.protoParser.GetPackages(ctx, module, dependentModules)
 	if err != nil {
+		return err
+	}
+
+	documentations := make(model.PackageDocumentations, 0, len(packages))
+	for _, modulePackage := range packages {
+		packageDocument, err := docsService.protoParser.GetPackageDocumentation(ctx, modulePackage.GetName(), module, dependentModules)
 		if err != nil {
-			return nil, nil, e.NewInternalError(err.Error())
+			return err
 		}
+
+		content, marshalErr := proto.Marshal(packageDocument)
+		if marshalErr != nil {
+			return e.NewInternalError(marshalErr.Error())
+		}
+		documentations = append(documentations, &model.PackageDocumentation{
+			RepositoryID: commit.RepositoryID,
+			CommitID:     commit.CommitID,
+			PackageName:  modulePackage.GetName(),
+			Content:      content,
+		})
+	}
+
+	if createErr := docsService.packageDocumentationMapper.CreateByCommitID(commit.CommitID, documentations); createErr != nil {
+		return e.NewInternalError(createErr.Error())
 	}
 
-	// 接着查询blobs
-	fileBlobs, err := docsService.fileMapper.FindAllBlobsByCommitID(commitID)
+	return nil
+}
+
+// IndexSymbols 提取commit中定义的符号，替换仓库之前的符号索引，已经索引了更新的commit时不替换
+func (docsService *DocsServiceImpl) IndexSymbols(ctx context.Context, commit *model.Commit) e.ResponseError {
+	module, dependentModules, err := docsService.moduleLoader.getModules(ctx, commit)
 	if err != nil {
-		return nil, nil, e.NewInternalError(err.Error())
+		return err
+	}
+
+	symbols, err := docsService.protoParser.GetSymbols(ctx, module, dependentModules)
+	if err != nil {
+		return err
+	}
+
+	modelSymbols := make(model.Symbols, 0, len(symbols))
+	for _, symbol := range symbols {
+		modelSymbols = append(modelSymbols, &model.Symbol{
+			RepositoryID: commit.RepositoryID,
+			CommitID:     commit.CommitID,
+			CommitName:   commit.CommitName,
+			Kind:         symbol.Kind,
+			Name:         symbol.Name,
+			FullName:     symbol.FullName,
+			PackageName:  symbol.PackageName,
+			FilePath:     symbol.FilePath,
+			StartLine:    symbol.StartLine,
+			StartColumn:  symbol.StartColumn,
+			EndLine:      symbol.EndLine,
+			EndColumn:    symbol.EndColumn,
+		})
 	}
 
-	// 读取
-	fileManifest, blobSet, err := docsService.storageHelper.ReadToManifestAndBlobSet(ctx, modelFileManifest, fileBlobs)
+	if replaceErr := docsService.symbolMapper.ReplaceByRepositoryID(commit.RepositoryID, commit.SequenceID, modelSymbols); replaceErr != nil {
+		return e.NewInternalError(replaceErr.Error())
+	}
+
+	return nil
+}
+
+// IndexRepositorySymbols 使用仓库最新的非draft commit重建符号索引，用于补全历史仓库的索引
+func (docsService *DocsServiceImpl) IndexRepositorySymbols(ctx context.Context, repositoryID string) e.ResponseError {
+	commit, err := docsService.commitMapper.FindLastByRepositoryID(repositoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 还没有commit的仓库
+			return nil
+		}
+
+		return e.NewInternalError(err.Error())
+	}
+
+	return docsService.IndexSymbols(ctx, commit)
+}
+
+// ListCommitsWithoutPackageDocumentation 查询还没有生成包文档的commits，用于补全历史commit的文档
+func (docsService *DocsServiceImpl) ListCommitsWithoutPackageDocumentation(ctx context.Context, afterID int64, limit int) (model.Commits, e.ResponseError) {
+	commits, err := docsService.commitMapper.FindPageWithoutPackageDocumentation(afterID, limit)
+	if err != nil {
+		return nil, e.NewInternalError(err.Error())
+	}
+
+	return commits, nil
+}
+
+func (docsService *DocsServiceImpl) getManifestAndBlobSet(ctx context.Context, repositoryID string, reference string) (*manifest.Manifest, *manifest.BlobSet, e.ResponseError) {
+	// 查询reference对应的commit
+	commit, err := docsService.moduleLoader.getCommitByReference(repositoryID, reference)
 	if err != nil {
-		return nil, nil, e.NewInternalError(err.Error())
+		return nil, nil, err
 	}
 
-	return fileManifest, blobSet, nil
+	return docsService.moduleLoader.getManifestAndBlobSetByCommitID(ctx, commit.CommitID)
 }
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"github.com/ProtobufMan/bufman/internal/core/openapi"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/ProtobufMan/bufman/internal/mapper"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"github.com/bufbuild/connect-go"
+	"testing"
+)
+
+// testDocsPackageDocumentationMapper 已经生成的包文档，记录是否被读取
+type testDocsPackageDocumentationMapper struct {
+	mapper.PackageDocumentationMapper
+	calls int
+}
+
+func (packageDocumentationMapper *testDocsPackageDocumentationMapper) FindByCommitIDAndPackageName(commitID, packageName string) (*model.PackageDocumentation, error) {
+	packageDocumentationMapper.calls++
+	return &model.PackageDocumentation{CommitID: commitID, PackageName: packageName}, nil
+}
+
+func (packageDocumentationMapper *testDocsPackageDocumentationMapper) FindAllPackageNamesByCommitID(commitID string) ([]string, error) {
+	packageDocumentationMapper.calls++
+	return []string{"weather.v1"}, nil
+}
+
+func (packageDocumentationMapper *testDocsPackageDocumentationMapper) FindAllByCommitID(commitID string) (model.PackageDocumentations, error) {
+	packageDocumentationMapper.calls++
+	return model.PackageDocumentations{{CommitID: commitID, PackageName: "weather.v1"}}, nil
+}
+
+// TestStoredDocumentationChecksDependencies 已经生成的文档与实时编译一样，需要检查对依赖的访问权限
+func TestStoredDocumentationChecksDependencies(t *testing.T) {
+	commitMapper := &testCommitMapper{store: newTestStore(&model.Commit{
+		UserName:                      "bufman.io/acme",
+		RepositoryID:                  "weather-id",
+		RepositoryName:                "weather",
+		CommitID:                      "commit-id",
+		CommitName:                    "weather1",
+		BufManConfigDigest:            "config-digest",
+		PackageDocumentationGenerated: true,
+	})}
+	packageDocumentationMapper := &testDocsPackageDocumentationMapper{}
+	docsService := &DocsServiceImpl{
+		commitMapper:               commitMapper,
+		packageDocumentationMapper: packageDocumentationMapper,
+		moduleLoader: &moduleLoader{
+			commitMapper: commitMapper,
+			resolver:     &testResolver{dependencyErr: e.NewPermissionDeniedError("acme/secret")},
+		},
+	}
+	ctx := context.Background()
+
+	tests := map[string]func() e.ResponseError{
+		"GetModulePackages": func() e.ResponseError {
+			_, err := docsService.GetModulePackages(ctx, "weather-id", "main")
+			return err
+		},
+		"GetPackageDocumentation": func() e.ResponseError {
+			_, err := docsService.GetPackageDocumentation(ctx, "weather-id", "main", "weather.v1")
+			return err
+		},
+		"GetAllPackageDocumentations": func() e.ResponseError {
+			_, _, err := docsService.GetAllPackageDocumentations(ctx, "weather-id", "main")
+			return err
+		},
+	}
+	for name, call := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := call(); err == nil || err.Code() != connect.CodePermissionDenied {
+				t.Errorf("expected permission denied, got %v", err)
+			}
+		})
+	}
+	if packageDocumentationMapper.calls != 0 {
+		t.Errorf("expected stored documentations not read, got %d calls", packageDocumentationMapper.calls)
+	}
+}
+
+// TestExportSchemaChecksDependenciesBeforeCache 缓存中已经有导出结果时，也需要检查对依赖的访问权限
+func TestExportSchemaChecksDependenciesBeforeCache(t *testing.T) {
+	commit := &model.Commit{
+		UserName:           "bufman.io/acme",
+		RepositoryID:       "schema-id",
+		RepositoryName:     "weather",
+		CommitID:           "schema-commit-id",
+		CommitName:         "weather1",
+		BufManConfigDigest: "config-digest",
+	}
+	commitMapper := &testCommitMapper{store: newTestStore(commit)}
+	docsService := &DocsServiceImpl{
+		commitMapper: commitMapper,
+		moduleLoader: &moduleLoader{
+			commitMapper: commitMapper,
+			resolver:     &testResolver{dependencyErr: e.NewPermissionDeniedError("acme/secret")},
+		},
+	}
+	module, moduleErr := docsService.moduleLoader.newModule(commit)
+	if moduleErr != nil {
+		t.Fatal(moduleErr)
+	}
+	defer func(cache *resultCache) { schemaCache = cache }(schemaCache)
+	schemaCache = newResultCache(func() int64 { return 1 })
+	cached := []byte("{}")
+	_ = schemaCache.Add(modulesCacheKey(module, nil)+"/weather.v1/"+openapi.FormatOpenAPI, cached, int64(len(cached)))
+
+	_, content, err := docsService.ExportSchema(context.Background(), "schema-id", "main", "weather.v1", openapi.FormatOpenAPI)
+	if err == nil || err.Code() != connect.CodePermissionDenied {
+		t.Errorf("expected permission denied, got %v", err)
+	}
+	if content != nil {
+		t.Errorf("expected no cached content, got %s", content)
+	}
+}
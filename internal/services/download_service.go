@@ -5,20 +5,27 @@ import (
 	"errors"
 	"fmt"
 	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
+	"github.com/ProtobufMan/bufman/internal/core/archive"
+	"github.com/ProtobufMan/bufman/internal/core/resolve"
 	"github.com/ProtobufMan/bufman/internal/core/storage"
 	"github.com/ProtobufMan/bufman/internal/e"
 	"github.com/ProtobufMan/bufman/internal/mapper"
+	"github.com/ProtobufMan/bufman/internal/model"
 	"gorm.io/gorm"
+	"sort"
+	"strings"
 )
 
 type DownloadService interface {
 	DownloadManifestAndBlobs(ctx context.Context, registerID string, reference string) (*manifest.Manifest, *manifest.BlobSet, e.ResponseError)
+	DownloadArchiveFiles(ctx context.Context, repositoryID string, reference string, includeDependencies bool) (*model.Commit, []*archive.File, e.ResponseError)
 }
 
 type DownloadServiceImpl struct {
 	commitMapper  mapper.CommitMapper
 	fileMapper    mapper.FileMapper
 	storageHelper storage.StorageHelper
+	resolver      resolve.Resolver
 }
 
 func NewDownloadService() DownloadService {
@@ -26,26 +33,79 @@ func NewDownloadService() DownloadService {
 		commitMapper:  &mapper.CommitMapperImpl{},
 		fileMapper:    &mapper.FileMapperImpl{},
 		storageHelper: storage.NewStorageHelper(),
+		resolver:      resolve.NewResolver(NewAuthorizationService()),
 	}
 }
 
 func (downloadService *DownloadServiceImpl) DownloadManifestAndBlobs(ctx context.Context, registerID string, reference string) (*manifest.Manifest, *manifest.BlobSet, e.ResponseError) {
 	// 查询reference对应的commit
-	commit, err := downloadService.commitMapper.FindByRepositoryIDAndReference(registerID, reference)
+	commit, respErr := downloadService.getCommitByReference(registerID, reference)
+	if respErr != nil {
+		return nil, nil, respErr
+	}
+
+	return downloadService.getManifestAndBlobSet(ctx, commit)
+}
+
+// DownloadArchiveFiles 获取commit中的文件用于打包，includeDependencies为true时按照import路径加入所有依赖的proto文件
+// 文件内容在写入压缩包时才逐个读取，不会一次性读入内存
+func (downloadService *DownloadServiceImpl) DownloadArchiveFiles(ctx context.Context, repositoryID string, reference string, includeDependencies bool) (*model.Commit, []*archive.File, e.ResponseError) {
+	// 查询reference对应的commit
+	commit, respErr := downloadService.getCommitByReference(repositoryID, reference)
+	if respErr != nil {
+		return nil, nil, respErr
+	}
+
+	seen := map[string]struct{}{}
+	files, respErr := downloadService.appendArchiveFiles(ctx, nil, seen, commit, false)
+	if respErr != nil {
+		return nil, nil, respErr
+	}
+
+	if !includeDependencies || commit.BufManConfigDigest == "" {
+		return commit, files, nil
+	}
+
+	// 读取buf.yaml，获取全部依赖
+	bufConfig, respErr := downloadService.resolver.GetBufConfigFromCommitID(ctx, commit.CommitID)
+	if respErr != nil {
+		return nil, nil, respErr
+	}
+	dependentCommits, respErr := downloadService.resolver.GetAllDependenciesFromBufConfig(ctx, bufConfig)
+	if respErr != nil {
+		return nil, nil, respErr
+	}
+	for _, dependentCommit := range dependentCommits {
+		files, respErr = downloadService.appendArchiveFiles(ctx, files, seen, dependentCommit, true)
+		if respErr != nil {
+			return nil, nil, respErr
+		}
+	}
+
+	return commit, files, nil
+}
+
+func (downloadService *DownloadServiceImpl) getCommitByReference(repositoryID string, reference string) (*model.Commit, e.ResponseError) {
+	commit, err := downloadService.commitMapper.FindByRepositoryIDAndReference(repositoryID, reference)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil, e.NewNotFoundError(fmt.Sprintf("reference %s", reference))
+			return nil, e.NewNotFoundError(fmt.Sprintf("reference %s", reference))
+		}
+		if errors.Is(err, mapper.ErrInvalidReference) {
+			return nil, e.NewInvalidArgumentError("reference")
 		}
 
-		return nil, nil, e.NewInternalError(err.Error())
+		return nil, e.NewInternalError(err.Error())
 	}
 
+	return commit, nil
+}
+
+func (downloadService *DownloadServiceImpl) getManifestAndBlobSet(ctx context.Context, commit *model.Commit) (*manifest.Manifest, *manifest.BlobSet, e.ResponseError) {
 	// 查询文件清单
 	modelFileManifest, err := downloadService.fileMapper.FindManifestByCommitID(commit.CommitID)
 	if err != nil {
-		if err != nil {
-			return nil, nil, e.NewInternalError(err.Error())
-		}
+		return nil, nil, e.NewInternalError(err.Error())
 	}
 
 	// 接着查询blobs
@@ -62,3 +122,34 @@ func (downloadService *DownloadServiceImpl) DownloadManifestAndBlobs(ctx context
 
 	return fileManifest, blobSet, nil
 }
+
+// appendArchiveFiles 将commit中的文件按照路径顺序加入files，已经存在的路径会被跳过，protoOnly为true时只加入proto文件
+func (downloadService *DownloadServiceImpl) appendArchiveFiles(ctx context.Context, files []*archive.File, seen map[string]struct{}, commit *model.Commit, protoOnly bool) ([]*archive.File, e.ResponseError) {
+	fileBlobs, err := downloadService.fileMapper.FindAllBlobsByCommitID(commit.CommitID)
+	if err != nil {
+		return nil, e.NewInternalError(err.Error())
+	}
+	sort.Slice(fileBlobs, func(i, j int) bool {
+		return fileBlobs[i].FileName < fileBlobs[j].FileName
+	})
+
+	for _, fileBlob := range fileBlobs {
+		if _, ok := seen[fileBlob.FileName]; ok {
+			continue
+		}
+		if protoOnly && !strings.HasSuffix(fileBlob.FileName, ".proto") {
+			continue
+		}
+
+		seen[fileBlob.FileName] = struct{}{}
+		digest := fileBlob.Digest
+		files = append(files, &archive.File{
+			Path: fileBlob.FileName,
+			Open: func() ([]byte, error) {
+				return downloadService.storageHelper.ReadBlob(ctx, digest)
+			},
+		})
+	}
+
+	return files, nil
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"github.com/ProtobufMan/bufman/internal/core/archive"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestDownloadArchiveFilesReadsOnWrite(t *testing.T) {
+	store := newTestStore(&model.Commit{RepositoryID: "weather-id", CommitID: "commit-id", CommitName: "weather1"})
+	store.files["commit-id"] = map[string]string{"weather/v1/weather.proto": "weather-digest", "buf.yaml": "config-digest"}
+	storageHelper := &testStorageHelper{}
+	downloadService := &DownloadServiceImpl{
+		commitMapper:  &testCommitMapper{store: store},
+		fileMapper:    &testFileMapper{store: store},
+		storageHelper: storageHelper,
+	}
+
+	_, files, err := downloadService.DownloadArchiveFiles(context.Background(), "weather-id", "main", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].Path != "buf.yaml" || files[1].Path != "weather/v1/weather.proto" {
+		t.Fatalf("unexpected files %v", files)
+	}
+
+	// 打包之前不会读取文件内容
+	if len(storageHelper.reads) != 0 {
+		t.Fatalf("expected no reads before writing, got %v", storageHelper.reads)
+	}
+	if writeErr := archive.Write(io.Discard, archive.FormatTarGz, files, time.Now()); writeErr != nil {
+		t.Fatal(writeErr)
+	}
+	if len(storageHelper.reads) != 2 || storageHelper.reads[0] != "config-digest" || storageHelper.reads[1] != "weather-digest" {
+		t.Errorf("expected blobs read in archive order, got %v", storageHelper.reads)
+	}
+}
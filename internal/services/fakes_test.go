@@ -0,0 +1,456 @@
+package services
+
+import (
+	"context"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufconfig"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/ProtobufMan/bufman/internal/core/resolve"
+	"github.com/ProtobufMan/bufman/internal/core/storage"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/ProtobufMan/bufman/internal/mapper"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"gorm.io/gorm"
+	"sort"
+	"strings"
+)
+
+// testStore 各个测试mapper共用的内存数据，calls记录每种查询的调用次数
+type testStore struct {
+	commits      model.Commits                // 按照sequence id排列
+	files        map[string]map[string]string // commit id -> 路径 -> digest
+	tags         model.Tags
+	dependencies model.Dependencies
+	recorded     map[string]bool // 已经记录了依赖关系的commit
+	calls        map[string]int
+}
+
+func newTestStore(commits ...*model.Commit) *testStore {
+	return &testStore{
+		commits:  commits,
+		files:    map[string]map[string]string{},
+		recorded: map[string]bool{},
+		calls:    map[string]int{},
+	}
+}
+
+// blobs commit中的文件，按照路径排列
+func (store *testStore) blobs(commitID string) model.FileBlobs {
+	var fileBlobs model.FileBlobs
+	for path, digest := range store.files[commitID] {
+		fileBlobs = append(fileBlobs, &model.FileBlob{CommitID: commitID, FileName: path, Digest: digest})
+	}
+	sort.Slice(fileBlobs, func(i, j int) bool {
+		return fileBlobs[i].FileName < fileBlobs[j].FileName
+	})
+
+	return fileBlobs
+}
+
+func (store *testStore) repositoryCommits(repositoryID string) model.Commits {
+	var commits model.Commits
+	for _, commit := range store.commits {
+		if commit.RepositoryID == repositoryID {
+			commits = append(commits, commit)
+		}
+	}
+
+	return commits
+}
+
+type testCommitMapper struct {
+	mapper.CommitMapper
+	store *testStore
+}
+
+// FindByRepositoryIDAndReference reference为commit名称，main为仓库最新的commit
+func (commitMapper *testCommitMapper) FindByRepositoryIDAndReference(repositoryID string, reference string) (*model.Commit, error) {
+	commits := commitMapper.store.repositoryCommits(repositoryID)
+	for _, commit := range commits {
+		if commit.CommitName == reference {
+			return commit, nil
+		}
+	}
+	if reference == "main" && len(commits) > 0 {
+		return commits[len(commits)-1], nil
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (commitMapper *testCommitMapper) FindByRepositoryIDAndCommitName(repositoryID, commitName string) (*model.Commit, error) {
+	for _, commit := range commitMapper.store.repositoryCommits(repositoryID) {
+		if commit.CommitName == commitName {
+			return commit, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (commitMapper *testCommitMapper) FindAllByCommitIDs(commitIDs []string) (model.Commits, error) {
+	var commits model.Commits
+	for _, commitID := range commitIDs {
+		for _, commit := range commitMapper.store.commits {
+			if commit.CommitID == commitID {
+				commits = append(commits, commit)
+			}
+		}
+	}
+
+	return commits, nil
+}
+
+func (commitMapper *testCommitMapper) FindAllByRepositoryID(repositoryID string) (model.Commits, error) {
+	commitMapper.store.calls["commits"]++
+	return commitMapper.store.repositoryCommits(repositoryID), nil
+}
+
+func (commitMapper *testCommitMapper) FindPageByRepositoryID(repositoryID string, offset, limit int, reverse bool) (model.Commits, error) {
+	commitMapper.store.calls["pages"]++
+	commits := commitMapper.store.repositoryCommits(repositoryID)
+	if offset >= len(commits) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > len(commits) {
+		end = len(commits)
+	}
+
+	return commits[offset:end], nil
+}
+
+type testFileMapper struct {
+	mapper.FileMapper
+	store *testStore
+}
+
+func (fileMapper *testFileMapper) FindAllManifestsByRepositoryID(repositoryID string) (model.FileManifests, error) {
+	fileMapper.store.calls["manifests"]++
+	var fileManifests model.FileManifests
+	for _, commit := range fileMapper.store.repositoryCommits(repositoryID) {
+		fileManifests = append(fileManifests, &model.FileManifest{CommitID: commit.CommitID, Digest: commit.ManifestDigest})
+	}
+
+	return fileManifests, nil
+}
+
+func (fileMapper *testFileMapper) FindAllBlobsByRepositoryID(repositoryID string) (model.FileBlobs, error) {
+	fileMapper.store.calls["blobs"]++
+	var fileBlobs model.FileBlobs
+	for _, commit := range fileMapper.store.repositoryCommits(repositoryID) {
+		fileBlobs = append(fileBlobs, fileMapper.store.blobs(commit.CommitID)...)
+	}
+
+	return fileBlobs, nil
+}
+
+// FindAllBlobsByCommitID 按照路径倒序返回，调用方需要自己排序
+func (fileMapper *testFileMapper) FindAllBlobsByCommitID(commitID string) (model.FileBlobs, error) {
+	fileBlobs := fileMapper.store.blobs(commitID)
+	for i, j := 0, len(fileBlobs)-1; i < j; i, j = i+1, j-1 {
+		fileBlobs[i], fileBlobs[j] = fileBlobs[j], fileBlobs[i]
+	}
+
+	return fileBlobs, nil
+}
+
+func (fileMapper *testFileMapper) FindAllBlobsByRepositoryIDAndPath(repositoryID, path string) (model.FileBlobs, error) {
+	var fileBlobs model.FileBlobs
+	for _, commit := range fileMapper.store.repositoryCommits(repositoryID) {
+		if digest, ok := fileMapper.store.files[commit.CommitID][path]; ok {
+			fileBlobs = append(fileBlobs, &model.FileBlob{CommitID: commit.CommitID, FileName: path, Digest: digest})
+		}
+	}
+
+	return fileBlobs, nil
+}
+
+func (fileMapper *testFileMapper) FindBlobByCommitIDAndPath(commitID, path string) (*model.FileBlob, error) {
+	digest, ok := fileMapper.store.files[commitID][path]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &model.FileBlob{CommitID: commitID, FileName: path, Digest: digest}, nil
+}
+
+func (fileMapper *testFileMapper) FindAllBlobsByCommitIDAndDigest(commitID, digest string) (model.FileBlobs, error) {
+	var fileBlobs model.FileBlobs
+	for _, fileBlob := range fileMapper.store.blobs(commitID) {
+		if fileBlob.Digest == digest {
+			fileBlobs = append(fileBlobs, fileBlob)
+		}
+	}
+
+	return fileBlobs, nil
+}
+
+type testTagMapper struct {
+	mapper.TagMapper
+	store *testStore
+}
+
+func (tagMapper *testTagMapper) FindAllByRepositoryID(repositoryID string) (model.Tags, error) {
+	tagMapper.store.calls["tags"]++
+	var tags model.Tags
+	for _, tag := range tagMapper.store.tags {
+		if tag.RepositoryID == repositoryID {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, nil
+}
+
+// testDependencyMapper 依赖关系中Transitive为true的是间接依赖
+type testDependencyMapper struct {
+	mapper.DependencyMapper
+	store *testStore
+}
+
+func (dependencyMapper *testDependencyMapper) FindAllByRepositoryID(repositoryID string) (model.Dependencies, error) {
+	dependencyMapper.store.calls["dependencies"]++
+	var dependencies model.Dependencies
+	for _, dependency := range dependencyMapper.store.dependencies {
+		if dependency.RepositoryID == repositoryID {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	return dependencies, nil
+}
+
+func (dependencyMapper *testDependencyMapper) FindAllByDependencyRepositoryID(dependencyRepositoryID string) (model.Dependencies, error) {
+	var dependencies model.Dependencies
+	for _, dependency := range dependencyMapper.store.dependencies {
+		if dependency.DependencyRepositoryID == dependencyRepositoryID && !dependency.Transitive {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	return dependencies, nil
+}
+
+func (dependencyMapper *testDependencyMapper) FindAllByDependencyCommitIDs(dependencyCommitIDs []string) (model.Dependencies, error) {
+	var dependencies model.Dependencies
+	for _, dependency := range dependencyMapper.store.dependencies {
+		for _, commitID := range dependencyCommitIDs {
+			if dependency.DependencyCommitID == commitID && !dependency.Transitive {
+				dependencies = append(dependencies, dependency)
+			}
+		}
+	}
+
+	return dependencies, nil
+}
+
+func (dependencyMapper *testDependencyMapper) FindAllByCommitID(commitID string) (model.Dependencies, error) {
+	var dependencies model.Dependencies
+	for _, dependency := range dependencyMapper.store.dependencies {
+		if dependency.CommitID == commitID {
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	return dependencies, nil
+}
+
+func (dependencyMapper *testDependencyMapper) ReplaceByCommitID(commitID string, dependencies model.Dependencies) error {
+	kept := dependencies
+	for _, dependency := range dependencyMapper.store.dependencies {
+		if dependency.CommitID != commitID {
+			kept = append(kept, dependency)
+		}
+	}
+	dependencyMapper.store.dependencies = kept
+	dependencyMapper.store.recorded[commitID] = true
+
+	return nil
+}
+
+// testRepositoryMapper 在内存中模拟仓库的重命名和转移
+type testRepositoryMapper struct {
+	mapper.RepositoryMapper
+	repositories map[string]*model.Repository // repository id -> repository
+	redirects    map[string]string            // owner/name -> repository id
+	transfers    map[string]*model.RepositoryTransfer
+
+	forkedCommits model.Commits
+	queried       []string // FindAllByRepositoryIDs查询的仓库
+}
+
+func newTestRepositoryMapper(repositories ...*model.Repository) *testRepositoryMapper {
+	repositoryMapper := &testRepositoryMapper{
+		repositories: map[string]*model.Repository{},
+		redirects:    map[string]string{},
+		transfers:    map[string]*model.RepositoryTransfer{},
+	}
+	for _, repository := range repositories {
+		repositoryMapper.repositories[repository.RepositoryID] = repository
+	}
+
+	return repositoryMapper
+}
+
+func (repositoryMapper *testRepositoryMapper) Create(repository *model.Repository) error {
+	if _, ok := repositoryMapper.redirects[repository.UserName+"/"+repository.RepositoryName]; ok {
+		return mapper.ErrRepositoryRedirected
+	}
+	for _, existing := range repositoryMapper.repositories {
+		if existing.UserName == repository.UserName && existing.RepositoryName == repository.RepositoryName {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	repositoryMapper.repositories[repository.RepositoryID] = repository
+
+	return nil
+}
+
+func (repositoryMapper *testRepositoryMapper) CreateFork(repository *model.Repository, commits model.Commits) error {
+	repositoryMapper.repositories[repository.RepositoryID], repositoryMapper.forkedCommits = repository, commits
+	return nil
+}
+
+func (repositoryMapper *testRepositoryMapper) FindByUserNameAndRepositoryName(userName, repositoryName string) (*model.Repository, error) {
+	if repositoryID, ok := repositoryMapper.redirects[userName+"/"+repositoryName]; ok {
+		return repositoryMapper.FindByRepositoryID(repositoryID)
+	}
+
+	return repositoryMapper.FindByUserNameAndRepositoryNameWithoutRedirect(userName, repositoryName)
+}
+
+func (repositoryMapper *testRepositoryMapper) FindByUserNameAndRepositoryNameWithoutRedirect(userName, repositoryName string) (*model.Repository, error) {
+	for _, repository := range repositoryMapper.repositories {
+		if repository.UserName == userName && repository.RepositoryName == repositoryName {
+			return repository, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (repositoryMapper *testRepositoryMapper) FindByRepositoryID(repositoryID string) (*model.Repository, error) {
+	repository, ok := repositoryMapper.repositories[repositoryID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	updated := *repository
+
+	return &updated, nil
+}
+
+func (repositoryMapper *testRepositoryMapper) FindAllByRepositoryIDs(repositoryIDs []string) (model.Repositories, error) {
+	repositoryMapper.queried = repositoryIDs
+
+	var repositories model.Repositories
+	for _, repositoryID := range repositoryIDs {
+		if repository, ok := repositoryMapper.repositories[repositoryID]; ok {
+			repositories = append(repositories, repository)
+		}
+	}
+
+	return repositories, nil
+}
+
+func (repositoryMapper *testRepositoryMapper) UpdateOwnerAndNameByRepositoryID(repositoryID string, userID, userName, repositoryName string) error {
+	for _, repository := range repositoryMapper.repositories {
+		if repository.RepositoryID != repositoryID && repository.UserName == userName && repository.RepositoryName == repositoryName {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	fullName := userName + "/" + repositoryName
+	if redirectID, ok := repositoryMapper.redirects[fullName]; ok && redirectID != repositoryID {
+		return mapper.ErrRepositoryRedirected
+	}
+	delete(repositoryMapper.redirects, fullName)
+	repository := repositoryMapper.repositories[repositoryID]
+	repositoryMapper.redirects[repository.UserName+"/"+repository.RepositoryName] = repositoryID
+	repository.UserID, repository.UserName, repository.RepositoryName = userID, userName, repositoryName
+
+	return nil
+}
+
+func (repositoryMapper *testRepositoryMapper) CreateTransfer(transfer *model.RepositoryTransfer) error {
+	repositoryMapper.transfers[transfer.RepositoryID] = transfer
+	return nil
+}
+
+func (repositoryMapper *testRepositoryMapper) FindTransferByRepositoryID(repositoryID string) (*model.RepositoryTransfer, error) {
+	transfer, ok := repositoryMapper.transfers[repositoryID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return transfer, nil
+}
+
+func (repositoryMapper *testRepositoryMapper) AcceptTransfer(transfer *model.RepositoryTransfer) error {
+	delete(repositoryMapper.transfers, transfer.RepositoryID)
+	repository := repositoryMapper.repositories[transfer.RepositoryID]
+
+	return repositoryMapper.UpdateOwnerAndNameByRepositoryID(transfer.RepositoryID, transfer.ToUserID, transfer.ToUserName, repository.RepositoryName)
+}
+
+// testUserMapper 除了nobody以外的用户都存在，user id为用户名加上-id
+type testUserMapper struct {
+	mapper.UserMapper
+}
+
+func (userMapper *testUserMapper) FindByUserName(userName string) (*model.User, error) {
+	if userName == "nobody" {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return &model.User{UserID: userName + "-id", UserName: userName}, nil
+}
+
+func (userMapper *testUserMapper) FindByUserID(userID string) (*model.User, error) {
+	return userMapper.FindByUserName(strings.TrimSuffix(userID, "-id"))
+}
+
+// testStorageHelper digest即为文件内容，记录读取blob的顺序
+type testStorageHelper struct {
+	storage.StorageHelper
+	reads []string
+}
+
+func (storageHelper *testStorageHelper) ReadBlob(ctx context.Context, fileName string) ([]byte, error) {
+	storageHelper.reads = append(storageHelper.reads, fileName)
+	return []byte(fileName), nil
+}
+
+// testResolver buf.yaml中的依赖解析为固定的commits，只能访问acme下除了secret以外的仓库
+type testResolver struct {
+	resolve.Resolver
+	allCommits    model.Commits
+	directCommits model.Commits
+	dependencyErr e.ResponseError
+}
+
+func (resolver *testResolver) GetBufConfigFromCommitID(ctx context.Context, commitID string) (*bufconfig.Config, e.ResponseError) {
+	return &bufconfig.Config{}, nil
+}
+
+func (resolver *testResolver) GetAllDependenciesFromBufConfig(ctx context.Context, bufConfig *bufconfig.Config) (model.Commits, e.ResponseError) {
+	if resolver.dependencyErr != nil {
+		return nil, resolver.dependencyErr
+	}
+
+	return resolver.allCommits, nil
+}
+
+func (resolver *testResolver) GetDependenciesFromBufConfig(ctx context.Context, bufConfig *bufconfig.Config) (model.Commits, model.Commits, e.ResponseError) {
+	if resolver.dependencyErr != nil {
+		return nil, nil, resolver.dependencyErr
+	}
+
+	return resolver.allCommits, resolver.directCommits, nil
+}
+
+func (resolver *testResolver) CheckCanAccess(ctx context.Context, moduleReference bufmoduleref.ModuleReference) e.ResponseError {
+	if moduleReference.Owner() != "acme" || moduleReference.Repository() == "secret" {
+		return e.NewPermissionDeniedError(moduleReference.Owner() + "/" + moduleReference.Repository())
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ProtobufMan/bufman/internal/config"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/ProtobufMan/bufman/internal/mapper"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"gorm.io/gorm"
+)
+
+// 文件修改类型
+const (
+	FileChangeAdded    = "added"
+	FileChangeModified = "modified"
+	FileChangeDeleted  = "deleted"
+	FileChangeRenamed  = "renamed" // 内容相同的文件换了路径
+)
+
+// FileChange 文件在某个commit中的修改
+type FileChange struct {
+	Commit     *model.Commit
+	ChangeType string
+	OldPath    string // 新增时为空
+	NewPath    string // 删除时为空
+	OldDigest  string
+	NewDigest  string
+}
+
+type FileHistoryService interface {
+	GetFileHistory(ctx context.Context, repositoryID, reference, path string, offset, limit int, reverse bool) ([]*FileChange, e.ResponseError)
+}
+
+// fileHistoryCache 文件历史按照(commit, path)缓存
+var fileHistoryCache = newResultCache(func() int64 { return config.Properties.BufMan.FileHistoryCacheSize })
+
+type FileHistoryServiceImpl struct {
+	commitMapper mapper.CommitMapper
+	fileMapper   mapper.FileMapper
+	moduleLoader *moduleLoader
+}
+
+func NewFileHistoryService() FileHistoryService {
+	return &FileHistoryServiceImpl{
+		commitMapper: &mapper.CommitMapperImpl{},
+		fileMapper:   &mapper.FileMapperImpl{},
+		moduleLoader: newModuleLoader(),
+	}
+}
+
+// GetFileHistory 查询reference以及之前的commits中文件内容发生变化的commit，默认从旧到新排列，reverse为true时从新到旧
+func (fileHistoryService *FileHistoryServiceImpl) GetFileHistory(ctx context.Context, repositoryID, reference, path string, offset, limit int, reverse bool) ([]*FileChange, e.ResponseError) {
+	// 查询reference对应的commit
+	target, respErr := fileHistoryService.moduleLoader.getCommitByReference(repositoryID, reference)
+	if respErr != nil {
+		return nil, respErr
+	}
+
+	var changes []*FileChange
+	cacheKey := target.CommitID + "/" + path
+	if cached, ok := fileHistoryCache.Get(cacheKey); ok {
+		changes = cached.([]*FileChange)
+	} else {
+		changes, respErr = fileHistoryService.listFileChanges(target, path)
+		if respErr != nil {
+			return nil, respErr
+		}
+		_ = fileHistoryCache.Add(cacheKey, changes, int64(len(changes))*256)
+	}
+	if len(changes) == 0 {
+		return nil, e.NewNotFoundError(fmt.Sprintf("file %s", path))
+	}
+
+	// 分页
+	if offset >= len(changes) {
+		return nil, nil
+	}
+	end := offset + limit
+	if end > len(changes) {
+		end = len(changes)
+	}
+	page := make([]*FileChange, 0, end-offset)
+	for i := offset; i < end; i++ {
+		if reverse {
+			page = append(page, changes[len(changes)-1-i])
+		} else {
+			page = append(page, changes[i])
+		}
+	}
+
+	return page, nil
+}
+
+// listFileChanges 按照从旧到新的顺序计算文件的所有修改
+func (fileHistoryService *FileHistoryServiceImpl) listFileChanges(target *model.Commit, path string) ([]*FileChange, e.ResponseError) {
+	// 一次查询出所有commit中该路径的文件
+	fileBlobs, err := fileHistoryService.fileMapper.FindAllBlobsByRepositoryIDAndPath(target.RepositoryID, path)
+	if err != nil {
+		return nil, e.NewInternalError(err.Error())
+	}
+	digests := make(map[string]string, len(fileBlobs))
+	for _, fileBlob := range fileBlobs {
+		digests[fileBlob.CommitID] = fileBlob.Digest
+	}
+
+	var changes []*FileChange
+	var previous *model.Commit
+	lastDigest := ""
+	respErr := walkCommitHistory(fileHistoryService.commitMapper, target, func(commit *model.Commit) e.ResponseError {
+		defer func() {
+			previous = commit
+		}()
+
+		digest := digests[commit.CommitID]
+		if digest == lastDigest {
+			return nil
+		}
+
+		change := &FileChange{
+			Commit:    commit,
+			OldDigest: lastDigest,
+			NewDigest: digest,
+		}
+		switch {
+		case lastDigest == "":
+			change.ChangeType, change.NewPath = FileChangeAdded, path
+			if previous != nil {
+				// 上一个commit中内容相同、在当前commit中已经不存在的文件被重命名为path
+				oldPath, respErr := fileHistoryService.findRenamedPath(previous.CommitID, commit.CommitID, digest, path)
+				if respErr != nil {
+					return respErr
+				}
+				if oldPath != "" {
+					change.ChangeType, change.OldPath, change.OldDigest = FileChangeRenamed, oldPath, digest
+				}
+			}
+		case digest == "":
+			change.ChangeType, change.OldPath = FileChangeDeleted, path
+			// 当前commit中内容相同、在上一个commit中不存在的文件是path重命名后的文件
+			newPath, respErr := fileHistoryService.findRenamedPath(commit.CommitID, previous.CommitID, lastDigest, path)
+			if respErr != nil {
+				return respErr
+			}
+			if newPath != "" {
+				change.ChangeType, change.NewPath, change.NewDigest = FileChangeRenamed, newPath, lastDigest
+			}
+		default:
+			change.ChangeType, change.OldPath, change.NewPath = FileChangeModified, path, path
+		}
+
+		changes = append(changes, change)
+		lastDigest = digest
+		return nil
+	})
+	if respErr != nil {
+		return nil, respErr
+	}
+
+	return changes, nil
+}
+
+// findRenamedPath 在commitID中查找内容为digest、在otherCommitID中不存在的文件，没有时返回空
+func (fileHistoryService *FileHistoryServiceImpl) findRenamedPath(commitID, otherCommitID, digest, path string) (string, e.ResponseError) {
+	candidates, err := fileHistoryService.fileMapper.FindAllBlobsByCommitIDAndDigest(commitID, digest)
+	if err != nil {
+		return "", e.NewInternalError(err.Error())
+	}
+
+	for _, candidate := range candidates {
+		if candidate.FileName == path {
+			continue
+		}
+		_, err = fileHistoryService.fileMapper.FindBlobByCommitIDAndPath(otherCommitID, candidate.FileName)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return candidate.FileName, nil
+		}
+		if err != nil {
+			return "", e.NewInternalError(err.Error())
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"testing"
+)
+
+// newTestFileHistoryService a.proto在c3中被重命名为b.proto，b.proto在c5中被删除，c6中新增了与b.proto删除前内容相同的c.proto
+func newTestFileHistoryService() (*FileHistoryServiceImpl, *testStore) {
+	store := newTestHistoryStore(
+		map[string]string{"a.proto": "x"},
+		map[string]string{"a.proto": "y"},
+		map[string]string{"b.proto": "y"},
+		map[string]string{"b.proto": "z"},
+		map[string]string{},
+		map[string]string{"c.proto": "z"},
+	)
+	for _, commit := range store.commits {
+		for _, path := range []string{"a.proto", "b.proto", "c.proto"} {
+			fileHistoryCache.Del(commit.CommitID + "/" + path)
+		}
+	}
+
+	commitMapper := &testCommitMapper{store: store}
+	return &FileHistoryServiceImpl{
+		commitMapper: commitMapper,
+		fileMapper:   &testFileMapper{store: store},
+		moduleLoader: &moduleLoader{commitMapper: commitMapper},
+	}, store
+}
+
+// formatFileChange commit:type:old->new
+func formatFileChange(change *FileChange) string {
+	return change.Commit.CommitName + ":" + change.ChangeType + ":" + change.OldPath + "->" + change.NewPath
+}
+
+func formatFileChanges(changes []*FileChange) []string {
+	formatted := make([]string, 0, len(changes))
+	for _, change := range changes {
+		formatted = append(formatted, formatFileChange(change))
+	}
+
+	return formatted
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestListFileChanges(t *testing.T) {
+	fileHistoryService, store := newTestFileHistoryService()
+	target := store.commits[len(store.commits)-1]
+
+	tests := []struct {
+		path     string
+		expected []string
+	}{
+		{
+			// 删除时内容相同的文件出现在新路径上，视为重命名
+			path:     "a.proto",
+			expected: []string{"c1:added:->a.proto", "c2:modified:a.proto->a.proto", "c3:renamed:a.proto->b.proto"},
+		},
+		{
+			// 新增时上一个commit中内容相同的文件已经不存在，视为重命名；没有新路径的删除仍然是删除
+			path:     "b.proto",
+			expected: []string{"c3:renamed:a.proto->b.proto", "c4:modified:b.proto->b.proto", "c5:deleted:b.proto->"},
+		},
+		{
+			// 删除之后的commit中才出现的相同内容不是重命名
+			path:     "c.proto",
+			expected: []string{"c6:added:->c.proto"},
+		},
+	}
+	for _, test := range tests {
+		changes, err := fileHistoryService.listFileChanges(target, test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := formatFileChanges(changes); !equalStrings(actual, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.path, test.expected, actual)
+		}
+	}
+
+	// 重命名前后的digest相同
+	changes, err := fileHistoryService.listFileChanges(target, "b.proto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changes[0].OldDigest != "y" || changes[0].NewDigest != "y" || changes[2].OldDigest != "z" || changes[2].NewDigest != "" {
+		t.Errorf("unexpected digests %+v %+v", changes[0], changes[2])
+	}
+}
+
+func TestFindRenamedPath(t *testing.T) {
+	fileHistoryService, _ := newTestFileHistoryService()
+
+	tests := []struct {
+		commitID, otherCommitID, digest, path string
+		expected                              string
+	}{
+		{commitID: "c3-id", otherCommitID: "c2-id", digest: "y", path: "a.proto", expected: "b.proto"},
+		{commitID: "c2-id", otherCommitID: "c3-id", digest: "y", path: "b.proto", expected: "a.proto"},
+		// 候选文件在另一个commit中也存在，是复制而不是重命名
+		{commitID: "c3-id", otherCommitID: "c3-id", digest: "y", path: "a.proto", expected: ""},
+		{commitID: "c5-id", otherCommitID: "c4-id", digest: "z", path: "b.proto", expected: ""},
+	}
+	for _, test := range tests {
+		actual, err := fileHistoryService.findRenamedPath(test.commitID, test.otherCommitID, test.digest, test.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != test.expected {
+			t.Errorf("findRenamedPath(%s, %s, %s) = %q, want %q", test.commitID, test.otherCommitID, test.path, actual, test.expected)
+		}
+	}
+}
+
+func TestGetFileHistoryPagination(t *testing.T) {
+	fileHistoryService, _ := newTestFileHistoryService()
+	ctx := context.Background()
+
+	tests := []struct {
+		offset, limit int
+		reverse       bool
+		expected      []string
+	}{
+		{offset: 0, limit: 2, reverse: false, expected: []string{"c3:renamed:a.proto->b.proto", "c4:modified:b.proto->b.proto"}},
+		{offset: 2, limit: 2, reverse: false, expected: []string{"c5:deleted:b.proto->"}},
+		{offset: 0, limit: 2, reverse: true, expected: []string{"c5:deleted:b.proto->", "c4:modified:b.proto->b.proto"}},
+		{offset: 2, limit: 2, reverse: true, expected: []string{"c3:renamed:a.proto->b.proto"}},
+		{offset: 3, limit: 2, reverse: true, expected: []string{}},
+	}
+	for _, test := range tests {
+		changes, err := fileHistoryService.GetFileHistory(ctx, testHistoryRepositoryID, "c6", "b.proto", test.offset, test.limit, test.reverse)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := formatFileChanges(changes); !equalStrings(actual, test.expected) {
+			t.Errorf("offset %d reverse %v: expected %v, got %v", test.offset, test.reverse, test.expected, actual)
+		}
+	}
+
+	// 文件在target以及之前都不存在
+	if _, err := fileHistoryService.GetFileHistory(ctx, testHistoryRepositoryID, "c2", "c.proto", 0, 10, false); err == nil {
+		t.Error("expected not found")
+	}
+}
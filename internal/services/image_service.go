@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/ProtobufMan/bufman/internal/config"
+	"github.com/ProtobufMan/bufman/internal/core/image"
+	"github.com/ProtobufMan/bufman/internal/core/parser"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type ImageService interface {
+	GetImage(ctx context.Context, repositoryID, reference string, options *image.Options, format string) (*model.Commit, []byte, e.ResponseError)
+	GetFileDescriptorSet(ctx context.Context, repositoryID, reference string, symbols []string, format string) (*model.Commit, []byte, e.ResponseError)
+}
+
+// imageCache image按照模块以及依赖的commit和生成选项缓存
+var imageCache = newResultCache(func() int64 { return config.Properties.BufMan.ImageCacheSize })
+
+type ImageServiceImpl struct {
+	protoParser  parser.ProtoParser
+	moduleLoader *moduleLoader
+}
+
+func NewImageService() ImageService {
+	return &ImageServiceImpl{
+		protoParser:  parser.NewProtoParser(),
+		moduleLoader: newModuleLoader(),
+	}
+}
+
+// GetImage 编译模块以及依赖，生成FileDescriptorSet
+func (imageService *ImageServiceImpl) GetImage(ctx context.Context, repositoryID, reference string, options *image.Options, format string) (*model.Commit, []byte, e.ResponseError) {
+	// 查询reference对应的commit
+	commit, err := imageService.moduleLoader.getCommitByReference(repositoryID, reference)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// 解析依赖时会检查对依赖的访问权限，image中包含依赖的描述符，必须在查询缓存之前检查
+	module, dependentModules, err := imageService.moduleLoader.getModules(ctx, commit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cacheKey := imageCacheKey(modulesCacheKey(module, dependentModules), options, format)
+	if content, ok := imageCache.Get(cacheKey); ok {
+		return commit, content.([]byte), nil
+	}
+
+	files, err := imageService.protoParser.GetModuleFiles(ctx, module, dependentModules)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fileDescriptorSet, buildErr := image.Build(files, options)
+	if buildErr != nil {
+		if errors.Is(buildErr, image.ErrTypeNotFound) {
+			return nil, nil, e.NewNotFoundError(buildErr.Error())
+		}
+
+		return nil, nil, e.NewInternalError(buildErr.Error())
+	}
+	content, marshalErr := image.Marshal(fileDescriptorSet, format)
+	if marshalErr != nil {
+		if errors.Is(marshalErr, image.ErrUnknownFormat) {
+			return nil, nil, e.NewInvalidArgumentError(marshalErr.Error())
+		}
+
+		return nil, nil, e.NewInternalError(marshalErr.Error())
+	}
+	_ = imageCache.Add(cacheKey, content, int64(len(content)))
+
+	return commit, content, nil
+}
+
+// GetFileDescriptorSet 返回只包含symbols以及它们依赖的类型的最小FileDescriptorSet，用于动态客户端反射
+func (imageService *ImageServiceImpl) GetFileDescriptorSet(ctx context.Context, repositoryID, reference string, symbols []string, format string) (*model.Commit, []byte, e.ResponseError) {
+	// 顺序不影响结果，排序后可以命中同一个缓存
+	types := make([]string, len(symbols))
+	copy(types, symbols)
+	sort.Strings(types)
+
+	return imageService.GetImage(ctx, repositoryID, reference, &image.Options{
+		ExcludeSourceInfo: true,
+		Types:             types,
+	}, format)
+}
+
+func imageCacheKey(modulesKey string, options *image.Options, format string) string {
+	if options == nil {
+		options = &image.Options{}
+	}
+
+	return strings.Join([]string{
+		modulesKey,
+		format,
+		strconv.FormatBool(options.ExcludeImports),
+		strconv.FormatBool(options.ExcludeSourceInfo),
+		strings.Join(options.Types, ","),
+	}, "/")
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/ProtobufMan/bufman/internal/core/image"
+	"github.com/ProtobufMan/bufman/internal/core/parser"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"github.com/bufbuild/connect-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/apipb"
+	"testing"
+)
+
+// TestImageChecksDependenciesBeforeCache 缓存的image中包含依赖的描述符，命中缓存时也需要检查对依赖的访问权限
+func TestImageChecksDependenciesBeforeCache(t *testing.T) {
+	commit := &model.Commit{
+		UserName:           "bufman.io/acme",
+		RepositoryID:       "image-id",
+		RepositoryName:     "weather",
+		CommitID:           "image-commit-id",
+		CommitName:         "weather1",
+		BufManConfigDigest: "config-digest",
+	}
+	imageService := &ImageServiceImpl{
+		moduleLoader: &moduleLoader{
+			commitMapper: &testCommitMapper{store: newTestStore(commit)},
+			resolver:     &testResolver{dependencyErr: e.NewPermissionDeniedError("acme/secret")},
+		},
+	}
+	module, moduleErr := imageService.moduleLoader.newModule(commit)
+	if moduleErr != nil {
+		t.Fatal(moduleErr)
+	}
+	defer func(cache *resultCache) { imageCache = cache }(imageCache)
+	imageCache = newResultCache(func() int64 { return 1 })
+	cached := []byte("cached")
+	_ = imageCache.Add(imageCacheKey(modulesCacheKey(module, nil), nil, image.FormatBinary), cached, int64(len(cached)))
+	_ = imageCache.Add(imageCacheKey(modulesCacheKey(module, nil), &image.Options{
+		ExcludeSourceInfo: true,
+		Types:             []string{"weather.v1.Weather"},
+	}, image.FormatBinary), cached, int64(len(cached)))
+
+	_, content, err := imageService.GetImage(context.Background(), "image-id", "main", nil, image.FormatBinary)
+	if err == nil || err.Code() != connect.CodePermissionDenied || content != nil {
+		t.Errorf("expected permission denied for image, got %v", err)
+	}
+
+	_, content, err = imageService.GetFileDescriptorSet(context.Background(), "image-id", "main", []string{"weather.v1.Weather"}, image.FormatBinary)
+	if err == nil || err.Code() != connect.CodePermissionDenied || content != nil {
+		t.Errorf("expected permission denied for file descriptor set, got %v", err)
+	}
+}
+
+// testImageProtoParser 模块中只有google/protobuf/api.proto
+type testImageProtoParser struct {
+	parser.ProtoParser
+}
+
+func (protoParser *testImageProtoParser) GetModuleFiles(ctx context.Context, module *parser.Module, dependentModules []*parser.Module) ([]protoreflect.FileDescriptor, e.ResponseError) {
+	return []protoreflect.FileDescriptor{apipb.File_google_protobuf_api_proto}, nil
+}
+
+func TestGetFileDescriptorSet(t *testing.T) {
+	imageService := &ImageServiceImpl{
+		protoParser: &testImageProtoParser{},
+		moduleLoader: &moduleLoader{
+			commitMapper: &testCommitMapper{store: newTestStore(&model.Commit{
+				UserName:       "bufman.io/acme",
+				RepositoryID:   "api-id",
+				RepositoryName: "api",
+				CommitID:       "api-commit-id",
+				CommitName:     "api1",
+			})},
+		},
+	}
+	defer func(cache *resultCache) { imageCache = cache }(imageCache)
+	imageCache = newResultCache(func() int64 { return 1 })
+	ctx := context.Background()
+
+	_, content, err := imageService.GetFileDescriptorSet(ctx, "api-id", "main", []string{"google.protobuf.Mixin", "google.protobuf.Method"}, image.FormatBinary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileDescriptorSet := &descriptorpb.FileDescriptorSet{}
+	if unmarshalErr := proto.Unmarshal(content, fileDescriptorSet); unmarshalErr != nil {
+		t.Fatal(unmarshalErr)
+	}
+
+	// Method引用了type.proto中的Option，Option引用了any.proto；Api以及source_context.proto被裁剪
+	var paths []string
+	for _, file := range fileDescriptorSet.GetFile() {
+		paths = append(paths, file.GetName())
+		if file.SourceCodeInfo != nil {
+			t.Errorf("expected no source info in %s", file.GetName())
+		}
+	}
+	if fmt.Sprint(paths) != "[google/protobuf/any.proto google/protobuf/type.proto google/protobuf/api.proto]" {
+		t.Fatalf("unexpected files %v", paths)
+	}
+	for i, expected := range []string{"[Any]", "[Option]", "[Method Mixin]"} {
+		var messages []string
+		for _, message := range fileDescriptorSet.GetFile()[i].GetMessageType() {
+			messages = append(messages, message.GetName())
+		}
+		if fmt.Sprint(messages) != expected {
+			t.Errorf("%s: expected messages %s, got %v", paths[i], expected, messages)
+		}
+	}
+
+	// symbols的顺序不影响结果
+	_, reordered, err := imageService.GetFileDescriptorSet(ctx, "api-id", "main", []string{"google.protobuf.Method", "google.protobuf.Mixin"}, image.FormatBinary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(content, reordered) {
+		t.Error("expected same file descriptor set for reordered symbols")
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ProtobufMan/bufman-cli/private/bufpkg/bufmodule/bufmoduleref"
+	"github.com/ProtobufMan/bufman-cli/private/pkg/manifest"
+	"github.com/ProtobufMan/bufman/internal/core/parser"
+	"github.com/ProtobufMan/bufman/internal/core/resolve"
+	"github.com/ProtobufMan/bufman/internal/core/storage"
+	"github.com/ProtobufMan/bufman/internal/e"
+	"github.com/ProtobufMan/bufman/internal/mapper"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"gorm.io/gorm"
+	"sort"
+	"strings"
+)
+
+// moduleLoader 根据commit构造编译需要的模块，供文档、image等服务共用
+type moduleLoader struct {
+	commitMapper     mapper.CommitMapper
+	fileMapper       mapper.FileMapper
+	dependencyMapper mapper.DependencyMapper
+	storageHelper    storage.StorageHelper
+	resolver         resolve.Resolver
+}
+
+func newModuleLoader() *moduleLoader {
+	return &moduleLoader{
+		commitMapper:     &mapper.CommitMapperImpl{},
+		fileMapper:       &mapper.FileMapperImpl{},
+		dependencyMapper: &mapper.DependencyMapperImpl{},
+		storageHelper:    storage.NewStorageHelper(),
+		resolver:         resolve.NewResolver(NewAuthorizationService()),
+	}
+}
+
+// getModules 获取commit对应的模块以及全部依赖，文件只在编译缓存未命中时才会读取
+func (loader *moduleLoader) getModules(ctx context.Context, commit *model.Commit) (*parser.Module, []*parser.Module, e.ResponseError) {
+	module, err := loader.newModule(commit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dependentCommits, err := loader.getDependentCommits(ctx, commit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dependentModules := make([]*parser.Module, 0, len(dependentCommits))
+	for i := 0; i < len(dependentCommits); i++ {
+		dependentModule, err := loader.newModule(dependentCommits[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		dependentModules = append(dependentModules, dependentModule)
+	}
+
+	return module, dependentModules, nil
+}
+
+// getDependentCommits 获取commit的全部依赖commits，已经记录了依赖关系的commit使用push时解析得到的依赖，
+// 这样之后生成的文档、image等与push时的编译结果一致，不会因为依赖的分支或者版本范围有了新的commit而变化
+func (loader *moduleLoader) getDependentCommits(ctx context.Context, commit *model.Commit) (model.Commits, e.ResponseError) {
+	if commit.DependenciesRecorded {
+		dependentCommits, ok, err := loader.getRecordedDependentCommits(ctx, commit)
+		if err != nil || ok {
+			return dependentCommits, err
+		}
+	}
+	if commit.BufManConfigDigest == "" {
+		return nil, nil
+	}
+
+	// 读取buf.yaml
+	bufConfig, err := loader.resolver.GetBufConfigFromCommitID(ctx, commit.CommitID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 获取全部依赖commits
+	return loader.resolver.GetAllDependenciesFromBufConfig(ctx, bufConfig)
+}
+
+// getRecordedDependentCommits 查询记录的依赖commits，并与实时解析一样检查对依赖的访问权限
+// 被依赖的commit已经不存在时(例如draft被删除)返回false，由调用方退回到实时解析
+func (loader *moduleLoader) getRecordedDependentCommits(ctx context.Context, commit *model.Commit) (model.Commits, bool, e.ResponseError) {
+	dependencies, err := loader.dependencyMapper.FindAllByCommitID(commit.CommitID)
+	if err != nil {
+		return nil, false, e.NewInternalError(err.Error())
+	}
+	if len(dependencies) == 0 {
+		return nil, true, nil
+	}
+
+	commitIDs := make([]string, 0, len(dependencies))
+	for i := 0; i < len(dependencies); i++ {
+		commitIDs = append(commitIDs, dependencies[i].DependencyCommitID)
+	}
+	dependentCommits, err := loader.commitMapper.FindAllByCommitIDs(commitIDs)
+	if err != nil {
+		return nil, false, e.NewInternalError(err.Error())
+	}
+	if len(dependentCommits) != len(commitIDs) {
+		return nil, false, nil
+	}
+
+	for i := 0; i < len(dependentCommits); i++ {
+		remote, owner := dependentCommits[i].RemoteAndOwner()
+		moduleReference, err := bufmoduleref.NewModuleReference(remote, owner, dependentCommits[i].RepositoryName, dependentCommits[i].CommitName)
+		if err != nil {
+			return nil, false, e.NewInternalError(err.Error())
+		}
+		if respErr := loader.resolver.CheckCanAccess(ctx, moduleReference); respErr != nil {
+			return nil, false, respErr
+		}
+	}
+
+	return dependentCommits, true, nil
+}
+
+// modulesCacheKey 由模块以及全部依赖的commit组成缓存的key
+// 没有记录依赖关系的commit每次解析得到的依赖可能不同，所以不能只使用模块的commit作为key
+func modulesCacheKey(module *parser.Module, dependentModules []*parser.Module) string {
+	dependentKeys := make([]string, 0, len(dependentModules))
+	for _, dependentModule := range dependentModules {
+		dependentKeys = append(dependentKeys, dependentModule.Identity.IdentityString()+"@"+dependentModule.Commit)
+	}
+	sort.Strings(dependentKeys)
+
+	return module.Identity.IdentityString() + "@" + module.Commit + "|" + strings.Join(dependentKeys, ",")
+}
+
+func (loader *moduleLoader) newModule(commit *model.Commit) (*parser.Module, e.ResponseError) {
+	remote, owner := commit.RemoteAndOwner()
+	identity, err := bufmoduleref.NewModuleIdentity(remote, owner, commit.RepositoryName)
+	if err != nil {
+		return nil, e.NewInternalError(err.Error())
+	}
+
+	commitID := commit.CommitID
+	return &parser.Module{
+		Identity:       identity,
+		Commit:         commit.CommitName,
+		ManifestDigest: commit.ManifestDigest,
+		Load: func(ctx context.Context) (*manifest.Manifest, *manifest.BlobSet, e.ResponseError) {
+			return loader.getManifestAndBlobSetByCommitID(ctx, commitID)
+		},
+	}, nil
+}
+
+func (loader *moduleLoader) getCommitByReference(repositoryID string, reference string) (*model.Commit, e.ResponseError) {
+	commit, err := loader.commitMapper.FindByRepositoryIDAndReference(repositoryID, reference)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, e.NewNotFoundError(fmt.Sprintf("repository %s", repositoryID))
+		}
+		if errors.Is(err, mapper.ErrInvalidReference) {
+			return nil, e.NewInvalidArgumentError("reference")
+		}
+
+		return nil, e.NewInternalError(err.Error())
+	}
+
+	return commit, nil
+}
+
+func (loader *moduleLoader) getManifestAndBlobSetByCommitID(ctx context.Context, commitID string) (*manifest.Manifest, *manifest.BlobSet, e.ResponseError) {
+	// 查询文件清单
+	modelFileManifest, err := loader.fileMapper.FindManifestByCommitID(commitID)
+	if err != nil {
+		return nil, nil, e.NewInternalError(err.Error())
+	}
+
+	// 接着查询blobs
+	fileBlobs, err := loader.fileMapper.FindAllBlobsByCommitID(commitID)
+	if err != nil {
+		return nil, nil, e.NewInternalError(err.Error())
+	}
+
+	// 读取
+	fileManifest, blobSet, err := loader.storageHelper.ReadToManifestAndBlobSet(ctx, modelFileManifest, fileBlobs)
+	if err != nil {
+		return nil, nil, e.NewInternalError(err.Error())
+	}
+
+	return fileManifest, blobSet, nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"github.com/ProtobufMan/bufman/internal/core/parser"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"github.com/bufbuild/connect-go"
+	"testing"
+)
+
+func TestGetModulesUsesRecordedDependencies(t *testing.T) {
+	weather1 := &model.Commit{UserName: "bufman.io/acme", RepositoryID: "weather", RepositoryName: "weather", CommitID: "w1", CommitName: "weather1"}
+	weather3 := &model.Commit{UserName: "bufman.io/acme", RepositoryID: "weather", RepositoryName: "weather", CommitID: "w3", CommitName: "weather3"}
+	secret1 := &model.Commit{UserName: "bufman.io/acme", RepositoryID: "secret", RepositoryName: "secret", CommitID: "s1", CommitName: "secret1"}
+	store := newTestStore(weather1, weather3, secret1)
+	store.dependencies = model.Dependencies{
+		{CommitID: "a1", DependencyRepositoryID: "weather", DependencyCommitID: "w1"},
+		{CommitID: "a2", DependencyRepositoryID: "secret", DependencyCommitID: "s1"},
+		{CommitID: "a3", DependencyRepositoryID: "weather", DependencyCommitID: "w2"},
+	}
+	// 实时解析得到weather最新的commit，secret仓库不可访问
+	loader := &moduleLoader{
+		commitMapper:     &testCommitMapper{store: store},
+		dependencyMapper: &testDependencyMapper{store: store},
+		resolver:         &testResolver{allCommits: model.Commits{weather3}},
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		commit   *model.Commit
+		expected string
+	}{
+		// 依赖的分支上有了新的commit，仍然使用push时解析得到的commit
+		{name: "recorded", commit: &model.Commit{CommitID: "a1", DependenciesRecorded: true}, expected: "weather1"},
+		// 被依赖的commit已经被删除时退回到实时解析
+		{name: "deleted", commit: &model.Commit{CommitID: "a3", DependenciesRecorded: true, BufManConfigDigest: "config-digest"}, expected: "weather3"},
+		{name: "not recorded", commit: &model.Commit{CommitID: "a0", BufManConfigDigest: "config-digest"}, expected: "weather3"},
+	}
+	for _, test := range tests {
+		test.commit.UserName, test.commit.RepositoryName = "bufman.io/acme", "app"
+		_, dependentModules, err := loader.getModules(ctx, test.commit)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if len(dependentModules) != 1 || dependentModules[0].Commit != test.expected {
+			t.Errorf("%s: expected [%s], got %v", test.name, test.expected, dependentModules)
+		}
+	}
+
+	// 记录的依赖同样需要检查访问权限
+	_, _, err := loader.getModules(ctx, &model.Commit{UserName: "bufman.io/acme", RepositoryName: "app", CommitID: "a2", DependenciesRecorded: true})
+	if err == nil || err.Code() != connect.CodePermissionDenied {
+		t.Errorf("expected permission denied, got %v", err)
+	}
+}
+
+func TestModulesCacheKey(t *testing.T) {
+	loader := &moduleLoader{}
+	newModule := func(repositoryName, commitName string) *parser.Module {
+		module, err := loader.newModule(&model.Commit{UserName: "bufman.io/acme", RepositoryName: repositoryName, CommitName: commitName})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return module
+	}
+	app := newModule("app", "app1")
+	weather1, weather2, units1 := newModule("weather", "weather1"), newModule("weather", "weather2"), newModule("units", "units1")
+
+	// 依赖的顺序不影响key，依赖解析到不同的commit时key不同
+	if modulesCacheKey(app, []*parser.Module{weather1, units1}) != modulesCacheKey(app, []*parser.Module{units1, weather1}) {
+		t.Error("expected key independent of dependency order")
+	}
+	if modulesCacheKey(app, []*parser.Module{weather1, units1}) == modulesCacheKey(app, []*parser.Module{weather2, units1}) {
+		t.Error("expected different keys for different dependency commits")
+	}
+}
@@ -14,13 +14,14 @@ import (
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"io"
+	"strings"
 	"time"
 )
 
 type PushService interface {
-	PushManifestAndBlobs(ctx context.Context, userID, ownerName, repositoryName string, fileManifest *manifest.Manifest, fileBlobs *manifest.BlobSet) (*model.Commit, e.ResponseError)
-	PushManifestAndBlobsWithTags(ctx context.Context, userID, ownerName, repositoryName string, fileManifest *manifest.Manifest, fileBlobs *manifest.BlobSet, tagNames []string) (*model.Commit, e.ResponseError)
-	PushManifestAndBlobsWithDraft(ctx context.Context, userID, ownerName, repositoryName string, fileManifest *manifest.Manifest, fileBlobs *manifest.BlobSet, draftName string) (*model.Commit, e.ResponseError)
+	PushManifestAndBlobs(ctx context.Context, userID, ownerName, repositoryName string, fileManifest *manifest.Manifest, fileBlobs *manifest.BlobSet, dependentCommits, directDependentCommits model.Commits, warnings []string) (*model.Commit, e.ResponseError)
+	PushManifestAndBlobsWithTags(ctx context.Context, userID, ownerName, repositoryName string, fileManifest *manifest.Manifest, fileBlobs *manifest.BlobSet, dependentCommits, directDependentCommits model.Commits, warnings []string, tagNames []string) (*model.Commit, e.ResponseError)
+	PushManifestAndBlobsWithDraft(ctx context.Context, userID, ownerName, repositoryName string, fileManifest *manifest.Manifest, fileBlobs *manifest.BlobSet, dependentCommits, directDependentCommits model.Commits, warnings []string, draftName string) (*model.Commit, e.ResponseError)
 	GetManifestAndBlobSet(ctx context.Context, repositoryID string, reference string) (*manifest.Manifest, *manifest.BlobSet, e.ResponseError)
 }
 
@@ -49,6 +50,9 @@ func (pushService *PushServiceImpl) GetManifestAndBlobSet(ctx context.Context, r
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil, e.NewNotFoundError(fmt.Sprintf("repository %s", repositoryID))
 		}
+		if errors.Is(err, mapper.ErrInvalidReference) {
+			return nil, nil, e.NewInvalidArgumentError("reference")
+		}
 
 		return nil, nil, e.NewInternalError(err.Error())
 	}
@@ -76,8 +80,8 @@ func (pushService *PushServiceImpl) GetManifestAndBlobSet(ctx context.Context, r
 	return fileManifest, blobSet, nil
 }
 
-func (pushService *PushServiceImpl) PushManifestAndBlobs(ctx context.Context, userID, ownerName, repositoryName string, fileManifest *manifest.Manifest, fileBlobs *manifest.BlobSet) (*model.Commit, e.ResponseError) {
-	commit, err := pushService.toCommit(ctx, userID, ownerName, repositoryName, fileManifest, fileBlobs)
+func (pushService *PushServiceImpl) PushManifestAndBlobs(ctx context.Context, userID, ownerName, repositoryName string, fileManifest *manifest.Manifest, fileBlobs *manifest.BlobSet, dependentCommits, directDependentCommits model.Commits, warnings []string) (*model.Commit, e.ResponseError) {
+	commit, err := pushService.toCommit(ctx, userID, ownerName, repositoryName, fileManifest, fileBlobs, dependentCommits, directDependentCommits, warnings)
 	if err != nil {
 		return nil, err
 	}
@@ -104,8 +108,8 @@ func (pushService *PushServiceImpl) PushManifestAndBlobs(ctx context.Context, us
 	return commit, nil
 }
 
-func (pushService *PushServiceImpl) PushManifestAndBlobsWithTags(ctx context.Context, userID, ownerName, repositoryName string, fileManifest *manifest.Manifest, fileBlobs *manifest.BlobSet, tagNames []string) (*model.Commit, e.ResponseError) {
-	commit, err := pushService.toCommit(ctx, userID, ownerName, repositoryName, fileManifest, fileBlobs)
+func (pushService *PushServiceImpl) PushManifestAndBlobsWithTags(ctx context.Context, userID, ownerName, repositoryName string, fileManifest *manifest.Manifest, fileBlobs *manifest.BlobSet, dependentCommits, directDependentCommits model.Commits, warnings []string, tagNames []string) (*model.Commit, e.ResponseError) {
+	commit, err := pushService.toCommit(ctx, userID, ownerName, repositoryName, fileManifest, fileBlobs, dependentCommits, directDependentCommits, warnings)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +135,13 @@ func (pushService *PushServiceImpl) PushManifestAndBlobsWithTags(ctx context.Con
 
 	createErr := pushService.commitMapper.Create(commit)
 	if createErr != nil {
-		if errors.Is(createErr, mapper.ErrTagAndDraftDuplicated) || errors.Is(createErr, gorm.ErrDuplicatedKey) {
+		if errors.Is(createErr, mapper.ErrTagAndDraftDuplicated) {
+			return nil, e.NewAlreadyExistsError("draft with the same name")
+		}
+		if errors.Is(createErr, mapper.ErrTagDuplicated) {
+			return nil, e.NewAlreadyExistsError("tag")
+		}
+		if errors.Is(createErr, gorm.ErrDuplicatedKey) {
 			return nil, e.NewInternalError(registryv1alpha1connect.PushServicePushManifestAndBlobsProcedure)
 		}
 		if errors.Is(createErr, mapper.ErrLastCommitDuplicated) {
@@ -144,8 +154,8 @@ func (pushService *PushServiceImpl) PushManifestAndBlobsWithTags(ctx context.Con
 	return commit, nil
 }
 
-func (pushService *PushServiceImpl) PushManifestAndBlobsWithDraft(ctx context.Context, userID, ownerName, repositoryName string, fileManifest *manifest.Manifest, fileBlobs *manifest.BlobSet, draftName string) (*model.Commit, e.ResponseError) {
-	commit, err := pushService.toCommit(ctx, userID, ownerName, repositoryName, fileManifest, fileBlobs)
+func (pushService *PushServiceImpl) PushManifestAndBlobsWithDraft(ctx context.Context, userID, ownerName, repositoryName string, fileManifest *manifest.Manifest, fileBlobs *manifest.BlobSet, dependentCommits, directDependentCommits model.Commits, warnings []string, draftName string) (*model.Commit, e.ResponseError) {
+	commit, err := pushService.toCommit(ctx, userID, ownerName, repositoryName, fileManifest, fileBlobs, dependentCommits, directDependentCommits, warnings)
 	if err != nil {
 		return nil, err
 	}
@@ -160,7 +170,7 @@ func (pushService *PushServiceImpl) PushManifestAndBlobsWithDraft(ctx context.Co
 	createErr := pushService.commitMapper.Create(commit)
 	if createErr != nil {
 		if errors.Is(createErr, mapper.ErrTagAndDraftDuplicated) {
-			return nil, e.NewInternalError(registryv1alpha1connect.PushServicePushManifestAndBlobsProcedure)
+			return nil, e.NewAlreadyExistsError("tag with the same name")
 		}
 		if errors.Is(createErr, mapper.ErrLastCommitDuplicated) {
 			return nil, e.NewAlreadyExistsError("last commit")
@@ -172,21 +182,24 @@ func (pushService *PushServiceImpl) PushManifestAndBlobsWithDraft(ctx context.Co
 	return commit, nil
 }
 
-func (pushService *PushServiceImpl) toCommit(ctx context.Context, userID, ownerName, repositoryName string, fileManifest *manifest.Manifest, fileBlobs *manifest.BlobSet) (*model.Commit, e.ResponseError) {
+func (pushService *PushServiceImpl) toCommit(ctx context.Context, userID, ownerName, repositoryName string, fileManifest *manifest.Manifest, fileBlobs *manifest.BlobSet, dependentCommits, directDependentCommits model.Commits, warnings []string) (*model.Commit, e.ResponseError) {
 	// 获取user
 	user, err := pushService.userMapper.FindByUserID(userID)
 	if err != nil || user.UserName != ownerName {
 		return nil, e.NewPermissionDeniedError(registryv1alpha1connect.PushServicePushManifestAndBlobsProcedure)
 	}
 
-	// 获取repo
-	repository, err := pushService.repositoryMapper.FindByUserNameAndRepositoryName(ownerName, repositoryName)
+	// 获取repo，旧名称的重定向不能用于推送，仓库转移之后原来的用户无权推送
+	repository, err := pushService.repositoryMapper.FindByUserNameAndRepositoryNameWithoutRedirect(ownerName, repositoryName)
 	if err != nil {
 		return nil, e.NewNotFoundError("repository")
 	}
+	if repository.UserID != user.UserID {
+		return nil, e.NewPermissionDeniedError(registryv1alpha1connect.PushServicePushManifestAndBlobsProcedure)
+	}
 
 	commitID := uuid.NewString()
-	commitName := security.GenerateCommitName(user.UserName, repositoryName)
+	commitName := security.GenerateCommitName(repository.UserName, repository.RepositoryName)
 	createTime := time.Now()
 
 	// 生成file blobs
@@ -263,11 +276,14 @@ func (pushService *PushServiceImpl) toCommit(ctx context.Context, userID, ownerN
 		return nil, e.NewInternalError(err.Error())
 	}
 
+	// 记录本次解析得到的全部依赖，之后生成文档时使用相同的依赖
+	dependencies := toDependencies(repository.RepositoryID, commitID, commitName, dependentCommits, directDependentCommits)
+
 	commit := &model.Commit{
 		UserID:         user.UserID,
 		UserName:       user.UserName,
 		RepositoryID:   repository.RepositoryID,
-		RepositoryName: repositoryName,
+		RepositoryName: repository.RepositoryName,
 		CommitID:       commitID,
 		CommitName:     commitName,
 		CreatedTime:    createTime,
@@ -275,6 +291,10 @@ func (pushService *PushServiceImpl) toCommit(ctx context.Context, userID, ownerN
 		SequenceID:     0,
 		FileManifest:   modelFileManifest,
 		FileBlobs:      modelBlobs,
+		Dependencies:   dependencies,
+
+		DependencyWarnings:   strings.Join(warnings, "\n"),
+		DependenciesRecorded: true,
 	}
 	if configBlob != nil {
 		commit.BufManConfigDigest = configBlob.Digest().Hex()
@@ -318,3 +338,27 @@ func (pushService *PushServiceImpl) saveFileManifestAndBlobs(ctx context.Context
 
 	return nil
 }
+
+// toDependencies 将解析得到的全部依赖转换为依赖关系，不在直接依赖中的标记为间接依赖
+func toDependencies(repositoryID, commitID, commitName string, dependentCommits, directDependentCommits model.Commits) model.Dependencies {
+	direct := make(map[string]struct{}, len(directDependentCommits))
+	for i := 0; i < len(directDependentCommits); i++ {
+		direct[directDependentCommits[i].CommitID] = struct{}{}
+	}
+
+	dependencies := make(model.Dependencies, 0, len(dependentCommits))
+	for i := 0; i < len(dependentCommits); i++ {
+		_, ok := direct[dependentCommits[i].CommitID]
+		dependencies = append(dependencies, &model.Dependency{
+			RepositoryID:           repositoryID,
+			CommitID:               commitID,
+			CommitName:             commitName,
+			DependencyRepositoryID: dependentCommits[i].RepositoryID,
+			DependencyCommitID:     dependentCommits[i].CommitID,
+			DependencyCommitName:   dependentCommits[i].CommitName,
+			Transitive:             !ok,
+		})
+	}
+
+	return dependencies
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"context"
+	"github.com/bufbuild/connect-go"
+	"testing"
+)
+
+// TestPushIgnoresRedirect 仓库转移之后，原来的用户不能通过旧名称推送到转移后的仓库
+func TestPushIgnoresRedirect(t *testing.T) {
+	repositoryService, repositoryMapper := newTestOwnerRepositoryService()
+	ctx := context.Background()
+	repository, _ := repositoryMapper.FindByRepositoryID("weather")
+	if _, err := repositoryService.TransferRepository(ctx, repository, "bob"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repositoryService.AcceptRepositoryTransfer(ctx, "bob-id", repository); err != nil {
+		t.Fatal(err)
+	}
+
+	pushService := &PushServiceImpl{
+		userMapper:       &testUserMapper{},
+		repositoryMapper: repositoryMapper,
+	}
+	_, err := pushService.toCommit(ctx, "alice-id", "alice", "weather", nil, nil, nil, nil, nil)
+	if err == nil || err.Code() != connect.CodeNotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+
+	// 只有仓库当前的所属用户可以推送
+	_, err = pushService.toCommit(ctx, "alice-id", "bob", "weather", nil, nil, nil, nil, nil)
+	if err == nil || err.Code() != connect.CodePermissionDenied {
+		t.Errorf("expected permission denied, got %v", err)
+	}
+}
@@ -3,13 +3,18 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/ProtobufMan/bufman-cli/private/gen/proto/connect/bufman/alpha/registry/v1alpha1/registryv1alpha1connect"
 	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+	"github.com/ProtobufMan/bufman/internal/config"
+	"github.com/ProtobufMan/bufman/internal/core/resolve"
+	"github.com/ProtobufMan/bufman/internal/core/security"
 	"github.com/ProtobufMan/bufman/internal/e"
 	"github.com/ProtobufMan/bufman/internal/mapper"
 	"github.com/ProtobufMan/bufman/internal/model"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"sort"
 )
 
 type RepositoryService interface {
@@ -25,6 +30,16 @@ type RepositoryService interface {
 	DeprecateRepositoryByName(ctx context.Context, ownerName, repositoryName, deprecateMsg string) (*model.Repository, e.ResponseError)
 	UndeprecateRepositoryByName(ctx context.Context, ownerName, repositoryName string) (*model.Repository, e.ResponseError)
 	UpdateRepositorySettingsByName(ctx context.Context, ownerName, repositoryName string, visibility registryv1alpha1.Visibility, description string) e.ResponseError
+	ForkRepository(ctx context.Context, userID string, upstream *model.Repository, repositoryName string) (*model.Repository, e.ResponseError)
+	RenameRepository(ctx context.Context, repository *model.Repository, newRepositoryName string) (*model.Repository, e.ResponseError)
+	TransferRepository(ctx context.Context, repository *model.Repository, newOwnerName string) (*model.RepositoryTransfer, e.ResponseError)
+	AcceptRepositoryTransfer(ctx context.Context, userID string, repository *model.Repository) (*model.Repository, e.ResponseError)
+	CompareRepositoryWithUpstream(ctx context.Context, repository, upstream *model.Repository) (ahead model.Commits, behind model.Commits, respErr e.ResponseError)
+	ListRepositoryDependents(ctx context.Context, userID string, repository *model.Repository, reference string, transitive bool, offset, limit int) ([]*model.Dependent, e.ResponseError)
+	GetDependencyGraph(ctx context.Context, repository *model.Repository, reference string) (*resolve.DependencyGraph, e.ResponseError)
+	ListCommitsWithoutDependencies(ctx context.Context, afterID int64, limit int) (model.Commits, e.ResponseError)
+	RecordCommitDependencies(ctx context.Context, commit *model.Commit) e.ResponseError
+	UpdateRepositoryDependencyPolicy(ctx context.Context, repository *model.Repository, strictDependencies bool) e.ResponseError
 }
 
 type RepositoryServiceImpl struct {
@@ -32,6 +47,9 @@ type RepositoryServiceImpl struct {
 	userMapper       mapper.UserMapper
 	commitMapper     mapper.CommitMapper
 	tagMapper        mapper.TagMapper
+	fileMapper       mapper.FileMapper
+	dependencyMapper mapper.DependencyMapper
+	resolver         resolve.Resolver
 }
 
 func NewRepositoryService() RepositoryService {
@@ -40,6 +58,9 @@ func NewRepositoryService() RepositoryService {
 		userMapper:       &mapper.UserMapperImpl{},
 		commitMapper:     &mapper.CommitMapperImpl{},
 		tagMapper:        &mapper.TagMapperImpl{},
+		fileMapper:       &mapper.FileMapperImpl{},
+		dependencyMapper: &mapper.DependencyMapperImpl{},
+		resolver:         resolve.NewResolver(NewAuthorizationService()),
 	}
 }
 
@@ -133,7 +154,7 @@ func (repositoryService *RepositoryServiceImpl) CreateRepositoryByUserNameAndRep
 
 	err = repositoryService.repositoryMapper.Create(repository)
 	if err != nil {
-		if errors.Is(err, gorm.ErrDuplicatedKey) {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || errors.Is(err, mapper.ErrRepositoryRedirected) {
 			return nil, e.NewAlreadyExistsError("repository")
 		}
 
@@ -202,3 +223,450 @@ func (repositoryService *RepositoryServiceImpl) UpdateRepositorySettingsByName(c
 
 	return nil
 }
+
+// UpdateRepositoryDependencyPolicy 修改仓库push时的依赖检查策略
+func (repositoryService *RepositoryServiceImpl) UpdateRepositoryDependencyPolicy(ctx context.Context, repository *model.Repository, strictDependencies bool) e.ResponseError {
+	err := repositoryService.repositoryMapper.UpdateStrictDependenciesByRepositoryID(repository.RepositoryID, strictDependencies)
+	if err != nil {
+		return e.NewInternalError("update repository dependency policy")
+	}
+
+	return nil
+}
+
+func (repositoryService *RepositoryServiceImpl) ForkRepository(ctx context.Context, userID string, upstream *model.Repository, repositoryName string) (*model.Repository, e.ResponseError) {
+	// 查询用户
+	user, err := repositoryService.userMapper.FindByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, e.NewNotFoundError("user")
+		}
+
+		return nil, e.NewInternalError("fork repository")
+	}
+
+	// 一次查询出上游的commits、文件清单、文件、tags以及依赖关系
+	upstreamCommits, err := repositoryService.commitMapper.FindAllByRepositoryID(upstream.RepositoryID)
+	if err != nil {
+		return nil, e.NewInternalError("fork repository")
+	}
+	upstreamManifests, err := repositoryService.fileMapper.FindAllManifestsByRepositoryID(upstream.RepositoryID)
+	if err != nil {
+		return nil, e.NewInternalError("fork repository")
+	}
+	upstreamBlobs, err := repositoryService.fileMapper.FindAllBlobsByRepositoryID(upstream.RepositoryID)
+	if err != nil {
+		return nil, e.NewInternalError("fork repository")
+	}
+	upstreamTags, err := repositoryService.tagMapper.FindAllByRepositoryID(upstream.RepositoryID)
+	if err != nil {
+		return nil, e.NewInternalError("fork repository")
+	}
+	upstreamDependencies, err := repositoryService.dependencyMapper.FindAllByRepositoryID(upstream.RepositoryID)
+	if err != nil {
+		return nil, e.NewInternalError("fork repository")
+	}
+
+	// 按照commit分组
+	manifestMap := make(map[string]*model.FileManifest, len(upstreamManifests))
+	for i := 0; i < len(upstreamManifests); i++ {
+		manifestMap[upstreamManifests[i].CommitID] = upstreamManifests[i]
+	}
+	blobMap := make(map[string]model.FileBlobs, len(upstreamCommits))
+	for i := 0; i < len(upstreamBlobs); i++ {
+		blobMap[upstreamBlobs[i].CommitID] = append(blobMap[upstreamBlobs[i].CommitID], upstreamBlobs[i])
+	}
+	tagMap := make(map[string]model.Tags, len(upstreamTags))
+	for i := 0; i < len(upstreamTags); i++ {
+		tagMap[upstreamTags[i].CommitID] = append(tagMap[upstreamTags[i].CommitID], upstreamTags[i])
+	}
+	dependencyMap := make(map[string]model.Dependencies, len(upstreamCommits))
+	for i := 0; i < len(upstreamDependencies); i++ {
+		dependencyMap[upstreamDependencies[i].CommitID] = append(dependencyMap[upstreamDependencies[i].CommitID], upstreamDependencies[i])
+	}
+
+	repository := &model.Repository{
+		UserID:                 user.UserID,
+		UserName:               user.UserName,
+		RepositoryID:           uuid.NewString(),
+		RepositoryName:         repositoryName,
+		Visibility:             upstream.Visibility,
+		Url:                    upstream.Url,
+		Description:            upstream.Description,
+		ForkedFromRepositoryID: upstream.RepositoryID,
+	}
+
+	// 复制commit历史，文件内容通过digest共享，不需要复制
+	commits := make(model.Commits, 0, len(upstreamCommits))
+	for i := 0; i < len(upstreamCommits); i++ {
+		upstreamCommit := upstreamCommits[i]
+		commit := &model.Commit{
+			UserID:               user.UserID,
+			UserName:             user.UserName,
+			RepositoryID:         repository.RepositoryID,
+			RepositoryName:       repository.RepositoryName,
+			CommitID:             uuid.NewString(),
+			CommitName:           security.GenerateCommitName(user.UserName, repository.RepositoryName),
+			CreatedTime:          upstreamCommit.CreatedTime,
+			ManifestDigest:       upstreamCommit.ManifestDigest,
+			BufManConfigDigest:   upstreamCommit.BufManConfigDigest,
+			DocumentDigest:       upstreamCommit.DocumentDigest,
+			LicenseDigest:        upstreamCommit.LicenseDigest,
+			SequenceID:           upstreamCommit.SequenceID,
+			DependencyWarnings:   upstreamCommit.DependencyWarnings,
+			DependenciesRecorded: upstreamCommit.DependenciesRecorded,
+		}
+
+		// 文件清单
+		fileManifest, ok := manifestMap[upstreamCommit.CommitID]
+		if !ok {
+			return nil, e.NewInternalError("fork repository")
+		}
+		commit.FileManifest = &model.FileManifest{
+			Digest:   fileManifest.Digest,
+			CommitID: commit.CommitID,
+		}
+
+		// 文件blobs
+		fileBlobs := blobMap[upstreamCommit.CommitID]
+		commit.FileBlobs = make(model.FileBlobs, 0, len(fileBlobs))
+		for j := 0; j < len(fileBlobs); j++ {
+			commit.FileBlobs = append(commit.FileBlobs, &model.FileBlob{
+				Digest:   fileBlobs[j].Digest,
+				CommitID: commit.CommitID,
+				FileName: fileBlobs[j].FileName,
+			})
+		}
+
+		// tags
+		tags := tagMap[upstreamCommit.CommitID]
+		for j := 0; j < len(tags); j++ {
+			commit.Tags = append(commit.Tags, &model.Tag{
+				UserID:       user.UserID,
+				UserName:     user.UserName,
+				RepositoryID: repository.RepositoryID,
+				CommitID:     commit.CommitID,
+				CommitName:   commit.CommitName,
+				TagID:        uuid.NewString(),
+				CreatedTime:  tags[j].CreatedTime,
+				TagName:      tags[j].TagName,
+			})
+		}
+
+		// 依赖关系，fork之后同样作为被依赖仓库的依赖方
+		dependencies := dependencyMap[upstreamCommit.CommitID]
+		for j := 0; j < len(dependencies); j++ {
+			commit.Dependencies = append(commit.Dependencies, &model.Dependency{
+				RepositoryID:           repository.RepositoryID,
+				CommitID:               commit.CommitID,
+				CommitName:             commit.CommitName,
+				DependencyRepositoryID: dependencies[j].DependencyRepositoryID,
+				DependencyCommitID:     dependencies[j].DependencyCommitID,
+				DependencyCommitName:   dependencies[j].DependencyCommitName,
+				Transitive:             dependencies[j].Transitive,
+			})
+		}
+
+		commits = append(commits, commit)
+	}
+
+	err = repositoryService.repositoryMapper.CreateFork(repository, commits)
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || errors.Is(err, mapper.ErrRepositoryRedirected) {
+			return nil, e.NewAlreadyExistsError("repository")
+		}
+
+		return nil, e.NewInternalError("fork repository")
+	}
+
+	return repository, nil
+}
+
+func (repositoryService *RepositoryServiceImpl) CompareRepositoryWithUpstream(ctx context.Context, repository, upstream *model.Repository) (model.Commits, model.Commits, e.ResponseError) {
+	commits, err := repositoryService.commitMapper.FindAllByRepositoryID(repository.RepositoryID)
+	if err != nil {
+		return nil, nil, e.NewInternalError("compare repository with upstream")
+	}
+	upstreamCommits, err := repositoryService.commitMapper.FindAllByRepositoryID(upstream.RepositoryID)
+	if err != nil {
+		return nil, nil, e.NewInternalError("compare repository with upstream")
+	}
+
+	// fork与上游的commit name不同，以文件清单digest判断是否为相同的提交
+	digests := make(map[string]struct{}, len(commits))
+	for i := 0; i < len(commits); i++ {
+		digests[commits[i].ManifestDigest] = struct{}{}
+	}
+	upstreamDigests := make(map[string]struct{}, len(upstreamCommits))
+	for i := 0; i < len(upstreamCommits); i++ {
+		upstreamDigests[upstreamCommits[i].ManifestDigest] = struct{}{}
+	}
+
+	var ahead, behind model.Commits
+	for i := 0; i < len(commits); i++ {
+		if _, ok := upstreamDigests[commits[i].ManifestDigest]; !ok {
+			ahead = append(ahead, commits[i])
+		}
+	}
+	for i := 0; i < len(upstreamCommits); i++ {
+		if _, ok := digests[upstreamCommits[i].ManifestDigest]; !ok {
+			behind = append(behind, upstreamCommits[i])
+		}
+	}
+
+	return ahead, behind, nil
+}
+
+func (repositoryService *RepositoryServiceImpl) RenameRepository(ctx context.Context, repository *model.Repository, newRepositoryName string) (*model.Repository, e.ResponseError) {
+	return repositoryService.updateOwnerAndName(repository, repository.UserID, repository.UserName, newRepositoryName)
+}
+
+// TransferRepository 发起仓库转移，新的所属用户接受之后才会转移
+func (repositoryService *RepositoryServiceImpl) TransferRepository(ctx context.Context, repository *model.Repository, newOwnerName string) (*model.RepositoryTransfer, e.ResponseError) {
+	// 查询新的所属用户
+	user, err := repositoryService.userMapper.FindByUserName(newOwnerName)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, e.NewNotFoundError(fmt.Sprintf("user %s", newOwnerName))
+		}
+
+		return nil, e.NewInternalError("transfer repository")
+	}
+	if user.UserID == repository.UserID {
+		return nil, e.NewInvalidArgumentError(fmt.Sprintf("repository already belongs to %s", newOwnerName))
+	}
+
+	transfer := &model.RepositoryTransfer{
+		RepositoryID: repository.RepositoryID,
+		FromUserID:   repository.UserID,
+		ToUserID:     user.UserID,
+		ToUserName:   user.UserName,
+	}
+	err = repositoryService.repositoryMapper.CreateTransfer(transfer)
+	if err != nil {
+		return nil, e.NewInternalError("transfer repository")
+	}
+
+	return transfer, nil
+}
+
+// AcceptRepositoryTransfer 新的所属用户接受仓库转移
+func (repositoryService *RepositoryServiceImpl) AcceptRepositoryTransfer(ctx context.Context, userID string, repository *model.Repository) (*model.Repository, e.ResponseError) {
+	transfer, err := repositoryService.repositoryMapper.FindTransferByRepositoryID(repository.RepositoryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, e.NewNotFoundError("repository transfer")
+		}
+
+		return nil, e.NewInternalError("accept repository transfer")
+	}
+	if transfer.ToUserID != userID {
+		return nil, e.NewPermissionDeniedError("accept repository transfer")
+	}
+
+	err = repositoryService.repositoryMapper.AcceptTransfer(transfer)
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || errors.Is(err, mapper.ErrRepositoryRedirected) {
+			return nil, e.NewAlreadyExistsError(fmt.Sprintf("repository %s/%s", transfer.ToUserName, repository.RepositoryName))
+		}
+
+		return nil, e.NewInternalError("accept repository transfer")
+	}
+
+	// 查询更新后的仓库
+	updatedRepository, err := repositoryService.repositoryMapper.FindByRepositoryID(repository.RepositoryID)
+	if err != nil {
+		return nil, e.NewInternalError("accept repository transfer")
+	}
+
+	return updatedRepository, nil
+}
+
+func (repositoryService *RepositoryServiceImpl) updateOwnerAndName(repository *model.Repository, userID, userName, repositoryName string) (*model.Repository, e.ResponseError) {
+	err := repositoryService.repositoryMapper.UpdateOwnerAndNameByRepositoryID(repository.RepositoryID, userID, userName, repositoryName)
+	if err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) || errors.Is(err, mapper.ErrRepositoryRedirected) {
+			return nil, e.NewAlreadyExistsError(fmt.Sprintf("repository %s/%s", userName, repositoryName))
+		}
+
+		return nil, e.NewInternalError("update repository owner and name")
+	}
+
+	// 查询更新后的仓库
+	updatedRepository, err := repositoryService.repositoryMapper.FindByRepositoryID(repository.RepositoryID)
+	if err != nil {
+		return nil, e.NewInternalError("update repository owner and name")
+	}
+
+	return updatedRepository, nil
+}
+
+// ListRepositoryDependents 查询依赖该仓库(reference不为空时为对应commit)的仓库，transitive为true时包括间接依赖，userID无权访问的仓库不会返回
+func (repositoryService *RepositoryServiceImpl) ListRepositoryDependents(ctx context.Context, userID string, repository *model.Repository, reference string, transitive bool, offset, limit int) ([]*model.Dependent, e.ResponseError) {
+	// 直接依赖
+	var dependencies model.Dependencies
+	var err error
+	if reference == "" {
+		dependencies, err = repositoryService.dependencyMapper.FindAllByDependencyRepositoryID(repository.RepositoryID)
+	} else {
+		commit, findErr := repositoryService.commitMapper.FindByRepositoryIDAndReference(repository.RepositoryID, reference)
+		if findErr != nil {
+			if errors.Is(findErr, gorm.ErrRecordNotFound) {
+				return nil, e.NewNotFoundError(fmt.Sprintf("repository %s reference %s", repository.RepositoryName, reference))
+			}
+			if errors.Is(findErr, mapper.ErrInvalidReference) {
+				return nil, e.NewInvalidArgumentError(fmt.Sprintf("reference %s", reference))
+			}
+
+			return nil, e.NewInternalError("list repository dependents")
+		}
+		dependencies, err = repositoryService.dependencyMapper.FindAllByDependencyCommitIDs([]string{commit.CommitID})
+	}
+	if err != nil {
+		return nil, e.NewInternalError("list repository dependents")
+	}
+
+	// 按照commit逐层展开，无权访问的仓库不返回，也不通过它展开间接依赖
+	dependentMap := map[string]*model.Dependent{}   // repository id -> dependent
+	repositoryMap := map[string]*model.Repository{} // repository id -> 已经查询过的仓库，nil表示无权访问
+	visitedCommits := map[string]struct{}{}
+	for depth := 1; len(dependencies) > 0; depth++ {
+		err = repositoryService.findAccessibleRepositories(userID, repositoryMap, dependencies)
+		if err != nil {
+			return nil, e.NewInternalError("list repository dependents")
+		}
+
+		nextCommitIDs := make([]string, 0, len(dependencies))
+		for i := 0; i < len(dependencies); i++ {
+			dependency := dependencies[i]
+			if dependency.RepositoryID == repository.RepositoryID || repositoryMap[dependency.RepositoryID] == nil {
+				continue
+			}
+			if _, ok := visitedCommits[dependency.CommitID]; ok {
+				continue
+			}
+			visitedCommits[dependency.CommitID] = struct{}{}
+			nextCommitIDs = append(nextCommitIDs, dependency.CommitID)
+
+			dependent, ok := dependentMap[dependency.RepositoryID]
+			if !ok {
+				dependent = &model.Dependent{Repository: repositoryMap[dependency.RepositoryID], Depth: depth}
+				dependentMap[dependency.RepositoryID] = dependent
+			}
+			dependent.CommitNames = append(dependent.CommitNames, dependency.CommitName)
+		}
+
+		if !transitive {
+			break
+		}
+		dependencies, err = repositoryService.dependencyMapper.FindAllByDependencyCommitIDs(nextCommitIDs)
+		if err != nil {
+			return nil, e.NewInternalError("list repository dependents")
+		}
+	}
+
+	dependents := make([]*model.Dependent, 0, len(dependentMap))
+	for _, dependent := range dependentMap {
+		sort.Strings(dependent.CommitNames)
+		dependents = append(dependents, dependent)
+	}
+	sort.Slice(dependents, func(i, j int) bool {
+		if dependents[i].Depth != dependents[j].Depth {
+			return dependents[i].Depth < dependents[j].Depth
+		}
+		if dependents[i].Repository.UserName != dependents[j].Repository.UserName {
+			return dependents[i].Repository.UserName < dependents[j].Repository.UserName
+		}
+		return dependents[i].Repository.RepositoryName < dependents[j].Repository.RepositoryName
+	})
+
+	// 分页
+	if offset >= len(dependents) {
+		return []*model.Dependent{}, nil
+	}
+	end := offset + limit
+	if end > len(dependents) {
+		end = len(dependents)
+	}
+
+	return dependents[offset:end], nil
+}
+
+// findAccessibleRepositories 查询依赖方中还没有查询过的仓库，userID无权访问的仓库记录为nil
+func (repositoryService *RepositoryServiceImpl) findAccessibleRepositories(userID string, repositoryMap map[string]*model.Repository, dependencies model.Dependencies) error {
+	repositoryIDs := make([]string, 0, len(dependencies))
+	for i := 0; i < len(dependencies); i++ {
+		repositoryID := dependencies[i].RepositoryID
+		if _, ok := repositoryMap[repositoryID]; ok {
+			continue
+		}
+		repositoryMap[repositoryID] = nil
+		repositoryIDs = append(repositoryIDs, repositoryID)
+	}
+
+	repositories, err := repositoryService.repositoryMapper.FindAllByRepositoryIDs(repositoryIDs)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(repositories); i++ {
+		if registryv1alpha1.Visibility(repositories[i].Visibility) != registryv1alpha1.Visibility_VISIBILITY_PUBLIC && repositories[i].UserID != userID {
+			continue
+		}
+		repositoryMap[repositories[i].RepositoryID] = repositories[i]
+	}
+
+	return nil
+}
+
+// GetDependencyGraph 获取reference对应commit的完整依赖图
+func (repositoryService *RepositoryServiceImpl) GetDependencyGraph(ctx context.Context, repository *model.Repository, reference string) (*resolve.DependencyGraph, e.ResponseError) {
+	commit, err := repositoryService.commitMapper.FindByRepositoryIDAndReference(repository.RepositoryID, reference)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, e.NewNotFoundError(fmt.Sprintf("repository %s reference %s", repository.RepositoryName, reference))
+		}
+		if errors.Is(err, mapper.ErrInvalidReference) {
+			return nil, e.NewInvalidArgumentError(fmt.Sprintf("reference %s", reference))
+		}
+
+		return nil, e.NewInternalError("get dependency graph")
+	}
+
+	identity := fmt.Sprintf("%s/%s/%s", config.Properties.BufMan.ServerHost, repository.UserName, repository.RepositoryName)
+	return repositoryService.resolver.GetDependencyGraph(ctx, identity, commit)
+}
+
+// ListCommitsWithoutDependencies 查询还没有记录依赖关系的commits，用于补全历史commit的依赖关系
+func (repositoryService *RepositoryServiceImpl) ListCommitsWithoutDependencies(ctx context.Context, afterID int64, limit int) (model.Commits, e.ResponseError) {
+	commits, err := repositoryService.commitMapper.FindPageWithoutDependencies(afterID, limit)
+	if err != nil {
+		return nil, e.NewInternalError(err.Error())
+	}
+
+	return commits, nil
+}
+
+// RecordCommitDependencies 解析commit的buf.yaml并记录全部依赖，已有的记录会被替换
+// 历史commit无法得知push时的解析结果，按照当前的解析结果记录
+func (repositoryService *RepositoryServiceImpl) RecordCommitDependencies(ctx context.Context, commit *model.Commit) e.ResponseError {
+	var dependencies model.Dependencies
+	if commit.BufManConfigDigest != "" {
+		bufConfig, respErr := repositoryService.resolver.GetBufConfigFromCommitID(ctx, commit.CommitID)
+		if respErr != nil {
+			return respErr
+		}
+		allCommits, directCommits, respErr := repositoryService.resolver.GetDependenciesFromBufConfig(ctx, bufConfig)
+		if respErr != nil {
+			return respErr
+		}
+
+		dependencies = toDependencies(commit.RepositoryID, commit.CommitID, commit.CommitName, allCommits, directCommits)
+	}
+
+	err := repositoryService.dependencyMapper.ReplaceByCommitID(commit.CommitID, dependencies)
+	if err != nil {
+		return e.NewInternalError(err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	registryv1alpha1 "github.com/ProtobufMan/bufman-cli/private/gen/proto/go/bufman/alpha/registry/v1alpha1"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"github.com/bufbuild/connect-go"
+	"testing"
+)
+
+func TestForkRepository(t *testing.T) {
+	store := newTestStore(
+		&model.Commit{RepositoryID: "upstream", CommitID: "c1", CommitName: "commit1", ManifestDigest: "m1", SequenceID: 1},
+		&model.Commit{RepositoryID: "upstream", CommitID: "c2", CommitName: "commit2", ManifestDigest: "m2", SequenceID: 2},
+	)
+	store.files["c1"] = map[string]string{"a.proto": "a1"}
+	store.files["c2"] = map[string]string{"a.proto": "a2", "b.proto": "b1"}
+	store.tags = model.Tags{
+		{RepositoryID: "upstream", CommitID: "c2", TagName: "v1.0.0"},
+	}
+	store.dependencies = model.Dependencies{
+		{RepositoryID: "upstream", CommitID: "c2", CommitName: "commit2", DependencyRepositoryID: "weather", DependencyCommitID: "w1", DependencyCommitName: "weather1"},
+	}
+	repositoryMapper := newTestRepositoryMapper()
+	repositoryService := &RepositoryServiceImpl{
+		repositoryMapper: repositoryMapper,
+		userMapper:       &testUserMapper{},
+		commitMapper:     &testCommitMapper{store: store},
+		tagMapper:        &testTagMapper{store: store},
+		fileMapper:       &testFileMapper{store: store},
+		dependencyMapper: &testDependencyMapper{store: store},
+	}
+
+	upstream := &model.Repository{RepositoryID: "upstream", RepositoryName: "weather"}
+	repository, err := repositoryService.ForkRepository(context.Background(), "bob-id", upstream, "weather-fork")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repository.UserName != "bob" || repository.ForkedFromRepositoryID != "upstream" || repositoryMapper.repositories[repository.RepositoryID] != repository {
+		t.Fatalf("unexpected fork %+v", repository)
+	}
+
+	// 每种数据只查询一次
+	for _, name := range []string{"commits", "manifests", "blobs", "tags", "dependencies"} {
+		if store.calls[name] != 1 {
+			t.Errorf("expected 1 query for %s, got %d", name, store.calls[name])
+		}
+	}
+
+	commits := repositoryMapper.forkedCommits
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits, got %d", len(commits))
+	}
+	for _, commit := range commits {
+		if commit.RepositoryID != repository.RepositoryID || commit.CommitID == "c1" || commit.CommitID == "c2" {
+			t.Errorf("commit %s not copied into fork", commit.CommitID)
+		}
+		if commit.FileManifest.CommitID != commit.CommitID {
+			t.Errorf("manifest of %s not copied", commit.CommitID)
+		}
+		for _, fileBlob := range commit.FileBlobs {
+			if fileBlob.CommitID != commit.CommitID {
+				t.Errorf("blob %s of %s not copied", fileBlob.FileName, commit.CommitID)
+			}
+		}
+	}
+
+	first, second := commits[0], commits[1]
+	if first.FileManifest.Digest != "m1" || len(first.FileBlobs) != 1 || len(first.Tags) != 0 || len(first.Dependencies) != 0 {
+		t.Errorf("unexpected first commit %+v", first)
+	}
+	if second.FileManifest.Digest != "m2" || len(second.FileBlobs) != 2 {
+		t.Errorf("unexpected second commit %+v", second)
+	}
+	if len(second.Tags) != 1 || second.Tags[0].TagName != "v1.0.0" || second.Tags[0].CommitID != second.CommitID {
+		t.Errorf("unexpected tags %+v", second.Tags)
+	}
+	if len(second.Dependencies) != 1 {
+		t.Fatalf("expected 1 dependency, got %d", len(second.Dependencies))
+	}
+	dependency := second.Dependencies[0]
+	if dependency.RepositoryID != repository.RepositoryID || dependency.CommitID != second.CommitID || dependency.CommitName != second.CommitName || dependency.DependencyCommitID != "w1" {
+		t.Errorf("unexpected dependency %+v", dependency)
+	}
+}
+
+func newTestOwnerRepositoryService() (*RepositoryServiceImpl, *testRepositoryMapper) {
+	repositoryMapper := newTestRepositoryMapper(
+		&model.Repository{UserID: "alice-id", UserName: "alice", RepositoryID: "weather", RepositoryName: "weather"},
+		&model.Repository{UserID: "alice-id", UserName: "alice", RepositoryID: "units", RepositoryName: "units"},
+	)
+
+	return &RepositoryServiceImpl{repositoryMapper: repositoryMapper, userMapper: &testUserMapper{}}, repositoryMapper
+}
+
+func TestRenameRepository(t *testing.T) {
+	repositoryService, repositoryMapper := newTestOwnerRepositoryService()
+	repository, _ := repositoryMapper.FindByRepositoryID("weather")
+
+	updated, err := repositoryService.RenameRepository(context.Background(), repository, "forecast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.RepositoryName != "forecast" || updated.UserName != "alice" {
+		t.Errorf("unexpected repository %+v", updated)
+	}
+
+	// 新名称已经被占用
+	_, err = repositoryService.RenameRepository(context.Background(), updated, "units")
+	if err == nil || err.Code() != connect.CodeAlreadyExists {
+		t.Errorf("expected already exists, got %v", err)
+	}
+}
+
+func TestTransferRepository(t *testing.T) {
+	repositoryService, repositoryMapper := newTestOwnerRepositoryService()
+	ctx := context.Background()
+	repository, _ := repositoryMapper.FindByRepositoryID("weather")
+
+	// 不能转移给不存在的用户或者自己
+	if _, err := repositoryService.TransferRepository(ctx, repository, "nobody"); err == nil || err.Code() != connect.CodeNotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if _, err := repositoryService.TransferRepository(ctx, repository, "alice"); err == nil || err.Code() != connect.CodeInvalidArgument {
+		t.Errorf("expected invalid argument, got %v", err)
+	}
+
+	// 发起转移之后仓库不变
+	transfer, err := repositoryService.TransferRepository(ctx, repository, "bob")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if transfer.ToUserID != "bob-id" || transfer.FromUserID != "alice-id" {
+		t.Errorf("unexpected transfer %+v", transfer)
+	}
+	if unchanged, _ := repositoryMapper.FindByRepositoryID("weather"); unchanged.UserName != "alice" {
+		t.Fatalf("repository transferred before acceptance: %+v", unchanged)
+	}
+
+	// 只有新的所属用户可以接受
+	if _, err = repositoryService.AcceptRepositoryTransfer(ctx, "carol-id", repository); err == nil || err.Code() != connect.CodePermissionDenied {
+		t.Errorf("expected permission denied, got %v", err)
+	}
+	updated, err := repositoryService.AcceptRepositoryTransfer(ctx, "bob-id", repository)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.UserID != "bob-id" || updated.UserName != "bob" || updated.RepositoryName != "weather" {
+		t.Errorf("unexpected repository %+v", updated)
+	}
+
+	// 转移只能接受一次
+	if _, err = repositoryService.AcceptRepositoryTransfer(ctx, "bob-id", updated); err == nil || err.Code() != connect.CodeNotFound {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
+
+// TestRepositoryRedirectNotShadowed 旧名称上的重定向不能被新创建或者重命名的其他仓库占用
+func TestRepositoryRedirectNotShadowed(t *testing.T) {
+	repositoryService, repositoryMapper := newTestOwnerRepositoryService()
+	ctx := context.Background()
+	repository, _ := repositoryMapper.FindByRepositoryID("weather")
+
+	renamed, err := repositoryService.RenameRepository(ctx, repository, "forecast")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = repositoryService.CreateRepositoryByUserNameAndRepositoryName(ctx, "alice-id", "alice", "weather", registryv1alpha1.Visibility_VISIBILITY_PUBLIC); err == nil || err.Code() != connect.CodeAlreadyExists {
+		t.Errorf("expected already exists for create, got %v", err)
+	}
+	units, _ := repositoryMapper.FindByRepositoryID("units")
+	if _, err = repositoryService.RenameRepository(ctx, units, "weather"); err == nil || err.Code() != connect.CodeAlreadyExists {
+		t.Errorf("expected already exists for rename, got %v", err)
+	}
+
+	// 仓库本身可以改回旧名称
+	if _, err = repositoryService.RenameRepository(ctx, renamed, "weather"); err != nil {
+		t.Errorf("expected rename back to succeed, got %v", err)
+	}
+}
+
+func formatDependents(dependents []*model.Dependent) []string {
+	formatted := make([]string, 0, len(dependents))
+	for _, dependent := range dependents {
+		formatted = append(formatted, fmt.Sprintf("%s:%d", dependent.Repository.RepositoryName, dependent.Depth))
+	}
+
+	return formatted
+}
+
+// TestListRepositoryDependentsHidesPrivate 私有仓库依赖weather，公开的app依赖该私有仓库，无权访问私有仓库的用户也看不到app
+func TestListRepositoryDependentsHidesPrivate(t *testing.T) {
+	repositoryService, repositoryMapper := newTestOwnerRepositoryService()
+	for _, repository := range []*model.Repository{
+		{UserID: "carol-id", UserName: "carol", RepositoryID: "secret", RepositoryName: "secret", Visibility: uint8(registryv1alpha1.Visibility_VISIBILITY_PRIVATE)},
+		{UserID: "dave-id", UserName: "dave", RepositoryID: "app", RepositoryName: "app", Visibility: uint8(registryv1alpha1.Visibility_VISIBILITY_PUBLIC)},
+		{UserID: "erin-id", UserName: "erin", RepositoryID: "client", RepositoryName: "client", Visibility: uint8(registryv1alpha1.Visibility_VISIBILITY_PUBLIC)},
+	} {
+		repositoryMapper.repositories[repository.RepositoryID] = repository
+	}
+	store := newTestStore()
+	store.dependencies = model.Dependencies{
+		{RepositoryID: "secret", CommitID: "s1", CommitName: "secret1", DependencyRepositoryID: "weather", DependencyCommitID: "w1"},
+		{RepositoryID: "app", CommitID: "a1", CommitName: "app1", DependencyRepositoryID: "secret", DependencyCommitID: "s1"},
+		{RepositoryID: "app", CommitID: "a1", CommitName: "app1", DependencyRepositoryID: "weather", DependencyCommitID: "w1", Transitive: true},
+		{RepositoryID: "client", CommitID: "c1", CommitName: "client1", DependencyRepositoryID: "weather", DependencyCommitID: "w1"},
+	}
+	repositoryService.dependencyMapper = &testDependencyMapper{store: store}
+	weather, _ := repositoryMapper.FindByRepositoryID("weather")
+
+	tests := []struct {
+		userID   string
+		expected []string
+	}{
+		{userID: "bob-id", expected: []string{"client:1"}},
+		{userID: "carol-id", expected: []string{"secret:1", "client:1", "app:2"}},
+	}
+	for _, test := range tests {
+		dependents, err := repositoryService.ListRepositoryDependents(context.Background(), test.userID, weather, "", true, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := formatDependents(dependents); !equalStrings(actual, test.expected) {
+			t.Errorf("%s: expected %v, got %v", test.userID, test.expected, actual)
+		}
+	}
+}
+
+func TestRecordCommitDependencies(t *testing.T) {
+	store := newTestStore()
+	store.dependencies = model.Dependencies{
+		{RepositoryID: "app", CommitID: "a1", DependencyRepositoryID: "units", DependencyCommitID: "u1"},
+	}
+	repositoryService := &RepositoryServiceImpl{
+		dependencyMapper: &testDependencyMapper{store: store},
+		resolver: &testResolver{
+			allCommits: model.Commits{
+				{RepositoryID: "weather", CommitID: "w2", CommitName: "weather2"},
+				{RepositoryID: "units", CommitID: "u2", CommitName: "units2"},
+			},
+			directCommits: model.Commits{
+				{RepositoryID: "weather", CommitID: "w2", CommitName: "weather2"},
+			},
+		},
+	}
+	ctx := context.Background()
+
+	// 已有的依赖关系被替换为解析结果，不在直接依赖中的记录为间接依赖
+	commit := &model.Commit{RepositoryID: "app", CommitID: "a1", CommitName: "app1", BufManConfigDigest: "config-digest"}
+	if err := repositoryService.RecordCommitDependencies(ctx, commit); err != nil {
+		t.Fatal(err)
+	}
+	dependencies := store.dependencies
+	if len(dependencies) != 2 || dependencies[0].DependencyCommitID != "w2" || dependencies[0].CommitName != "app1" || dependencies[0].Transitive ||
+		dependencies[1].DependencyCommitID != "u2" || !dependencies[1].Transitive {
+		t.Errorf("unexpected dependencies %+v", dependencies)
+	}
+
+	// 没有buf.yaml的commit同样标记为已经记录
+	commit = &model.Commit{RepositoryID: "app", CommitID: "a0", CommitName: "app0"}
+	if err := repositoryService.RecordCommitDependencies(ctx, commit); err != nil {
+		t.Fatal(err)
+	}
+	if !store.recorded["a0"] || !store.recorded["a1"] || len(store.dependencies) != 2 {
+		t.Errorf("unexpected record %v %+v", store.recorded, store.dependencies)
+	}
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"github.com/ProtobufMan/bufman/internal/core/lru"
+	"sync"
+)
+
+// resultCache 按照commit计算结果的缓存，commit不可变，所以结果可以一直缓存，超过容量时淘汰最久未使用的结果
+// 配置在包初始化之后才会加载，所以在第一次使用时按照配置的容量创建，容量为0时不使用缓存
+type resultCache struct {
+	once    sync.Once
+	maxSize func() int64 // 缓存的容量，单位MB
+	lru     *lru.SizedLru
+}
+
+func newResultCache(maxSize func() int64) *resultCache {
+	return &resultCache{maxSize: maxSize}
+}
+
+func (cache *resultCache) getLru() *lru.SizedLru {
+	cache.once.Do(func() {
+		if maxSize := cache.maxSize(); maxSize > 0 {
+			cache.lru = lru.NewSizedLru(maxSize << 20)
+		}
+	})
+
+	return cache.lru
+}
+
+func (cache *resultCache) Get(key string) (interface{}, bool) {
+	if l := cache.getLru(); l != nil {
+		return l.Get(key)
+	}
+
+	return nil, false
+}
+
+// Add 加入结果，size为结果占用的内存，不使用缓存时返回false
+func (cache *resultCache) Add(key string, value interface{}, size int64) bool {
+	if l := cache.getLru(); l != nil {
+		return l.Add(key, value, size)
+	}
+
+	return false
+}
+
+func (cache *resultCache) Del(key string) {
+	if l := cache.getLru(); l != nil {
+		l.Del(key)
+	}
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"testing"
+)
+
+func TestResultCache(t *testing.T) {
+	cache := newResultCache(func() int64 { return 1 })
+	if !cache.Add("key", "value", 1<<10) {
+		t.Fatal("expected value cached")
+	}
+	if value, ok := cache.Get("key"); !ok || value != "value" {
+		t.Errorf("expected cached value, got %v", value)
+	}
+	// 超过容量的结果不会被缓存
+	if cache.Add("large", "value", 2<<20) {
+		t.Error("expected large value not cached")
+	}
+
+	// 容量为0时不使用缓存
+	disabled := newResultCache(func() int64 { return 0 })
+	if disabled.Add("key", "value", 1) {
+		t.Error("expected cache disabled")
+	}
+	if _, ok := disabled.Get("key"); ok {
+		t.Error("expected cache disabled")
+	}
+}
@@ -3,9 +3,11 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/ProtobufMan/bufman/internal/constant"
 	"github.com/ProtobufMan/bufman/internal/core/es"
 	"github.com/ProtobufMan/bufman/internal/core/lru"
+	"github.com/ProtobufMan/bufman/internal/core/parser"
 	"github.com/ProtobufMan/bufman/internal/e"
 	"github.com/ProtobufMan/bufman/internal/mapper"
 	"github.com/ProtobufMan/bufman/internal/model"
@@ -18,6 +20,7 @@ type SearchService interface {
 	SearchCurationPlugin(ctx context.Context, query string, offset, limit int, reverse bool) (model.Plugins, e.ResponseError)
 	SearchTag(ctx context.Context, repositoryID, query string, offset, limit int, reverse bool) (model.Tags, e.ResponseError)
 	SearchDraft(ctx context.Context, repositoryID, query string, offset, limit int, reverse bool) (model.Commits, e.ResponseError)
+	SearchSymbol(ctx context.Context, userID, query, kind, packageName, repositoryID string, offset, limit int, reverse bool) (model.Symbols, map[string]*model.Repository, e.ResponseError)
 }
 
 func NewSearchService() SearchService {
@@ -27,6 +30,7 @@ func NewSearchService() SearchService {
 		commitMapper:     &mapper.CommitMapperImpl{},
 		tagMapper:        &mapper.TagMapperImpl{},
 		pluginMapper:     &mapper.PluginMapperImpl{},
+		symbolMapper:     &mapper.SymbolMapperImpl{},
 	}
 }
 
@@ -36,6 +40,7 @@ type SearchServiceImpl struct {
 	commitMapper     mapper.CommitMapper
 	tagMapper        mapper.TagMapper
 	pluginMapper     mapper.PluginMapper
+	symbolMapper     mapper.SymbolMapper
 }
 
 func (searchService *SearchServiceImpl) SearchUser(ctx context.Context, query string, offset, limit int, reverse bool) (model.Users, e.ResponseError) {
@@ -138,3 +143,39 @@ func (searchService *SearchServiceImpl) SearchDraft(ctx context.Context, reposit
 
 	return commits, nil
 }
+
+// SearchSymbol 搜索符号，同时返回符号所在的仓库，key为repository id
+func (searchService *SearchServiceImpl) SearchSymbol(ctx context.Context, userID, query, kind, packageName, repositoryID string, offset, limit int, reverse bool) (model.Symbols, map[string]*model.Repository, e.ResponseError) {
+	if kind != "" && !parser.IsSymbolKind(kind) {
+		return nil, nil, e.NewInvalidArgumentError(fmt.Sprintf("kind %s (must be message, field, enum, service or method)", kind))
+	}
+
+	symbols, err := searchService.symbolMapper.FindAccessiblePageByQuery(userID, &mapper.SymbolQuery{
+		Query:        query,
+		Kind:         kind,
+		PackageName:  packageName,
+		RepositoryID: repositoryID,
+	}, offset, limit, reverse)
+	if err != nil {
+		return nil, nil, e.NewInternalError(err.Error())
+	}
+
+	repositoryIDSet := map[string]struct{}{}
+	repositoryIDs := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		if _, ok := repositoryIDSet[symbol.RepositoryID]; !ok {
+			repositoryIDSet[symbol.RepositoryID] = struct{}{}
+			repositoryIDs = append(repositoryIDs, symbol.RepositoryID)
+		}
+	}
+	repositories, err := searchService.repositoryMapper.FindAllByRepositoryIDs(repositoryIDs)
+	if err != nil {
+		return nil, nil, e.NewInternalError(err.Error())
+	}
+	repositoryMap := make(map[string]*model.Repository, len(repositories))
+	for _, repository := range repositories {
+		repositoryMap[repository.RepositoryID] = repository
+	}
+
+	return symbols, repositoryMap, nil
+}
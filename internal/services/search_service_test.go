@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"github.com/ProtobufMan/bufman/internal/mapper"
+	"github.com/ProtobufMan/bufman/internal/model"
+	"github.com/bufbuild/connect-go"
+	"testing"
+)
+
+// testSearchSymbolMapper 记录查询条件，按照条件过滤符号
+type testSearchSymbolMapper struct {
+	mapper.SymbolMapper
+	symbols model.Symbols
+	query   *mapper.SymbolQuery
+	userID  string
+}
+
+func (symbolMapper *testSearchSymbolMapper) FindAccessiblePageByQuery(userID string, query *mapper.SymbolQuery, offset, limit int, reverse bool) (model.Symbols, error) {
+	symbolMapper.userID, symbolMapper.query = userID, query
+
+	var symbols model.Symbols
+	for _, symbol := range symbolMapper.symbols {
+		if query.Kind != "" && symbol.Kind != query.Kind {
+			continue
+		}
+		symbols = append(symbols, symbol)
+	}
+
+	return symbols, nil
+}
+
+func TestSearchSymbol(t *testing.T) {
+	symbolMapper := &testSearchSymbolMapper{symbols: model.Symbols{
+		{RepositoryID: "weather-id", Kind: "message", FullName: "weather.v1.Weather"},
+		{RepositoryID: "weather-id", Kind: "field", FullName: "weather.v1.Weather.temperature"},
+		{RepositoryID: "units-id", Kind: "message", FullName: "units.v1.Celsius"},
+	}}
+	repositoryMapper := newTestRepositoryMapper(
+		&model.Repository{RepositoryID: "weather-id", RepositoryName: "weather-id-name"},
+		&model.Repository{RepositoryID: "units-id", RepositoryName: "units-id-name"},
+	)
+	searchService := &SearchServiceImpl{symbolMapper: symbolMapper, repositoryMapper: repositoryMapper}
+
+	symbols, repositories, err := searchService.SearchSymbol(context.Background(), "alice-id", "weather_v1%", "message", "weather.v1", "", 0, 10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 查询条件原样传给mapper，由mapper负责转义
+	if symbolMapper.userID != "alice-id" || symbolMapper.query.Query != "weather_v1%" || symbolMapper.query.PackageName != "weather.v1" {
+		t.Errorf("unexpected query %s %+v", symbolMapper.userID, symbolMapper.query)
+	}
+	if len(symbols) != 2 {
+		t.Fatalf("expected two messages, got %d", len(symbols))
+	}
+
+	// 每个仓库只查询一次
+	if len(repositoryMapper.queried) != 2 {
+		t.Errorf("expected two repositories queried, got %v", repositoryMapper.queried)
+	}
+	for _, symbol := range symbols {
+		if repository, ok := repositories[symbol.RepositoryID]; !ok || repository.RepositoryName != symbol.RepositoryID+"-name" {
+			t.Errorf("missing repository for %s", symbol.FullName)
+		}
+	}
+}
+
+func TestSearchSymbolInvalidKind(t *testing.T) {
+	searchService := &SearchServiceImpl{symbolMapper: &testSearchSymbolMapper{}, repositoryMapper: newTestRepositoryMapper()}
+
+	_, _, err := searchService.SearchSymbol(context.Background(), "alice-id", "Weather", "oneof", "", "", 0, 10, false)
+	if err == nil || err.Code() != connect.CodeInvalidArgument {
+		t.Errorf("expected invalid argument, got %v", err)
+	}
+}
@@ -40,6 +40,8 @@ func (tagService *TagServiceImpl) CreateRepositoryTag(ctx context.Context, repos
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, e.NewNotFoundError("commit")
 		}
+
+		return nil, e.NewInternalError(registryv1alpha1connect.RepositoryTagServiceCreateRepositoryTagProcedure)
 	}
 
 	tag := &model.Tag{
@@ -53,6 +55,13 @@ func (tagService *TagServiceImpl) CreateRepositoryTag(ctx context.Context, repos
 	}
 	err = tagService.tagMapper.Create(tag)
 	if err != nil {
+		if errors.Is(err, mapper.ErrTagDuplicated) {
+			return nil, e.NewAlreadyExistsError("tag")
+		}
+		if errors.Is(err, mapper.ErrTagAndDraftDuplicated) {
+			return nil, e.NewAlreadyExistsError("draft with the same name")
+		}
+
 		return nil, e.NewInternalError(registryv1alpha1connect.RepositoryTagServiceCreateRepositoryTagProcedure)
 	}
 